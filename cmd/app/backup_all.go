@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+func newBackupAllCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	var (
+		opts    usecase.BackupAllOptions
+		dryRun  bool
+		verbose bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "backup-all [root...]",
+		Short: "Back up every enabled repository under the sweep roots",
+		Long: "Back up every enabled repository under the sweep roots.\n\n" +
+			"Repositories with backup.enabled=true are discovered below the given roots (default: [sweep] roots " +
+			"in config.toml) and backed up in parallel, at most [sweep] concurrency at a time. A repository whose " +
+			"backup lock is held is skipped. The summary lists every repository with the exit code a single " +
+			"backup would have returned; the command fails only when a repository backup failed.",
+		Run: func(cmd *cobra.Command, args []string) {
+			state, err := prepareCommand(cmd.Context(), depsFactory, verbose)
+			if err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			defer state.cleanup()
+			state.cfg.DryRun = dryRun
+			state.cfg.Trigger = "sweep"
+			opts.Roots = args
+			report, sweepErr := usecase.BackupAll(cmd.Context(), state.cfg, opts, state.deps, state.logger)
+			if report == nil {
+				handleCmdError(exitCode, sweepErr)
+				return
+			}
+			if _, err := fmt.Fprint(os.Stdout, usecase.FormatBackupAll(report, mapExitCode)); err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			handleCmdError(exitCode, sweepErr)
+		},
+	}
+
+	cmd.Flags().IntVarP(&opts.Concurrency, "jobs", "j", 0,
+		"repositories backed up in parallel (default: [sweep] concurrency)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "discover repositories and plan backups without changes")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+
+	return cmd
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+func newCheckIgnoreCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check-ignore PATH...",
+		Short: "Show which .devbackignore pattern excludes a path",
+		Long: "Show which .devbackignore pattern excludes a path.\n\n" +
+			".devbackignore files use gitignore syntax and may appear in any directory of the repository; " +
+			"~/.config/devback/ignore applies to every repository. For each PATH the deciding pattern is " +
+			"printed as <source>:<line>:<pattern><TAB><path>, like 'git check-ignore -v -n'. A pattern starting " +
+			"with ! keeps the path in backups; \"::\" means no pattern matched.",
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			logger := setupLogger(false)
+			deps := depsFactory(logger)
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				handleCmdError(exitCode, fmt.Errorf("resolve home dir: %w", usecase.ErrCritical))
+				return
+			}
+			opts := usecase.CheckIgnoreOptions{Paths: args, HomeDir: homeDir}
+			report, err := usecase.CheckIgnore(cmd.Context(), opts, deps, logger)
+			if err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			if _, err := fmt.Fprint(os.Stdout, usecase.FormatCheckIgnore(report)); err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			*exitCode = exitSuccess
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+func newConfigCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Read, change and validate config.toml",
+		Long: "Read, change and validate ~/.config/devback/config.toml.\n\n" +
+			"Keys are written as table.key, e.g. backup.keep_count; entries of [[targets]] and [[repo]] are " +
+			"numbered from 0, e.g. targets[0].bucket. set and unset rewrite the file with the standard " +
+			"comments, so comments added by hand are lost; edit keeps the file as written.",
+	}
+
+	cmd.AddCommand(newConfigGetCmd(depsFactory, exitCode))
+	cmd.AddCommand(newConfigSetCmd(depsFactory, exitCode))
+	cmd.AddCommand(newConfigUnsetCmd(depsFactory, exitCode))
+	cmd.AddCommand(newConfigEditCmd(depsFactory, exitCode))
+	cmd.AddCommand(newConfigValidateCmd(depsFactory, exitCode))
+	cmd.AddCommand(newConfigShowCmd(depsFactory, exitCode))
+
+	return cmd
+}
+
+func newConfigGetCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get KEY",
+		Short: "Print the value of a setting",
+		Long: "Print the value of a setting, or its default when config.toml does not set it. " +
+			"Lists are printed one item per line.",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			opts := usecase.ConfigOptions{Key: args[0]}
+			runConfigCmd(cmd.Context(), depsFactory, exitCode, &opts,
+				func(ctx context.Context, deps *usecase.Dependencies, _ *slog.Logger) (string, error) {
+					value, err := usecase.ConfigGet(ctx, opts, deps)
+					if err != nil || value == "" {
+						return "", err
+					}
+					return value + "\n", nil
+				})
+		},
+	}
+}
+
+func newConfigSetCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set KEY VALUE...",
+		Short: "Change a setting",
+		Long: "Change a setting and save config.toml if the result is valid. Lists such as sweep.roots take " +
+			"every remaining argument as an item; other settings take exactly one value.",
+		Args: cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			opts := usecase.ConfigOptions{Key: args[0], Values: args[1:]}
+			runConfigCmd(cmd.Context(), depsFactory, exitCode, &opts,
+				func(ctx context.Context, deps *usecase.Dependencies, logger *slog.Logger) (string, error) {
+					return "", usecase.ConfigSet(ctx, opts, deps, logger)
+				})
+		},
+	}
+
+	// Values such as -1 are arguments, not flags.
+	cmd.Flags().SetInterspersed(false)
+
+	return cmd
+}
+
+func newConfigUnsetCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	return &cobra.Command{
+		Use:   "unset KEY",
+		Short: "Restore the default of a setting",
+		Long: "Restore the default of a setting and save config.toml. A whole [[targets]] or [[repo]] " +
+			"entry such as targets[1] is removed.",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			opts := usecase.ConfigOptions{Key: args[0]}
+			runConfigCmd(cmd.Context(), depsFactory, exitCode, &opts,
+				func(ctx context.Context, deps *usecase.Dependencies, logger *slog.Logger) (string, error) {
+					return "", usecase.ConfigUnset(ctx, opts, deps, logger)
+				})
+		},
+	}
+}
+
+func newConfigEditCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit",
+		Short: "Edit config.toml in $VISUAL or $EDITOR",
+		Long: "Edit a copy of config.toml (config.edit.toml) in $VISUAL, $EDITOR or vi. The copy replaces " +
+			"config.toml only when it is valid; otherwise the problems are listed and the copy is kept, " +
+			"so the next edit continues where you left off.",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			var opts usecase.ConfigOptions
+			runConfigCmd(cmd.Context(), depsFactory, exitCode, &opts,
+				func(ctx context.Context, deps *usecase.Dependencies, logger *slog.Logger) (string, error) {
+					return "", usecase.ConfigEdit(ctx, opts, deps, logger, runEditor)
+				})
+		},
+	}
+}
+
+func newConfigValidateCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "Check config.toml for unknown keys and invalid values",
+		Long: "Check config.toml for syntax errors, unknown keys, values of the wrong type and values out of " +
+			"range. Every problem is listed with its line; the exit code is 2 when there is any.",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			var opts usecase.ConfigOptions
+			runConfigCmd(cmd.Context(), depsFactory, exitCode, &opts,
+				func(ctx context.Context, deps *usecase.Dependencies, _ *slog.Logger) (string, error) {
+					path, exists, err := usecase.ConfigValidate(ctx, opts, deps)
+					if err != nil {
+						return "", err
+					}
+					if !exists {
+						return fmt.Sprintf("%s does not exist, defaults are used\n", path), nil
+					}
+					return fmt.Sprintf("✓ %s is valid\n", path), nil
+				})
+		},
+	}
+}
+
+func newConfigShowCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	var opts usecase.ConfigOptions
+
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print all settings",
+		Long: "Print every setting of config.toml, with defaults for the keys it does not set. --effective " +
+			"applies the per-repository overrides of the current repository and names their source.",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runConfigCmd(cmd.Context(), depsFactory, exitCode, &opts,
+				func(ctx context.Context, deps *usecase.Dependencies, logger *slog.Logger) (string, error) {
+					return usecase.ConfigShow(ctx, opts, deps, logger)
+				})
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Effective, "effective", false, "apply the overrides of the current repository")
+
+	return cmd
+}
+
+// runConfigCmd fills in the home directory of opts, runs fn and prints its output.
+func runConfigCmd(
+	ctx context.Context,
+	depsFactory func(*slog.Logger) *usecase.Dependencies,
+	exitCode *int,
+	opts *usecase.ConfigOptions,
+	fn func(ctx context.Context, deps *usecase.Dependencies, logger *slog.Logger) (string, error),
+) {
+	logger := setupLogger(false)
+	deps := depsFactory(logger)
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		handleCmdError(exitCode, fmt.Errorf("resolve home dir: %w", usecase.ErrCritical))
+		return
+	}
+	opts.HomeDir = homeDir
+	out, err := fn(ctx, deps, logger)
+	if err != nil {
+		handleCmdError(exitCode, err)
+		return
+	}
+	if _, err := fmt.Fprint(os.Stdout, out); err != nil {
+		handleCmdError(exitCode, err)
+		return
+	}
+	*exitCode = exitSuccess
+}
+
+// runEditor opens path in $VISUAL, $EDITOR or vi and waits for it to exit.
+func runEditor(path string) error {
+	editor := strings.TrimSpace(os.Getenv("VISUAL"))
+	if editor == "" {
+		editor = strings.TrimSpace(os.Getenv("EDITOR"))
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := strings.Fields(editor)
+	cmd := exec.Command(args[0], append(args[1:], path)...) // #nosec G204 -- the editor is chosen by the user.
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+const defaultIdentityFile = "identity.txt"
+
+func newDecryptCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	var (
+		opts           usecase.RestoreOptions
+		identity       string
+		passphraseFile string
+		verbose        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "decrypt <snapshot>",
+		Short: "Decrypt an encrypted snapshot into a working repository",
+		Long: "Decrypt an encrypted snapshot into a working repository.\n\n" +
+			"<snapshot> is a snapshot ID (YYYY-MM-DD/HHMMSS-N) or \"latest\". Keys come from " +
+			"encryption.identity_file and encryption.passphrase_file unless overridden; without either " +
+			"the passphrase is read from the terminal.",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			state, err := prepareCommand(cmd.Context(), depsFactory, verbose)
+			if err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			defer state.cleanup()
+			opts.Snapshot = snapshotArg(args)
+			if err := applyDecryptKeys(&state.cfg.Encryption, identity, passphraseFile); err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			_, err = usecase.Decrypt(cmd.Context(), state.cfg, opts, state.deps, state.logger)
+			handleCmdError(exitCode, err)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Target, "to", "", "target directory for the decrypted repository")
+	cmd.Flags().StringVar(&identity, "identity", "", "identity file with the X25519 secret key")
+	cmd.Flags().StringVar(&passphraseFile, "passphrase-file", "", "file containing the passphrase")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "decrypt into a non-empty target")
+	cmd.Flags().BoolVar(&opts.RestoreExternal, "restore-external", false,
+		"copy the snapshot's external paths back to their original locations (existing files are kept)")
+	cmd.Flags().StringVar(&opts.RepoKey, "repo-key", "", "repository key (default: derived from current repository)")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+// applyDecryptKeys lets flags override configured keys and falls back to a passphrase prompt.
+func applyDecryptKeys(settings *usecase.EncryptionSettings, identity, passphraseFile string) error {
+	if identity != "" || passphraseFile != "" {
+		settings.IdentityFile = identity
+		settings.PassphraseFile = passphraseFile
+	}
+	if settings.IdentityFile != "" || settings.PassphraseFile != "" {
+		return nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("no identity or passphrase configured (use --identity or --passphrase-file): %w",
+			usecase.ErrUsage)
+	}
+	fmt.Fprint(os.Stderr, "Passphrase: ")
+	pass, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return fmt.Errorf("read passphrase: %v: %w", err, usecase.ErrUsage)
+	}
+	if len(pass) == 0 {
+		return fmt.Errorf("empty passphrase: %w", usecase.ErrUsage)
+	}
+	settings.Passphrase = string(pass)
+	return nil
+}
+
+func newKeygenCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	var opts usecase.KeygenOptions
+
+	cmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Generate an X25519 identity for snapshot encryption",
+		Long: "Generate an X25519 identity for snapshot encryption.\n\n" +
+			"The secret key is written to --output (default ~/.config/devback/" + defaultIdentityFile + ") " +
+			"and the public recipient is printed; add it to encryption.recipients.",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			logger := setupLogger(false)
+			if opts.Output == "" {
+				homeDir, err := os.UserHomeDir()
+				if err != nil {
+					handleCmdError(exitCode, fmt.Errorf("resolve home dir: %v: %w", err, usecase.ErrCritical))
+					return
+				}
+				opts.Output = filepath.Join(homeDir, ".config", "devback", defaultIdentityFile)
+			}
+			recipient, err := usecase.Keygen(cmd.Context(), opts, depsFactory(logger), logger)
+			if err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			_, err = fmt.Fprintln(os.Stdout, recipient)
+			handleCmdError(exitCode, err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", "", "identity file to write")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "overwrite an existing identity file")
+
+	return cmd
+}
@@ -10,6 +10,7 @@ const (
 	exitCriticalError = 1
 	exitLockBusy      = 76
 	exitUsageError    = 2
+	exitVerifyFailed  = 65
 	exitInterrupted   = 130
 )
 
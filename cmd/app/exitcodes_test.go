@@ -12,6 +12,7 @@ func TestExitCodes(t *testing.T) {
 		{"exitCriticalError", exitCriticalError, 1},
 		{"exitLockBusy", exitLockBusy, 76},
 		{"exitUsageError", exitUsageError, 2},
+		{"exitVerifyFailed", exitVerifyFailed, 65},
 		{"exitInterrupted", exitInterrupted, 130},
 	}
 
@@ -26,6 +26,7 @@ type hookPreflight struct {
 	gitDir     string
 	configFile usecase.ConfigFile
 	runtimeCfg *usecase.Config
+	trigger    string
 	cleanup    func()
 }
 
@@ -44,6 +44,7 @@ func runHookPostCommit(
 		return exitSuccess
 	}
 	defer preflight.cleanup()
+	preflight.trigger = "post-commit"
 	if ctx.Err() != nil {
 		return exitSuccess
 	}
@@ -88,6 +89,8 @@ func runBackupWithNotify(ctx context.Context, hookCfg *hookConfig, preflight *ho
 	cfg.BackupDir = preflight.runtimeCfg.BackupDir
 	cfg.Verbose = hookCfg.verbose
 	cfg.DryRun = hookCfg.dryRun
+	cfg.Trigger = preflight.trigger
+	cfg.Version = version
 
 	result, err := usecase.Backup(ctx, cfg, preflight.deps, preflight.logger)
 	if err != nil {
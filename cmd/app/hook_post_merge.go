@@ -40,6 +40,7 @@ func runHookPostMerge(
 		return exitSuccess
 	}
 	defer preflight.cleanup()
+	preflight.trigger = "post-merge"
 	if ctx.Err() != nil {
 		return exitSuccess
 	}
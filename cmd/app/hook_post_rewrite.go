@@ -56,6 +56,7 @@ func runHookPostRewrite(
 		return exitSuccess
 	}
 	defer preflight.cleanup()
+	preflight.trigger = "post-rewrite"
 	if ctx.Err() != nil {
 		return exitSuccess
 	}
@@ -110,6 +111,8 @@ func runPostRewriteBackup(
 	cfg.BackupDir = preflight.runtimeCfg.BackupDir
 	cfg.Verbose = hookCfg.verbose
 	cfg.DryRun = hookCfg.dryRun
+	cfg.Trigger = preflight.trigger
+	cfg.Version = version
 
 	result, err := usecase.Backup(ctx, cfg, preflight.deps, preflight.logger)
 	if err != nil {
@@ -34,6 +34,10 @@ func (m *mockGitPort) ConfigGet(ctx context.Context, repoPath, key string) (stri
 	return lookupConfig(m.localConfig, key)
 }
 
+func (m *mockGitPort) ConfigGetLocal(ctx context.Context, repoPath, key string) (string, error) {
+	return lookupConfig(m.localConfig, key)
+}
+
 func (m *mockGitPort) ConfigGetGlobal(ctx context.Context, key string) (string, error) {
 	return lookupConfig(m.globalConfig, key)
 }
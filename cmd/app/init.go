@@ -17,6 +17,7 @@ func newInitCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *
 		force         bool
 		noGitConfig   bool
 		templatesOnly bool
+		uninstall     bool
 		dryRun        bool
 	)
 
@@ -42,6 +43,7 @@ func newInitCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *
 				Force:         force,
 				NoGitConfig:   noGitConfig,
 				TemplatesOnly: templatesOnly,
+				Uninstall:     uninstall,
 				DryRun:        dryRun,
 				HomeDir:       homeDir,
 				BinaryPath:    filepath.Clean(exePath),
@@ -60,7 +62,14 @@ func newInitCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *
 	)
 	cmd.Flags().BoolVar(&noGitConfig, "no-gitconfig", false, "skip global git config change")
 	cmd.Flags().BoolVar(&templatesOnly, "templates-only", false, "install/update templates only")
+	cmd.Flags().BoolVar(
+		&uninstall, "uninstall", false,
+		"remove templates and unset init.templateDir if it points at them (keeps config and backups)",
+	)
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "plan changes without writing to disk")
+	cmd.MarkFlagsMutuallyExclusive("uninstall", "backup-dir")
+	cmd.MarkFlagsMutuallyExclusive("uninstall", "force")
+	cmd.MarkFlagsMutuallyExclusive("uninstall", "templates-only")
 
 	_ = cmd.RegisterFlagCompletionFunc("backup-dir",
 		func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
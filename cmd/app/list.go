@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+func newListCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	var (
+		opts    usecase.ListOptions
+		asJSON  bool
+		verbose bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List snapshots of the current or all repositories",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			state, err := prepareCommand(cmd.Context(), depsFactory, verbose)
+			if err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			defer state.cleanup()
+			report, err := usecase.List(cmd.Context(), state.cfg, opts, state.deps, state.logger)
+			if err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			if asJSON {
+				data, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					handleCmdError(exitCode, fmt.Errorf("encode json: %w", usecase.ErrCritical))
+					return
+				}
+				_, err = fmt.Fprintln(os.Stdout, string(data))
+				handleCmdError(exitCode, err)
+				return
+			}
+			_, err = fmt.Fprint(os.Stdout, usecase.FormatList(report, time.Now()))
+			handleCmdError(exitCode, err)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.AllRepos, "all-repos", false, "list snapshots of every repository under backup.base_dir")
+	cmd.Flags().StringVar(&opts.RepoKey, "repo-key", "", "repository key (default: derived from current repository)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print machine-readable JSON")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	cmd.MarkFlagsMutuallyExclusive("all-repos", "repo-key")
+
+	return cmd
+}
@@ -81,10 +81,30 @@ func newRootCmd(
 	cmd.Flags().BoolVar(&cfg.DryRun, "dry-run", false, "full dry-run (no filesystem changes)")
 	cmd.Flags().BoolVar(&cfg.PrintRepoKey, "print-repo-key", false, "print repository key and exit (no backup)")
 	cmd.Flags().BoolVar(&cfg.TestLocks, "test-locks", false, "test enhanced lock system and exit")
+	cmd.Flags().BoolVar(&cfg.Pin, "pin", false, "pin the new snapshot so rotation never removes it")
+	cmd.Flags().StringVar(&cfg.Label, "label", "", "label recorded in the snapshot manifest (and pin)")
+	cmd.Flags().BoolVar(&cfg.AllowLarge, "allow-large", false,
+		"include ignored/untracked files skipped by the size and type guards")
 
 	cmd.AddCommand(newInitCmd(depsFactory, &exitCode))
 	cmd.AddCommand(newSetupCmd(depsFactory, &exitCode))
+	cmd.AddCommand(newTeardownCmd(depsFactory, &exitCode))
 	cmd.AddCommand(newStatusCmd(depsFactory, &exitCode))
+	cmd.AddCommand(newBackupAllCmd(depsFactory, &exitCode))
+	cmd.AddCommand(newWatchCmd(depsFactory, &exitCode))
+	cmd.AddCommand(newScheduleCmd(depsFactory, &exitCode))
+	cmd.AddCommand(newRemoteCmd(depsFactory, &exitCode))
+	cmd.AddCommand(newListCmd(depsFactory, &exitCode))
+	cmd.AddCommand(newRestoreCmd(depsFactory, &exitCode))
+	cmd.AddCommand(newDecryptCmd(depsFactory, &exitCode))
+	cmd.AddCommand(newKeygenCmd(depsFactory, &exitCode))
+	cmd.AddCommand(newVerifyCmd(depsFactory, &exitCode))
+	cmd.AddCommand(newPinCmd(depsFactory, &exitCode))
+	cmd.AddCommand(newUnpinCmd(depsFactory, &exitCode))
+	cmd.AddCommand(newMigrateCmd(depsFactory, &exitCode))
+	cmd.AddCommand(newReposCmd(depsFactory, &exitCode))
+	cmd.AddCommand(newCheckIgnoreCmd(depsFactory, &exitCode))
+	cmd.AddCommand(newConfigCmd(depsFactory, &exitCode))
 	cmd.AddCommand(newHookCmd(depsFactory, &exitCode))
 	cmd.AddCommand(newVersionCmd())
 
@@ -112,6 +132,10 @@ func runRootCommand(
 	defer cleanup()
 	logger = fileLogger
 	logger.Info("Starting devback application")
+	if cfg.Trigger == "" {
+		cfg.Trigger = "manual"
+	}
+	cfg.Version = version
 	if !cfg.TestLocks && !cfg.PrintRepoKey && cfg.BackupDir == "" {
 		fmt.Fprintln(os.Stderr, "backup.base_dir not configured (run: devback init --backup-dir <path>)")
 		return exitUsageError
@@ -152,6 +176,37 @@ func initRootState(
 	}, nil
 }
 
+// commandState holds the runtime config and dependencies shared by subcommands.
+type commandState struct {
+	cfg     *usecase.Config
+	deps    *usecase.Dependencies
+	logger  *slog.Logger
+	cleanup func()
+}
+
+// prepareCommand loads config and logging for subcommands that operate on backups.
+func prepareCommand(
+	ctx context.Context,
+	depsFactory func(*slog.Logger) *usecase.Dependencies,
+	verbose bool,
+) (commandState, error) {
+	logger := setupLogger(verbose)
+	state, err := initRootState(ctx, depsFactory, logger)
+	if err != nil {
+		return commandState{}, err
+	}
+	cfg := &usecase.Config{Verbose: verbose, Version: version}
+	if state.configExists {
+		applyBackupConfig(cfg, state.backupCfg)
+	}
+	if cfg.BackupDir == "" {
+		return commandState{}, fmt.Errorf(
+			"backup.base_dir not configured (run: devback init --backup-dir <path>): %w", usecase.ErrUsage)
+	}
+	fileLogger, cleanup := withFileLogging(logger, state.configFile.Logging, verbose)
+	return commandState{cfg: cfg, deps: state.deps, logger: fileLogger, cleanup: cleanup}, nil
+}
+
 func executeRootAction(
 	cfg *usecase.Config,
 	deps *usecase.Dependencies,
@@ -187,6 +242,8 @@ func mapExitCode(err error) int {
 		return exitLockBusy
 	case errors.Is(err, usecase.ErrInterrupted):
 		return exitInterrupted
+	case errors.Is(err, usecase.ErrVerifyFailed):
+		return exitVerifyFailed
 	default:
 		return exitCriticalError
 	}
@@ -213,7 +270,7 @@ func loadConfigFile(
 	}
 	cfg, err := deps.Config.Load(ctx, configPath)
 	if err != nil {
-		return usecase.ConfigFile{}, false, fmt.Errorf("load config: %w", usecase.ErrCritical)
+		return usecase.ConfigFile{}, false, usecase.ConfigLoadError(err)
 	}
 	return cfg, exists, nil
 }
@@ -225,12 +282,35 @@ func applyBackupConfig(target, source *usecase.Config) {
 	target.BackupDir = source.BackupDir
 	target.KeepCount = source.KeepCount
 	target.KeepDays = source.KeepDays
+	target.KeepHourly = source.KeepHourly
+	target.KeepDaily = source.KeepDaily
+	target.KeepWeekly = source.KeepWeekly
+	target.KeepMonthly = source.KeepMonthly
+	target.KeepYearly = source.KeepYearly
 	target.MaxTotalGBPerRepo = source.MaxTotalGBPerRepo
 	target.SizeMarginMB = source.SizeMarginMB
 	target.RepoKeyStyle = source.RepoKeyStyle
 	target.AutoRemoteMerge = source.AutoRemoteMerge
 	target.RemoteHashLen = source.RemoteHashLen
 	target.NoSize = source.NoSize
+	target.Dedup = source.Dedup
+	target.Format = source.Format
+	target.TrackedChanges = source.TrackedChanges
+	target.MaxFileMB = source.MaxFileMB
+	target.MaxUntrackedMB = source.MaxUntrackedMB
+	target.SkipExtensions = source.SkipExtensions
+	target.Encryption = source.Encryption
+	target.SweepRoots = source.SweepRoots
+	target.SweepConcurrency = source.SweepConcurrency
+	target.SweepMaxDepth = source.SweepMaxDepth
+	target.WatchDebounce = source.WatchDebounce
+	target.WatchMinInterval = source.WatchMinInterval
+	target.WatchKeepCount = source.WatchKeepCount
+	target.WatchKeepHours = source.WatchKeepHours
+	target.Targets = source.Targets
+	target.RepoRules = source.RepoRules
+	target.GlobalIgnoreFile = source.GlobalIgnoreFile
+	target.HomeDir = source.HomeDir
 }
 
 func setupLogger(verbose bool) *slog.Logger {
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+func newMigrateCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	var (
+		opts    usecase.MigrateOptions
+		verbose bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Move snapshots from one repository key to another",
+		Long: "Move snapshots from one repository key to another.\n\n" +
+			"Every completed snapshot under backup.base_dir/<from> is moved to backup.base_dir/<to> while " +
+			"both backup locks are held. Snapshots whose ID already exists under <to> are left in place. " +
+			"Backups also migrate snapshots of the current repository from previous keys automatically.",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			state, err := prepareCommand(cmd.Context(), depsFactory, verbose)
+			if err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			defer state.cleanup()
+			_, err = usecase.Migrate(cmd.Context(), state.cfg, opts, state.deps, state.logger)
+			handleCmdError(exitCode, err)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.From, "from", "", "repository key to move snapshots from")
+	cmd.Flags().StringVar(&opts.To, "to", "", "repository key to move snapshots to")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "show what would be moved without changes")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+func newPinCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	var (
+		opts    usecase.PinOptions
+		verbose bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "pin [snapshot]",
+		Short: "Pin a snapshot so rotation never removes it",
+		Long: "Pin a snapshot so rotation never removes it.\n\n" +
+			"[snapshot] is a snapshot ID (YYYY-MM-DD/HHMMSS-N) or \"latest\" (default). The pin is stored as " +
+			"a .pinned marker in the snapshot directory; keep_days, keep_count, GFS rules and max_total_gb " +
+			"all skip pinned snapshots. Use 'devback --pin --label NAME' to create a pinned snapshot.",
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			state, err := prepareCommand(cmd.Context(), depsFactory, verbose)
+			if err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			defer state.cleanup()
+			opts.Snapshot = snapshotArg(args)
+			_, err = usecase.Pin(cmd.Context(), state.cfg, opts, state.deps, state.logger)
+			handleCmdError(exitCode, err)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Label, "label", "", "label stored with the pin")
+	cmd.Flags().StringVar(&opts.RepoKey, "repo-key", "", "repository key (default: derived from current repository)")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+
+	return cmd
+}
+
+func newUnpinCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	var (
+		opts    usecase.PinOptions
+		verbose bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "unpin [snapshot]",
+		Short: "Remove the pin from a snapshot",
+		Long: "Remove the pin from a snapshot so rotation may remove it again.\n\n" +
+			"[snapshot] is a snapshot ID (YYYY-MM-DD/HHMMSS-N) or \"latest\" (default).",
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			state, err := prepareCommand(cmd.Context(), depsFactory, verbose)
+			if err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			defer state.cleanup()
+			opts.Snapshot = snapshotArg(args)
+			_, err = usecase.Unpin(cmd.Context(), state.cfg, opts, state.deps, state.logger)
+			handleCmdError(exitCode, err)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.RepoKey, "repo-key", "", "repository key (default: derived from current repository)")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+
+	return cmd
+}
+
+// snapshotArg returns the snapshot ID argument; "latest" and no argument select the latest snapshot.
+func snapshotArg(args []string) string {
+	if len(args) == 0 || args[0] == "latest" {
+		return ""
+	}
+	return args[0]
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+func newRemoteCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remote",
+		Short: "Copy snapshots to and from the [[targets]] in config.toml",
+		Long: "Copy completed snapshots to the remote targets configured as [[targets]] in config.toml.\n\n" +
+			"After every backup the new snapshot is uploaded by a background `devback remote push`; " +
+			"snapshots it missed (target unreachable, interrupted upload) are uploaded by the next push. " +
+			"Each target applies its own keep_count and keep_days.",
+	}
+
+	cmd.AddCommand(newRemotePushCmd(depsFactory, exitCode))
+	cmd.AddCommand(newRemoteListCmd(depsFactory, exitCode))
+	cmd.AddCommand(newRemotePullCmd(depsFactory, exitCode))
+
+	return cmd
+}
+
+func newRemotePushCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	var (
+		opts    usecase.RemoteOptions
+		baseDir string
+		verbose bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "push",
+		Short: "Upload snapshots missing on the targets and apply remote retention",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			state, err := prepareCommand(cmd.Context(), depsFactory, verbose)
+			if err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			defer state.cleanup()
+			if baseDir != "" {
+				state.cfg.BackupDir = baseDir
+			}
+			report, err := usecase.RemotePush(cmd.Context(), state.cfg, opts, state.deps, state.logger)
+			if report != nil && len(report.Results) > 0 {
+				if _, werr := fmt.Fprint(os.Stdout, usecase.FormatRemotePush(report)); werr != nil && err == nil {
+					err = werr
+				}
+			}
+			handleCmdError(exitCode, err)
+		},
+	}
+
+	addRemoteFlags(cmd, &opts, &verbose, true)
+	cmd.Flags().StringVar(&baseDir, "base-dir", "", "snapshot base directory (default: backup.base_dir)")
+
+	return cmd
+}
+
+func newRemoteListCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	var (
+		opts    usecase.RemoteOptions
+		asJSON  bool
+		verbose bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List snapshots stored on the targets",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			state, err := prepareCommand(cmd.Context(), depsFactory, verbose)
+			if err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			defer state.cleanup()
+			report, err := usecase.RemoteList(cmd.Context(), state.cfg, opts, state.deps, state.logger)
+			if err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			if asJSON {
+				data, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					handleCmdError(exitCode, fmt.Errorf("encode json: %w", usecase.ErrCritical))
+					return
+				}
+				_, err = fmt.Fprintln(os.Stdout, string(data))
+				handleCmdError(exitCode, err)
+				return
+			}
+			_, err = fmt.Fprint(os.Stdout, usecase.FormatRemoteList(report, time.Now()))
+			handleCmdError(exitCode, err)
+		},
+	}
+
+	addRemoteFlags(cmd, &opts, &verbose, true)
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print machine-readable JSON")
+
+	return cmd
+}
+
+func newRemotePullCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	var (
+		opts    usecase.RemoteOptions
+		verbose bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "pull <snapshot>",
+		Short: "Download a snapshot from a target into the local store",
+		Long: "Download a completed snapshot (YYYY-MM-DD/HHMMSS or HHMMSS) from the first target holding it " +
+			"into backup.base_dir, where restore, verify and list treat it like any other snapshot.",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			state, err := prepareCommand(cmd.Context(), depsFactory, verbose)
+			if err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			defer state.cleanup()
+			opts.Snapshot = args[0]
+			result, err := usecase.RemotePull(cmd.Context(), state.cfg, opts, state.deps, state.logger)
+			if err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			_, err = fmt.Fprintf(os.Stdout, "Pulled %s from %s (%d files) → %s\n",
+				result.SnapshotID, result.Target, result.Files, result.SnapshotDir)
+			handleCmdError(exitCode, err)
+		},
+	}
+
+	addRemoteFlags(cmd, &opts, &verbose, false)
+
+	return cmd
+}
+
+func addRemoteFlags(cmd *cobra.Command, opts *usecase.RemoteOptions, verbose *bool, allRepos bool) {
+	cmd.Flags().StringVar(&opts.Target, "target", "", "only use the [[targets]] entry with this name")
+	cmd.Flags().StringVar(&opts.RepoKey, "repo-key", "", "repository key (default: derived from current repository)")
+	cmd.Flags().BoolVarP(verbose, "verbose", "v", false, "verbose output")
+	if allRepos {
+		cmd.Flags().BoolVar(&opts.AllRepos, "all-repos", false, "every repository under backup.base_dir")
+		cmd.MarkFlagsMutuallyExclusive("all-repos", "repo-key")
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+func newReposCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	var opts usecase.ReposOptions
+
+	cmd := &cobra.Command{
+		Use:   "repos",
+		Short: "List and re-check repositories registered by setup",
+		Long: "List and re-check repositories registered by setup.\n\n" +
+			"devback setup records every repository in ~/.local/share/devback/repos.json. This command checks " +
+			"each entry again: whether it is still a git repository, whether backup is enabled and hooks are " +
+			"installed, its current repository key and the time of its last snapshot. Entries whose path is " +
+			"gone are reported as stale; --prune removes them and refreshes the recorded keys.",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			logger := setupLogger(false)
+			deps := depsFactory(logger)
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				handleCmdError(exitCode, fmt.Errorf("resolve home dir: %w", usecase.ErrCritical))
+				return
+			}
+			opts.HomeDir = homeDir
+			report, err := usecase.Repos(cmd.Context(), opts, deps, logger)
+			if err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			if _, err := fmt.Fprint(os.Stdout, usecase.FormatRepos(report)); err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			*exitCode = exitSuccess
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Prune, "prune", false, "remove stale entries and refresh recorded repository keys")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "with --prune, show what would be removed without changes")
+
+	return cmd
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+func newRestoreCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	var (
+		opts    usecase.RestoreOptions
+		verbose bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore a snapshot into a working repository",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			state, err := prepareCommand(cmd.Context(), depsFactory, verbose)
+			if err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			defer state.cleanup()
+			state.cfg.DryRun = opts.DryRun
+			_, err = usecase.Restore(cmd.Context(), state.cfg, opts, state.deps, state.logger)
+			handleCmdError(exitCode, err)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Snapshot, "snapshot", "", "snapshot ID to restore (YYYY-MM-DD/HHMMSS-N)")
+	cmd.Flags().BoolVar(&opts.Latest, "latest", false, "restore the latest completed snapshot (default)")
+	cmd.Flags().StringVar(&opts.Target, "to", "", "target directory for the restored repository")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "restore into a non-empty target")
+	cmd.Flags().BoolVar(&opts.RestoreExternal, "restore-external", false,
+		"copy the snapshot's external paths back to their original locations (existing files are kept)")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "show what would be restored without changes")
+	cmd.Flags().StringVar(&opts.RepoKey, "repo-key", "", "repository key (default: derived from current repository)")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	cmd.MarkFlagsMutuallyExclusive("snapshot", "latest")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
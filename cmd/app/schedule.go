@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+func newScheduleCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Run backup-all periodically with systemd or launchd",
+		Long: "Run devback backup-all periodically, so repositories without recent commits still get snapshots " +
+			"of their untracked state.\n\n" +
+			"On Linux a systemd user service and timer (devback-backup.service/.timer) are installed in " +
+			"~/.config/systemd/user; on macOS a launch agent is installed in ~/Library/LaunchAgents. " +
+			"backup-all backs up the enabled repositories under [sweep] roots in config.toml.",
+	}
+
+	cmd.AddCommand(newScheduleInstallCmd(depsFactory, exitCode))
+	cmd.AddCommand(newScheduleStatusCmd(depsFactory, exitCode))
+	cmd.AddCommand(newScheduleRemoveCmd(depsFactory, exitCode))
+
+	return cmd
+}
+
+func newScheduleInstallCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	var opts usecase.ScheduleOptions
+
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install or update the periodic backup-all job",
+		Long: "Install or update the periodic backup-all job.\n\n" +
+			"The job runs this devback binary with the current PATH. Re-running install replaces the job, " +
+			"e.g. to change the interval. --dry-run prints the generated files without installing them.",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			logger := setupLogger(false)
+			deps := depsFactory(logger)
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				handleCmdError(exitCode, fmt.Errorf("resolve home dir: %w", usecase.ErrCritical))
+				return
+			}
+			exe, err := os.Executable()
+			if err != nil {
+				handleCmdError(exitCode, fmt.Errorf("resolve devback executable: %w", usecase.ErrCritical))
+				return
+			}
+			opts.HomeDir = homeDir
+			opts.Executable = exe
+			opts.Path = os.Getenv("PATH")
+			result, err := usecase.InstallSchedule(cmd.Context(), opts, deps, logger)
+			if err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			if _, err := fmt.Fprint(os.Stdout, usecase.FormatScheduleResult(result)); err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			*exitCode = exitSuccess
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Every, "every", "1h", "interval between runs, e.g. 30m, 1h, 6h or 1d")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "print the generated files without installing them")
+
+	return cmd
+}
+
+func newScheduleStatusCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the periodic backup-all job",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			logger := setupLogger(false)
+			info, err := usecase.ScheduleStatus(cmd.Context(), depsFactory(logger), logger)
+			if err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			if _, err := fmt.Fprint(os.Stdout, usecase.FormatSchedule(info)); err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			*exitCode = exitSuccess
+		},
+	}
+}
+
+func newScheduleRemoveCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove",
+		Short: "Stop and remove the periodic backup-all job",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			logger := setupLogger(false)
+			_, err := usecase.RemoveSchedule(cmd.Context(), depsFactory(logger), logger)
+			handleCmdError(exitCode, err)
+		},
+	}
+}
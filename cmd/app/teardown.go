@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+func newTeardownCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "teardown",
+		Short: "Undo setup in current repository",
+		Long: "Undo devback setup in the current repository.\n\n" +
+			"Hooks merged by setup are replaced with the originals kept in *.devback.orig, hooks installed " +
+			"by devback alone are removed, backup.enabled and backup.slug are unset (including config.worktree) " +
+			"and the repository is dropped from the registry. Snapshots are kept.",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			logger := setupLogger(false)
+			deps := depsFactory(logger)
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				handleCmdError(exitCode, fmt.Errorf("resolve home dir: %w", usecase.ErrCritical))
+				return
+			}
+			opts := usecase.TeardownOptions{DryRun: dryRun, HomeDir: homeDir}
+			_, err = usecase.Teardown(cmd.Context(), opts, deps, logger)
+			handleCmdError(exitCode, err)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "plan changes without writing to disk")
+
+	return cmd
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+func newVerifyCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	var (
+		opts    usecase.VerifyOptions
+		asJSON  bool
+		verbose bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check snapshot checksums and git object connectivity",
+		Long: "Check snapshot checksums and git object connectivity.\n\n" +
+			"Every file is compared with checksums.json recorded at backup time and " +
+			"'git fsck --connectivity-only' runs against the snapshot's .git. Without --snapshot or --all " +
+			"the latest snapshot is verified. Broken snapshots exit with code 65; --quarantine marks them " +
+			"so rotation keeps them aside and never removes the last good snapshot in their place.",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			state, err := prepareCommand(cmd.Context(), depsFactory, verbose)
+			if err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			defer state.cleanup()
+			report, verifyErr := usecase.Verify(cmd.Context(), state.cfg, opts, state.deps, state.logger)
+			if report == nil {
+				handleCmdError(exitCode, verifyErr)
+				return
+			}
+			if asJSON {
+				data, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					handleCmdError(exitCode, fmt.Errorf("encode json: %w", usecase.ErrCritical))
+					return
+				}
+				_, err = fmt.Fprintln(os.Stdout, string(data))
+				if err != nil {
+					handleCmdError(exitCode, err)
+					return
+				}
+			} else if _, err := fmt.Fprint(os.Stdout, usecase.FormatVerify(report)); err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			handleCmdError(exitCode, verifyErr)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Snapshot, "snapshot", "", "snapshot ID to verify (YYYY-MM-DD/HHMMSS-N)")
+	cmd.Flags().BoolVar(&opts.All, "all", false, "verify every snapshot of the repository")
+	cmd.Flags().BoolVar(&opts.Quarantine, "quarantine", false, "mark broken snapshots as quarantined")
+	cmd.Flags().StringVar(&opts.RepoKey, "repo-key", "", "repository key (default: derived from current repository)")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print machine-readable JSON")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	cmd.MarkFlagsMutuallyExclusive("snapshot", "all")
+
+	return cmd
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+func newWatchCmd(depsFactory func(*slog.Logger) *usecase.Dependencies, exitCode *int) *cobra.Command {
+	var verbose bool
+
+	cmd := &cobra.Command{
+		Use:   "watch [repo...]",
+		Short: "Snapshot repositories when uncommitted changes settle",
+		Long: "Watch repositories for filesystem changes and snapshot uncommitted work.\n\n" +
+			"Without arguments every repository registered by devback setup with backup.enabled=true is " +
+			"watched. After [watch] debounce_seconds without changes (and at most once per " +
+			"[watch] min_interval_minutes) a snapshot tagged trigger=watch is taken with the usual lock and " +
+			"rotation. Watch snapshots are rotated by the [watch] keep_count and keep_hours limits and never " +
+			"push commit snapshots out of backup.keep_count. Changes in .git, the backup directory and paths " +
+			"matched by .devbackignore are ignored. Runs in the foreground until interrupted.",
+		Run: func(cmd *cobra.Command, args []string) {
+			state, err := prepareCommand(cmd.Context(), depsFactory, verbose)
+			if err != nil {
+				handleCmdError(exitCode, err)
+				return
+			}
+			defer state.cleanup()
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				handleCmdError(exitCode, fmt.Errorf("resolve home dir: %w", usecase.ErrCritical))
+				return
+			}
+			opts := usecase.WatchOptions{Repos: args, HomeDir: homeDir}
+			handleCmdError(exitCode, usecase.Watch(cmd.Context(), state.cfg, opts, state.deps, state.logger))
+		},
+	}
+
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+
+	return cmd
+}
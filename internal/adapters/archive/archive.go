@@ -0,0 +1,293 @@
+package archive
+
+import (
+	"archive/tar"
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+// Adapter implements ArchivePort with archive/tar and an in-process zstd codec.
+type Adapter struct {
+	logger *slog.Logger
+}
+
+// New creates a new archive adapter.
+func New(logger *slog.Logger) *Adapter {
+	if logger == nil {
+		panic("archive adapter requires logger")
+	}
+	return &Adapter{logger: logger}
+}
+
+// Create opens a new archive at path. For tar.zst the stream is compressed with zstd;
+// with a sealer the final stream is encrypted before it reaches the file.
+func (a *Adapter) Create(
+	ctx context.Context,
+	archivePath,
+	format string,
+	sealer usecase.Sealer,
+) (usecase.ArchiveWriter, error) {
+	_ = ctx
+	if format != usecase.SnapshotFormatTar && format != usecase.SnapshotFormatTarZst {
+		return nil, fmt.Errorf("unsupported archive format %q", format)
+	}
+	// #nosec G304 - path is controlled by usecase
+	file, err := os.OpenFile(archivePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	w := &writer{file: file}
+	var sink io.Writer = file
+	if sealer != nil {
+		if w.seal, err = sealer.SealWriter(file); err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("start encryption: %w", err)
+		}
+		sink = w.seal
+	}
+	if format == usecase.SnapshotFormatTarZst {
+		if w.zw, err = zstd.NewWriter(sink); err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("start zstd: %w", err)
+		}
+		sink = w.zw
+	}
+	w.buf = bufio.NewWriterSize(sink, 1<<20)
+	w.tw = tar.NewWriter(w.buf)
+	return w, nil
+}
+
+// Extract unpacks the archive at archivePath into dir and returns the number of regular files written.
+// Symlinks are created last so no entry is ever written through a link from the archive.
+func (a *Adapter) Extract(ctx context.Context, archivePath, format, dir string, opener usecase.Opener) (int, error) {
+	file, err := os.Open(archivePath) // #nosec G304 - path is controlled by usecase
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = file.Close() // Ignore close error in defer
+	}()
+
+	var src io.Reader = file
+	if opener != nil {
+		if src, err = opener.OpenReader(file); err != nil {
+			return 0, fmt.Errorf("decrypt archive: %w", err)
+		}
+	}
+	switch format {
+	case usecase.SnapshotFormatTar:
+	case usecase.SnapshotFormatTarZst:
+		zr, err := zstd.NewReader(src)
+		if err != nil {
+			return 0, fmt.Errorf("zstd: %w", err)
+		}
+		defer zr.Close()
+		src = zr
+	default:
+		return 0, fmt.Errorf("unsupported archive format %q", format)
+	}
+	return extractTar(ctx, tar.NewReader(src), dir)
+}
+
+type pendingLink struct {
+	target string
+	path   string
+}
+
+func extractTar(ctx context.Context, tr *tar.Reader, dir string) (int, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return 0, err
+	}
+	var files int
+	var links []pendingLink
+	for {
+		if ctx.Err() != nil {
+			return files, ctx.Err()
+		}
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return files, fmt.Errorf("read archive: %w", err)
+		}
+		dst, err := entryPath(dir, hdr.Name)
+		if err != nil {
+			return files, err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(dst, entryMode(hdr, 0o700)); err != nil {
+				return files, err
+			}
+		case tar.TypeReg:
+			if err := extractFile(tr, hdr, dst); err != nil {
+				return files, err
+			}
+			files++
+		case tar.TypeSymlink:
+			links = append(links, pendingLink{target: hdr.Linkname, path: dst})
+		default:
+			continue
+		}
+	}
+	for _, l := range links {
+		if err := os.MkdirAll(filepath.Dir(l.path), 0o750); err != nil {
+			return files, err
+		}
+		_ = os.RemoveAll(l.path)
+		if err := os.Symlink(l.target, l.path); err != nil {
+			return files, err
+		}
+	}
+	return files, nil
+}
+
+func extractFile(tr *tar.Reader, hdr *tar.Header, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return err
+	}
+	_ = os.Remove(dst)
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, entryMode(hdr, 0)) // #nosec G304 - path is validated
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, tr); err != nil { // #nosec G110 - archives are produced by devback
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(dst, entryMode(hdr, 0)); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, hdr.ModTime, hdr.ModTime)
+}
+
+// entryPath resolves an archive entry name inside dir, rejecting absolute and escaping names.
+func entryPath(dir, name string) (string, error) {
+	rel := path.Clean(strings.TrimSuffix(name, "/"))
+	if rel == "." || rel == ".." || path.IsAbs(rel) || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("invalid archive entry %q", name)
+	}
+	return filepath.Join(dir, filepath.FromSlash(rel)), nil
+}
+
+func entryMode(hdr *tar.Header, extra fs.FileMode) fs.FileMode {
+	return fs.FileMode(hdr.Mode).Perm() | extra // #nosec G115 - masked to permission bits
+}
+
+type writer struct {
+	mu   sync.Mutex
+	file *os.File
+	buf  *bufio.Writer
+	tw   *tar.Writer
+	zw   *zstd.Encoder
+	seal io.WriteCloser
+}
+
+// AddDir appends a directory entry.
+func (w *writer) AddDir(name string, info usecase.FileInfo) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     strings.TrimSuffix(name, "/") + "/",
+		Mode:     int64(info.Mode() & 0o7777),
+		ModTime:  info.ModTime(),
+	})
+}
+
+// AddSymlink appends a symlink entry.
+func (w *writer) AddSymlink(name, target string, info usecase.FileInfo) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeSymlink,
+		Name:     name,
+		Linkname: target,
+		Mode:     int64(info.Mode() & 0o7777),
+		ModTime:  info.ModTime(),
+	})
+}
+
+// AddFile appends the content of src. The size is taken from the open file; if the file
+// shrinks while it is read the entry is zero-padded and an error is returned, so the archive
+// itself stays readable.
+func (w *writer) AddFile(ctx context.Context, name, src string, info usecase.FileInfo) error {
+	in, err := os.Open(src) // #nosec G304 - path is controlled by usecase
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = in.Close() // Ignore close error in defer
+	}()
+	st, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     st.Size(),
+		Mode:     int64(info.Mode() & 0o7777),
+		ModTime:  st.ModTime(),
+	}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	n, err := io.CopyN(w.tw, in, st.Size())
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, io.EOF) {
+		return err
+	}
+	if _, padErr := io.CopyN(w.tw, zeroReader{}, st.Size()-n); padErr != nil {
+		return padErr
+	}
+	return fmt.Errorf("%s: file shrank while archiving", src)
+}
+
+// Close finishes the archive and flushes the compressor.
+func (w *writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	errs := []error{w.tw.Close(), w.buf.Flush()}
+	if w.zw != nil {
+		errs = append(errs, w.zw.Close())
+	}
+	if w.seal != nil {
+		errs = append(errs, w.seal.Close())
+	}
+	errs = append(errs, w.file.Sync(), w.file.Close())
+	return errors.Join(errs...)
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
@@ -0,0 +1,178 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+type fileInfo struct {
+	os.FileInfo
+}
+
+func (f fileInfo) Mode() int          { return int(f.FileInfo.Mode()) }
+func (f fileInfo) IsSymlink() bool    { return f.FileInfo.Mode()&os.ModeSymlink != 0 }
+func (f fileInfo) IsRegular() bool    { return f.FileInfo.Mode().IsRegular() }
+func (f fileInfo) ModTime() time.Time { return f.FileInfo.ModTime() }
+
+func lstat(t *testing.T, path string) usecase.FileInfo {
+	t.Helper()
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fileInfo{info}
+}
+
+func TestAdapter_RoundTrip(t *testing.T) {
+	for _, format := range []string{usecase.SnapshotFormatTar, usecase.SnapshotFormatTarZst} {
+		t.Run(format, func(t *testing.T) {
+			ctx := context.Background()
+			adapter := New(slog.Default())
+			src := t.TempDir()
+			if err := os.MkdirAll(filepath.Join(src, "dir"), 0o750); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(src, "dir", "file.txt"), []byte("payload"), 0o640); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Symlink("file.txt", filepath.Join(src, "dir", "link")); err != nil {
+				t.Fatal(err)
+			}
+
+			archivePath := filepath.Join(t.TempDir(), "snapshot."+format)
+			w, err := adapter.Create(ctx, archivePath, format, nil)
+			if err != nil {
+				t.Fatalf("create: %v", err)
+			}
+			file := filepath.Join(src, "dir", "file.txt")
+			steps := []error{
+				w.AddDir("dir", lstat(t, filepath.Join(src, "dir"))),
+				w.AddFile(ctx, "dir/file.txt", file, lstat(t, file)),
+				w.AddSymlink("dir/link", "file.txt", lstat(t, filepath.Join(src, "dir", "link"))),
+				w.Close(),
+			}
+			for i, err := range steps {
+				if err != nil {
+					t.Fatalf("step %d: %v", i, err)
+				}
+			}
+
+			if format == usecase.SnapshotFormatTarZst {
+				// A standard zstd frame, so the zstd command can unpack it as well.
+				raw, err := os.ReadFile(archivePath)
+				if err != nil || !bytes.HasPrefix(raw, []byte{0x28, 0xb5, 0x2f, 0xfd}) {
+					t.Fatalf("expected a zstd frame (%v)", err)
+				}
+			}
+
+			dst := t.TempDir()
+			files, err := adapter.Extract(ctx, archivePath, format, dst, nil)
+			if err != nil || files != 1 {
+				t.Fatalf("extract: files=%d err=%v", files, err)
+			}
+			data, err := os.ReadFile(filepath.Join(dst, "dir", "link"))
+			if err != nil || string(data) != "payload" {
+				t.Fatalf("unexpected content via symlink: %q (%v)", data, err)
+			}
+			info, err := os.Stat(filepath.Join(dst, "dir", "file.txt"))
+			if err != nil || info.Mode().Perm() != 0o640 {
+				t.Fatalf("unexpected mode: %v (%v)", info, err)
+			}
+		})
+	}
+}
+
+func TestEntryPath_RejectsEscapes(t *testing.T) {
+	for _, name := range []string{"../evil", "/abs", "a/../../evil", ".", ""} {
+		if _, err := entryPath("/dst", name); err == nil {
+			t.Fatalf("expected %q to be rejected", name)
+		}
+	}
+	got, err := entryPath("/dst", ".git/HEAD")
+	if err != nil || got != filepath.Join("/dst", ".git", "HEAD") {
+		t.Fatalf("unexpected path %q (%v)", got, err)
+	}
+}
+
+func TestAdapter_CreateRejectsUnknownFormat(t *testing.T) {
+	adapter := New(slog.Default())
+	if _, err := adapter.Create(context.Background(), filepath.Join(t.TempDir(), "x"), "zip", nil); err == nil {
+		t.Fatal("expected error for unsupported format")
+	}
+}
+
+// xorCipher is a reversible stand-in for the encryption adapter.
+type xorCipher struct{}
+
+func (xorCipher) Scheme() string                                      { return "xor" }
+func (xorCipher) PassphraseKDF() string                               { return "" }
+func (xorCipher) SealFile(ctx context.Context, src, dst string) error { return nil }
+func (xorCipher) OpenFile(ctx context.Context, src, dst string) error { return nil }
+func (xorCipher) SealWriter(w io.Writer) (io.WriteCloser, error)      { return xorWriter{w}, nil }
+func (xorCipher) OpenReader(r io.Reader) (io.Reader, error)           { return xorReader{r}, nil }
+
+type xorWriter struct{ w io.Writer }
+
+func (x xorWriter) Write(p []byte) (int, error) {
+	buf := make([]byte, len(p))
+	for i, b := range p {
+		buf[i] = b ^ 0x5a
+	}
+	return x.w.Write(buf)
+}
+
+func (x xorWriter) Close() error { return nil }
+
+type xorReader struct{ r io.Reader }
+
+func (x xorReader) Read(p []byte) (int, error) {
+	n, err := x.r.Read(p)
+	for i := range p[:n] {
+		p[i] ^= 0x5a
+	}
+	return n, err
+}
+
+func TestAdapter_SealedRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	adapter := New(slog.Default())
+	src := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(src, []byte("payload"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	archivePath := filepath.Join(t.TempDir(), "snapshot.tar")
+	w, err := adapter.Create(ctx, archivePath, usecase.SnapshotFormatTar, xorCipher{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.AddFile(ctx, "file.txt", src, lstat(t, src)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(archivePath)
+	if err != nil || bytes.Contains(raw, []byte("payload")) {
+		t.Fatalf("expected sealed archive (%v)", err)
+	}
+	if _, err := adapter.Extract(ctx, archivePath, usecase.SnapshotFormatTar, t.TempDir(), nil); err == nil {
+		t.Fatal("expected sealed archive to be unreadable without opener")
+	}
+	dst := t.TempDir()
+	files, err := adapter.Extract(ctx, archivePath, usecase.SnapshotFormatTar, dst, xorCipher{})
+	if err != nil || files != 1 {
+		t.Fatalf("extract: files=%d err=%v", files, err)
+	}
+	data, err := os.ReadFile(filepath.Join(dst, "file.txt"))
+	if err != nil || string(data) != "payload" {
+		t.Fatalf("unexpected content %q (%v)", data, err)
+	}
+}
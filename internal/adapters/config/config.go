@@ -6,10 +6,9 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strconv"
 	"strings"
 
-	"github.com/BurntSushi/toml"
-
 	"github.com/arumata/devback/internal/usecase"
 )
 
@@ -26,7 +25,8 @@ func New(logger *slog.Logger) *Adapter {
 	return &Adapter{logger: logger}
 }
 
-// Load reads config from path or returns defaults when file is missing.
+// Load reads config from path or returns defaults when file is missing. Unknown keys, wrong
+// types and invalid values are reported together as a *usecase.ConfigError.
 func (a *Adapter) Load(ctx context.Context, path string) (usecase.ConfigFile, error) {
 	_ = ctx
 	if strings.TrimSpace(path) == "" {
@@ -42,13 +42,39 @@ func (a *Adapter) Load(ctx context.Context, path string) (usecase.ConfigFile, er
 	}
 
 	cfg := usecase.DefaultConfigFile()
-	if _, err := toml.Decode(string(data), &cfg); err != nil {
-		return usecase.ConfigFile{}, fmt.Errorf("parse config toml: %w", err)
+	index, issues, err := decodeStrict(path, string(data), &cfg)
+	if err != nil {
+		return usecase.ConfigFile{}, err
+	}
+	if err := validateConfig(path, cfg, index, issues); err != nil {
+		return usecase.ConfigFile{}, err
 	}
 
 	return cfg, nil
 }
 
+// LoadRepo reads a repository .devback.toml; a missing file yields an empty RepoConfigFile.
+// Unknown keys and wrong types are reported as a *usecase.ConfigError.
+func (a *Adapter) LoadRepo(ctx context.Context, path string) (usecase.RepoConfigFile, error) {
+	_ = ctx
+	var cfg usecase.RepoConfigFile
+	data, err := os.ReadFile(path) // #nosec G304 - path is controlled by usecase
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	_, issues, err := decodeStrict(path, string(data), &cfg)
+	if err != nil {
+		return usecase.RepoConfigFile{}, err
+	}
+	if len(issues) > 0 {
+		return usecase.RepoConfigFile{}, &usecase.ConfigError{Path: path, Issues: issues}
+	}
+	return cfg, nil
+}
+
 // Save writes config to path in TOML format with inline documentation.
 func (a *Adapter) Save(ctx context.Context, path string, cfg usecase.ConfigFile) error {
 	_ = ctx
@@ -56,7 +82,7 @@ func (a *Adapter) Save(ctx context.Context, path string, cfg usecase.ConfigFile)
 		return errors.New("config path is empty")
 	}
 
-	content := renderCommentedTOML(cfg)
+	content := renderCommentedTOML(cfg) + renderTargets(cfg.Targets) + renderRepos(cfg.Repos)
 
 	// #nosec G306 G304 - config is not secret, path is controlled by usecase.
 	return os.WriteFile(path, []byte(content), 0o644)
@@ -81,6 +107,16 @@ keep_count = %[2]d
 # Maximum snapshot age in days.
 keep_days = %[3]d
 
+# Generational (grandfather-father-son) retention: keep the newest snapshot of
+# each of the last N hours, days, ISO weeks, months and years (0 disables a rule).
+# Snapshots kept by these rules are never removed by keep_count or keep_days;
+# max_total_gb still applies. Preview with: devback --dry-run
+keep_hourly = %[20]d
+keep_daily = %[21]d
+keep_weekly = %[22]d
+keep_monthly = %[23]d
+keep_yearly = %[24]d
+
 # Maximum total size (GB) of snapshots per repository.
 # Ignored when no_size = true.
 max_total_gb = %[4]d
@@ -92,23 +128,46 @@ size_margin_mb = %[5]d
 # When true, max_total_gb and size_margin_mb are ignored.
 no_size = %[6]t
 
+# Hardlink files unchanged since the previous snapshot instead of copying them.
+# Size-based rotation counts shared files once.
+dedup = %[7]t
+
+# Snapshot layout: "dir" (plain directory), "tar" or "tar.zst" (single archive,
+# zstd-compressed). Restore unpacks archives automatically.
+format = %[8]q
+
+# Capture tracked files with unstaged changes (staged changes are kept by the
+# copied index): "off", "copy" (copies under _tracked/files/ in the snapshot) or
+# "patch" (_tracked/worktree.patch and _tracked/index.patch from git diff --binary).
+# Restore reapplies them on top of the worktree rebuilt from the index.
+tracked_changes = %[32]q
+
+# Guards against ignored/untracked files bloating every snapshot (VM images,
+# database dumps). Files larger than max_file_mb, files whose name ends with
+# an entry of skip_binary_extensions, and - once the ignored/untracked total
+# exceeds max_untracked_total_mb - the largest remaining files are skipped with
+# a warning. 0 or [] disables a guard; devback --allow-large bypasses them all.
+max_file_mb = %[33]d
+max_untracked_total_mb = %[34]d
+skip_binary_extensions = %[35]s
+
 # ── Desktop Notifications ────────────────────────────────────────
 [notifications]
 
 # Enable notifications after backup completion.
-enabled = %[7]t
+enabled = %[9]t
 
 # Notification sound ("default" = system default).
-sound = %[8]q
+sound = %[10]q
 
 # ── Logging ──────────────────────────────────────────────────────
 [logging]
 
 # Log directory. Supports ~, $HOME, ${HOME}. Created automatically.
-dir = %[9]q
+dir = %[11]q
 
 # Minimum log level: debug, info, warn, error.
-level = %[10]q
+level = %[12]q
 
 # ── Repository Key ───────────────────────────────────────────────
 [repo_key]
@@ -117,13 +176,57 @@ level = %[10]q
 #   auto             - auto-detect: slug, remote, or name+hash (default)
 #   custom           - uses backup.slug (set via: devback setup --slug)
 #   remote-hierarchy - host/owner/repo from remote.origin.url
-style = %[11]q
+style = %[13]q
 
 # Merge snapshots from clones sharing the same remote.origin.url.
-auto_remote_merge = %[12]t
+auto_remote_merge = %[14]t
 
 # Hash suffix length for remote-hierarchy style.
-remote_hash_len = %[13]d
+remote_hash_len = %[15]d
+
+# ── Encryption ───────────────────────────────────────────────────
+[encryption]
+
+# Encrypt snapshot files client-side as they are written.
+# Decrypt with: devback decrypt <snapshot> --to <dir>
+enabled = %[16]t
+
+# Public age X25519 recipients, age1... (create a key pair with: devback keygen).
+recipients = %[17]s
+
+# File holding a passphrase (first line), applied with age's scrypt.
+passphrase_file = %[18]q
+
+# Secret identity used by devback decrypt (written by devback keygen).
+identity_file = %[19]q
+
+# ── Backup Sweep ─────────────────────────────────────────────────
+[sweep]
+
+# Directories searched by devback backup-all for repositories with
+# backup.enabled=true. Supports ~, $HOME, ${HOME}.
+roots = %[25]s
+
+# Number of repositories backed up in parallel.
+concurrency = %[26]d
+
+# How many directory levels below each root are searched.
+max_depth = %[27]d
+
+# ── Watch Daemon ─────────────────────────────────────────────────
+[watch]
+
+# Seconds without filesystem changes before devback watch takes a snapshot.
+debounce_seconds = %[28]d
+
+# Minimum minutes between two watch snapshots of the same repository.
+min_interval_minutes = %[29]d
+
+# Snapshots taken by devback watch (trigger=watch) are rotated by these
+# limits only; they never count toward backup.keep_count, keep_days or the
+# generational rules. max_total_gb still applies. 0 disables a limit.
+keep_count = %[30]d
+keep_hours = %[31]d
 `,
 		cfg.Backup.BaseDir,
 		cfg.Backup.KeepCount,
@@ -131,6 +234,8 @@ remote_hash_len = %[13]d
 		cfg.Backup.MaxTotalGB,
 		cfg.Backup.SizeMarginMB,
 		cfg.Backup.NoSize,
+		cfg.Backup.Dedup,
+		cfg.Backup.Format,
 		cfg.Notifications.Enabled,
 		cfg.Notifications.Sound,
 		cfg.Logging.Dir,
@@ -138,5 +243,134 @@ remote_hash_len = %[13]d
 		cfg.RepoKey.Style,
 		cfg.RepoKey.AutoRemoteMerge,
 		cfg.RepoKey.RemoteHashLen,
+		cfg.Encryption.Enabled,
+		tomlStringArray(cfg.Encryption.Recipients),
+		cfg.Encryption.PassphraseFile,
+		cfg.Encryption.IdentityFile,
+		cfg.Backup.KeepHourly,
+		cfg.Backup.KeepDaily,
+		cfg.Backup.KeepWeekly,
+		cfg.Backup.KeepMonthly,
+		cfg.Backup.KeepYearly,
+		tomlStringArray(cfg.Sweep.Roots),
+		cfg.Sweep.Concurrency,
+		cfg.Sweep.MaxDepth,
+		cfg.Watch.DebounceSeconds,
+		cfg.Watch.MinIntervalMinutes,
+		cfg.Watch.KeepCount,
+		cfg.Watch.KeepHours,
+		cfg.Backup.TrackedChanges,
+		cfg.Backup.MaxFileMB,
+		cfg.Backup.MaxUntrackedTotalMB,
+		tomlStringArray(cfg.Backup.SkipBinaryExtensions),
 	)
 }
+
+// renderTargets renders the [[targets]] entries; array tables must follow every plain table.
+func renderTargets(targets []usecase.TargetConfig) string {
+	var b strings.Builder
+	b.WriteString(`
+# ── Remote Targets ───────────────────────────────────────────────
+
+# Completed snapshots are uploaded to every [[targets]] entry by a background
+# devback remote push after each backup, and pruned there with the entry's own
+# keep_count and keep_days (0 disables a limit; the newest snapshot is kept).
+# type = "s3": S3-compatible storage (AWS S3, MinIO, ...). Credentials are read
+# from credentials_file (access_key_id / secret_access_key lines) or from
+# AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+# type = "sftp": a directory on an SSH server, url = "user@host:/path" (or
+# "sftp://user@host:port/path"). Keys come from ssh-agent, identity_file or
+# ~/.ssh/id_*; the host key must be in known_hosts_file (~/.ssh/known_hosts).
+# type = "dir": a directory on a second disk, path = "/media/usb/devback". The
+# directory must exist; while it is missing (disk not mounted) or a server is
+# unreachable, snapshots stay queued and are uploaded by the next push.
+# verify = "size" (default) compares stored sizes, "hash" reads every stored
+# file back and compares its SHA-256 before the snapshot is marked done.
+#
+# [[targets]]
+# name = "offsite"
+# type = "s3"
+# endpoint = "https://s3.eu-central-1.amazonaws.com"
+# bucket = "devback"
+# prefix = "laptop"
+# region = "eu-central-1"
+# credentials_file = "~/.config/devback/s3-credentials"
+# keep_count = 90
+# keep_days = 365
+#
+# [[targets]]
+# name = "nas"
+# type = "sftp"
+# url = "backup@nas.local:/srv/devback"
+# keep_count = 200
+#
+# [[targets]]
+# name = "usb"
+# type = "dir"
+# path = "/media/usb/devback"
+# verify = "hash"
+`)
+	for _, t := range targets {
+		b.WriteString("\n[[targets]]\n")
+		for _, kv := range [][2]string{
+			{"name", t.Name}, {"type", t.Type}, {"endpoint", t.Endpoint}, {"bucket", t.Bucket},
+			{"prefix", t.Prefix}, {"region", t.Region}, {"credentials_file", t.CredentialsFile},
+			{"url", t.URL}, {"identity_file", t.IdentityFile}, {"known_hosts_file", t.KnownHostsFile},
+			{"path", t.Path}, {"verify", t.Verify},
+		} {
+			if kv[1] != "" {
+				fmt.Fprintf(&b, "%s = %q\n", kv[0], kv[1])
+			}
+		}
+		fmt.Fprintf(&b, "keep_count = %d\nkeep_days = %d\n", t.KeepCount, t.KeepDays)
+	}
+	return b.String()
+}
+
+func tomlStringArray(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// renderRepos renders the [[repo]] override entries; only the fields an entry sets are written.
+func renderRepos(repos []usecase.RepoConfig) string {
+	var b strings.Builder
+	b.WriteString(`
+# ── Per-Repository Overrides ─────────────────────────────────────
+
+# [[repo]] entries override base_dir, keep_count, keep_days, max_total_gb and
+# format of [backup] for the repositories whose origin remote (host/owner/repo)
+# or root path matches the glob in match. Later entries win over earlier ones.
+# Precedence, lowest first: [backup], the repository's .devback.toml, matching
+# [[repo]] entries, git config (backup.baseDir, backup.keepCount,
+# backup.keepDays, backup.maxTotalGb, backup.format).
+# devback status shows the effective values and where they come from.
+#
+# [[repo]]
+# match = "github.com/acme/*"
+# keep_count = 100
+# base_dir = "/mnt/big/devback"
+`)
+	for _, r := range repos {
+		b.WriteString("\n[[repo]]\n")
+		fmt.Fprintf(&b, "match = %q\n", r.Match)
+		if r.BaseDir != nil {
+			fmt.Fprintf(&b, "base_dir = %q\n", *r.BaseDir)
+		}
+		for _, kv := range []struct {
+			key   string
+			value *int
+		}{{"keep_count", r.KeepCount}, {"keep_days", r.KeepDays}, {"max_total_gb", r.MaxTotalGB}} {
+			if kv.value != nil {
+				fmt.Fprintf(&b, "%s = %d\n", kv.key, *kv.value)
+			}
+		}
+		if r.Format != nil {
+			fmt.Fprintf(&b, "format = %q\n", *r.Format)
+		}
+	}
+	return b.String()
+}
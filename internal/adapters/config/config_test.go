@@ -2,6 +2,7 @@ package config
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -34,12 +35,21 @@ func TestAdapter_SaveAndLoad(t *testing.T) {
 
 	original := usecase.ConfigFile{
 		Backup: usecase.BackupConfig{
-			BaseDir:      "/backup",
-			KeepCount:    15,
-			KeepDays:     60,
-			MaxTotalGB:   5,
-			SizeMarginMB: 12,
-			NoSize:       false,
+			BaseDir:              "/backup",
+			KeepCount:            15,
+			KeepDays:             60,
+			KeepHourly:           24,
+			KeepDaily:            7,
+			KeepWeekly:           4,
+			KeepMonthly:          12,
+			KeepYearly:           2,
+			MaxTotalGB:           5,
+			SizeMarginMB:         12,
+			NoSize:               false,
+			TrackedChanges:       "patch",
+			MaxFileMB:            256,
+			MaxUntrackedTotalMB:  2048,
+			SkipBinaryExtensions: []string{".qcow2", ".sql.gz"},
 		},
 		Notifications: usecase.NotificationsConfig{
 			Enabled: false,
@@ -54,6 +64,40 @@ func TestAdapter_SaveAndLoad(t *testing.T) {
 			AutoRemoteMerge: true,
 			RemoteHashLen:   12,
 		},
+		Encryption: usecase.EncryptionConfig{
+			Enabled:        true,
+			Recipients:     []string{"age1abc", "age1def"},
+			PassphraseFile: "~/.config/devback/passphrase",
+			IdentityFile:   "~/.config/devback/identity.txt",
+		},
+		Sweep: usecase.SweepConfig{
+			Roots:       []string{"~/src", "/work"},
+			Concurrency: 4,
+			MaxDepth:    3,
+		},
+		Watch: usecase.WatchConfig{
+			DebounceSeconds:    10,
+			MinIntervalMinutes: 2,
+			KeepCount:          50,
+			KeepHours:          12,
+		},
+		Targets: []usecase.TargetConfig{
+			{Name: "offsite", Type: "s3", Bucket: "devback", Prefix: "laptop", KeepCount: 30},
+			{Type: "s3", Endpoint: "http://127.0.0.1:9000", Bucket: "minio", Region: "us-east-1", KeepDays: 7},
+			{
+				Name: "nas", Type: "sftp", URL: "backup@nas:/srv/devback", IdentityFile: "~/.ssh/id_backup",
+				KnownHostsFile: "~/.ssh/known_hosts",
+			},
+			{Name: "usb", Type: "dir", Path: "/media/usb/devback", Verify: "hash", KeepCount: 200},
+		},
+		Repos: []usecase.RepoConfig{
+			{Match: "github.com/acme/*", BackupOverrides: usecase.BackupOverrides{
+				BaseDir:   strPtr("/mnt/big"),
+				KeepCount: intPtr(100),
+				Format:    strPtr("tar"),
+			}},
+			{Match: "~/scratch/*", BackupOverrides: usecase.BackupOverrides{KeepDays: intPtr(0), MaxTotalGB: intPtr(1)}},
+		},
 	}
 
 	if err := adapter.Save(context.Background(), path, original); err != nil {
@@ -91,10 +135,16 @@ func TestAdapter_SaveProducesCommentedTOML(t *testing.T) {
 		"# ── Desktop Notifications",
 		"# ── Logging",
 		"# ── Repository Key",
+		"# ── Encryption",
+		"# ── Backup Sweep",
+		"# ── Watch Daemon",
 		"[backup]",
 		"[notifications]",
 		"[logging]",
 		"[repo_key]",
+		"[encryption]",
+		"[sweep]",
+		"[watch]",
 	} {
 		if !strings.Contains(content, marker) {
 			t.Errorf("expected config to contain %q", marker)
@@ -116,3 +166,114 @@ func TestAdapter_LoadInvalidTOML(t *testing.T) {
 		t.Fatal("expected error for invalid toml")
 	}
 }
+
+func TestAdapter_LoadReportsIssues(t *testing.T) {
+	t.Parallel()
+	adapter := New(slog.Default())
+	path := filepath.Join(t.TempDir(), "config.toml")
+	data := strings.Join([]string{
+		"[backup]",
+		"keep_count = -5",
+		"colour = \"red\"",
+		"",
+		"[logging]",
+		"level = \"verbose\"",
+		"",
+		"[[targets]]",
+		"type = \"dir\"",
+		"path = \"/mnt/a\"",
+		"",
+		"[[targets]]",
+		"type = \"s3\"",
+		"bucket = \"b\"",
+		"buckt = \"b\"",
+		"",
+		"[extra]",
+		"a = 1",
+	}, "\n")
+	// #nosec G306 - test data does not require restrictive permissions.
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	_, err := adapter.Load(context.Background(), path)
+	if !errors.Is(err, usecase.ErrUsage) {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+	var cfgErr *usecase.ConfigError
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("expected *usecase.ConfigError, got %T", err)
+	}
+	var got []string
+	for _, issue := range cfgErr.Issues {
+		got = append(got, issue.String())
+	}
+	want := []string{
+		"line 2: backup.keep_count must not be negative, got -5",
+		"line 3: unknown key backup.colour",
+		`line 6: logging.level "verbose" is not supported (use debug, info, warn or error)`,
+		"line 15: unknown key targets[1].buckt",
+		"line 17: unknown key extra",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected issues:\n%s", strings.Join(got, "\n"))
+	}
+
+	// #nosec G306 - test data does not require restrictive permissions.
+	if err := os.WriteFile(path, []byte("[backup]\n\nkeep_count = \"many\"\n"), 0o644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+	_, err = adapter.Load(context.Background(), path)
+	if !errors.As(err, &cfgErr) || len(cfgErr.Issues) != 1 || cfgErr.Issues[0].Line != 3 {
+		t.Fatalf("expected a type error on line 3, got %v", err)
+	}
+}
+
+func TestAdapter_LoadRepo(t *testing.T) {
+	t.Parallel()
+	adapter := New(slog.Default())
+	dir := t.TempDir()
+
+	cfg, err := adapter.LoadRepo(context.Background(), filepath.Join(dir, ".devback.toml"))
+	if err != nil {
+		t.Fatalf("unexpected error for missing file: %v", err)
+	}
+	if !reflect.DeepEqual(cfg, usecase.RepoConfigFile{}) {
+		t.Fatalf("expected empty overrides, got %+v", cfg)
+	}
+
+	path := filepath.Join(dir, "repo.toml")
+	// #nosec G306 - test data does not require restrictive permissions.
+	if err := os.WriteFile(path, []byte("[backup]\nkeep_count = 5\nformat = \"tar\"\n"), 0o644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+	cfg, err = adapter.LoadRepo(context.Background(), path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := usecase.BackupOverrides{KeepCount: intPtr(5), Format: strPtr("tar")}
+	if !reflect.DeepEqual(cfg.Backup, want) {
+		t.Fatalf("unexpected overrides: %+v", cfg.Backup)
+	}
+
+	// #nosec G306 - test data does not require restrictive permissions.
+	if err := os.WriteFile(path, []byte("[backup]\nkeep_count = \"many\"\n"), 0o644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+	if _, err := adapter.LoadRepo(context.Background(), path); err == nil {
+		t.Fatal("expected error for invalid value")
+	}
+
+	// #nosec G306 - test data does not require restrictive permissions.
+	if err := os.WriteFile(path, []byte("[backup]\nkeep_cnt = 5\n"), 0o644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+	if _, err := adapter.LoadRepo(context.Background(), path); err == nil ||
+		!strings.Contains(err.Error(), "line 2: unknown key backup.keep_cnt") {
+		t.Fatalf("expected unknown key error, got %v", err)
+	}
+}
+
+func intPtr(v int) *int { return &v }
+
+func strPtr(v string) *string { return &v }
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+// decodeErrorPattern matches the type errors of toml.Decode, which are not ParseErrors.
+//
+//nolint:gochecknoglobals // compiled once.
+var decodeErrorPattern = regexp.MustCompile(`^toml: (?:line (\d+) )?\(last key "([^"]*)"\): (.*)$`)
+
+// decodeStrict decodes data into v. Syntax errors and wrong types stop decoding and are returned
+// as a *usecase.ConfigError; unknown keys are returned as issues with their lines.
+func decodeStrict(path, data string, v any) (*keyIndex, []usecase.ConfigIssue, error) {
+	md, err := toml.Decode(data, v)
+	if err != nil {
+		return nil, nil, &usecase.ConfigError{Path: path, Issues: []usecase.ConfigIssue{decodeIssue(err)}}
+	}
+	index := newKeyIndex(data)
+	var issues []usecase.ConfigIssue
+	undecoded := map[string]bool{}
+	for _, key := range md.Undecoded() {
+		undecoded[key.String()] = true
+		if len(key) > 1 && undecoded[key[:len(key)-1].String()] {
+			continue // reported with its table.
+		}
+		name, line := index.next(key.String())
+		issues = append(issues, usecase.ConfigIssue{
+			Key:     name,
+			Line:    line,
+			Message: fmt.Sprintf("unknown key %s", name),
+		})
+	}
+	return index, issues, nil
+}
+
+func decodeIssue(err error) usecase.ConfigIssue {
+	var parseErr toml.ParseError
+	if errors.As(err, &parseErr) {
+		return usecase.ConfigIssue{Key: parseErr.LastKey, Line: parseErr.Position.Line, Message: parseErr.Message}
+	}
+	if m := decodeErrorPattern.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return usecase.ConfigIssue{Key: m[2], Line: line, Message: m[2] + ": " + m[3]}
+	}
+	return usecase.ConfigIssue{Message: strings.TrimPrefix(err.Error(), "toml: ")}
+}
+
+// keyIndex records the line every key of a TOML document is defined on. Entries of arrays of
+// tables are numbered (targets[1].bucket); a table maps to its header line.
+type keyIndex struct {
+	lines map[string]int
+	// keys lists every key in document order, for MetaData keys, which have no entry numbers.
+	keys []indexedKey
+	used map[int]bool
+}
+
+type indexedKey struct {
+	name  string
+	plain string
+	line  int
+}
+
+func newKeyIndex(data string) *keyIndex {
+	index := &keyIndex{lines: map[string]int{}, used: map[int]bool{}}
+	table, plainTable := "", ""
+	entries := map[string]int{}
+	for i, raw := range strings.Split(data, "\n") {
+		line := strings.TrimSpace(raw)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "[["):
+			end := strings.Index(line, "]]")
+			if end < 0 {
+				continue
+			}
+			plainTable = normalizeKey(line[2:end])
+			table = fmt.Sprintf("%s[%d]", plainTable, entries[plainTable])
+			entries[plainTable]++
+		case strings.HasPrefix(line, "["):
+			end := strings.Index(line, "]")
+			if end < 0 {
+				continue
+			}
+			plainTable = normalizeKey(line[1:end])
+			table = plainTable
+		default:
+			eq := strings.Index(line, "=")
+			if eq <= 0 {
+				continue
+			}
+			key := normalizeKey(line[:eq])
+			index.add(joinKey(table, key), joinKey(plainTable, key), i+1)
+			continue
+		}
+		index.add(table, plainTable, i+1)
+	}
+	return index
+}
+
+func (k *keyIndex) add(name, plain string, line int) {
+	if _, ok := k.lines[name]; !ok {
+		k.lines[name] = line
+	}
+	k.keys = append(k.keys, indexedKey{name: name, plain: plain, line: line})
+}
+
+// next returns the numbered name and line of the first not yet returned occurrence of a key
+// without entry numbers.
+func (k *keyIndex) next(plain string) (string, int) {
+	for _, key := range k.keys {
+		if key.plain == plain && !k.used[key.line] {
+			k.used[key.line] = true
+			return key.name, key.line
+		}
+	}
+	return plain, 0
+}
+
+// line returns the line of key, or of the closest enclosing table when key is not in the file.
+func (k *keyIndex) line(key string) int {
+	for key != "" {
+		if line, ok := k.lines[key]; ok {
+			return line
+		}
+		dot := strings.LastIndex(key, ".")
+		if dot < 0 {
+			break
+		}
+		key = key[:dot]
+	}
+	return 0
+}
+
+// normalizeKey strips spaces and quotes from the parts of a dotted key.
+func normalizeKey(key string) string {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
+
+func joinKey(table, key string) string {
+	if table == "" {
+		return key
+	}
+	return table + "." + key
+}
+
+// validateConfig adds the range errors of cfg, with the lines from index, to issues and returns
+// them all as a *usecase.ConfigError.
+func validateConfig(path string, cfg usecase.ConfigFile, index *keyIndex, issues []usecase.ConfigIssue) error {
+	for _, issue := range usecase.ValidateConfigFile(cfg) {
+		issue.Line = index.line(issue.Key)
+		issues = append(issues, issue)
+	}
+	if len(issues) == 0 {
+		return nil
+	}
+	usecase.SortConfigIssues(issues)
+	return &usecase.ConfigError{Path: path, Issues: issues}
+}
@@ -0,0 +1,64 @@
+package encryption
+
+import "strings"
+
+// bech32 encoding (BIP 173) as used by age for X25519 keys. Only encoding is needed here:
+// age.ParseX25519Identity decodes and checks the result.
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var bech32Generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+func bech32Polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i, g := range bech32Generator {
+			if top>>i&1 == 1 {
+				chk ^= g
+			}
+		}
+	}
+	return chk
+}
+
+// bech32Encode encodes data under hrp; the result is upper case when hrp is.
+func bech32Encode(hrp string, data []byte) string {
+	lower := strings.ToLower(hrp)
+	var values []byte
+	acc, bits := uint32(0), 0
+	for _, b := range data {
+		acc = acc<<8 | uint32(b)
+		for bits += 8; bits >= 5; {
+			bits -= 5
+			values = append(values, byte(acc>>bits)&31)
+		}
+	}
+	if bits > 0 {
+		values = append(values, byte(acc<<(5-bits))&31)
+	}
+	check := make([]byte, 0, 2*len(lower)+1+len(values)+6)
+	for _, c := range []byte(lower) {
+		check = append(check, c>>5)
+	}
+	check = append(check, 0)
+	for _, c := range []byte(lower) {
+		check = append(check, c&31)
+	}
+	check = append(check, values...)
+	mod := bech32Polymod(append(check, 0, 0, 0, 0, 0, 0)) ^ 1
+	for i := 0; i < 6; i++ {
+		values = append(values, byte(mod>>(5*(5-i)))&31)
+	}
+	var b strings.Builder
+	b.WriteString(lower)
+	b.WriteByte('1')
+	for _, v := range values {
+		b.WriteByte(bech32Charset[v])
+	}
+	if hrp != lower {
+		return strings.ToUpper(b.String())
+	}
+	return b.String()
+}
@@ -0,0 +1,316 @@
+// Package encryption implements client-side snapshot encryption with age (filippo.io/age).
+//
+// Every encrypted snapshot file is a standard age file for the configured X25519 recipients.
+// A passphrase does not wrap each file with scrypt, which would cost about a second per file:
+// the sealer draws a random salt for the run, stretches the passphrase with scrypt into an
+// X25519 identity and encrypts every file to it as well. Only the salt and work factor go into
+// the snapshot manifest, so decryption derives the same identity once per snapshot.
+package encryption
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"filippo.io/age"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+const bufferSize = 64 * 1024
+
+const (
+	// passphraseLabel keeps the derived identity apart from other scrypt uses of the passphrase.
+	passphraseLabel         = "devback/v1/passphrase-identity"
+	passphraseWorkFactor    = 18 // about a second, like age's scrypt recipient
+	passphraseMaxWorkFactor = 22
+	passphraseSaltSize      = 16
+)
+
+var errNoMatchingKey = errors.New("no identity or passphrase matches this file")
+
+// Adapter implements EncryptionPort.
+type Adapter struct {
+	logger *slog.Logger
+}
+
+// New creates a new encryption adapter.
+func New(logger *slog.Logger) *Adapter {
+	if logger == nil {
+		panic("encryption adapter requires logger")
+	}
+	return &Adapter{logger: logger}
+}
+
+// GenerateIdentity creates a new age X25519 key pair.
+func (a *Adapter) GenerateIdentity() (string, string, error) {
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		return "", "", err
+	}
+	return id.Recipient().String(), id.String(), nil
+}
+
+// ValidateRecipient parses recipient as an age X25519 public key.
+func (a *Adapter) ValidateRecipient(recipient string) error {
+	_, err := parseRecipient(recipient)
+	return err
+}
+
+// NewSealer resolves recipients and the passphrase from settings.
+func (a *Adapter) NewSealer(ctx context.Context, settings usecase.EncryptionSettings) (usecase.Sealer, error) {
+	_ = ctx
+	s := &sealer{}
+	for _, r := range settings.Recipients {
+		recipient, err := parseRecipient(r)
+		if err != nil {
+			return nil, err
+		}
+		s.recipients = append(s.recipients, recipient)
+		s.x25519 = true
+	}
+	pass, err := resolvePassphrase(settings)
+	if err != nil {
+		return nil, err
+	}
+	if pass != "" {
+		salt := make([]byte, passphraseSaltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		id, err := derivePassphraseIdentity(pass, salt, passphraseWorkFactor)
+		if err != nil {
+			return nil, err
+		}
+		s.passphraseKDF = formatPassphraseKDF(salt, passphraseWorkFactor)
+		s.recipients = append(s.recipients, id.Recipient())
+	}
+	if len(s.recipients) == 0 {
+		return nil, errors.New("encryption needs at least one recipient or a passphrase")
+	}
+	return s, nil
+}
+
+// NewOpener resolves identities from the identity file and, for snapshots sealed with a
+// passphrase, from the passphrase and settings.PassphraseKDF.
+func (a *Adapter) NewOpener(ctx context.Context, settings usecase.EncryptionSettings) (usecase.Opener, error) {
+	_ = ctx
+	o := &opener{}
+	if settings.IdentityFile != "" {
+		ids, err := readIdentities(settings.IdentityFile)
+		if err != nil {
+			return nil, err
+		}
+		o.identities = ids
+	}
+	pass, err := resolvePassphrase(settings)
+	if err != nil {
+		return nil, err
+	}
+	if pass != "" && settings.PassphraseKDF != "" {
+		salt, logN, err := parsePassphraseKDF(settings.PassphraseKDF)
+		if err != nil {
+			return nil, err
+		}
+		id, err := derivePassphraseIdentity(pass, salt, logN)
+		if err != nil {
+			return nil, err
+		}
+		o.identities = append(o.identities, id)
+	}
+	if len(o.identities) == 0 {
+		if pass != "" {
+			return nil, errors.New("snapshot is not encrypted with a passphrase; use its identity file")
+		}
+		return nil, errors.New("decryption needs an identity file or a passphrase")
+	}
+	return o, nil
+}
+
+func resolvePassphrase(settings usecase.EncryptionSettings) (string, error) {
+	if settings.Passphrase != "" {
+		return settings.Passphrase, nil
+	}
+	if settings.PassphraseFile == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(settings.PassphraseFile) // #nosec G304 - path comes from user config
+	if err != nil {
+		return "", fmt.Errorf("read passphrase file: %w", err)
+	}
+	pass := strings.TrimRight(string(data), "\r\n")
+	if pass == "" {
+		return "", errors.New("passphrase file is empty")
+	}
+	return pass, nil
+}
+
+func parseRecipient(s string) (*age.X25519Recipient, error) {
+	r, err := age.ParseX25519Recipient(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient %q: %w", s, err)
+	}
+	return r, nil
+}
+
+func readIdentities(path string) ([]age.Identity, error) {
+	file, err := os.Open(path) // #nosec G304 - path comes from user config
+	if err != nil {
+		return nil, fmt.Errorf("read identity file: %w", err)
+	}
+	defer func() {
+		_ = file.Close() // Ignore close error in defer
+	}()
+	ids, err := age.ParseIdentities(file)
+	if err != nil {
+		return nil, fmt.Errorf("identity file: %w", err)
+	}
+	return ids, nil
+}
+
+// derivePassphraseIdentity stretches pass with scrypt into the X25519 identity of a run.
+func derivePassphraseIdentity(pass string, salt []byte, logN int) (*age.X25519Identity, error) {
+	key, err := scrypt.Key([]byte(pass), append([]byte(passphraseLabel), salt...), 1<<logN, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	return age.ParseX25519Identity(bech32Encode("AGE-SECRET-KEY-", key))
+}
+
+// formatPassphraseKDF records the scrypt parameters of a run as "scrypt <logN> <salt>".
+func formatPassphraseKDF(salt []byte, logN int) string {
+	return fmt.Sprintf("scrypt %d %s", logN, base64.RawStdEncoding.EncodeToString(salt))
+}
+
+func parsePassphraseKDF(s string) ([]byte, int, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 3 || fields[0] != "scrypt" {
+		return nil, 0, fmt.Errorf("unsupported passphrase parameters %q", s)
+	}
+	logN, err := strconv.Atoi(fields[1])
+	if err != nil || logN < 1 || logN > passphraseMaxWorkFactor {
+		return nil, 0, fmt.Errorf("unsupported scrypt work factor %q", fields[1])
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(fields[2])
+	if err != nil || len(salt) != passphraseSaltSize {
+		return nil, 0, fmt.Errorf("invalid scrypt salt %q", fields[2])
+	}
+	return salt, logN, nil
+}
+
+type sealer struct {
+	recipients    []age.Recipient
+	x25519        bool
+	passphraseKDF string
+}
+
+// Scheme names the key types used, for snapshot metadata.
+func (s *sealer) Scheme() string {
+	var parts []string
+	if s.x25519 {
+		parts = append(parts, "x25519")
+	}
+	if s.passphraseKDF != "" {
+		parts = append(parts, "passphrase")
+	}
+	return strings.Join(parts, "+")
+}
+
+// PassphraseKDF returns the scrypt parameters of the run identity, or "" without a passphrase.
+func (s *sealer) PassphraseKDF() string {
+	return s.passphraseKDF
+}
+
+// SealWriter returns a writer that encrypts everything written to w. Close must be called
+// to emit the final chunk; it does not close w.
+func (s *sealer) SealWriter(w io.Writer) (io.WriteCloser, error) {
+	return age.Encrypt(w, s.recipients...)
+}
+
+// SealFile writes an encrypted copy of src to dst with src's permissions.
+func (s *sealer) SealFile(ctx context.Context, src, dst string) error {
+	return transformFile(ctx, src, dst, func(in io.Reader, out io.Writer) error {
+		w, err := s.SealWriter(out)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, in); err != nil {
+			return err
+		}
+		return w.Close()
+	})
+}
+
+type opener struct {
+	identities []age.Identity
+}
+
+// OpenReader parses the age header from r and returns a reader of the decrypted payload.
+func (o *opener) OpenReader(r io.Reader) (io.Reader, error) {
+	plain, err := age.Decrypt(r, o.identities...)
+	if err != nil {
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			return nil, errNoMatchingKey
+		}
+		return nil, err
+	}
+	return plain, nil
+}
+
+// OpenFile writes a decrypted copy of src to dst with src's permissions.
+func (o *opener) OpenFile(ctx context.Context, src, dst string) error {
+	return transformFile(ctx, src, dst, func(in io.Reader, out io.Writer) error {
+		r, err := o.OpenReader(in)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, r)
+		return err
+	})
+}
+
+// transformFile streams src through fn into a new dst, creating parent directories.
+func transformFile(ctx context.Context, src, dst string, fn func(io.Reader, io.Writer) error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	in, err := os.Open(src) // #nosec G304 - paths are controlled by usecase
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = in.Close() // Ignore close error in defer
+	}()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm()) // #nosec G304
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriterSize(out, bufferSize)
+	if err := fn(bufio.NewReaderSize(in, bufferSize), bw); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("%s: %w", filepath.Base(src), err)
+	}
+	if err := bw.Flush(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,281 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+func writeIdentity(t *testing.T, a *Adapter) (string, string) {
+	t.Helper()
+	recipient, identity, err := a.GenerateIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "identity.txt")
+	if err := os.WriteFile(path, []byte("# comment\n"+identity+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return recipient, path
+}
+
+func seal(t *testing.T, s usecase.Sealer, plain []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := s.SealWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(plain); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// chunkSize is the payload chunk size of the age format.
+const chunkSize = 64 * 1024
+
+func open(o usecase.Opener, sealed []byte) ([]byte, error) {
+	r, err := o.OpenReader(bytes.NewReader(sealed))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestAdapter_RecipientRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	a := New(slog.Default())
+	recipient, identity := writeIdentity(t, a)
+	sealer, err := a.NewSealer(ctx, usecase.EncryptionSettings{Recipients: []string{recipient}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opener, err := a.NewOpener(ctx, usecase.EncryptionSettings{IdentityFile: identity})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sealer.Scheme() != "x25519" {
+		t.Fatalf("unexpected scheme %q", sealer.Scheme())
+	}
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, 2*chunkSize + 17} {
+		plain := bytes.Repeat([]byte{byte(size)}, size)
+		sealed := seal(t, sealer, plain)
+		if size >= 16 && bytes.Contains(sealed, plain) {
+			t.Fatalf("size %d: plaintext visible in ciphertext", size)
+		}
+		got, err := open(opener, sealed)
+		if err != nil || !bytes.Equal(got, plain) {
+			t.Fatalf("size %d: round trip failed (%v)", size, err)
+		}
+	}
+}
+
+func TestAdapter_PassphraseRoundTripAndWrongKey(t *testing.T) {
+	ctx := context.Background()
+	a := New(slog.Default())
+	passFile := filepath.Join(t.TempDir(), "pass")
+	if err := os.WriteFile(passFile, []byte("correct horse\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sealer, err := a.NewSealer(ctx, usecase.EncryptionSettings{PassphraseFile: passFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sealer.Scheme() != "passphrase" {
+		t.Fatalf("unexpected scheme %q", sealer.Scheme())
+	}
+	sealed := seal(t, sealer, []byte("secret"))
+	kdf := sealer.PassphraseKDF()
+	if strings.Contains(string(sealed)+kdf, "correct horse") {
+		t.Fatal("passphrase leaked into ciphertext")
+	}
+	// The manifest gets only the scrypt parameters, never a key.
+	if fields := strings.Fields(kdf); len(fields) != 3 || fields[0] != "scrypt" || fields[1] != "18" {
+		t.Fatalf("unexpected passphrase parameters %q", kdf)
+	}
+
+	opener, err := a.NewOpener(ctx, usecase.EncryptionSettings{Passphrase: "correct horse", PassphraseKDF: kdf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := open(opener, sealed); err != nil || string(got) != "secret" {
+		t.Fatalf("unexpected result %q (%v)", got, err)
+	}
+
+	wrong, err := a.NewOpener(ctx, usecase.EncryptionSettings{Passphrase: "wrong", PassphraseKDF: kdf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := open(wrong, sealed); err == nil {
+		t.Fatal("expected wrong passphrase to fail")
+	}
+	if _, err := a.NewOpener(ctx, usecase.EncryptionSettings{Passphrase: "correct horse"}); err == nil {
+		t.Fatal("expected passphrase without passphrase parameters to fail")
+	}
+	for _, bad := range []string{"scrypt 30 " + strings.Fields(kdf)[2], "scrypt 18 c2FsdA", "argon2 18 x"} {
+		if _, err := a.NewOpener(ctx, usecase.EncryptionSettings{Passphrase: "x", PassphraseKDF: bad}); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+	_, otherIdentity := writeIdentity(t, a)
+	other, err := a.NewOpener(ctx, usecase.EncryptionSettings{IdentityFile: otherIdentity})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := open(other, sealed); err == nil {
+		t.Fatal("expected unrelated identity to fail")
+	}
+}
+
+func TestAdapter_FilesUseFreshKeys(t *testing.T) {
+	ctx := context.Background()
+	a := New(slog.Default())
+	recipient, identity := writeIdentity(t, a)
+	passFile := filepath.Join(t.TempDir(), "pass")
+	if err := os.WriteFile(passFile, []byte("correct horse\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sealer, err := a.NewSealer(ctx, usecase.EncryptionSettings{Recipients: []string{recipient}, PassphraseFile: passFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sealer.Scheme() != "x25519+passphrase" {
+		t.Fatalf("unexpected scheme %q", sealer.Scheme())
+	}
+	// Both key stanzas of every file wrap its file key with a fresh ephemeral share.
+	header := func(sealed []byte) []string {
+		end := bytes.Index(sealed, []byte("\n---"))
+		if end < 0 {
+			t.Fatal("missing age header")
+		}
+		return strings.Split(string(sealed[:end]), "\n")[1:]
+	}
+	plain := []byte("same content")
+	first, second := seal(t, sealer, plain), seal(t, sealer, plain)
+	seen := map[string]bool{}
+	for _, line := range append(header(first), header(second)...) {
+		if seen[line] {
+			t.Fatalf("key stanza %q used for two files", line)
+		}
+		seen[line] = true
+	}
+	// Each stanza is an argument line with the ephemeral share and a body with the wrapped key.
+	if len(seen) != 8 {
+		t.Fatalf("expected two stanzas per file, got %v", seen)
+	}
+	if bytes.Equal(first, second) {
+		t.Fatal("equal plaintexts must not give equal ciphertexts")
+	}
+	opener, err := a.NewOpener(ctx, usecase.EncryptionSettings{IdentityFile: identity})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := open(opener, second); err != nil || !bytes.Equal(got, plain) {
+		t.Fatalf("unexpected result %q (%v)", got, err)
+	}
+}
+
+func TestAdapter_DetectsTamperingAndTruncation(t *testing.T) {
+	ctx := context.Background()
+	a := New(slog.Default())
+	recipient, identity := writeIdentity(t, a)
+	sealer, err := a.NewSealer(ctx, usecase.EncryptionSettings{Recipients: []string{recipient}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opener, err := a.NewOpener(ctx, usecase.EncryptionSettings{IdentityFile: identity})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed := seal(t, sealer, bytes.Repeat([]byte("x"), 2*chunkSize))
+
+	flipped := bytes.Clone(sealed)
+	flipped[len(flipped)-1] ^= 1
+	if _, err := open(opener, flipped); err == nil {
+		t.Fatal("expected modified ciphertext to fail")
+	}
+	// Dropping the final chunk leaves a stream that ends on a full, non-final chunk.
+	truncated := sealed[:len(sealed)-chunkSize-16]
+	if _, err := open(opener, truncated); err == nil {
+		t.Fatal("expected truncated ciphertext to fail")
+	}
+	if _, err := open(opener, []byte("plain text\n")); err == nil {
+		t.Fatal("expected unencrypted input to fail")
+	}
+}
+
+func TestAdapter_SealFilePreservesMode(t *testing.T) {
+	ctx := context.Background()
+	a := New(slog.Default())
+	recipient, identity := writeIdentity(t, a)
+	sealer, err := a.NewSealer(ctx, usecase.EncryptionSettings{Recipients: []string{recipient}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opener, err := a.NewOpener(ctx, usecase.EncryptionSettings{IdentityFile: identity})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	if err := os.WriteFile(src, []byte("payload"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	enc := filepath.Join(dir, "sub", "enc")
+	dec := filepath.Join(dir, "dec.txt")
+	if err := sealer.SealFile(ctx, src, enc); err != nil {
+		t.Fatal(err)
+	}
+	if err := opener.OpenFile(ctx, enc, dec); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(dec)
+	if err != nil || string(data) != "payload" {
+		t.Fatalf("unexpected content %q (%v)", data, err)
+	}
+	info, err := os.Stat(enc)
+	if err != nil || info.Mode().Perm() != 0o640 {
+		t.Fatalf("unexpected mode: %v (%v)", info, err)
+	}
+}
+
+func TestAdapter_RejectsInvalidSettings(t *testing.T) {
+	ctx := context.Background()
+	a := New(slog.Default())
+	if _, err := a.NewSealer(ctx, usecase.EncryptionSettings{}); err == nil {
+		t.Fatal("expected sealer without keys to fail")
+	}
+	if _, err := a.NewSealer(ctx, usecase.EncryptionSettings{Recipients: []string{"age1xyz"}}); err == nil {
+		t.Fatal("expected invalid recipient to fail")
+	}
+	if _, err := a.NewOpener(ctx, usecase.EncryptionSettings{}); err == nil {
+		t.Fatal("expected opener without keys to fail")
+	}
+}
+
+func TestAdapter_ValidateRecipient(t *testing.T) {
+	a := New(slog.Default())
+	recipient, _, err := a.GenerateIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.ValidateRecipient(recipient); err != nil {
+		t.Fatalf("generated recipient must be valid: %v", err)
+	}
+	for _, bad := range []string{"", "age1abc", "ssh-ed25519 AAAA", recipient + "x"} {
+		if err := a.ValidateRecipient(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+
+package filesystem
+
+import (
+	"syscall"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+// FileID returns device and inode numbers from the underlying stat data.
+func (a *Adapter) FileID(info usecase.FileInfo) (usecase.FileID, bool) {
+	if info == nil {
+		return usecase.FileID{}, false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return usecase.FileID{}, false
+	}
+	//nolint:unconvert // Dev is int32 on darwin and uint64 on linux.
+	return usecase.FileID{Device: uint64(st.Dev), Inode: st.Ino}, true
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package filesystem
+
+import "github.com/arumata/devback/internal/usecase"
+
+// FileID is not available on Windows; hardlinked files are counted per path.
+func (a *Adapter) FileID(info usecase.FileInfo) (usecase.FileID, bool) {
+	return usecase.FileID{}, false
+}
@@ -2,6 +2,8 @@ package filesystem
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -112,7 +114,7 @@ func (a *Adapter) CreateDirExclusive(ctx context.Context, path string, perm int)
 	return os.Mkdir(path, fs.FileMode(perm))
 }
 
-// Copy copies file from src to dst
+// Copy copies file from src to dst, cloning it when the filesystem supports reflinks
 func (a *Adapter) Copy(ctx context.Context, src, dst string) error {
 	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
 		return err
@@ -140,10 +142,11 @@ func (a *Adapter) Copy(ctx context.Context, src, dst string) error {
 		_ = dstFile.Close() // Ignore close error in defer
 	}()
 
-	// Copy content
-	_, err = io.Copy(dstFile, srcFile)
-	if err != nil {
-		return err
+	// Share extents when the filesystem supports reflinks, copy bytes otherwise
+	if err := cloneFile(dstFile, srcFile); err != nil {
+		if _, err := io.Copy(dstFile, srcFile); err != nil {
+			return err
+		}
 	}
 
 	// Set permissions
@@ -165,6 +168,11 @@ func (a *Adapter) Symlink(ctx context.Context, target, path string) error {
 	return os.Symlink(target, path)
 }
 
+// Link creates newname as a hard link to oldname
+func (a *Adapter) Link(ctx context.Context, oldname, newname string) error {
+	return os.Link(oldname, newname)
+}
+
 // Chmod changes file mode
 func (a *Adapter) Chmod(ctx context.Context, path string, perm int) error {
 	if perm < 0 || perm > 0o777 {
@@ -178,6 +186,25 @@ func (a *Adapter) Chtimes(ctx context.Context, path string, atime, mtime time.Ti
 	return os.Chtimes(path, atime, mtime)
 }
 
+// HashFile returns the hex-encoded SHA-256 of the file content
+func (a *Adapter) HashFile(ctx context.Context, path string) (string, error) {
+	f, err := os.Open(path) // #nosec G304 - path is controlled by usecase
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close() // Ignore close error in defer
+	}()
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 // GetWorkingDir returns current working directory
 func (a *Adapter) GetWorkingDir(ctx context.Context) (string, error) {
 	return os.Getwd()
@@ -3,6 +3,7 @@ package filesystem
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -74,3 +75,101 @@ func TestCreateDirExclusive_InvalidPerm(t *testing.T) {
 		t.Fatalf("expected mode 0755, got %o", info.Mode().Perm())
 	}
 }
+
+func TestLinkSharesFileID(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file ids are not exposed on windows")
+	}
+	ctx := context.Background()
+	adapter := New(slog.Default())
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	dst := filepath.Join(root, "dst")
+	other := filepath.Join(root, "other")
+	for _, p := range []string{src, other} {
+		if err := os.WriteFile(p, []byte("data"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := adapter.Link(ctx, src, dst); err != nil {
+		t.Fatalf("link: %v", err)
+	}
+	ids := make(map[string]bool)
+	for _, p := range []string{src, dst, other} {
+		info, err := adapter.Lstat(ctx, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, ok := adapter.FileID(info)
+		if !ok {
+			t.Fatalf("expected file id for %s", p)
+		}
+		ids[fmt.Sprint(id)] = true
+	}
+	if len(ids) != 2 {
+		t.Fatalf("expected linked files to share one id, got %d distinct ids", len(ids))
+	}
+	if err := adapter.Link(ctx, src, dst); err == nil {
+		t.Fatal("expected link over existing file to fail")
+	}
+}
+
+func TestCopy_ContentAndMode(t *testing.T) {
+	ctx := context.Background()
+	adapter := New(slog.Default())
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	dst := filepath.Join(root, "nested", "dst")
+	data := []byte("pack data")
+	if err := os.WriteFile(src, data, 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	// t.TempDir is usually tmpfs or ext4, so this exercises the byte copy path;
+	// on btrfs/XFS the same call goes through FICLONE.
+	if err := adapter.Copy(ctx, src, dst); err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	got, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(data) {
+		t.Fatalf("unexpected content: %q", got)
+	}
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0o640 {
+		t.Fatalf("unexpected mode: %v", info.Mode().Perm())
+	}
+	if os.SameFile(mustStat(t, src), info) {
+		t.Fatal("expected an independent copy")
+	}
+}
+
+func mustStat(t *testing.T, path string) os.FileInfo {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info
+}
+
+func TestHashFile(t *testing.T) {
+	adapter := New(slog.Default())
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, []byte("abc"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sum, err := adapter.HashFile(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"; sum != want {
+		t.Fatalf("HashFile = %s, want %s", sum, want)
+	}
+}
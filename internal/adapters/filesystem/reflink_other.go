@@ -0,0 +1,15 @@
+//go:build !linux
+
+package filesystem
+
+import (
+	"errors"
+	"os"
+)
+
+var errReflinkUnsupported = errors.New("reflink is not supported on this platform")
+
+// cloneFile is unavailable outside Linux; Copy falls back to a byte copy.
+func cloneFile(dst, src *os.File) error {
+	return errReflinkUnsupported
+}
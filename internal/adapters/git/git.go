@@ -2,11 +2,14 @@
 package git
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -160,38 +163,37 @@ func (a *Adapter) IsClean(ctx context.Context, repoPath string) (bool, error) {
 	return strings.TrimSpace(string(output)) == "", nil
 }
 
-// GetStatus returns repository status
+// GetStatus returns repository status. A path staged and then modified again is listed
+// in both StagedFiles and ModifiedFiles; renames are reported by their new path.
 func (a *Adapter) GetStatus(ctx context.Context, repoPath string) (usecase.GitStatus, error) {
-	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain")
+	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain", "-z", "--untracked-files=all")
 	cmd.Dir = repoPath
 	output, err := cmd.Output()
 	if err != nil {
-		return usecase.GitStatus{}, err
+		return usecase.GitStatus{}, fmt.Errorf("git status failed: %w", err)
 	}
 
 	var status usecase.GitStatus
-	lines := strings.Split(string(output), "\n")
-
-	for _, line := range lines {
-		if strings.TrimSpace(line) == "" {
+	entries := strings.Split(string(output), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
 			continue
 		}
-		if len(line) < 3 {
-			continue
+		x, y, filePath := entry[0], entry[1], entry[3:]
+		if x == 'R' || x == 'C' {
+			// The original path of a rename or copy follows as a separate entry.
+			i++
 		}
 
-		statusCode := line[:2]
-		filePath := line[3:]
-
-		switch {
-		case statusCode[0] != ' ' && statusCode[0] != '?':
-			// Staged file
-			status.StagedFiles = append(status.StagedFiles, filePath)
-		case statusCode[0] == '?' && statusCode[1] == '?':
-			// Untracked file
+		if x == '?' && y == '?' {
 			status.UntrackedFiles = append(status.UntrackedFiles, filePath)
-		case statusCode[1] != ' ':
-			// Modified file
+			continue
+		}
+		if x != ' ' && x != '!' {
+			status.StagedFiles = append(status.StagedFiles, filePath)
+		}
+		if y != ' ' && y != '!' {
 			status.ModifiedFiles = append(status.ModifiedFiles, filePath)
 		}
 	}
@@ -201,6 +203,33 @@ func (a *Adapter) GetStatus(ctx context.Context, repoPath string) (usecase.GitSt
 	return status, nil
 }
 
+// Diff returns a binary-safe patch of unstaged changes, or of staged changes when staged is set.
+func (a *Adapter) Diff(ctx context.Context, repoPath string, staged bool) ([]byte, error) {
+	args := []string{"diff", "--binary", "--no-color", "--no-ext-diff", "--ignore-submodules"}
+	if staged {
+		args = append(args, "--cached")
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = repoPath
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
+
+// ApplyPatch applies patchPath to the working tree of repoPath.
+func (a *Adapter) ApplyPatch(ctx context.Context, repoPath, patchPath string) error {
+	cmd := exec.CommandContext(ctx, "git", "apply", "--binary", "--whitespace=nowarn", patchPath)
+	cmd.Dir = repoPath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git apply failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 // GetLog returns commit log
 func (a *Adapter) GetLog(ctx context.Context, repoPath string, limit int) ([]usecase.GitCommit, error) {
 	args := []string{"log", "--oneline", "--format=%H|%an|%ad|%s", "--date=iso"}
@@ -275,6 +304,17 @@ func (a *Adapter) ConfigGet(ctx context.Context, repoPath, key string) (string,
 	return strings.TrimSpace(string(output)), nil
 }
 
+// ConfigGetLocal reads git config value from the repository config file only.
+func (a *Adapter) ConfigGetLocal(ctx context.Context, repoPath, key string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "config", "--local", "--get", key)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
 // ConfigSet sets git config value.
 func (a *Adapter) ConfigSet(ctx context.Context, repoPath, key, value string) error {
 	cmd := exec.CommandContext(ctx, "git", "config", key, value)
@@ -320,6 +360,36 @@ func (a *Adapter) ConfigSetGlobal(ctx context.Context, key, value string) error
 	return cmd.Run()
 }
 
+// ConfigUnset removes local git config value; a missing key is not an error.
+func (a *Adapter) ConfigUnset(ctx context.Context, repoPath, key string) error {
+	cmd := exec.CommandContext(ctx, "git", "config", "--local", "--unset-all", key)
+	cmd.Dir = repoPath
+	return runConfigUnset(cmd)
+}
+
+// ConfigUnsetWorktree removes git worktree config value; a missing key is not an error.
+func (a *Adapter) ConfigUnsetWorktree(ctx context.Context, repoPath, key string) error {
+	cmd := exec.CommandContext(ctx, "git", "config", "--worktree", "--unset-all", key)
+	cmd.Dir = repoPath
+	return runConfigUnset(cmd)
+}
+
+// ConfigUnsetGlobal removes global git config value; a missing key is not an error.
+func (a *Adapter) ConfigUnsetGlobal(ctx context.Context, key string) error {
+	cmd := exec.CommandContext(ctx, "git", "config", "--global", "--unset-all", key)
+	return runConfigUnset(cmd)
+}
+
+// runConfigUnset treats exit code 5 (key not set) as success.
+func runConfigUnset(cmd *exec.Cmd) error {
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 5 {
+		return nil
+	}
+	return err
+}
+
 // GitDir returns git directory for repo
 func (a *Adapter) GitDir(ctx context.Context, repoPath string) (string, error) {
 	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--git-dir")
@@ -413,3 +483,44 @@ func (a *Adapter) ListIgnoredUntracked(ctx context.Context, repoPath string) ([]
 	}
 	return results, nil
 }
+
+// FsckConnectivity checks that all objects reachable from refs exist in gitDir.
+func (a *Adapter) FsckConnectivity(ctx context.Context, gitDir string) error {
+	cmd := exec.CommandContext(ctx, "git", "--git-dir", gitDir, "fsck", "--connectivity-only", "--no-progress")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git fsck failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// RestoreWorktree rebuilds working tree files from the index.
+// A missing index is recreated from HEAD first.
+func (a *Adapter) RestoreWorktree(ctx context.Context, repoPath string) error {
+	indexCmd := exec.CommandContext(ctx, "git", "rev-parse", "--git-path", "index")
+	indexCmd.Dir = repoPath
+	indexOut, err := indexCmd.Output()
+	if err != nil {
+		return fmt.Errorf("git rev-parse failed: %w", err)
+	}
+	indexPath := strings.TrimSpace(string(indexOut))
+	if !filepath.IsAbs(indexPath) {
+		indexPath = filepath.Join(repoPath, indexPath)
+	}
+	if _, err := os.Stat(indexPath); os.IsNotExist(err) {
+		// Unborn HEAD has nothing to reset to; checkout-index below is then a no-op.
+		reset := exec.CommandContext(ctx, "git", "reset", "--quiet")
+		reset.Dir = repoPath
+		_ = reset.Run()
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "checkout-index", "--all", "--force")
+	cmd.Dir = repoPath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git checkout-index failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	refresh := exec.CommandContext(ctx, "git", "update-index", "-q", "--refresh")
+	refresh.Dir = repoPath
+	_ = refresh.Run()
+	return nil
+}
@@ -119,6 +119,19 @@ func TestAdapter_RemotesAndConfig(t *testing.T) {
 	}
 
 	_, _ = adapter.ConfigGet(ctx, repoDir, "user.name")
+
+	if err := adapter.ConfigSet(ctx, repoDir, "backup.slug", "team"); err != nil {
+		t.Fatal(err)
+	}
+	if err := adapter.ConfigUnset(ctx, repoDir, "backup.slug"); err != nil {
+		t.Fatalf("unset: %v", err)
+	}
+	if value, err := adapter.ConfigGet(ctx, repoDir, "backup.slug"); err == nil || value != "" {
+		t.Fatalf("expected backup.slug to be unset, got %q", value)
+	}
+	if err := adapter.ConfigUnset(ctx, repoDir, "backup.slug"); err != nil {
+		t.Fatalf("unsetting a missing key must succeed: %v", err)
+	}
 }
 
 func TestAdapter_RepoRootAndIgnored(t *testing.T) {
@@ -157,6 +170,89 @@ func TestAdapter_RepoRootAndIgnored(t *testing.T) {
 	}
 }
 
+func TestAdapter_RestoreWorktree(t *testing.T) {
+	ctx := context.Background()
+	adapter := New(slog.Default())
+	repoDir := t.TempDir()
+	setupRepo(t, adapter, repoDir)
+
+	if err := os.Remove(filepath.Join(repoDir, "file.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(repoDir, ".git", "index")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := adapter.RestoreWorktree(ctx, repoDir); err != nil {
+		t.Fatalf("restore worktree: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(repoDir, "file.txt"))
+	if err != nil || string(data) != "data" {
+		t.Fatalf("expected file.txt restored, data=%q err=%v", data, err)
+	}
+	clean, err := adapter.IsClean(ctx, repoDir)
+	if err != nil || !clean {
+		t.Fatalf("expected clean worktree, clean=%v err=%v", clean, err)
+	}
+}
+
+func TestAdapter_StatusDiffAndApply(t *testing.T) {
+	ctx := context.Background()
+	adapter := New(slog.Default())
+	repoDir := t.TempDir()
+	setupRepo(t, adapter, repoDir)
+
+	file := filepath.Join(repoDir, "file.txt")
+	requireNoErr(t, os.WriteFile(file, []byte("staged"), 0o600), "write staged")
+	requireNoErr(t, adapter.Add(ctx, repoDir, []string{"file.txt"}), "stage")
+	requireNoErr(t, os.WriteFile(file, []byte("staged\nunstaged"), 0o600), "write unstaged")
+
+	status, err := adapter.GetStatus(ctx, repoDir)
+	requireNoErr(t, err, "get status")
+	requireTrue(t, len(status.StagedFiles) == 1 && len(status.ModifiedFiles) == 1,
+		"expected file.txt to be both staged and modified")
+
+	staged, err := adapter.Diff(ctx, repoDir, true)
+	requireNoErr(t, err, "diff --cached")
+	requireTrue(t, len(staged) > 0, "expected staged patch")
+	worktree, err := adapter.Diff(ctx, repoDir, false)
+	requireNoErr(t, err, "diff")
+	patch := filepath.Join(t.TempDir(), "worktree.patch")
+	requireNoErr(t, os.WriteFile(patch, worktree, 0o600), "write patch")
+
+	cmd := exec.Command("git", "checkout", "--", "file.txt")
+	cmd.Dir = repoDir
+	requireNoErr(t, cmd.Run(), "discard unstaged change")
+	requireNoErr(t, adapter.ApplyPatch(ctx, repoDir, patch), "apply patch")
+	data, err := os.ReadFile(file) // #nosec G304 - test data
+	requireNoErr(t, err, "read file")
+	requireTrue(t, string(data) == "staged\nunstaged", "expected unstaged change to be reapplied")
+}
+
+func TestAdapter_FsckConnectivity(t *testing.T) {
+	ctx := context.Background()
+	adapter := New(slog.Default())
+	repoDir := t.TempDir()
+	setupRepo(t, adapter, repoDir)
+	gitDir := filepath.Join(repoDir, ".git")
+
+	if err := adapter.FsckConnectivity(ctx, gitDir); err != nil {
+		t.Fatalf("fsck on intact repo: %v", err)
+	}
+	objects, err := filepath.Glob(filepath.Join(gitDir, "objects", "??", "*"))
+	if err != nil || len(objects) == 0 {
+		t.Fatalf("expected loose objects: %v", err)
+	}
+	for _, obj := range objects {
+		if err := os.Remove(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := adapter.FsckConnectivity(ctx, gitDir); err == nil {
+		t.Fatal("expected fsck to fail with missing objects")
+	}
+}
+
 func TestAdapter_BranchCheckout(t *testing.T) {
 	ctx := context.Background()
 	adapter := New(slog.Default())
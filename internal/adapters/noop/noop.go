@@ -88,6 +88,16 @@ func (a Adapter) Symlink(ctx context.Context, target, path string) error {
 	return errNotImplemented
 }
 
+// Link returns error for filesystem operations
+func (a Adapter) Link(ctx context.Context, oldname, newname string) error {
+	return errNotImplemented
+}
+
+// HashFile returns error for filesystem operations
+func (a Adapter) HashFile(ctx context.Context, path string) (string, error) {
+	return "", errNotImplemented
+}
+
 // Chmod returns error for filesystem operations
 func (a Adapter) Chmod(ctx context.Context, path string, perm int) error {
 	return errNotImplemented
@@ -168,6 +178,11 @@ func (a Adapter) IsPermission(err error) bool {
 	return false
 }
 
+// FileID reports no identity for filesystem operations
+func (a Adapter) FileID(info usecase.FileInfo) (usecase.FileID, bool) {
+	return usecase.FileID{}, false
+}
+
 // TempDir returns error for filesystem operations
 func (a Adapter) TempDir(ctx context.Context, dir, prefix string) (string, error) {
 	return "", errNotImplemented
@@ -233,6 +248,16 @@ func (a Adapter) GetStatus(ctx context.Context, repoPath string) (usecase.GitSta
 	return usecase.GitStatus{}, errNotImplemented
 }
 
+// Diff returns error for git operations
+func (a Adapter) Diff(ctx context.Context, repoPath string, staged bool) ([]byte, error) {
+	return nil, errNotImplemented
+}
+
+// ApplyPatch returns error for git operations
+func (a Adapter) ApplyPatch(ctx context.Context, repoPath, patchPath string) error {
+	return errNotImplemented
+}
+
 // GetLog returns error for git operations
 func (a Adapter) GetLog(ctx context.Context, repoPath string, limit int) ([]usecase.GitCommit, error) {
 	return nil, errNotImplemented
@@ -258,6 +283,11 @@ func (a Adapter) ConfigGetGlobal(ctx context.Context, key string) (string, error
 	return "", errNotImplemented
 }
 
+// ConfigGetLocal returns error for git operations
+func (a Adapter) ConfigGetLocal(ctx context.Context, repoPath, key string) (string, error) {
+	return "", errNotImplemented
+}
+
 // ConfigGetWorktree returns error for git operations
 func (a Adapter) ConfigGetWorktree(ctx context.Context, repoPath, key string) (string, error) {
 	return "", errNotImplemented
@@ -273,6 +303,21 @@ func (a Adapter) ConfigSetGlobal(ctx context.Context, key, value string) error {
 	return errNotImplemented
 }
 
+// ConfigUnset returns error for git operations
+func (a Adapter) ConfigUnset(ctx context.Context, repoPath, key string) error {
+	return errNotImplemented
+}
+
+// ConfigUnsetWorktree returns error for git operations
+func (a Adapter) ConfigUnsetWorktree(ctx context.Context, repoPath, key string) error {
+	return errNotImplemented
+}
+
+// ConfigUnsetGlobal returns error for git operations
+func (a Adapter) ConfigUnsetGlobal(ctx context.Context, key string) error {
+	return errNotImplemented
+}
+
 // GitDir returns error for git operations
 func (a Adapter) GitDir(ctx context.Context, repoPath string) (string, error) {
 	return "", errNotImplemented
@@ -293,6 +338,16 @@ func (a Adapter) ListIgnoredUntracked(ctx context.Context, repoPath string) ([]s
 	return nil, errNotImplemented
 }
 
+// RestoreWorktree returns error for git operations
+func (a Adapter) RestoreWorktree(ctx context.Context, repoPath string) error {
+	return errNotImplemented
+}
+
+// FsckConnectivity returns error for git operations
+func (a Adapter) FsckConnectivity(ctx context.Context, gitDir string) error {
+	return errNotImplemented
+}
+
 // Load returns error for config operations
 func (a Adapter) Load(ctx context.Context, path string) (usecase.ConfigFile, error) {
 	return usecase.ConfigFile{}, errNotImplemented
@@ -303,6 +358,11 @@ func (a Adapter) Save(ctx context.Context, path string, cfg usecase.ConfigFile)
 	return errNotImplemented
 }
 
+// LoadRepo returns error for config operations
+func (a Adapter) LoadRepo(ctx context.Context, path string) (usecase.RepoConfigFile, error) {
+	return usecase.RepoConfigFile{}, errNotImplemented
+}
+
 // List returns error for templates operations
 func (a Adapter) List(ctx context.Context) ([]usecase.TemplateEntry, error) {
 	return nil, errNotImplemented
@@ -348,6 +408,85 @@ func (a Adapter) GetPID() int {
 	return 0
 }
 
+// Hostname returns empty string for process operations
+func (a Adapter) Hostname() string {
+	return ""
+}
+
+// StartBackground returns error for process operations
+func (a Adapter) StartBackground(args []string) error {
+	return errNotImplemented
+}
+
+// Create returns error for archive operations
+func (a Adapter) Create(
+	ctx context.Context, path, format string, sealer usecase.Sealer,
+) (usecase.ArchiveWriter, error) {
+	return nil, errNotImplemented
+}
+
+// Extract returns error for archive operations
+func (a Adapter) Extract(ctx context.Context, path, format, dir string, opener usecase.Opener) (int, error) {
+	return 0, errNotImplemented
+}
+
+// NewSealer returns error for encryption operations
+func (a Adapter) NewSealer(ctx context.Context, settings usecase.EncryptionSettings) (usecase.Sealer, error) {
+	return nil, errNotImplemented
+}
+
+// NewOpener returns error for encryption operations
+func (a Adapter) NewOpener(ctx context.Context, settings usecase.EncryptionSettings) (usecase.Opener, error) {
+	return nil, errNotImplemented
+}
+
+// GenerateIdentity returns error for encryption operations
+func (a Adapter) GenerateIdentity() (string, string, error) {
+	return "", "", errNotImplemented
+}
+
+// ValidateRecipient returns error for encryption operations
+func (a Adapter) ValidateRecipient(recipient string) error {
+	return errNotImplemented
+}
+
+// Watch returns error for filesystem watching
+func (a Adapter) Watch(
+	ctx context.Context, root string, skip func(rel string) bool,
+) (<-chan usecase.WatchEvent, error) {
+	return nil, errNotImplemented
+}
+
+// Backend reports that scheduling is unavailable
+func (a Adapter) Backend() string {
+	return ""
+}
+
+// Render returns error for scheduler operations
+func (a Adapter) Render(spec usecase.ScheduleSpec) ([]usecase.ScheduleFile, error) {
+	return nil, errNotImplemented
+}
+
+// Install returns error for scheduler operations
+func (a Adapter) Install(ctx context.Context, spec usecase.ScheduleSpec) error {
+	return errNotImplemented
+}
+
+// Status returns error for scheduler operations
+func (a Adapter) Status(ctx context.Context) (usecase.ScheduleInfo, error) {
+	return usecase.ScheduleInfo{}, errNotImplemented
+}
+
+// Remove returns error for scheduler operations
+func (a Adapter) Remove(ctx context.Context) ([]string, error) {
+	return nil, errNotImplemented
+}
+
+// Open returns error for storage operations
+func (a Adapter) Open(ctx context.Context, target usecase.TargetSettings) (usecase.SnapshotStore, error) {
+	return nil, errNotImplemented
+}
+
 // New creates a new no-op adapter.
 func New(logger *slog.Logger) *Adapter {
 	if logger == nil {
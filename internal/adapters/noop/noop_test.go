@@ -32,6 +32,7 @@ func TestAdapter_NoopFileSystem(t *testing.T) {
 	_, err = adapter.Readlink(ctx, "path")
 	expectErr(t, err, "Readlink")
 	expectErr(t, adapter.Symlink(ctx, "target", "path"), "Symlink")
+	expectErr(t, adapter.Link(ctx, "old", "new"), "Link")
 	expectErr(t, adapter.Chmod(ctx, "path", 0o600), "Chmod")
 
 	now := time.Now()
@@ -47,6 +48,9 @@ func TestAdapter_NoopFileSystem(t *testing.T) {
 	expectEmptyString(t, adapter.Base("path"), "Base")
 	expectEmptyString(t, adapter.Dir("path"), "Dir")
 	expectEmptyString(t, adapter.Ext("path"), "Ext")
+	if _, ok := adapter.FileID(nil); ok {
+		t.Fatal("expected no file id")
+	}
 }
 
 func TestAdapter_NoopGit(t *testing.T) {
@@ -71,17 +75,27 @@ func TestAdapter_NoopGit(t *testing.T) {
 	expectErr(t, err, "IsClean")
 	_, err = adapter.GetStatus(ctx, "repo")
 	expectErr(t, err, "GetStatus")
+	_, err = adapter.Diff(ctx, "repo", true)
+	expectErr(t, err, "Diff")
+	expectErr(t, adapter.ApplyPatch(ctx, "repo", "patch"), "ApplyPatch")
 	_, err = adapter.GetLog(ctx, "repo", 1)
 	expectErr(t, err, "GetLog")
 	_, err = adapter.RepoRoot(ctx)
 	expectErr(t, err, "RepoRoot")
 	_, err = adapter.ConfigGet(ctx, "repo", "key")
 	expectErr(t, err, "ConfigGet")
+	_, err = adapter.ConfigGetLocal(ctx, "repo", "key")
+	expectErr(t, err, "ConfigGetLocal")
 	_, err = adapter.ConfigGetWorktree(ctx, "repo", "key")
 	expectErr(t, err, "ConfigGetWorktree")
 	expectErr(t, adapter.ConfigSetWorktree(ctx, "repo", "key", "value"), "ConfigSetWorktree")
+	expectErr(t, adapter.ConfigUnset(ctx, "repo", "key"), "ConfigUnset")
+	expectErr(t, adapter.ConfigUnsetWorktree(ctx, "repo", "key"), "ConfigUnsetWorktree")
+	expectErr(t, adapter.ConfigUnsetGlobal(ctx, "key"), "ConfigUnsetGlobal")
 	_, err = adapter.ListIgnoredUntracked(ctx, "repo")
 	expectErr(t, err, "ListIgnoredUntracked")
+	expectErr(t, adapter.RestoreWorktree(ctx, "repo"), "RestoreWorktree")
+	expectErr(t, adapter.FsckConnectivity(ctx, "repo/.git"), "FsckConnectivity")
 }
 
 func TestAdapter_NoopLock(t *testing.T) {
@@ -99,6 +113,62 @@ func TestAdapter_NoopProcess(t *testing.T) {
 	adapter := New(slog.Default())
 
 	expectZeroInt(t, adapter.GetPID(), "GetPID")
+	if adapter.Hostname() != "" {
+		t.Fatal("expected empty Hostname")
+	}
+	expectErr(t, adapter.StartBackground([]string{"remote", "push"}), "StartBackground")
+}
+
+func TestAdapter_NoopArchive(t *testing.T) {
+	ctx := context.Background()
+	adapter := New(slog.Default())
+
+	_, err := adapter.Create(ctx, "path", usecase.SnapshotFormatTar, nil)
+	expectErr(t, err, "Create")
+	_, err = adapter.Extract(ctx, "path", usecase.SnapshotFormatTar, "dir", nil)
+	expectErr(t, err, "Extract")
+}
+
+func TestAdapter_NoopEncryption(t *testing.T) {
+	ctx := context.Background()
+	adapter := New(slog.Default())
+
+	_, err := adapter.NewSealer(ctx, usecase.EncryptionSettings{})
+	expectErr(t, err, "NewSealer")
+	_, err = adapter.NewOpener(ctx, usecase.EncryptionSettings{})
+	expectErr(t, err, "NewOpener")
+	_, _, err = adapter.GenerateIdentity()
+	expectErr(t, err, "GenerateIdentity")
+}
+
+func TestAdapter_NoopWatch(t *testing.T) {
+	adapter := New(slog.Default())
+
+	_, err := adapter.Watch(context.Background(), "root", nil)
+	expectErr(t, err, "Watch")
+}
+
+func TestAdapter_NoopScheduler(t *testing.T) {
+	ctx := context.Background()
+	adapter := New(slog.Default())
+
+	if adapter.Backend() != "" {
+		t.Fatal("expected no scheduler backend")
+	}
+	_, err := adapter.Render(usecase.ScheduleSpec{})
+	expectErr(t, err, "Render")
+	expectErr(t, adapter.Install(ctx, usecase.ScheduleSpec{}), "Install")
+	_, err = adapter.Status(ctx)
+	expectErr(t, err, "Status")
+	_, err = adapter.Remove(ctx)
+	expectErr(t, err, "Remove")
+}
+
+func TestAdapter_NoopStorage(t *testing.T) {
+	adapter := New(slog.Default())
+
+	_, err := adapter.Open(context.Background(), usecase.TargetSettings{Type: usecase.TargetTypeS3})
+	expectErr(t, err, "Open")
 }
 
 func TestAdapter_NoopConfigAndTemplates(t *testing.T) {
@@ -108,6 +178,8 @@ func TestAdapter_NoopConfigAndTemplates(t *testing.T) {
 	_, err := adapter.Load(ctx, "path")
 	expectErr(t, err, "Load")
 	expectErr(t, adapter.Save(ctx, "path", usecase.ConfigFile{}), "Save")
+	_, err = adapter.LoadRepo(ctx, "path")
+	expectErr(t, err, "LoadRepo")
 	_, err = adapter.List(ctx)
 	expectErr(t, err, "List")
 	_, err = adapter.Read(ctx, "name")
@@ -1,8 +1,10 @@
 package process
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
+	"os/exec"
 )
 
 // Adapter implements ProcessPort using real process operations
@@ -22,3 +24,29 @@ func New(logger *slog.Logger) *Adapter {
 func (a *Adapter) GetPID() int {
 	return os.Getpid()
 }
+
+// Hostname returns the host name reported by the kernel, or empty string on error.
+func (a *Adapter) Hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// StartBackground runs the current executable with args in its own session, with stdio
+// discarded, and returns once it has started. The child is reaped when it exits.
+func (a *Adapter) StartBackground(args []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("resolve executable: %w", err)
+	}
+	cmd := exec.Command(exe, args...) // #nosec G204 - re-runs devback itself with fixed arguments
+	cmd.SysProcAttr = detachedProcAttr()
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	a.logger.Debug("started background process", "pid", cmd.Process.Pid, "args", args)
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
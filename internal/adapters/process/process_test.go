@@ -17,6 +17,9 @@ func TestAdapter_ProcessInfo(t *testing.T) {
 	if pid != os.Getpid() {
 		t.Fatalf("expected pid %d, got %d", os.Getpid(), pid)
 	}
+	if hostname, err := os.Hostname(); err == nil && adapter.Hostname() != hostname {
+		t.Fatalf("expected hostname %q, got %q", hostname, adapter.Hostname())
+	}
 
 	if !adapter.IsProcessRunning(ctx, pid) {
 		t.Fatal("expected current process to be running")
@@ -44,3 +44,8 @@ func (a *Adapter) GetProcessInfo(ctx context.Context, pid int) (usecase.ProcessI
 		MemoryMB:   0,
 	}, nil
 }
+
+// detachedProcAttr starts the child in a new session so terminal signals do not reach it.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
@@ -64,3 +64,8 @@ func (a *Adapter) GetProcessInfo(ctx context.Context, pid int) (usecase.ProcessI
 		MemoryMB:   0,
 	}, nil
 }
+
+// detachedProcAttr starts the child without a console in its own process group.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: windows.CREATE_NEW_PROCESS_GROUP | windows.DETACHED_PROCESS}
+}
@@ -0,0 +1,118 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+const launchdLabel = "com.github.arumata.devback.backup-all"
+
+//nolint:gochecknoglobals // compiled once, read-only.
+var (
+	launchdIntervalRe = regexp.MustCompile(`<key>StartInterval</key>\s*<integer>(\d+)</integer>`)
+	launchdArgsRe     = regexp.MustCompile(`(?s)<key>ProgramArguments</key>\s*<array>(.*?)</array>`)
+	launchdStringRe   = regexp.MustCompile(`<string>(.*?)</string>`)
+)
+
+func (a *Adapter) launchdPlistPath() string {
+	return filepath.Join(a.homeDir, "Library", "LaunchAgents", launchdLabel+".plist")
+}
+
+func (a *Adapter) launchdDomain() string {
+	return "gui/" + strconv.Itoa(a.uid)
+}
+
+// launchdBootout unloads the agent; an agent that is not loaded is not an error.
+func (a *Adapter) launchdBootout(ctx context.Context) {
+	if _, err := a.run(ctx, "launchctl", "bootout", a.launchdDomain()+"/"+launchdLabel); err != nil {
+		a.logger.Debug("unload launchd agent", "error", err)
+	}
+}
+
+// renderLaunchdPlist renders a launch agent that runs spec every spec.Every at low priority.
+func renderLaunchdPlist(spec usecase.ScheduleSpec) string {
+	var args strings.Builder
+	for _, arg := range append([]string{spec.Executable}, spec.Args...) {
+		fmt.Fprintf(&args, "\t\t<string>%s</string>\n", xmlEscape(arg))
+	}
+	env := ""
+	if spec.Path != "" {
+		env = fmt.Sprintf("\t<key>EnvironmentVariables</key>\n\t<dict>\n\t\t<key>PATH</key>\n"+
+			"\t\t<string>%s</string>\n\t</dict>\n", xmlEscape(spec.Path))
+	}
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<!-- %s -->
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+%s	</array>
+%s	<key>StartInterval</key>
+	<integer>%d</integer>
+	<key>RunAtLoad</key>
+	<false/>
+	<key>ProcessType</key>
+	<string>Background</string>
+	<key>LowPriorityIO</key>
+	<true/>
+	<key>Nice</key>
+	<integer>10</integer>
+</dict>
+</plist>
+`, generatedHeader, launchdLabel, args.String(), env, int64(spec.Every/time.Second))
+}
+
+func xmlEscape(value string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;").Replace(value)
+}
+
+func xmlUnescape(value string) string {
+	return strings.NewReplacer("&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'", "&amp;", "&").Replace(value)
+}
+
+func (a *Adapter) launchdStatus(ctx context.Context) usecase.ScheduleInfo {
+	info := usecase.ScheduleInfo{Backend: backendLaunchd}
+	plistPath := a.launchdPlistPath()
+	data, err := os.ReadFile(plistPath) // #nosec G304 - plist path is built by the adapter
+	if err != nil {
+		return info
+	}
+	info.Installed = true
+	info.Files = []string{plistPath}
+	if m := launchdIntervalRe.FindSubmatch(data); m != nil {
+		if secs, err := strconv.Atoi(string(m[1])); err == nil {
+			info.Every = time.Duration(secs) * time.Second
+		}
+	}
+	if m := launchdArgsRe.FindSubmatch(data); m != nil {
+		var args []string
+		for _, s := range launchdStringRe.FindAllSubmatch(m[1], -1) {
+			args = append(args, xmlUnescape(string(s[1])))
+		}
+		info.Command = strings.Join(args, " ")
+	}
+
+	output, err := a.run(ctx, "launchctl", "print", a.launchdDomain()+"/"+launchdLabel)
+	if err != nil {
+		info.Detail = "agent is not loaded (run: devback schedule install)"
+		return info
+	}
+	info.Active = true
+	for _, line := range strings.Split(string(output), "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "last exit code = "); ok {
+			info.LastRun = "exit code " + value
+		}
+	}
+	return info
+}
@@ -0,0 +1,182 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+const (
+	backendSystemd = "systemd"
+	backendLaunchd = "launchd"
+
+	generatedHeader = "Generated by devback schedule install; remove with: devback schedule remove"
+)
+
+// runner executes a service manager command and returns its combined output.
+type runner func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+// Adapter implements SchedulerPort with systemd user units on Linux and a launchd agent on macOS.
+type Adapter struct {
+	logger  *slog.Logger
+	goos    string
+	homeDir string
+	env     func(string) string
+	uid     int
+	run     runner
+}
+
+// New creates a new scheduler adapter for the current platform.
+func New(logger *slog.Logger) *Adapter {
+	if logger == nil {
+		panic("scheduler adapter requires logger")
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		logger.Debug("resolve home dir for scheduler", "error", err)
+	}
+	return &Adapter{
+		logger:  logger,
+		goos:    runtime.GOOS,
+		homeDir: homeDir,
+		env:     os.Getenv,
+		uid:     os.Getuid(),
+		run:     runCommand,
+	}
+}
+
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	output, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		return output, fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return output, nil
+}
+
+// Backend names the service manager used on this platform.
+func (a *Adapter) Backend() string {
+	switch a.goos {
+	case "linux":
+		return backendSystemd
+	case "darwin":
+		return backendLaunchd
+	default:
+		return ""
+	}
+}
+
+// Render returns the job files for spec.
+func (a *Adapter) Render(spec usecase.ScheduleSpec) ([]usecase.ScheduleFile, error) {
+	if a.homeDir == "" {
+		return nil, errors.New("home directory is unknown")
+	}
+	switch a.Backend() {
+	case backendSystemd:
+		return []usecase.ScheduleFile{
+			{Path: a.systemdUnitPath(systemdServiceName), Content: renderSystemdService(spec)},
+			{Path: a.systemdUnitPath(systemdTimerName), Content: renderSystemdTimer(spec)},
+		}, nil
+	case backendLaunchd:
+		return []usecase.ScheduleFile{{Path: a.launchdPlistPath(), Content: renderLaunchdPlist(spec)}}, nil
+	default:
+		return nil, fmt.Errorf("scheduling is not supported on %s", a.goos)
+	}
+}
+
+// Install writes the job files and loads the job.
+func (a *Adapter) Install(ctx context.Context, spec usecase.ScheduleSpec) error {
+	files, err := a.Render(spec)
+	if err != nil {
+		return err
+	}
+	if a.Backend() == backendLaunchd {
+		// bootstrap fails for a loaded label, so an existing agent is unloaded first.
+		a.launchdBootout(ctx)
+	}
+	for _, f := range files {
+		if err := writeFile(f); err != nil {
+			return err
+		}
+	}
+	if a.Backend() == backendLaunchd {
+		_, err := a.run(ctx, "launchctl", "bootstrap", a.launchdDomain(), a.launchdPlistPath())
+		return err
+	}
+	for _, args := range [][]string{
+		{"--user", "daemon-reload"},
+		{"--user", "enable", systemdTimerName},
+		{"--user", "restart", systemdTimerName},
+	} {
+		if _, err := a.run(ctx, "systemctl", args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Status reports whether the job files exist and the job is loaded.
+func (a *Adapter) Status(ctx context.Context) (usecase.ScheduleInfo, error) {
+	switch a.Backend() {
+	case backendSystemd:
+		return a.systemdStatus(ctx), nil
+	case backendLaunchd:
+		return a.launchdStatus(ctx), nil
+	default:
+		return usecase.ScheduleInfo{}, fmt.Errorf("scheduling is not supported on %s", a.goos)
+	}
+}
+
+// Remove unloads the job and deletes its files.
+func (a *Adapter) Remove(ctx context.Context) ([]string, error) {
+	var paths []string
+	switch a.Backend() {
+	case backendSystemd:
+		paths = []string{a.systemdUnitPath(systemdTimerName), a.systemdUnitPath(systemdServiceName)}
+		if _, err := a.run(ctx, "systemctl", "--user", "disable", "--now", systemdTimerName); err != nil {
+			a.logger.Debug("disable systemd timer", "error", err)
+		}
+	case backendLaunchd:
+		paths = []string{a.launchdPlistPath()}
+		a.launchdBootout(ctx)
+	default:
+		return nil, fmt.Errorf("scheduling is not supported on %s", a.goos)
+	}
+
+	var removed []string
+	for _, p := range paths {
+		if err := os.Remove(p); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return removed, err
+		}
+		removed = append(removed, p)
+	}
+	if a.Backend() == backendSystemd && len(removed) > 0 {
+		if _, err := a.run(ctx, "systemctl", "--user", "daemon-reload"); err != nil {
+			a.logger.Debug("reload systemd user units", "error", err)
+		}
+	}
+	return removed, nil
+}
+
+func writeFile(f usecase.ScheduleFile) error {
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o750); err != nil {
+		return err
+	}
+	// #nosec G306 - unit files are read by the user's service manager and contain no secrets.
+	return os.WriteFile(f.Path, []byte(f.Content), 0o644)
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
@@ -0,0 +1,160 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+func testSpec() usecase.ScheduleSpec {
+	return usecase.ScheduleSpec{
+		Executable: "/opt/dev back/devback",
+		Args:       []string{"backup-all"},
+		Every:      90 * time.Minute,
+		Path:       "/usr/bin:/opt/50%<tools>",
+	}
+}
+
+func TestRenderSystemdUnits(t *testing.T) {
+	service := renderSystemdService(testSpec())
+	for _, want := range []string{
+		"Type=oneshot\n",
+		`ExecStart="/opt/dev back/devback" "backup-all"` + "\n",
+		`Environment="PATH=/usr/bin:/opt/50%%<tools>"` + "\n",
+	} {
+		if !strings.Contains(service, want) {
+			t.Fatalf("service missing %q:\n%s", want, service)
+		}
+	}
+	timer := renderSystemdTimer(testSpec())
+	for _, want := range []string{
+		"OnStartupSec=5min\n",
+		"OnUnitActiveSec=5400s\n",
+		"Unit=devback-backup.service\n",
+		"WantedBy=timers.target\n",
+	} {
+		if !strings.Contains(timer, want) {
+			t.Fatalf("timer missing %q:\n%s", want, timer)
+		}
+	}
+}
+
+func TestRenderLaunchdPlist(t *testing.T) {
+	plist := renderLaunchdPlist(testSpec())
+	for _, want := range []string{
+		"<string>" + launchdLabel + "</string>",
+		"<string>/opt/dev back/devback</string>\n\t\t<string>backup-all</string>",
+		"<string>/usr/bin:/opt/50%&lt;tools&gt;</string>",
+		"<key>StartInterval</key>\n\t<integer>5400</integer>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Fatalf("plist missing %q:\n%s", want, plist)
+		}
+	}
+}
+
+type recordedRunner struct {
+	calls  []string
+	output map[string]string
+}
+
+func (r *recordedRunner) run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	call := name + " " + strings.Join(args, " ")
+	r.calls = append(r.calls, call)
+	for prefix, out := range r.output {
+		if strings.HasPrefix(call, prefix) {
+			return []byte(out), nil
+		}
+	}
+	return nil, nil
+}
+
+func newTestAdapter(t *testing.T, goos string, r *recordedRunner) *Adapter {
+	t.Helper()
+	return &Adapter{
+		logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+		goos:    goos,
+		homeDir: t.TempDir(),
+		env:     func(string) string { return "" },
+		uid:     501,
+		run:     r.run,
+	}
+}
+
+func TestAdapter_SystemdLifecycle(t *testing.T) {
+	ctx := context.Background()
+	r := &recordedRunner{output: map[string]string{"systemctl --user show": "ActiveState=active\n" +
+		"LastTriggerUSec=n/a\nNextElapseUSecRealtime=Fri 2026-10-16 10:00:00 UTC\n"}}
+	a := newTestAdapter(t, "linux", r)
+
+	if err := a.Install(ctx, testSpec()); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if got := strings.Join(r.calls, "; "); !strings.Contains(got, "systemctl --user enable devback-backup.timer") {
+		t.Fatalf("expected timer to be enabled, got %s", got)
+	}
+	info, err := a.Status(ctx)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !info.Installed || !info.Active || info.Every != 90*time.Minute || len(info.Files) != 2 {
+		t.Fatalf("unexpected status: %+v", info)
+	}
+	if info.LastRun != "" || !strings.Contains(info.NextRun, "2026-10-16") {
+		t.Fatalf("unexpected run times: %+v", info)
+	}
+
+	removed, err := a.Remove(ctx)
+	if err != nil || len(removed) != 2 {
+		t.Fatalf("expected two removed units, got %v (%v)", removed, err)
+	}
+	for _, p := range removed {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be removed", p)
+		}
+	}
+	if info, _ := a.Status(ctx); info.Installed {
+		t.Fatal("expected no job after remove")
+	}
+}
+
+func TestAdapter_LaunchdLifecycle(t *testing.T) {
+	ctx := context.Background()
+	r := &recordedRunner{output: map[string]string{"launchctl print": "\tstate = not running\n\tlast exit code = 0\n"}}
+	a := newTestAdapter(t, "darwin", r)
+
+	if err := a.Install(ctx, testSpec()); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	want := "launchctl bootstrap gui/501 " + a.launchdPlistPath()
+	if r.calls[len(r.calls)-1] != want {
+		t.Fatalf("expected %q, got %v", want, r.calls)
+	}
+	info, err := a.Status(ctx)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !info.Installed || !info.Active || info.Every != 90*time.Minute ||
+		info.Command != "/opt/dev back/devback backup-all" || info.LastRun != "exit code 0" {
+		t.Fatalf("unexpected status: %+v", info)
+	}
+	if removed, err := a.Remove(ctx); err != nil || len(removed) != 1 {
+		t.Fatalf("expected plist to be removed, got %v (%v)", removed, err)
+	}
+}
+
+func TestAdapter_UnsupportedPlatform(t *testing.T) {
+	a := newTestAdapter(t, "windows", &recordedRunner{})
+	if a.Backend() != "" {
+		t.Fatal("expected no backend on windows")
+	}
+	if _, err := a.Render(testSpec()); err == nil {
+		t.Fatal("expected render error")
+	}
+}
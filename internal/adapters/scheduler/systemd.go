@@ -0,0 +1,145 @@
+package scheduler
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+const (
+	systemdServiceName = "devback-backup.service"
+	systemdTimerName   = "devback-backup.timer"
+)
+
+// systemdUnitPath returns name inside the systemd user unit directory.
+func (a *Adapter) systemdUnitPath(name string) string {
+	base := strings.TrimSpace(a.env("XDG_CONFIG_HOME"))
+	if base == "" {
+		base = filepath.Join(a.homeDir, ".config")
+	}
+	return filepath.Join(base, "systemd", "user", name)
+}
+
+// renderSystemdService renders the oneshot service started by the timer.
+func renderSystemdService(spec usecase.ScheduleSpec) string {
+	args := make([]string, 0, len(spec.Args)+1)
+	for _, arg := range append([]string{spec.Executable}, spec.Args...) {
+		args = append(args, systemdQuote(strings.ReplaceAll(arg, "$", "$$")))
+	}
+	env := ""
+	if spec.Path != "" {
+		env = "Environment=" + systemdQuote("PATH="+spec.Path) + "\n"
+	}
+	return fmt.Sprintf(`# %s
+[Unit]
+Description=devback backup of all enabled repositories
+Documentation=https://github.com/arumata/devback#devback-schedule
+
+[Service]
+Type=oneshot
+ExecStart=%s
+%sNice=10
+IOSchedulingClass=idle
+`, generatedHeader, strings.Join(args, " "), env)
+}
+
+// renderSystemdTimer renders the timer that starts the service every spec.Every; the first run
+// follows five minutes after the user manager starts, i.e. after login.
+func renderSystemdTimer(spec usecase.ScheduleSpec) string {
+	return fmt.Sprintf(`# %s
+[Unit]
+Description=Run devback backup-all periodically
+
+[Timer]
+OnStartupSec=5min
+OnUnitActiveSec=%ds
+AccuracySec=1min
+Unit=%s
+
+[Install]
+WantedBy=timers.target
+`, generatedHeader, int64(spec.Every/time.Second), systemdServiceName)
+}
+
+// systemdQuote double-quotes value for a unit file, escaping backslashes, quotes and specifiers.
+func systemdQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	value = strings.ReplaceAll(value, "%", "%%")
+	return `"` + value + `"`
+}
+
+func (a *Adapter) systemdStatus(ctx context.Context) usecase.ScheduleInfo {
+	info := usecase.ScheduleInfo{Backend: backendSystemd}
+	timerPath := a.systemdUnitPath(systemdTimerName)
+	servicePath := a.systemdUnitPath(systemdServiceName)
+	for _, p := range []string{servicePath, timerPath} {
+		if fileExists(p) {
+			info.Files = append(info.Files, p)
+		}
+	}
+	if !fileExists(timerPath) {
+		return info
+	}
+	info.Installed = true
+	if value := unitValue(timerPath, "OnUnitActiveSec"); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			info.Every = d
+		}
+	}
+	info.Command = unitValue(servicePath, "ExecStart")
+
+	output, err := a.run(ctx, "systemctl", "--user", "show", systemdTimerName,
+		"--property=ActiveState,LastTriggerUSec,NextElapseUSecRealtime")
+	if err != nil {
+		info.Detail = err.Error()
+		return info
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "ActiveState":
+			info.Active = value == "active"
+		case "LastTriggerUSec":
+			info.LastRun = systemdTimestamp(value)
+		case "NextElapseUSecRealtime":
+			info.NextRun = systemdTimestamp(value)
+		}
+	}
+	return info
+}
+
+// unitValue returns the first value of key in a unit file, or "" when it is missing.
+func unitValue(path, key string) string {
+	file, err := os.Open(path) // #nosec G304 - unit path is built by the adapter
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = file.Close() }()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), key+"="); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// systemdTimestamp hides the placeholders systemctl prints for timestamps that are not set.
+func systemdTimestamp(value string) string {
+	switch value = strings.TrimSpace(value); value {
+	case "", "0", "n/a":
+		return ""
+	default:
+		return value
+	}
+}
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+// dirPartSuffix marks files still being copied; they are never listed.
+const dirPartSuffix = ".devback-part"
+
+// dirStore mirrors snapshots into a directory, typically on a second disk. The directory
+// must already exist: a missing one usually means the disk is not mounted, and creating it
+// would fill the mount point on the system disk instead.
+type dirStore struct {
+	root string
+}
+
+func (a *Adapter) openDir(target usecase.TargetSettings) (*dirStore, error) {
+	root := filepath.Join(target.Path, filepath.FromSlash(target.Prefix))
+	info, err := os.Stat(target.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s does not exist (not mounted?): %w", target.Path, usecase.ErrTargetUnavailable)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", target.Path, usecase.ErrTargetUnavailable)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", target.Path)
+	}
+	if err := os.MkdirAll(root, 0o700); err != nil {
+		return nil, err
+	}
+	a.logger.Debug("opened dir target", "path", root)
+	return &dirStore{root: root}, nil
+}
+
+func (s *dirStore) localPath(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(key))
+}
+
+// List returns the files below prefix; files still being copied are left out.
+func (s *dirStore) List(ctx context.Context, prefix string) ([]usecase.StoredObject, error) {
+	dir := s.root
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		dir = s.localPath(prefix[:i])
+	}
+	var objects []usecase.StoredObject
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !d.Type().IsRegular() || strings.HasSuffix(d.Name(), dirPartSuffix) {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, usecase.StoredObject{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// Put copies src next to its destination, syncs it and renames it into place.
+func (s *dirStore) Put(ctx context.Context, key, src string) error {
+	dst := s.localPath(key)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+		return err
+	}
+	tmp := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+dirPartSuffix)
+	if err := copyFileSynced(ctx, src, tmp); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
+
+// Get copies key to dst.
+func (s *dirStore) Get(ctx context.Context, key, dst string) error {
+	return copyFileSynced(ctx, s.localPath(key), dst)
+}
+
+// Delete removes key and the directories it leaves empty; a missing key is not an error.
+func (s *dirStore) Delete(ctx context.Context, key string) error {
+	p := s.localPath(key)
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for dir := filepath.Dir(p); dir != s.root && strings.HasPrefix(dir, s.root); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// Close is a no-op for directories.
+func (s *dirStore) Close() error {
+	return nil
+}
+
+func copyFileSynced(ctx context.Context, src, dst string) error {
+	in, err := os.Open(src) // #nosec G304 - paths are chosen by the usecase
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	// #nosec G304 - paths are chosen by the usecase
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, contextReader{ctx: ctx, r: in}); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// contextReader stops a copy once ctx is canceled.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+func TestDirStore_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	mount := t.TempDir()
+	a := newTestStorage(nil)
+	target := usecase.TargetSettings{Name: "usb", Type: usecase.TargetTypeDir, Path: mount, Prefix: "laptop"}
+	opened, err := a.Open(ctx, target)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	store := opened.(*dirStore)
+	defer func() { _ = store.Close() }()
+
+	dir := t.TempDir()
+	small := writeTestFile(t, dir, "small", "hello")
+	empty := writeTestFile(t, dir, "empty", "")
+	for key, src := range map[string]string{
+		"repo/2026-10-16/100000/objects/pack/a.pack": small,
+		"repo/2026-10-16/100000/.done":               empty,
+		"other/2026-10-16/100000/.done":              empty,
+	} {
+		if err := store.Put(ctx, key, src); err != nil {
+			t.Fatalf("put %s: %v", key, err)
+		}
+	}
+	stored := filepath.Join(mount, "laptop", "repo", "2026-10-16", "100000", "objects", "pack", "a.pack")
+	if data, _ := os.ReadFile(stored); string(data) != "hello" {
+		t.Fatalf("expected plain file on the mirror, got %q", data)
+	}
+	// A copy interrupted before its rename must stay invisible.
+	writeTestFile(t, filepath.Dir(stored), ".b.pack"+dirPartSuffix, "half")
+
+	objects, err := store.List(ctx, "repo/")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	sizes := map[string]int64{}
+	for _, obj := range objects {
+		sizes[obj.Key] = obj.Size
+	}
+	want := map[string]int64{"repo/2026-10-16/100000/objects/pack/a.pack": 5, "repo/2026-10-16/100000/.done": 0}
+	if len(sizes) != len(want) {
+		t.Fatalf("unexpected listing: %v", sizes)
+	}
+	for key, size := range want {
+		if got, ok := sizes[key]; !ok || got != size {
+			t.Fatalf("unexpected listing for %s: %v", key, sizes)
+		}
+	}
+
+	dst := filepath.Join(dir, "copy")
+	if err := store.Get(ctx, "repo/2026-10-16/100000/objects/pack/a.pack", dst); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "hello" {
+		t.Fatalf("unexpected content %q", data)
+	}
+
+	if err := store.Delete(ctx, "other/2026-10-16/100000/.done"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(mount, "laptop", "other")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected empty directories pruned: %v", err)
+	}
+	if err := store.Delete(ctx, "other/2026-10-16/100000/.done"); err != nil {
+		t.Fatalf("deleting a missing key must succeed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(mount, "laptop")); err != nil {
+		t.Fatalf("pruning must stop at the target root: %v", err)
+	}
+}
+
+func TestDirStore_OpenErrors(t *testing.T) {
+	ctx := context.Background()
+	a := newTestStorage(nil)
+	missing := filepath.Join(t.TempDir(), "usb")
+	_, err := a.Open(ctx, usecase.TargetSettings{Name: "usb", Type: usecase.TargetTypeDir, Path: missing})
+	if !errors.Is(err, usecase.ErrTargetUnavailable) {
+		t.Fatalf("expected unavailable target for a missing mount, got %v", err)
+	}
+	if _, statErr := os.Stat(missing); !errors.Is(statErr, os.ErrNotExist) {
+		t.Fatal("a missing mount point must not be created")
+	}
+
+	file := writeTestFile(t, t.TempDir(), "file", "x")
+	_, err = a.Open(ctx, usecase.TargetSettings{Name: "usb", Type: usecase.TargetTypeDir, Path: file})
+	if err == nil || errors.Is(err, usecase.ErrTargetUnavailable) {
+		t.Fatalf("expected configuration error for a file path, got %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+const (
+	s3DefaultRegion = "us-east-1"
+	s3PartSize      = 64 << 20 // files larger than this are uploaded in parts
+	s3MaxRetries    = 3
+)
+
+// s3Store talks to an S3-compatible service (AWS S3, MinIO, ...) through minio-go with
+// path-style requests.
+type s3Store struct {
+	client   *minio.Client
+	bucket   string
+	prefix   string
+	partSize uint64
+}
+
+type s3Credentials struct {
+	accessKey    string
+	secretKey    string
+	sessionToken string
+}
+
+func (a *Adapter) openS3(ctx context.Context, target usecase.TargetSettings) (*s3Store, error) {
+	region := target.Region
+	if region == "" {
+		region = s3DefaultRegion
+	}
+	endpoint := target.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") ||
+		strings.Trim(u.Path, "/") != "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", endpoint)
+	}
+	creds, err := loadS3Credentials(target.CredentialsFile, a.env)
+	if err != nil {
+		return nil, err
+	}
+	client, err := minio.New(u.Host, &minio.Options{
+		Creds:        credentials.NewStaticV4(creds.accessKey, creds.secretKey, creds.sessionToken),
+		Secure:       u.Scheme == "https",
+		Region:       region,
+		BucketLookup: minio.BucketLookupPath,
+		MaxRetries:   s3MaxRetries,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint %q: %w", endpoint, err)
+	}
+	exists, err := client.BucketExists(ctx, target.Bucket)
+	if err != nil {
+		var resp minio.ErrorResponse
+		if !errors.As(err, &resp) && ctx.Err() == nil {
+			// No S3 response at all: offline or the endpoint is down.
+			return nil, fmt.Errorf("%s unreachable (%v): %w", u.Host, err, usecase.ErrTargetUnavailable)
+		}
+		return nil, fmt.Errorf("bucket %s: %w", target.Bucket, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("bucket %s does not exist", target.Bucket)
+	}
+	return &s3Store{client: client, bucket: target.Bucket, prefix: target.Prefix, partSize: s3PartSize}, nil
+}
+
+// loadS3Credentials reads "key = value" lines (AWS credentials file names are accepted) from
+// path, or falls back to AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN.
+func loadS3Credentials(path string, env func(string) string) (s3Credentials, error) {
+	if path == "" {
+		creds := s3Credentials{
+			accessKey:    env("AWS_ACCESS_KEY_ID"),
+			secretKey:    env("AWS_SECRET_ACCESS_KEY"),
+			sessionToken: env("AWS_SESSION_TOKEN"),
+		}
+		if creds.accessKey == "" || creds.secretKey == "" {
+			return s3Credentials{}, errors.New(
+				"no S3 credentials: set credentials_file or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+		}
+		return creds, nil
+	}
+	file, err := os.Open(path) // #nosec G304 - path comes from the user's config
+	if err != nil {
+		return s3Credentials{}, fmt.Errorf("read credentials: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	var creds s3Credentials
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimPrefix(strings.ToLower(strings.TrimSpace(key)), "aws_") {
+		case "access_key_id":
+			creds.accessKey = value
+		case "secret_access_key":
+			creds.secretKey = value
+		case "session_token":
+			creds.sessionToken = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return s3Credentials{}, fmt.Errorf("read credentials: %w", err)
+	}
+	if creds.accessKey == "" || creds.secretKey == "" {
+		return s3Credentials{}, fmt.Errorf("%s: access_key_id and secret_access_key are required", path)
+	}
+	return creds, nil
+}
+
+func (s *s3Store) objectKey(key string) string {
+	if s.prefix == "" {
+		return key
+	}
+	return s.prefix + "/" + key
+}
+
+// List returns the objects below prefix.
+func (s *s3Store) List(ctx context.Context, prefix string) ([]usecase.StoredObject, error) {
+	var objects []usecase.StoredObject
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{
+		Prefix:    s.objectKey(prefix),
+		Recursive: true,
+	}) {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("s3: list: %w", obj.Err)
+		}
+		key := obj.Key
+		if s.prefix != "" {
+			key = strings.TrimPrefix(key, s.prefix+"/")
+		}
+		objects = append(objects, usecase.StoredObject{Key: key, Size: obj.Size, ModTime: obj.LastModified})
+	}
+	return objects, nil
+}
+
+// Put uploads src with a Content-MD5 per request, in parts when it is larger than the part size.
+func (s *s3Store) Put(ctx context.Context, key, src string) error {
+	_, err := s.client.FPutObject(ctx, s.bucket, s.objectKey(key), src, minio.PutObjectOptions{
+		PartSize:       s.partSize,
+		SendContentMd5: true,
+	})
+	if err != nil {
+		return fmt.Errorf("s3: put %s: %w", key, err)
+	}
+	return nil
+}
+
+// Get downloads key into dst.
+func (s *s3Store) Get(ctx context.Context, key, dst string) error {
+	obj, err := s.client.GetObject(ctx, s.bucket, s.objectKey(key), minio.GetObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("s3: get %s: %w", key, err)
+	}
+	defer func() { _ = obj.Close() }()
+	file, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600) // #nosec G304 - dst is chosen by the usecase
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, obj); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("s3: get %s: %w", key, err)
+	}
+	return file.Close()
+}
+
+// Delete removes key; S3 reports success for missing keys as well.
+func (s *s3Store) Delete(ctx context.Context, key string) error {
+	err := s.client.RemoveObject(ctx, s.bucket, s.objectKey(key), minio.RemoveObjectOptions{})
+	if err != nil && minio.ToErrorResponse(err).Code != minio.NoSuchKey {
+		return fmt.Errorf("s3: delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// Close is a no-op: the minio client only holds pooled connections.
+func (s *s3Store) Close() error {
+	return nil
+}
@@ -0,0 +1,335 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5" // #nosec G501 - the S3 protocol digest
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+// fakeS3 is a minimal in-memory MinIO stand-in. It checks the access key, decodes
+// aws-chunked bodies and verifies Content-MD5 when the client sends one.
+type fakeS3 struct {
+	bucket    string
+	accessKey string
+	mu        sync.Mutex
+	objects   map[string][]byte
+	uploads   map[string]map[int][]byte
+	md5s      int
+	pages     int
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	f := &fakeS3{
+		bucket:    "backups",
+		accessKey: "AK",
+		objects:   map[string][]byte{},
+		uploads:   map[string]map[int][]byte{},
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !strings.Contains(r.Header.Get("Authorization"), "Credential="+f.accessKey+"/") {
+		f.fail(w, http.StatusForbidden, "InvalidAccessKeyId")
+		return
+	}
+	rest, ok := strings.CutPrefix(r.URL.Path, "/"+f.bucket)
+	if !ok {
+		f.fail(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	key := strings.TrimPrefix(rest, "/")
+	query := r.URL.Query()
+	var body []byte
+	if r.Method == http.MethodPut || r.Method == http.MethodPost {
+		var err error
+		if body, err = readS3Body(r); err != nil {
+			f.fail(w, http.StatusBadRequest, "BadDigest")
+			return
+		}
+	}
+	switch {
+	case key == "" && r.Method == http.MethodHead:
+	case key == "" && r.Method == http.MethodGet:
+		f.list(w, query.Get("prefix"), query.Get("continuation-token"))
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		id := fmt.Sprintf("upload-%d", len(f.uploads)+1)
+		f.uploads[id] = map[int][]byte{}
+		_, _ = fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", id)
+	case r.Method == http.MethodPut && query.Has("partNumber"):
+		var n int
+		_, _ = fmt.Sscanf(query.Get("partNumber"), "%d", &n)
+		f.uploads[query.Get("uploadId")][n] = body
+		f.countMD5(r)
+		w.Header().Set("ETag", fmt.Sprintf("%q", fmt.Sprintf("etag-%d", n)))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		var complete struct {
+			Parts []struct {
+				PartNumber int `xml:"PartNumber"`
+			} `xml:"Part"`
+		}
+		if err := xml.Unmarshal(body, &complete); err != nil {
+			f.fail(w, http.StatusBadRequest, "MalformedXML")
+			return
+		}
+		var data []byte
+		for _, p := range complete.Parts {
+			data = append(data, f.uploads[query.Get("uploadId")][p.PartNumber]...)
+		}
+		delete(f.uploads, query.Get("uploadId"))
+		f.objects[key] = data
+		_, _ = fmt.Fprintf(w, "<CompleteMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key>"+
+			"<ETag>&quot;etag&quot;</ETag></CompleteMultipartUploadResult>", f.bucket, key)
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		f.objects[key] = body
+		f.countMD5(r)
+		w.Header().Set("ETag", `"etag"`)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			f.fail(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", "Fri, 16 Oct 2026 10:00:00 GMT")
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		f.fail(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+// readS3Body returns the request payload, decoding aws-chunked streaming uploads and
+// checking Content-MD5.
+func readS3Body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		return data, checkMD5(r, data)
+	}
+	var data []byte
+	br := bufio.NewReader(r.Body)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return data, checkMD5(r, data)
+		}
+		chunk := make([]byte, size+2) // payload and CRLF
+		if _, err := io.ReadFull(br, chunk); err != nil {
+			return nil, err
+		}
+		data = append(data, chunk[:size]...)
+	}
+}
+
+func checkMD5(r *http.Request, data []byte) error {
+	if want := r.Header.Get("Content-Md5"); want != "" {
+		sum := md5.Sum(data) // #nosec G401 - the S3 protocol digest
+		if base64.StdEncoding.EncodeToString(sum[:]) != want {
+			return errors.New("content-md5 mismatch")
+		}
+	}
+	return nil
+}
+
+func (f *fakeS3) countMD5(r *http.Request) {
+	if r.Header.Get("Content-Md5") != "" {
+		f.md5s++
+	}
+}
+
+// list returns at most two keys per page to exercise continuation tokens.
+func (f *fakeS3) list(w http.ResponseWriter, prefix, after string) {
+	f.pages++
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) && key > after {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	truncated := len(keys) > 2
+	if truncated {
+		keys = keys[:2]
+	}
+	var b strings.Builder
+	b.WriteString("<ListBucketResult>")
+	for _, key := range keys {
+		fmt.Fprintf(&b, "<Contents><Key>%s</Key><Size>%d</Size>", key, len(f.objects[key]))
+		b.WriteString("<LastModified>2026-10-16T10:00:00.000Z</LastModified></Contents>")
+	}
+	if truncated {
+		fmt.Fprintf(&b, "<IsTruncated>true</IsTruncated><NextContinuationToken>%s</NextContinuationToken>", keys[1])
+	}
+	b.WriteString("</ListBucketResult>")
+	_, _ = io.WriteString(w, b.String())
+}
+
+func (f *fakeS3) fail(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
+
+func newTestStorage(env map[string]string) *Adapter {
+	a := New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	a.env = func(key string) string { return env[key] }
+	return a
+}
+
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestS3Store_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	fake, srv := newFakeS3(t)
+	a := newTestStorage(map[string]string{"AWS_ACCESS_KEY_ID": "AK", "AWS_SECRET_ACCESS_KEY": "SK"})
+	target := usecase.TargetSettings{
+		Type: usecase.TargetTypeS3, Endpoint: srv.URL, Bucket: "backups", Prefix: "laptop", Region: "eu-test-1",
+	}
+	opened, err := a.Open(ctx, target)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	store := opened.(*s3Store)
+	defer func() { _ = store.Close() }()
+
+	dir := t.TempDir()
+	small := writeTestFile(t, dir, "small", "hello")
+	store.partSize = 5 << 20 // the smallest part size S3 accepts
+	content := strings.Repeat("0123456789", 1<<19) + "tail"
+	large := writeTestFile(t, dir, "large", content)
+	for key, src := range map[string]string{
+		"repo/2026-10-16/100000/a b+c.txt": small,
+		"repo/2026-10-16/100000/.done":     small,
+		"repo/2026-10-16/100000/big.tar":   large,
+	} {
+		if err := store.Put(ctx, key, src); err != nil {
+			t.Fatalf("put %s: %v", key, err)
+		}
+	}
+	if string(fake.objects["laptop/repo/2026-10-16/100000/big.tar"]) != content {
+		t.Fatalf("multipart upload not assembled: %d bytes", len(fake.objects["laptop/repo/2026-10-16/100000/big.tar"]))
+	}
+	if len(fake.uploads) != 0 {
+		t.Fatalf("expected no pending multipart uploads, got %d", len(fake.uploads))
+	}
+	if fake.md5s != 4 {
+		t.Fatalf("expected Content-MD5 on 2 objects and 2 parts, got %d", fake.md5s)
+	}
+
+	objects, err := store.List(ctx, "repo/")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(objects) != 3 || fake.pages != 2 {
+		t.Fatalf("expected 3 objects over 2 pages, got %d over %d: %+v", len(objects), fake.pages, objects)
+	}
+	if objects[0].Key != "repo/2026-10-16/100000/.done" || objects[1].Size != 5 || objects[0].ModTime.IsZero() {
+		t.Fatalf("unexpected objects: %+v", objects)
+	}
+
+	dst := filepath.Join(dir, "download")
+	if err := store.Get(ctx, "repo/2026-10-16/100000/a b+c.txt", dst); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "hello" {
+		t.Fatalf("unexpected download %q", data)
+	}
+	if err := store.Get(ctx, "repo/missing", dst); err == nil {
+		t.Fatal("expected error for missing object")
+	}
+	if err := store.Delete(ctx, "repo/2026-10-16/100000/a b+c.txt"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, ok := fake.objects["laptop/repo/2026-10-16/100000/a b+c.txt"]; ok {
+		t.Fatal("expected object to be deleted")
+	}
+}
+
+func TestS3Store_OpenErrors(t *testing.T) {
+	ctx := context.Background()
+	_, srv := newFakeS3(t)
+	target := usecase.TargetSettings{Type: usecase.TargetTypeS3, Endpoint: srv.URL, Bucket: "backups", Region: "eu-test-1"}
+
+	if _, err := newTestStorage(nil).Open(ctx, target); err == nil || !strings.Contains(err.Error(), "credentials") {
+		t.Fatalf("expected missing credentials error, got %v", err)
+	}
+	wrong := newTestStorage(map[string]string{"AWS_ACCESS_KEY_ID": "wrong", "AWS_SECRET_ACCESS_KEY": "SK"})
+	var resp minio.ErrorResponse
+	if _, err := wrong.Open(ctx, target); !errors.As(err, &resp) || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected access denied, got %v", err)
+	}
+	target.Bucket = "other"
+	right := newTestStorage(map[string]string{"AWS_ACCESS_KEY_ID": "AK", "AWS_SECRET_ACCESS_KEY": "SK"})
+	if _, err := right.Open(ctx, target); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("expected missing bucket error, got %v", err)
+	}
+	target.Type = "ftp"
+	if _, err := right.Open(ctx, target); err == nil {
+		t.Fatal("expected unsupported type error")
+	}
+}
+
+func TestLoadS3Credentials_File(t *testing.T) {
+	path := writeTestFile(t, t.TempDir(), "credentials",
+		"[default]\naws_access_key_id = AK\naws_secret_access_key = S=K\n# comment\n")
+	creds, err := loadS3Credentials(path, func(string) string { return "" })
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if creds.accessKey != "AK" || creds.secretKey != "S=K" {
+		t.Fatalf("unexpected credentials: %+v", creds)
+	}
+	empty := writeTestFile(t, t.TempDir(), "credentials", "access_key_id = AK\n")
+	if _, err := loadS3Credentials(empty, nil); err == nil {
+		t.Fatal("expected error without secret key")
+	}
+}
@@ -0,0 +1,465 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+const (
+	sshDefaultPort   = "22"
+	sshDialTimeout   = 30 * time.Second
+	sftpPartSuffix   = ".devback-part" // uploads in progress; resumed by the next Put
+	sftpFilePerm     = 0o600
+	sftpDirPerm      = 0o700
+	sftpDefaultRoot  = "."
+	sshDefaultKeyDir = ".ssh"
+	sftpPosixRename  = "posix-rename@openssh.com"
+)
+
+// sshDefaultIdentities are tried in ~/.ssh when identity_file is not set, as ssh(1) does.
+var sshDefaultIdentities = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// sftpStore keeps snapshots as plain files below root on an SSH server. A file is written
+// to a hidden temporary name next to its final path and renamed when complete, so a
+// half-written object is never listed; an interrupted upload continues where it stopped.
+// Writes stay sequential so that a temporary file never has holes to resume over.
+type sftpStore struct {
+	conn   *ssh.Client
+	client *sftp.Client
+	agent  net.Conn
+	root   string
+
+	mu   sync.Mutex
+	dirs map[string]bool
+}
+
+// sftpAddress is a parsed target url: "user@host:/path" or "sftp://user@host:port/path".
+type sftpAddress struct {
+	user string
+	host string
+	port string
+	path string
+}
+
+func parseSFTPURL(raw string) (sftpAddress, error) {
+	var addr sftpAddress
+	if strings.HasPrefix(raw, "sftp://") || strings.HasPrefix(raw, "ssh://") {
+		u, err := url.Parse(raw)
+		if err != nil || u.Hostname() == "" {
+			return addr, fmt.Errorf("invalid sftp url %q", raw)
+		}
+		addr = sftpAddress{user: u.User.Username(), host: u.Hostname(), port: u.Port(), path: u.Path}
+	} else {
+		rest := raw
+		if at := strings.Index(rest, "@"); at >= 0 && at < strings.Index(rest+":", ":") {
+			addr.user, rest = rest[:at], rest[at+1:]
+		}
+		if strings.HasPrefix(rest, "[") {
+			end := strings.Index(rest, "]:")
+			if end < 0 {
+				return addr, fmt.Errorf("invalid sftp url %q: expected [host]:path", raw)
+			}
+			addr.host, addr.path = rest[1:end], rest[end+2:]
+		} else {
+			host, p, ok := strings.Cut(rest, ":")
+			if !ok {
+				return addr, fmt.Errorf("invalid sftp url %q: expected user@host:/path", raw)
+			}
+			addr.host, addr.path = host, p
+		}
+		if addr.host == "" {
+			return addr, fmt.Errorf("invalid sftp url %q: missing host", raw)
+		}
+	}
+	if addr.port == "" {
+		addr.port = sshDefaultPort
+	}
+	if addr.path == "" {
+		addr.path = sftpDefaultRoot
+	}
+	return addr, nil
+}
+
+func (a *Adapter) openSFTP(ctx context.Context, target usecase.TargetSettings) (*sftpStore, error) {
+	addr, err := parseSFTPURL(target.URL)
+	if err != nil {
+		return nil, err
+	}
+	if addr.user == "" {
+		addr.user = a.env("USER")
+	}
+	if addr.user == "" {
+		return nil, fmt.Errorf("sftp url %q: user is required", target.URL)
+	}
+	hostKeys, err := a.sshHostKeyCallback(target.KnownHostsFile)
+	if err != nil {
+		return nil, err
+	}
+	auth, agentConn, err := a.sshAuthMethods(target.IdentityFile)
+	if err != nil {
+		return nil, err
+	}
+	store := &sftpStore{agent: agentConn, root: path.Join(addr.path, target.Prefix), dirs: map[string]bool{}}
+	fail := func(err error) (*sftpStore, error) {
+		_ = store.Close()
+		return nil, err
+	}
+
+	hostPort := net.JoinHostPort(addr.host, addr.port)
+	dialer := net.Dialer{Timeout: sshDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", hostPort)
+	if err != nil {
+		return fail(fmt.Errorf("%s unreachable (%v): %w", hostPort, err, usecase.ErrTargetUnavailable))
+	}
+	_ = conn.SetDeadline(time.Now().Add(sshDialTimeout))
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, hostPort, &ssh.ClientConfig{
+		User:            addr.user,
+		Auth:            auth,
+		HostKeyCallback: hostKeys,
+		Timeout:         sshDialTimeout,
+	})
+	if err != nil {
+		_ = conn.Close()
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) && len(keyErr.Want) == 0 {
+			return fail(fmt.Errorf("host key of %s is not in known_hosts; connect once with ssh to add it", hostPort))
+		}
+		return fail(fmt.Errorf("ssh %s: %w", hostPort, err))
+	}
+	_ = conn.SetDeadline(time.Time{})
+	store.conn = ssh.NewClient(sshConn, chans, reqs)
+
+	if store.client, err = sftp.NewClient(store.conn); err != nil {
+		return fail(fmt.Errorf("start sftp subsystem: %w", err))
+	}
+	if err := store.mkdirAll(ctx, store.root); err != nil {
+		return fail(fmt.Errorf("create %s: %w", store.root, err))
+	}
+	return store, nil
+}
+
+// sshHostKeyCallback verifies server keys against known_hosts (~/.ssh/known_hosts by default).
+func (a *Adapter) sshHostKeyCallback(file string) (ssh.HostKeyCallback, error) {
+	if file == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("known_hosts: %w", err)
+		}
+		file = filepath.Join(home, sshDefaultKeyDir, "known_hosts")
+	}
+	callback, err := knownhosts.New(file)
+	if err != nil {
+		return nil, fmt.Errorf("known_hosts: %w", err)
+	}
+	return callback, nil
+}
+
+// sshAuthMethods offers the keys of a running ssh-agent first, then identity_file or the
+// default keys in ~/.ssh. Passphrase-protected keys can only be used through the agent.
+func (a *Adapter) sshAuthMethods(identityFile string) ([]ssh.AuthMethod, net.Conn, error) {
+	var (
+		methods   []ssh.AuthMethod
+		agentConn net.Conn
+	)
+	if sock := a.env("SSH_AUTH_SOCK"); sock != "" {
+		conn, err := net.Dial("unix", sock)
+		if err != nil {
+			a.logger.Debug("ssh-agent unavailable", "socket", sock, "error", err)
+		} else {
+			agentConn = conn
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+
+	var signers []ssh.Signer
+	if identityFile != "" {
+		signer, err := loadSSHIdentity(identityFile)
+		if err != nil && agentConn == nil {
+			return nil, nil, fmt.Errorf("identity_file: %w", err)
+		}
+		if err != nil {
+			a.logger.Debug("identity_file not usable, relying on ssh-agent", "file", identityFile, "error", err)
+		} else {
+			signers = append(signers, signer)
+		}
+	} else if home, err := os.UserHomeDir(); err == nil {
+		for _, name := range sshDefaultIdentities {
+			if signer, err := loadSSHIdentity(filepath.Join(home, sshDefaultKeyDir, name)); err == nil {
+				signers = append(signers, signer)
+			}
+		}
+	}
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+	if len(methods) == 0 {
+		return nil, nil, errors.New("no ssh keys: start ssh-agent or set identity_file")
+	}
+	return methods, agentConn, nil
+}
+
+func loadSSHIdentity(file string) (ssh.Signer, error) {
+	data, err := os.ReadFile(file) // #nosec G304 - path comes from the user's config
+	if err != nil {
+		return nil, err
+	}
+	signer, err := ssh.ParsePrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		return nil, fmt.Errorf("%s is protected by a passphrase; add it to ssh-agent", file)
+	}
+	return signer, err
+}
+
+func (s *sftpStore) remotePath(key string) string {
+	return path.Join(s.root, key)
+}
+
+// mkdirAll creates dir and its parents; directories known to exist are remembered.
+func (s *sftpStore) mkdirAll(ctx context.Context, dir string) error {
+	s.mu.Lock()
+	known := s.dirs[dir]
+	s.mu.Unlock()
+	if known || dir == "/" || dir == "." {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	info, err := s.client.Stat(dir)
+	switch {
+	case err == nil && !info.IsDir():
+		return fmt.Errorf("%s is not a directory", dir)
+	case err != nil && !errors.Is(err, fs.ErrNotExist):
+		return err
+	case err != nil:
+		if err := s.mkdirAll(ctx, path.Dir(dir)); err != nil {
+			return err
+		}
+		if err := s.client.Mkdir(dir); err != nil {
+			// Another upload may have created it meanwhile.
+			if info, serr := s.client.Stat(dir); serr != nil || !info.IsDir() {
+				return err
+			}
+		} else if err := s.client.Chmod(dir, sftpDirPerm); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	s.dirs[dir] = true
+	s.mu.Unlock()
+	return nil
+}
+
+// List returns the files below prefix; files still being uploaded are left out.
+func (s *sftpStore) List(ctx context.Context, prefix string) ([]usecase.StoredObject, error) {
+	dir := prefix
+	if !strings.HasSuffix(dir, "/") {
+		dir = path.Dir(dir)
+	}
+	dir = strings.Trim(dir, "/")
+	if dir == "." {
+		dir = ""
+	}
+	var objects []usecase.StoredObject
+	err := s.walk(ctx, dir, func(key string, info fs.FileInfo) {
+		if strings.HasPrefix(key, prefix) && !strings.HasSuffix(key, sftpPartSuffix) {
+			objects = append(objects, usecase.StoredObject{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// walk calls fn for every regular file below the directory rel; missing directories are empty.
+func (s *sftpStore) walk(ctx context.Context, rel string, fn func(key string, info fs.FileInfo)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	entries, err := s.client.ReadDir(s.remotePath(rel))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("list %s: %w", s.remotePath(rel), err)
+	}
+	for _, entry := range entries {
+		key := entry.Name()
+		if rel != "" {
+			key = rel + "/" + entry.Name()
+		}
+		switch {
+		case entry.IsDir():
+			if err := s.walk(ctx, key, fn); err != nil {
+				return err
+			}
+		case entry.Mode().IsRegular():
+			fn(key, entry)
+		}
+	}
+	return nil
+}
+
+// Put uploads src to a temporary file and renames it into place. When an earlier upload of
+// the same key was interrupted, the transfer continues after the bytes already stored.
+func (s *sftpStore) Put(ctx context.Context, key, src string) error {
+	file, err := os.Open(src) // #nosec G304 - src is a snapshot file chosen by the usecase
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	dst := s.remotePath(key)
+	if err := s.mkdirAll(ctx, path.Dir(dst)); err != nil {
+		return fmt.Errorf("create %s: %w", path.Dir(dst), err)
+	}
+	tmp := path.Join(path.Dir(dst), "."+path.Base(dst)+sftpPartSuffix)
+
+	var offset int64
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if part, err := s.client.Stat(tmp); err == nil && part.Size() > 0 && part.Size() <= info.Size() {
+		offset = part.Size()
+		flags = os.O_WRONLY | os.O_CREATE
+	}
+	remote, err := s.client.OpenFile(tmp, flags)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", tmp, err)
+	}
+	err = s.writeFrom(ctx, remote, file, offset)
+	if cerr := remote.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("write %s: %w", tmp, err)
+	}
+	if err := s.rename(tmp, dst); err != nil {
+		return fmt.Errorf("rename %s: %w", tmp, err)
+	}
+	return nil
+}
+
+// writeFrom copies file from offset on into remote at the same offset; a new temporary
+// file gets sftpFilePerm first.
+func (s *sftpStore) writeFrom(ctx context.Context, remote *sftp.File, file *os.File, offset int64) error {
+	if offset == 0 {
+		if err := remote.Chmod(sftpFilePerm); err != nil {
+			return err
+		}
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := remote.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := remote.ReadFrom(contextReader{ctx: ctx, r: file})
+	return err
+}
+
+// rename replaces newName atomically when the server supports posix-rename@openssh.com;
+// plain SSH_FXP_RENAME fails if newName exists, so it is removed first.
+func (s *sftpStore) rename(oldName, newName string) error {
+	if _, ok := s.client.HasExtension(sftpPosixRename); ok {
+		return s.client.PosixRename(oldName, newName)
+	}
+	if err := s.client.Remove(newName); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return s.client.Rename(oldName, newName)
+}
+
+// Get downloads key to dst.
+func (s *sftpStore) Get(ctx context.Context, key, dst string) error {
+	name := s.remotePath(key)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	remote, err := s.client.Open(name)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", name, err)
+	}
+	defer func() { _ = remote.Close() }()
+	file, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600) // #nosec G304 - dst is chosen by the usecase
+	if err != nil {
+		return err
+	}
+	// WriteTo keeps several reads in flight; contextWriter stops it once ctx is canceled.
+	if _, err := remote.WriteTo(contextWriter{ctx: ctx, w: file}); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("read %s: %w", name, err)
+	}
+	return file.Close()
+}
+
+// Delete removes key and the directories it leaves empty; a missing key is not an error.
+func (s *sftpStore) Delete(ctx context.Context, key string) error {
+	name := s.remotePath(key)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := s.client.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove %s: %w", name, err)
+	}
+	for dir := path.Dir(name); dir != s.root && dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if s.client.RemoveDirectory(dir) != nil {
+			break
+		}
+		s.mu.Lock()
+		delete(s.dirs, dir)
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+// Close ends the SFTP session and the SSH connection.
+func (s *sftpStore) Close() error {
+	var err error
+	if s.client != nil {
+		err = s.client.Close()
+	}
+	if s.conn != nil {
+		if cerr := s.conn.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if s.agent != nil {
+		_ = s.agent.Close()
+	}
+	return err
+}
+
+// contextWriter stops a copy once ctx is canceled.
+type contextWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (c contextWriter) Write(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.w.Write(p)
+}
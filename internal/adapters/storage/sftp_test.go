@@ -0,0 +1,405 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+// fakeSFTP is an in-process SSH server whose sftp subsystem, from pkg/sftp, serves the
+// local file system.
+type fakeSFTP struct {
+	addr       string
+	knownHosts string
+	authorized ssh.PublicKey
+}
+
+func newFakeSFTP(t *testing.T, authorized ssh.PublicKey) *fakeSFTP {
+	t.Helper()
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	f := &fakeSFTP{addr: listener.Addr().String(), authorized: authorized}
+	f.knownHosts = filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(f.addr)}, hostSigner.PublicKey())
+	if err := os.WriteFile(f.knownHosts, []byte(line+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if bytes.Equal(key.Marshal(), f.authorized.Marshal()) {
+				return &ssh.Permissions{}, nil
+			}
+			return nil, errors.New("unknown key")
+		},
+	}
+	config.AddHostKey(hostSigner)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.serveConn(conn, config)
+		}
+	}()
+	return f
+}
+
+func (f *fakeSFTP) serveConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChan := range chans {
+		if newChan.ChannelType() != "session" {
+			_ = newChan.Reject(ssh.UnknownChannelType, "session only")
+			continue
+		}
+		ch, requests, err := newChan.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			for req := range requests {
+				ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+				_ = req.Reply(ok, nil)
+				if !ok {
+					continue
+				}
+				go func() {
+					defer func() { _ = ch.Close() }()
+					server, err := sftp.NewServer(ch)
+					if err != nil {
+						return
+					}
+					_ = server.Serve()
+				}()
+			}
+		}()
+	}
+}
+
+func newTestSSHKey(t *testing.T) (ed25519.PrivateKey, ssh.PublicKey, string) {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(file, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return priv, signer.PublicKey(), file
+}
+
+func sftpTestTarget(server *fakeSFTP, root, identity string) usecase.TargetSettings {
+	return usecase.TargetSettings{
+		Name:           "home",
+		Type:           usecase.TargetTypeSFTP,
+		URL:            "sftp://backup@" + server.addr + root,
+		IdentityFile:   identity,
+		KnownHostsFile: server.knownHosts,
+	}
+}
+
+func TestSFTPStore_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	_, pub, identity := newTestSSHKey(t)
+	server := newFakeSFTP(t, pub)
+	root := filepath.Join(t.TempDir(), "devback")
+	a := newTestStorage(nil)
+	opened, err := a.Open(ctx, sftpTestTarget(server, root, identity))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	store := opened.(*sftpStore)
+	defer func() { _ = store.Close() }()
+
+	dir := t.TempDir()
+	large := bytes.Repeat([]byte("0123456789abcdef"), 20000) // several chunks in flight
+	bigSrc := filepath.Join(dir, "big")
+	if err := os.WriteFile(bigSrc, large, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	small := writeTestFile(t, dir, "small", "hello")
+	empty := writeTestFile(t, dir, "empty", "")
+	keys := map[string]string{
+		"repo/2026-10-16/100000/objects/pack/big.pack": bigSrc,
+		"repo/2026-10-16/100000/a b.txt":               small,
+		"repo/2026-10-16/100000/.done":                 empty,
+		"other/2026-10-16/100000/.done":                empty,
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, len(keys))
+	for key, src := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- store.Put(ctx, key, src)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("put: %v", err)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "repo", "2026-10-16", "100000", "a b.txt")); string(data) != "hello" {
+		t.Fatalf("expected plain file on the server, got %q", data)
+	}
+
+	objects, err := store.List(ctx, "repo/")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	sizes := map[string]int64{}
+	for _, obj := range objects {
+		sizes[obj.Key] = obj.Size
+		if obj.ModTime.IsZero() {
+			t.Fatalf("expected modification time for %s", obj.Key)
+		}
+	}
+	want := map[string]int64{
+		"repo/2026-10-16/100000/objects/pack/big.pack": int64(len(large)),
+		"repo/2026-10-16/100000/a b.txt":               5,
+		"repo/2026-10-16/100000/.done":                 0,
+	}
+	if len(sizes) != len(want) {
+		t.Fatalf("unexpected objects: %+v", objects)
+	}
+	for key, size := range want {
+		if sizes[key] != size {
+			t.Fatalf("unexpected size of %s: %d (objects %+v)", key, sizes[key], objects)
+		}
+	}
+	if objects, err := store.List(ctx, "missing/"); err != nil || len(objects) != 0 {
+		t.Fatalf("expected empty listing for missing prefix: %+v (%v)", objects, err)
+	}
+
+	dst := filepath.Join(dir, "download")
+	if err := store.Get(ctx, "repo/2026-10-16/100000/objects/pack/big.pack", dst); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if data, _ := os.ReadFile(dst); !bytes.Equal(data, large) {
+		t.Fatalf("download differs: %d bytes, want %d", len(data), len(large))
+	}
+	if err := store.Get(ctx, "repo/missing", dst); err == nil {
+		t.Fatal("expected error for missing object")
+	}
+
+	for key := range want {
+		if err := store.Delete(ctx, key); err != nil {
+			t.Fatalf("delete %s: %v", key, err)
+		}
+	}
+	if err := store.Delete(ctx, "repo/missing"); err != nil {
+		t.Fatalf("deleting a missing key must succeed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "repo")); !os.IsNotExist(err) {
+		t.Fatalf("empty directories must be removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "other", "2026-10-16", "100000", ".done")); err != nil {
+		t.Fatalf("other repository must be untouched: %v", err)
+	}
+}
+
+func TestSFTPStore_ResumesInterruptedPut(t *testing.T) {
+	ctx := context.Background()
+	_, pub, identity := newTestSSHKey(t)
+	server := newFakeSFTP(t, pub)
+	root := t.TempDir()
+	store, err := newTestStorage(nil).openSFTP(ctx, sftpTestTarget(server, root, identity))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	content := bytes.Repeat([]byte("resume-"), 30000)
+	src := filepath.Join(t.TempDir(), "pack")
+	if err := os.WriteFile(src, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(root, "repo", "2026-10-16", "100000")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	// The stored prefix differs from the source: a resumed upload keeps it, a restarted one
+	// would overwrite it.
+	partial := filepath.Join(dir, ".pack"+sftpPartSuffix)
+	done := len(content) / 3
+	stored := bytes.Repeat([]byte("x"), done)
+	if err := os.WriteFile(partial, stored, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if objects, _ := store.List(ctx, "repo/"); len(objects) != 0 {
+		t.Fatalf("unfinished files must not be listed: %+v", objects)
+	}
+
+	if err := store.Put(ctx, "repo/2026-10-16/100000/pack", src); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "pack"))
+	if !bytes.Equal(data, append(stored, content[done:]...)) {
+		t.Fatalf("expected the upload to continue after the %d stored bytes", done)
+	}
+	if _, err := os.Stat(partial); !os.IsNotExist(err) {
+		t.Fatalf("temporary file must be renamed: %v", err)
+	}
+}
+
+func TestSFTPStore_AgentAuthAndPlainRename(t *testing.T) {
+	ctx := context.Background()
+	priv, pub, _ := newTestSSHKey(t)
+	server := newFakeSFTP(t, pub)
+	if err := sftp.SetSFTPExtensions("hardlink@openssh.com"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = sftp.SetSFTPExtensions("hardlink@openssh.com", sftpPosixRename, "statvfs@openssh.com")
+	})
+
+	sockDir, err := os.MkdirTemp("", "agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(sockDir) })
+	sock := filepath.Join(sockDir, "agent.sock")
+	listener, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: priv}); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() { _ = agent.ServeAgent(keyring, conn) }()
+		}
+	}()
+
+	root := t.TempDir()
+	a := newTestStorage(map[string]string{"SSH_AUTH_SOCK": sock})
+	target := sftpTestTarget(server, root, filepath.Join(t.TempDir(), "missing-key"))
+	store, err := a.openSFTP(ctx, target)
+	if err != nil {
+		t.Fatalf("open with agent: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	first := writeTestFile(t, t.TempDir(), "first", "first")
+	second := writeTestFile(t, t.TempDir(), "second", "second version")
+	for _, src := range []string{first, second} {
+		if err := store.Put(ctx, "repo/2026-10-16/100000/.layout.json", src); err != nil {
+			t.Fatalf("put %s: %v", src, err)
+		}
+	}
+	data, _ := os.ReadFile(filepath.Join(root, "repo", "2026-10-16", "100000", ".layout.json"))
+	if string(data) != "second version" {
+		t.Fatalf("expected replaced object, got %q", data)
+	}
+}
+
+func TestSFTPStore_OpenErrors(t *testing.T) {
+	ctx := context.Background()
+	_, pub, identity := newTestSSHKey(t)
+	_, _, otherIdentity := newTestSSHKey(t)
+	server := newFakeSFTP(t, pub)
+	a := newTestStorage(nil)
+	root := t.TempDir()
+
+	unknownHost := sftpTestTarget(server, root, identity)
+	unknownHost.KnownHostsFile = writeTestFile(t, t.TempDir(), "known_hosts", "")
+	wrongKey := sftpTestTarget(server, root, otherIdentity)
+	noKey := sftpTestTarget(server, root, filepath.Join(t.TempDir(), "missing"))
+	badURL := sftpTestTarget(server, root, identity)
+	badURL.URL = "backup@host-without-path"
+
+	for _, tc := range []struct {
+		name   string
+		target usecase.TargetSettings
+		want   string
+	}{
+		{"unknown host", unknownHost, "not in known_hosts"},
+		{"wrong key", wrongKey, "unable to authenticate"},
+		{"missing key", noKey, "identity_file"},
+		{"bad url", badURL, "invalid sftp url"},
+	} {
+		store, err := a.Open(ctx, tc.target)
+		if err == nil {
+			_ = store.Close()
+			t.Fatalf("%s: expected error", tc.name)
+		}
+		if !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: expected %q in %v", tc.name, tc.want, err)
+		}
+	}
+}
+
+func TestParseSFTPURL(t *testing.T) {
+	for _, tc := range []struct {
+		raw  string
+		want sftpAddress
+	}{
+		{"me@nas:/srv/devback", sftpAddress{user: "me", host: "nas", port: "22", path: "/srv/devback"}},
+		{"nas:backups", sftpAddress{host: "nas", port: "22", path: "backups"}},
+		{"me@nas:", sftpAddress{user: "me", host: "nas", port: "22", path: "."}},
+		{"me@[fe80::1]:/srv", sftpAddress{user: "me", host: "fe80::1", port: "22", path: "/srv"}},
+		{"nas:/srv/a@b", sftpAddress{host: "nas", port: "22", path: "/srv/a@b"}},
+		{"sftp://me@nas:2222/srv", sftpAddress{user: "me", host: "nas", port: "2222", path: "/srv"}},
+	} {
+		got, err := parseSFTPURL(tc.raw)
+		if err != nil || got != tc.want {
+			t.Fatalf("%s: got %+v (%v), want %+v", tc.raw, got, err, tc.want)
+		}
+	}
+	for _, raw := range []string{"nas", "me@:/srv", "[::1/srv", "sftp:///srv"} {
+		if _, err := parseSFTPURL(raw); err == nil {
+			t.Fatalf("%s: expected error", raw)
+		}
+	}
+}
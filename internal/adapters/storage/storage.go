@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+// Adapter implements StoragePort for the remote target types.
+type Adapter struct {
+	logger *slog.Logger
+	env    func(string) string
+}
+
+// New creates a new storage adapter.
+func New(logger *slog.Logger) *Adapter {
+	if logger == nil {
+		panic("storage adapter requires logger")
+	}
+	return &Adapter{logger: logger, env: os.Getenv}
+}
+
+// Open connects to target.
+func (a *Adapter) Open(ctx context.Context, target usecase.TargetSettings) (usecase.SnapshotStore, error) {
+	switch target.Type {
+	case usecase.TargetTypeS3:
+		return a.openS3(ctx, target)
+	case usecase.TargetTypeSFTP:
+		return a.openSFTP(ctx, target)
+	case usecase.TargetTypeDir:
+		return a.openDir(target)
+	default:
+		return nil, fmt.Errorf("unsupported target type %q", target.Type)
+	}
+}
@@ -0,0 +1,139 @@
+package watch
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+const (
+	defaultPollInterval = 2 * time.Second
+	eventBuffer         = 256
+)
+
+// Adapter implements WatcherPort with inotify on Linux and periodic tree scans elsewhere.
+type Adapter struct {
+	logger       *slog.Logger
+	pollInterval time.Duration
+}
+
+// New creates a new watch adapter.
+func New(logger *slog.Logger) *Adapter {
+	if logger == nil {
+		panic("watch adapter requires logger")
+	}
+	return &Adapter{logger: logger, pollInterval: defaultPollInterval}
+}
+
+// relPath returns path relative to root with forward slashes ("" for root itself).
+func relPath(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+func skipNothing(string) bool { return false }
+
+// send delivers ev unless ctx is done first.
+func send(ctx context.Context, events chan<- usecase.WatchEvent, ev usecase.WatchEvent) bool {
+	select {
+	case events <- ev:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// poll reports changes by comparing the size and modification time of every file between scans.
+func (a *Adapter) poll(
+	ctx context.Context,
+	root string,
+	skip func(rel string) bool,
+) (<-chan usecase.WatchEvent, error) {
+	prev, err := scanTree(root, skip)
+	if err != nil {
+		return nil, err
+	}
+	events := make(chan usecase.WatchEvent, eventBuffer)
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(a.pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			cur, err := scanTree(root, skip)
+			if err != nil {
+				a.logger.Warn("watch: scan failed", "root", root, "error", err)
+				continue
+			}
+			for _, path := range diffTrees(prev, cur) {
+				if !send(ctx, events, usecase.WatchEvent{Path: path}) {
+					return
+				}
+			}
+			prev = cur
+		}
+	}()
+	return events, nil
+}
+
+func scanTree(root string, skip func(rel string) bool) (map[string]fileState, error) {
+	state := make(map[string]fileState)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if rel := relPath(root, path); rel != "" && skip(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		state[path] = fileState{size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+	return state, err
+}
+
+// diffTrees returns the sorted paths that were added, removed or modified between two scans.
+func diffTrees(prev, cur map[string]fileState) []string {
+	var changed []string
+	for path, st := range cur {
+		if old, ok := prev[path]; !ok || old.size != st.size || !old.modTime.Equal(st.modTime) {
+			changed = append(changed, path)
+		}
+	}
+	for path := range prev {
+		if _, ok := cur[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -0,0 +1,157 @@
+//go:build linux
+
+package watch
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+const (
+	inotifyMask = unix.IN_CREATE | unix.IN_CLOSE_WRITE | unix.IN_MODIFY | unix.IN_DELETE |
+		unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_ONLYDIR | unix.IN_DONT_FOLLOW
+	pollTimeoutMs = 500
+)
+
+// Watch reports changes below root with inotify. Directories created later are watched as
+// they appear; a kernel queue overflow is reported as an Overflow event.
+func (a *Adapter) Watch(
+	ctx context.Context,
+	root string,
+	skip func(rel string) bool,
+) (<-chan usecase.WatchEvent, error) {
+	if skip == nil {
+		skip = skipNothing
+	}
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify init: %w", err)
+	}
+	w := &inotifyWatch{fd: fd, root: root, skip: skip, dirs: make(map[int32]string), logger: a.logger}
+	if err := w.addTree(root); err != nil {
+		_ = unix.Close(fd)
+		return nil, err
+	}
+	events := make(chan usecase.WatchEvent, eventBuffer)
+	go w.run(ctx, events)
+	return events, nil
+}
+
+type inotifyWatch struct {
+	fd     int
+	root   string
+	skip   func(rel string) bool
+	dirs   map[int32]string
+	logger *slog.Logger
+}
+
+func (w *inotifyWatch) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if rel := relPath(w.root, path); rel != "" && w.skip(rel) {
+			return filepath.SkipDir
+		}
+		wd, err := unix.InotifyAddWatch(w.fd, path, inotifyMask)
+		if err != nil {
+			if errors.Is(err, unix.ENOSPC) {
+				return fmt.Errorf("inotify watch limit reached (raise fs.inotify.max_user_watches): %w", err)
+			}
+			w.logger.Debug("watch: directory not watched", "path", path, "error", err)
+			return nil
+		}
+		w.dirs[int32(wd)] = path // #nosec G115 -- watch descriptors are small kernel ids
+		return nil
+	})
+}
+
+func (w *inotifyWatch) run(ctx context.Context, events chan<- usecase.WatchEvent) {
+	defer close(events)
+	defer func() { _ = unix.Close(w.fd) }()
+
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	fds := []unix.PollFd{{Fd: int32(w.fd), Events: unix.POLLIN}} // #nosec G115 -- fds fit in int32
+	for ctx.Err() == nil {
+		n, err := unix.Poll(fds, pollTimeoutMs)
+		if err != nil {
+			if errors.Is(err, unix.EINTR) {
+				continue
+			}
+			w.logger.Warn("watch: poll failed", "root", w.root, "error", err)
+			return
+		}
+		if n == 0 {
+			continue
+		}
+		n, err = unix.Read(w.fd, buf)
+		if err != nil {
+			if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR) {
+				continue
+			}
+			w.logger.Warn("watch: read failed", "root", w.root, "error", err)
+			return
+		}
+		for _, ev := range w.parse(buf[:n]) {
+			if !send(ctx, events, ev) {
+				return
+			}
+		}
+	}
+}
+
+// parse decodes a buffer of struct inotify_event records.
+func (w *inotifyWatch) parse(buf []byte) []usecase.WatchEvent {
+	var out []usecase.WatchEvent
+	for off := 0; off+unix.SizeofInotifyEvent <= len(buf); {
+		wd := int32(binary.NativeEndian.Uint32(buf[off:])) // #nosec G115 -- kernel int32 field
+		mask := binary.NativeEndian.Uint32(buf[off+4:])
+		nameLen := int(binary.NativeEndian.Uint32(buf[off+12:]))
+		start := off + unix.SizeofInotifyEvent
+		off = min(start+nameLen, len(buf))
+		name := strings.TrimRight(string(buf[start:off]), "\x00")
+
+		if mask&unix.IN_Q_OVERFLOW != 0 {
+			out = append(out, usecase.WatchEvent{Overflow: true})
+			continue
+		}
+		dir, ok := w.dirs[wd]
+		if !ok {
+			continue
+		}
+		if mask&unix.IN_IGNORED != 0 {
+			delete(w.dirs, wd)
+			continue
+		}
+		path := dir
+		if name != "" {
+			path = filepath.Join(dir, name)
+		}
+		if rel := relPath(w.root, path); rel != "" && w.skip(rel) {
+			continue
+		}
+		if mask&unix.IN_ISDIR != 0 && mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0 {
+			if err := w.addTree(path); err != nil {
+				w.logger.Warn("watch: new directory not watched", "path", path, "error", err)
+			}
+		}
+		out = append(out, usecase.WatchEvent{Path: path})
+	}
+	return out
+}
@@ -0,0 +1,21 @@
+//go:build !linux
+
+package watch
+
+import (
+	"context"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+// Watch reports changes below root by scanning the tree periodically; inotify is Linux-only.
+func (a *Adapter) Watch(
+	ctx context.Context,
+	root string,
+	skip func(rel string) bool,
+) (<-chan usecase.WatchEvent, error) {
+	if skip == nil {
+		skip = skipNothing
+	}
+	return a.poll(ctx, root, skip)
+}
@@ -0,0 +1,108 @@
+package watch
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arumata/devback/internal/usecase"
+)
+
+type watchFunc func(ctx context.Context, root string, skip func(rel string) bool) (<-chan usecase.WatchEvent, error)
+
+func skipGit(rel string) bool {
+	return rel == ".git" || strings.HasPrefix(rel, ".git/")
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitForPath reads events until one reports want; events for skipped paths fail the test.
+func waitForPath(t *testing.T, events <-chan usecase.WatchEvent, root, want string) {
+	t.Helper()
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				t.Fatal("event channel closed early")
+			}
+			if strings.HasPrefix(ev.Path, filepath.Join(root, ".git")) {
+				t.Fatalf("unexpected event for skipped path %s", ev.Path)
+			}
+			if ev.Path == want {
+				return
+			}
+		case <-deadline:
+			t.Fatalf("no event for %s", want)
+		}
+	}
+}
+
+func testWatch(t *testing.T, watch watchFunc) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(root, "src", "main.go"), "package main\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := watch(ctx, root, skipGit)
+	if err != nil {
+		t.Fatalf("watch failed: %v", err)
+	}
+
+	writeFile(t, filepath.Join(root, ".git", "index"), "index")
+	writeFile(t, filepath.Join(root, "src", "main.go"), "package main\n\nfunc main() {}\n")
+	waitForPath(t, events, root, filepath.Join(root, "src", "main.go"))
+
+	// Directories created after the watch started are watched too.
+	if err := os.MkdirAll(filepath.Join(root, "pkg", "util"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	writeFile(t, filepath.Join(root, "pkg", "util", "util.go"), "package util\n")
+	waitForPath(t, events, root, filepath.Join(root, "pkg", "util", "util.go"))
+
+	cancel()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("event channel not closed after cancel")
+		}
+	}
+}
+
+func TestAdapter_WatchReportsChanges(t *testing.T) {
+	adapter := New(slog.Default())
+	adapter.pollInterval = 20 * time.Millisecond
+	testWatch(t, adapter.Watch)
+}
+
+func TestAdapter_PollReportsChanges(t *testing.T) {
+	adapter := New(slog.Default())
+	adapter.pollInterval = 20 * time.Millisecond
+	testWatch(t, adapter.poll)
+}
+
+func TestAdapter_WatchMissingRoot(t *testing.T) {
+	adapter := New(slog.Default())
+	if _, err := adapter.Watch(context.Background(), filepath.Join(t.TempDir(), "missing"), nil); err == nil {
+		t.Fatal("expected error for missing root")
+	}
+}
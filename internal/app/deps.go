@@ -3,13 +3,18 @@ package app
 import (
 	"log/slog"
 
+	"github.com/arumata/devback/internal/adapters/archive"
 	"github.com/arumata/devback/internal/adapters/config"
+	"github.com/arumata/devback/internal/adapters/encryption"
 	"github.com/arumata/devback/internal/adapters/filesystem"
 	"github.com/arumata/devback/internal/adapters/git"
 	"github.com/arumata/devback/internal/adapters/lock"
 	"github.com/arumata/devback/internal/adapters/notification"
 	"github.com/arumata/devback/internal/adapters/process"
+	"github.com/arumata/devback/internal/adapters/scheduler"
+	"github.com/arumata/devback/internal/adapters/storage"
 	"github.com/arumata/devback/internal/adapters/templates"
+	"github.com/arumata/devback/internal/adapters/watch"
 	"github.com/arumata/devback/internal/usecase"
 )
 
@@ -25,6 +30,11 @@ func NewDefaultDependencies(logger *slog.Logger) *usecase.Dependencies {
 	notificationAdapter := notification.New(logger)
 	processAdapter := process.New(logger)
 	templatesAdapter := templates.New(logger)
+	archiveAdapter := archive.New(logger)
+	encryptionAdapter := encryption.New(logger)
+	watchAdapter := watch.New(logger)
+	schedulerAdapter := scheduler.New(logger)
+	storageAdapter := storage.New(logger)
 
 	return &usecase.Dependencies{
 		FileSystem:   fsAdapter,
@@ -34,5 +44,10 @@ func NewDefaultDependencies(logger *slog.Logger) *usecase.Dependencies {
 		Process:      processAdapter,
 		Templates:    templatesAdapter,
 		Notification: notificationAdapter,
+		Archive:      archiveAdapter,
+		Encryption:   encryptionAdapter,
+		Watcher:      watchAdapter,
+		Scheduler:    schedulerAdapter,
+		Storage:      storageAdapter,
 	}
 }
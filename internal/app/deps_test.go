@@ -5,7 +5,9 @@ import (
 	"log/slog"
 	"testing"
 
+	"github.com/arumata/devback/internal/adapters/archive"
 	"github.com/arumata/devback/internal/adapters/config"
+	"github.com/arumata/devback/internal/adapters/encryption"
 	"github.com/arumata/devback/internal/adapters/filesystem"
 	"github.com/arumata/devback/internal/adapters/git"
 	"github.com/arumata/devback/internal/adapters/lock"
@@ -68,6 +70,13 @@ func TestNewDefaultDependencies(t *testing.T) {
 	if _, ok := deps.Templates.(*templates.Adapter); !ok {
 		t.Error("Expected Templates to be templates.Adapter")
 	}
+
+	if _, ok := deps.Archive.(*archive.Adapter); !ok {
+		t.Error("Expected Archive to be archive.Adapter")
+	}
+	if _, ok := deps.Encryption.(*encryption.Adapter); !ok {
+		t.Error("Expected Encryption to be encryption.Adapter")
+	}
 }
 
 func BenchmarkNewDefaultDependencies(b *testing.B) {
@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const snapshotArchiveBase = "snapshot"
+
+// normalizeSnapshotFormat validates backup.format; an empty value means "dir".
+func normalizeSnapshotFormat(format string) (string, error) {
+	switch f := strings.ToLower(strings.TrimSpace(format)); f {
+	case "":
+		return SnapshotFormatDir, nil
+	case SnapshotFormatDir, SnapshotFormatTar, SnapshotFormatTarZst:
+		return f, nil
+	default:
+		return "", fmt.Errorf("backup.format %q is not supported (use dir, tar or tar.zst): %w", format, ErrUsage)
+	}
+}
+
+func isArchiveFormat(format string) bool {
+	return format == SnapshotFormatTar || format == SnapshotFormatTarZst
+}
+
+// snapshotArchiveName returns the archive file name stored inside an archive snapshot directory.
+func snapshotArchiveName(format string) string {
+	return snapshotArchiveBase + "." + format
+}
+
+// detectSnapshotArchive returns the archive path and format of an archive snapshot,
+// or empty strings for a directory snapshot.
+func detectSnapshotArchive(ctx context.Context, fs FileSystemPort, snapDir string) (string, string) {
+	for _, format := range []string{SnapshotFormatTarZst, SnapshotFormatTar} {
+		p := fs.Join(snapDir, snapshotArchiveName(format))
+		if info, err := fs.Stat(ctx, p); err == nil && info.IsRegular() {
+			return p, format
+		}
+	}
+	return "", ""
+}
+
+// format reports the on-disk format of s.
+func (s snapshot) format() string {
+	if s.Format == "" {
+		return SnapshotFormatDir
+	}
+	return s.Format
+}
+
+// archiveRepoSnapshot streams .git, the selected ignored/untracked files, the tracked changes
+// captured for cfg.TrackedChanges and the .devbackinclude paths into a single archive inside
+// targetPath. The snapshot directory keeps only the archive and protocol markers.
+func archiveRepoSnapshot(
+	ctx context.Context,
+	cfg *Config,
+	deps *Dependencies,
+	repoRoot,
+	targetPath string,
+	result *BackupResult,
+	bc *backupContext,
+) error {
+	if deps.Archive == nil {
+		return fmt.Errorf("archive adapter not available: %w", ErrCritical)
+	}
+	dirs, err := resolveSnapshotGitDirs(ctx, deps, repoRoot)
+	if err != nil {
+		return err
+	}
+	if _, err := deps.FileSystem.Stat(ctx, dirs.commonDir); err != nil {
+		return fmt.Errorf("git common dir not found: %w", err)
+	}
+	keep, err := selectUntrackedPaths(ctx, cfg, deps, repoRoot, result, bc)
+	if err != nil {
+		return err
+	}
+	tracked, cleanupTracked, err := prepareTrackedChanges(ctx, deps, cfg.TrackedChanges, repoRoot, bc)
+	if err != nil {
+		return err
+	}
+	defer cleanupTracked()
+	external, err := resolveExternalPaths(ctx, cfg, deps, repoRoot, bc)
+	if err != nil {
+		return err
+	}
+
+	archivePath := deps.FileSystem.Join(targetPath, snapshotArchiveName(cfg.Format))
+	bc.vlogf("→ Archive .git and %d item(s) -> %s", len(keep), archivePath)
+	w, err := deps.Archive.Create(ctx, archivePath, cfg.Format, bc.sealer)
+	if err != nil {
+		return fmt.Errorf("create archive: %w", err)
+	}
+	var copyErrors []string
+	walkErr := archiveGitDir(ctx, deps, w, dirs.commonDir, result, &copyErrors)
+	keepStart := len(copyErrors)
+	for _, rel := range keep {
+		if ctx.Err() != nil || walkErr != nil {
+			break
+		}
+		src := deps.FileSystem.Join(repoRoot, rel)
+		info, err := deps.FileSystem.Lstat(ctx, src)
+		if err != nil {
+			bc.warnf("skip '%s': %v", rel, err)
+			recordCopyError(deps.FileSystem, rel, err, result, &copyErrors)
+			continue
+		}
+		archiveEntry(ctx, deps, w, src, archiveName(deps.FileSystem, rel), info, result, &copyErrors)
+		bc.vlogf("   ARCHIVED: %s", rel)
+	}
+	keepErrors := len(copyErrors) - keepStart
+	if tracked != nil && walkErr == nil {
+		for _, rel := range tracked.paths {
+			if ctx.Err() != nil {
+				break
+			}
+			src := deps.FileSystem.Join(tracked.srcRoot, rel)
+			info, err := deps.FileSystem.Lstat(ctx, src)
+			if err != nil {
+				recordCopyError(deps.FileSystem, rel, err, result, &copyErrors)
+				continue
+			}
+			name := archiveName(deps.FileSystem, deps.FileSystem.Join(tracked.dstRel, rel))
+			archiveEntry(ctx, deps, w, src, name, info, result, &copyErrors)
+		}
+	}
+	if walkErr == nil && ctx.Err() == nil {
+		walkErr = archiveExternalPaths(ctx, deps, w, external, result, &copyErrors)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("finish archive: %w", err)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if walkErr != nil {
+		return walkErr
+	}
+	if len(copyErrors) > 0 {
+		if keepStart > 0 || len(copyErrors) > keepErrors || !onlyPermissionErrors(result) {
+			return fmt.Errorf("failed to archive %d item(s)", len(copyErrors))
+		}
+		bc.warnf("skipped %d unreadable ignored/untracked item(s)", keepErrors)
+	}
+	bc.logf("✓ Archived .git and %d ignored/untracked item(s) (%s)", len(keep), cfg.Format)
+	if tracked != nil {
+		result.TrackedChanges = tracked.changed
+		logTrackedChanges(cfg.TrackedChanges, tracked, bc)
+	}
+	if len(external) > 0 {
+		bc.logf("✓ Archived external: %d path(s), %d file(s)", len(external), result.ExternalFiles)
+	}
+	return nil
+}
+
+// archiveGitDir adds the git common dir as ".git", leaving out linked worktree metadata.
+func archiveGitDir(
+	ctx context.Context,
+	deps *Dependencies,
+	w ArchiveWriter,
+	srcGit string,
+	result *BackupResult,
+	copyErrors *[]string,
+) error {
+	worktrees := "worktrees"
+	return deps.FileSystem.Walk(ctx, srcGit, func(path string, info FileInfo, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			recordCopyError(deps.FileSystem, path, err, result, copyErrors)
+			return nil
+		}
+		rel, err := deps.FileSystem.Rel(srcGit, path)
+		if err != nil {
+			recordCopyError(deps.FileSystem, path, err, result, copyErrors)
+			return nil
+		}
+		name := archiveName(deps.FileSystem, rel)
+		if name == worktrees || strings.HasPrefix(name, worktrees+"/") || info == nil {
+			return nil
+		}
+		if name == "." {
+			name = ".git"
+		} else {
+			name = ".git/" + name
+		}
+		archiveEntry(ctx, deps, w, path, name, info, result, copyErrors)
+		return nil
+	})
+}
+
+func archiveEntry(
+	ctx context.Context,
+	deps *Dependencies,
+	w ArchiveWriter,
+	src,
+	name string,
+	info FileInfo,
+	result *BackupResult,
+	copyErrors *[]string,
+) {
+	var err error
+	switch {
+	case info.IsDir():
+		err = w.AddDir(name, info)
+	case info.IsSymlink():
+		var target string
+		if target, err = deps.FileSystem.Readlink(ctx, src); err == nil {
+			err = w.AddSymlink(name, target, info)
+		}
+	case info.IsRegular():
+		if err = w.AddFile(ctx, name, src, info); err == nil && result != nil {
+			result.CopiedFiles++
+		}
+	default:
+		return
+	}
+	if err != nil {
+		recordCopyError(deps.FileSystem, src, err, result, copyErrors)
+	}
+}
+
+// archiveName converts a relative OS path into a slash-separated archive entry name.
+func archiveName(fs FileSystemPort, rel string) string {
+	return strings.ReplaceAll(rel, string(fs.PathSeparator()), "/")
+}
+
+// extractSnapshotArchive unpacks an archive snapshot into target.
+func extractSnapshotArchive(
+	ctx context.Context,
+	deps *Dependencies,
+	snap snapshot,
+	target string,
+	result *BackupResult,
+	opener Opener,
+) error {
+	if deps.Archive == nil {
+		return fmt.Errorf("archive adapter not available")
+	}
+	files, err := deps.Archive.Extract(ctx, snap.Archive, snap.format(), target, opener)
+	if result != nil {
+		result.CopiedFiles += files
+	}
+	if err != nil {
+		return fmt.Errorf("extract %s: %w", deps.FileSystem.Base(snap.Archive), err)
+	}
+	return nil
+}
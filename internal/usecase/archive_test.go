@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizeSnapshotFormat(t *testing.T) {
+	for in, want := range map[string]string{"": "dir", "dir": "dir", " TAR ": "tar", "tar.zst": "tar.zst"} {
+		got, err := normalizeSnapshotFormat(in)
+		if err != nil || got != want {
+			t.Fatalf("normalizeSnapshotFormat(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := normalizeSnapshotFormat("zip"); !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+}
+
+func TestHandleBackupFlow_ArchiveFormat(t *testing.T) {
+	ctx := context.Background()
+	repoRoot := t.TempDir()
+	runGitForTest(t, repoRoot, "init", "-q")
+	writeTestFile(t, filepath.Join(repoRoot, "tracked.txt"), "tracked")
+	writeTestFile(t, filepath.Join(repoRoot, ".gitignore"), "ignored.txt\n")
+	runGitForTest(t, repoRoot, "add", "tracked.txt", ".gitignore")
+	runGitForTest(t, repoRoot, "commit", "-q", "-m", "init")
+	writeTestFile(t, filepath.Join(repoRoot, "ignored.txt"), "ignored")
+
+	backupDir := t.TempDir()
+	repoKey := "repo--deadbeef"
+	repoDir := filepath.Join(backupDir, repoKey)
+	cfg := &Config{BackupDir: backupDir, NoSize: true, Format: SnapshotFormatTar}
+	deps := &Dependencies{
+		FileSystem: newTestFileSystem(),
+		Git:        newTestGitAdapter(),
+		Lock:       &mockLock{},
+		Process:    &mockProcess{},
+		Archive:    testArchive{},
+	}
+	result, err := handleBackupFlow(ctx, cfg, deps, repoRoot, repoDir, newTestBackupContext(false))
+	if err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	if result.CopiedFiles == 0 || result.TotalFiles != result.CopiedFiles {
+		t.Fatalf("expected archived files to be counted: %+v", result)
+	}
+
+	snaps, err := listSnapshots(ctx, deps, repoDir)
+	if err != nil || len(snaps) != 1 {
+		t.Fatalf("expected one snapshot, got %d (%v)", len(snaps), err)
+	}
+	snap := snaps[0]
+	if snap.Format != SnapshotFormatTar || snap.Archive != filepath.Join(snap.TimeDir, "snapshot.tar") {
+		t.Fatalf("expected tar snapshot, got %+v", snap)
+	}
+	entries, err := os.ReadDir(snap.TimeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name() != "snapshot.tar" && !isSnapshotMarker(e.Name()) {
+			t.Fatalf("unexpected entry next to archive: %s", e.Name())
+		}
+	}
+	m, err := readSnapshotManifest(ctx, deps, snap.TimeDir)
+	if err != nil || m.Format != SnapshotFormatTar {
+		t.Fatalf("expected format in manifest: %+v (%v)", m, err)
+	}
+
+	target := filepath.Join(t.TempDir(), "restored")
+	if _, err := Restore(ctx, cfg, RestoreOptions{RepoKey: repoKey, Target: target}, deps,
+		newTestBackupContext(false).logger); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	for name, want := range map[string]string{"tracked.txt": "tracked", "ignored.txt": "ignored"} {
+		data, err := os.ReadFile(filepath.Join(target, name))
+		if err != nil || string(data) != want {
+			t.Fatalf("restored %s = %q (%v), want %q", name, data, err, want)
+		}
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"log/slog"
 	"net/url"
-	"path"
 	"runtime"
 	"sort"
 	"strings"
@@ -17,8 +16,11 @@ import (
 )
 
 type backupContext struct {
-	logger  *slog.Logger
-	verbose bool
+	logger   *slog.Logger
+	verbose  bool
+	linkDest *linkDest
+	sealer   Sealer
+	opener   Opener
 }
 
 func newBackupContext(logger *slog.Logger, verbose bool) *backupContext {
@@ -221,9 +223,12 @@ func shortHash(s string) string {
 	return hex.EncodeToString(h[:])[:8]
 }
 
+// defaultRemoteHashLen is the repo_key.remote_hash_len used when none is configured.
+const defaultRemoteHashLen = 8
+
 func shortHashN(s string, n int) string {
 	if n <= 0 {
-		n = 8
+		n = defaultRemoteHashLen
 	}
 	if n > 64 {
 		n = 64
@@ -378,58 +383,6 @@ func repoKeyNameHash(fs FileSystemPort, repoRoot string) string {
 	return fmt.Sprintf("%s--%s", base, shortHash(repoRoot))
 }
 
-func readDevbackIgnore(ctx context.Context, deps *Dependencies, repoRoot string, bc *backupContext) ([]string, error) {
-	path := deps.FileSystem.Join(repoRoot, ".devbackignore")
-	data, err := deps.FileSystem.ReadFile(ctx, path)
-	if err != nil {
-		if deps.FileSystem.IsNotExist(err) {
-			bc.vlogf("→ No .devbackignore in repo")
-			return nil, nil
-		}
-		return nil, err
-	}
-
-	bc.vlogf("→ Found .devbackignore (normalized):")
-
-	excludes := []string{}
-	for _, line := range strings.Split(string(data), "\n") {
-		line = strings.ReplaceAll(line, "\r", "")
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		line = strings.TrimSuffix(line, "/")
-		excludes = append(excludes, line)
-		bc.vlogf("   exclude='%s'", line)
-	}
-	return excludes, nil
-}
-
-func shouldSkip(p string, excludes []string) (bool, string) {
-	normalizedPath := strings.ReplaceAll(p, "\\", "/")
-	for _, ex := range excludes {
-		pattern := strings.ReplaceAll(ex, "\\", "/")
-		hasMeta := strings.ContainsAny(pattern, "*?[")
-		hasSlash := strings.Contains(pattern, "/")
-
-		if hasMeta {
-			if hasSlash {
-				if ok, _ := path.Match(pattern, normalizedPath); ok {
-					return true, ex
-				}
-			} else if ok, _ := path.Match(pattern, path.Base(normalizedPath)); ok {
-				return true, ex
-			}
-			continue
-		}
-
-		if normalizedPath == pattern || strings.HasPrefix(normalizedPath, pattern+"/") {
-			return true, ex
-		}
-	}
-	return false, ""
-}
-
 func isPermissionError(fs FileSystemPort, err error) bool {
 	if err == nil || fs == nil {
 		return false
@@ -456,6 +409,12 @@ func recordCopyError(fs FileSystemPort, path string, err error, result *BackupRe
 	result.PartialSuccess = true
 }
 
+// onlyPermissionErrors reports whether every copy error recorded in result is a permission error.
+// Unreadable ignored/untracked files are skipped and leave a partial snapshot instead of failing it.
+func onlyPermissionErrors(result *BackupResult) bool {
+	return len(result.PermissionErrs) > 0 && len(result.OtherErrors) == 0
+}
+
 func copyFile(ctx context.Context, deps *Dependencies, src, dst string, mode int) error {
 	if err := deps.FileSystem.Copy(ctx, src, dst); err != nil {
 		return err
@@ -496,7 +455,7 @@ func copyDirRecursive(
 			return nil
 		}
 
-		copyDirEntry(ctx, deps, path, target, info, result, &copyErrors)
+		copyDirEntry(ctx, deps, path, target, info, result, &copyErrors, bc)
 		return nil
 	})
 
@@ -522,6 +481,7 @@ func copyDirEntry(
 	info FileInfo,
 	result *BackupResult,
 	copyErrors *[]string,
+	bc *backupContext,
 ) {
 	if info.IsDir() {
 		if err := deps.FileSystem.CreateDir(ctx, target, info.Mode()&0o777); err != nil {
@@ -553,7 +513,7 @@ func copyDirEntry(
 		recordCopyError(deps.FileSystem, parentDir, err, result, copyErrors)
 		return
 	}
-	if err := copyFile(ctx, deps, path, target, info.Mode()); err != nil {
+	if err := copyOrLinkFile(ctx, deps, path, target, info, bc); err != nil {
 		recordCopyError(deps.FileSystem, path, err, result, copyErrors)
 		return
 	}
@@ -675,7 +635,7 @@ func copySelectedPath(ctx context.Context, state *copySelectedState, rel string)
 	if err := createParentDirForCopy(ctx, state.deps, dst, rel); err != nil {
 		return err
 	}
-	if err := copyFile(ctx, state.deps, src, dst, fi.Mode()); err != nil {
+	if err := copyOrLinkFile(ctx, state.deps, src, dst, fi, state.bc); err != nil {
 		return fmt.Errorf("copy '%s': %w", rel, err)
 	}
 	state.recordCopied()
@@ -719,6 +679,23 @@ type snapshot struct {
 	DateDir string
 	TimeDir string
 	Done    string
+	Archive string
+	Format  string
+	// Quarantined snapshots failed verification; rotation neither counts nor removes them.
+	Quarantined bool
+	// Pinned snapshots carry a .pinned marker and are skipped by every rotation pass.
+	Pinned bool
+	// Protected snapshots are kept by every rotation pass.
+	Protected bool
+	// KeptBy lists the generational retention rules that keep the snapshot.
+	KeptBy []string
+	// Watch snapshots were taken by devback watch and are rotated by the [watch] limits only.
+	Watch bool
+}
+
+// retained reports whether the age and count passes must keep the snapshot.
+func (s snapshot) retained() bool {
+	return s.Pinned || s.Protected || len(s.KeptBy) > 0
 }
 
 func listSnapshots(ctx context.Context, deps *Dependencies, repoDir string) ([]snapshot, error) {
@@ -752,7 +729,13 @@ func listSnapshots(ctx context.Context, deps *Dependencies, repoDir string) ([]s
 			td := deps.FileSystem.Join(dd, tname)
 			donePath := deps.FileSystem.Join(td, ".done")
 			if _, err := deps.FileSystem.Stat(ctx, donePath); err == nil {
-				snaps = append(snaps, snapshot{DateDir: dd, TimeDir: td, Done: donePath})
+				archive, format := detectSnapshotArchive(ctx, deps.FileSystem, td)
+				_, qerr := deps.FileSystem.Stat(ctx, deps.FileSystem.Join(td, quarantineMarker))
+				_, perr := deps.FileSystem.Stat(ctx, deps.FileSystem.Join(td, pinnedMarker))
+				snaps = append(snaps, snapshot{
+					DateDir: dd, TimeDir: td, Done: donePath, Archive: archive, Format: format,
+					Quarantined: qerr == nil, Pinned: perr == nil,
+				})
 			}
 		}
 	}
@@ -761,6 +744,46 @@ func listSnapshots(ctx context.Context, deps *Dependencies, repoDir string) ([]s
 	return snaps, nil
 }
 
+// snapshotID returns the "<date>/<time>" identifier of a snapshot relative to repoDir.
+func snapshotID(fs FileSystemPort, repoDir string, s snapshot) string {
+	rel, err := fs.Rel(repoDir, s.TimeDir)
+	if err != nil {
+		return fs.Base(s.DateDir) + "/" + fs.Base(s.TimeDir)
+	}
+	return strings.ReplaceAll(rel, string(fs.PathSeparator()), "/")
+}
+
+// findSnapshot resolves a completed snapshot by ID; an empty ID selects the latest one that is
+// not quarantined. A quarantined snapshot is only used when it is named.
+func findSnapshot(ctx context.Context, deps *Dependencies, repoDir, id string) (snapshot, error) {
+	snaps, err := listSnapshots(ctx, deps, repoDir)
+	if err != nil {
+		if deps.FileSystem.IsNotExist(err) {
+			return snapshot{}, fmt.Errorf("no snapshots in %s: %w", repoDir, ErrUsage)
+		}
+		return snapshot{}, fmt.Errorf("list snapshots in %s: %w", repoDir, ErrCritical)
+	}
+	if len(snaps) == 0 {
+		return snapshot{}, fmt.Errorf("no completed snapshots in %s: %w", repoDir, ErrUsage)
+	}
+	id = strings.Trim(strings.ReplaceAll(strings.TrimSpace(id), "\\", "/"), "/")
+	if id == "" {
+		for i := len(snaps) - 1; i >= 0; i-- {
+			if !snaps[i].Quarantined {
+				return snaps[i], nil
+			}
+		}
+		return snapshot{}, fmt.Errorf("every snapshot in %s is quarantined; name one with its ID: %w", repoDir, ErrUsage)
+	}
+	for _, s := range snaps {
+		sid := snapshotID(deps.FileSystem, repoDir, s)
+		if sid == id || (!strings.Contains(id, "/") && deps.FileSystem.Base(s.TimeDir) == id) {
+			return s, nil
+		}
+	}
+	return snapshot{}, fmt.Errorf("snapshot %q not found or not completed: %w", id, ErrUsage)
+}
+
 func matchDateDir(name string) bool {
 	if len(name) != 10 {
 		return false
@@ -821,19 +844,11 @@ func humanKB(kb int64) string {
 	return fmt.Sprintf("%d KiB", kb)
 }
 
+// dirSizeKB returns the size of regular files under root, counting hardlinked files once.
 func dirSizeKB(ctx context.Context, deps *Dependencies, root string, bc *backupContext) (int64, error) {
-	var total int64
-	walkErr := deps.FileSystem.Walk(ctx, root, func(path string, info FileInfo, err error) error {
-		if err != nil {
-			bc.warnf("walk '%s': %v", path, err)
-			return nil
-		}
-		if info != nil && info.IsRegular() {
-			total += info.Size()
-		}
-		return nil
-	})
-	return (total + 1023) / 1024, walkErr
+	usage := newDiskUsage(deps.FileSystem)
+	walkErr := usage.addTree(ctx, 0, root, bc)
+	return usage.totalKB(), walkErr
 }
 
 func removeSnapshot(ctx context.Context, deps *Dependencies, s snapshot, bc *backupContext) {
@@ -853,15 +868,22 @@ func rotateRepo(ctx context.Context, deps *Dependencies, repoDir string, cfg *Co
 		bc.warnf("rotation(list): %v", err)
 		return
 	}
-	alive := make([]bool, len(snaps))
-	for i := range alive {
-		alive[i] = true
-	}
+	alive := markRotationCandidates(snaps)
 
 	now := time.Now()
-	applyKeepDays(ctx, deps, cfg, dryRun, bc, snaps, alive, now)
-	applyKeepCount(ctx, deps, cfg, dryRun, bc, snaps, alive)
+	markWatchSnapshots(ctx, deps, snaps)
+	applyWatchRetention(ctx, deps, cfg, dryRun, bc, snaps, alive, now)
+	regular := regularRotationCandidates(snaps, alive)
+	applyGFS(ctx, deps, cfg, snaps, regular)
+	applyKeepDays(ctx, deps, cfg, dryRun, bc, snaps, regular, now)
+	applyKeepCount(ctx, deps, cfg, dryRun, bc, snaps, regular)
+	for i := range snaps {
+		if !snaps[i].Watch {
+			alive[i] = regular[i]
+		}
+	}
 	applySizeLimit(ctx, deps, cfg, dryRun, bc, snaps, alive)
+	explainRetention(cfg, dryRun, bc, snaps, alive)
 
 	if dryRun {
 		bc.logf("ℹ️ Rotation was DRY-RUN (no deletions performed).")
@@ -871,6 +893,24 @@ func rotateRepo(ctx context.Context, deps *Dependencies, repoDir string, cfg *Co
 	}
 }
 
+// markRotationCandidates returns the alive mask for rotation. Quarantined snapshots are left out
+// so they neither count toward the limits nor get removed, and the newest good snapshot is protected.
+func markRotationCandidates(snaps []snapshot) []bool {
+	alive := make([]bool, len(snaps))
+	protected := false
+	for i := len(snaps) - 1; i >= 0; i-- {
+		if snaps[i].Quarantined {
+			continue
+		}
+		alive[i] = true
+		if !protected {
+			snaps[i].Protected = true
+			protected = true
+		}
+	}
+	return alive
+}
+
 func applyKeepDays(
 	ctx context.Context,
 	deps *Dependencies,
@@ -886,7 +926,7 @@ func applyKeepDays(
 	}
 	limit := time.Duration(cfg.KeepDays) * 24 * time.Hour
 	for i, s := range snaps {
-		if !alive[i] {
+		if !alive[i] || s.retained() {
 			continue
 		}
 		fi, err := deps.FileSystem.Stat(ctx, s.Done)
@@ -926,7 +966,7 @@ func applyKeepCount(
 	}
 	toRemove := live - cfg.KeepCount
 	for i := 0; i < len(snaps) && toRemove > 0; i++ {
-		if !alive[i] {
+		if !alive[i] || snaps[i].retained() {
 			continue
 		}
 		bc.logf("[rotate:count] remove %s (exceeds %d)", snaps[i].TimeDir, cfg.KeepCount)
@@ -953,44 +993,32 @@ func applySizeLimit(
 	kbLimit := int64(cfg.MaxTotalGBPerRepo) * 1024 * 1024
 	kbLimit += int64(cfg.SizeMarginMB) * 1024
 
-	sizes := make([]int64, len(snaps))
-	var totalKB int64
-	for i, s := range snaps {
-		if !alive[i] {
-			continue
-		}
-		kb, _ := dirSizeKB(ctx, deps, s.TimeDir, bc)
-		sizes[i] = kb
-		totalKB += kb
-	}
+	usage, _ := measureSnapshots(ctx, deps, snaps, alive, bc)
+	totalKB := usage.totalKB()
 	bc.vlogf("[rotate:size] total=%s limit=%s", humanKB(totalKB), humanKB(kbLimit))
 
 	for i := 0; i < len(snaps) && totalKB > kbLimit; i++ {
-		if !alive[i] {
+		if !alive[i] || snaps[i].Pinned || snaps[i].Protected {
 			continue
 		}
-		bc.logf(
-			"[rotate:size] remove %s (total %s > %s)",
-			snaps[i].TimeDir,
-			humanKB(totalKB),
-			humanKB(kbLimit),
-		)
+		reason := fmt.Sprintf("total %s > %s", humanKB(totalKB), humanKB(kbLimit))
+		if len(snaps[i].KeptBy) > 0 {
+			reason += "; overrides " + strings.Join(snaps[i].KeptBy, ", ")
+		}
+		bc.logf("[rotate:size] remove %s (%s)", snaps[i].TimeDir, reason)
 		if !dryRun {
 			removeSnapshot(ctx, deps, snaps[i], bc)
 		}
-		totalKB -= sizes[i]
+		usage.release(i)
+		totalKB = usage.totalKB()
 		alive[i] = false
 	}
 }
 
 func logRotationSummary(ctx context.Context, deps *Dependencies, repoDir string, bc *backupContext) {
 	snapsFinal, _ := listSnapshots(ctx, deps, repoDir)
-	var totalKB int64
-	for _, s := range snapsFinal {
-		kb, _ := dirSizeKB(ctx, deps, s.TimeDir, bc)
-		totalKB += kb
-	}
-	bc.logf("[rotate:summary] %d snapshots, total %s", len(snapsFinal), humanKB(totalKB))
+	usage, _ := measureSnapshots(ctx, deps, snapsFinal, nil, bc)
+	bc.logf("[rotate:summary] %d snapshots, total %s", len(snapsFinal), humanKB(usage.totalKB()))
 }
 
 func buildSnapshotPath(fs FileSystemPort, backupDir, repoKey string, now time.Time) string {
@@ -1008,9 +1036,25 @@ func printConfig(cfg *Config, bc *backupContext) {
 	bc.vlogf("   Backup directory: %s", cfg.BackupDir)
 	bc.vlogf("   Keep count: %d", cfg.KeepCount)
 	bc.vlogf("   Keep days: %d", cfg.KeepDays)
+	if gfsEnabled(cfg) {
+		bc.vlogf("   Keep hourly/daily/weekly/monthly/yearly: %d/%d/%d/%d/%d",
+			cfg.KeepHourly, cfg.KeepDaily, cfg.KeepWeekly, cfg.KeepMonthly, cfg.KeepYearly)
+	}
 	bc.vlogf("   Max total GB per repo: %d", cfg.MaxTotalGBPerRepo)
 	bc.vlogf("   Size margin MB: %d", cfg.SizeMarginMB)
 	bc.vlogf("   No size check: %t", cfg.NoSize)
+	bc.vlogf("   Dedup: %t", cfg.Dedup)
+	format := cfg.Format
+	if format == "" {
+		format = SnapshotFormatDir
+	}
+	bc.vlogf("   Format: %s", format)
+	bc.vlogf("   Max file MB: %d", cfg.MaxFileMB)
+	bc.vlogf("   Max untracked total MB: %d", cfg.MaxUntrackedMB)
+	if len(cfg.SkipExtensions) > 0 {
+		bc.vlogf("   Skip binary extensions: %s", strings.Join(cfg.SkipExtensions, " "))
+	}
+	bc.vlogf("   Encryption: %t", cfg.Encryption.Enabled)
 	bc.vlogf("   Snapshot time format: HHMMSS-NNNNNNNNN")
 
 	style := strings.TrimSpace(cfg.RepoKeyStyle)
@@ -1072,6 +1116,7 @@ func printBackupSummary(result *BackupResult, bc *backupContext) {
 
 func copyRepoSnapshot(
 	ctx context.Context,
+	cfg *Config,
 	deps *Dependencies,
 	repoRoot,
 	targetPath string,
@@ -1098,13 +1143,47 @@ func copyRepoSnapshot(
 		return err
 	}
 
-	excludes, err := readDevbackIgnore(ctx, deps, repoRoot, bc)
+	keep, err := selectUntrackedPaths(ctx, cfg, deps, repoRoot, result, bc)
+	if err != nil {
+		return err
+	}
+
+	if err := copySelectedFiles(ctx, deps, keep, repoRoot, targetPath, result, bc); err != nil {
+		if ctx.Err() != nil || !onlyPermissionErrors(result) {
+			return err
+		}
+		bc.warnf("skipped %d unreadable ignored/untracked item(s)", len(result.PermissionErrs))
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if len(keep) > 0 {
+		bc.logf("✓ Copied ignored/untracked: %d item(s)", len(keep))
+	} else {
+		bc.logf("⌘ No ignored/untracked files to copy (after exclusions)")
+	}
+
+	return nil
+}
+
+// selectUntrackedPaths lists ignored/untracked paths from git and drops those excluded by
+// .devbackignore files and the global ignore file, then those rejected by the size and type
+// guards (counted in result).
+func selectUntrackedPaths(
+	ctx context.Context,
+	cfg *Config,
+	deps *Dependencies,
+	repoRoot string,
+	result *BackupResult,
+	bc *backupContext,
+) ([]string, error) {
+	ignore, err := newIgnoreMatcher(ctx, deps, repoRoot, cfg.GlobalIgnoreFile, bc)
 	if err != nil {
 		bc.warnf(".devbackignore: %v", err)
 	}
 	allPaths, err := deps.Git.ListIgnoredUntracked(ctx, repoRoot)
 	if err != nil {
-		return fmt.Errorf("git ls-files: %w", ErrCritical)
+		return nil, fmt.Errorf("git ls-files: %w", ErrCritical)
 	}
 	if bc.verbose {
 		bc.vlogf("→ Raw ignored/untracked from git: %d", len(allPaths))
@@ -1114,31 +1193,19 @@ func copyRepoSnapshot(
 	}
 	keep := make([]string, 0, len(allPaths))
 	for _, p := range allPaths {
-		if skip, ex := shouldSkip(p, excludes); skip {
-			bc.vlogf("   SKIP: %s (matched '%s')", p, ex)
+		if skip, rule := ignore.excluded(ctx, p, strings.HasSuffix(p, "/")); skip {
+			bc.vlogf("   SKIP: %s (matched '%s' in %s:%d)", p, rule.pattern, rule.source, rule.line)
 			continue
 		}
 		bc.vlogf("   KEEP: %s", p)
 		keep = append(keep, p)
 	}
-
-	if err := copySelectedFiles(ctx, deps, keep, repoRoot, targetPath, result, bc); err != nil {
-		return err
-	}
-	if ctx.Err() != nil {
-		return ctx.Err()
-	}
-	if len(keep) > 0 {
-		bc.logf("✓ Copied ignored/untracked: %d item(s)", len(keep))
-	} else {
-		bc.logf("⌘ No ignored/untracked files to copy (after exclusions)")
-	}
-
-	return nil
+	return applyUntrackedGuards(ctx, cfg, deps, repoRoot, keep, result, bc), nil
 }
 
 func planRepoSnapshot(
 	ctx context.Context,
+	cfg *Config,
 	deps *Dependencies,
 	repoRoot,
 	targetPath string,
@@ -1153,33 +1220,19 @@ func planRepoSnapshot(
 	if _, err := deps.FileSystem.Stat(ctx, srcGit); err != nil {
 		return 0, fmt.Errorf("git common dir not found: %w", err)
 	}
-	bc.logf("Dry run: would copy .git to:%s", dstGit)
-
-	excludes, err := readDevbackIgnore(ctx, deps, repoRoot, bc)
-	if err != nil {
-		bc.warnf(".devbackignore: %v", err)
+	if !isArchiveFormat(cfg.Format) {
+		bc.logf("Dry run: would copy .git to:%s", dstGit)
 	}
-	allPaths, err := deps.Git.ListIgnoredUntracked(ctx, repoRoot)
+
+	keep, err := selectUntrackedPaths(ctx, cfg, deps, repoRoot, &BackupResult{}, bc)
 	if err != nil {
-		return 0, fmt.Errorf("git ls-files: %w", ErrCritical)
-	}
-	if bc.verbose {
-		bc.vlogf("→ Raw ignored/untracked from git: %d", len(allPaths))
-		for i, p := range allPaths {
-			bc.vlogf("   %4d  %s", i+1, p)
-		}
-	}
-	keep := make([]string, 0, len(allPaths))
-	for _, p := range allPaths {
-		if skip, ex := shouldSkip(p, excludes); skip {
-			bc.vlogf("   SKIP: %s (matched '%s')", p, ex)
-			continue
-		}
-		bc.vlogf("   KEEP: %s", p)
-		keep = append(keep, p)
+		return 0, err
 	}
 
-	if len(keep) > 0 {
+	if isArchiveFormat(cfg.Format) {
+		archivePath := deps.FileSystem.Join(targetPath, snapshotArchiveName(cfg.Format))
+		bc.logf("Dry run: would archive .git and %d ignored/untracked item(s) to:%s", len(keep), archivePath)
+	} else if len(keep) > 0 {
 		bc.logf("Dry run: would copy ignored/untracked: %d item(s)", len(keep))
 	} else {
 		bc.logf("Dry run: no ignored/untracked files to copy (after exclusions)")
@@ -1202,11 +1255,21 @@ func handleDryRun(
 
 	snapshotDir := buildSnapshotPath(deps.FileSystem, cfg.BackupDir, repoKey, time.Now())
 	bc.logf("Dry run: backup skipped; would create:%s", snapshotDir)
+	if cfg.Pin {
+		bc.logf("Dry run: would pin the new snapshot%s", formatPinLabel(strings.TrimSpace(cfg.Label)))
+	}
 
-	if _, err := planRepoSnapshot(ctx, deps, repoRoot, snapshotDir, bc); err != nil {
+	if _, err := planRepoSnapshot(ctx, cfg, deps, repoRoot, snapshotDir, bc); err != nil {
+		return nil, fmt.Errorf("dry run planning failed: %w", ErrCritical)
+	}
+	if err := planTrackedChanges(ctx, deps, cfg.TrackedChanges, repoRoot, bc); err != nil {
+		return nil, fmt.Errorf("dry run planning failed: %w", ErrCritical)
+	}
+	if err := planExternalPaths(ctx, cfg, deps, repoRoot, bc); err != nil {
 		return nil, fmt.Errorf("dry run planning failed: %w", ErrCritical)
 	}
 
+	migrateLegacySnapshots(ctx, cfg, deps, repoRoot, repoKey, true, bc)
 	repoDir := deps.FileSystem.Join(cfg.BackupDir, repoKey)
 	if _, err := deps.FileSystem.Stat(ctx, repoDir); err == nil {
 		rotateRepo(ctx, deps, repoDir, cfg, true, bc)
@@ -1228,6 +1291,12 @@ func handleBackupFlow(
 	if ctx.Err() != nil {
 		return nil, ErrInterrupted
 	}
+	sealer, err := newSnapshotSealer(ctx, cfg, deps)
+	if err != nil {
+		return nil, err
+	}
+	bc.sealer = sealer
+	defer func() { bc.sealer = nil }()
 	now := time.Now()
 	dateDir := now.Format("2006-01-02")
 	targetPath, err := createUniqueSnapshotDir(ctx, deps, repoDir, dateDir, now)
@@ -1256,7 +1325,25 @@ func handleBackupFlow(
 	}
 
 	result := &BackupResult{}
-	if err := copyRepoSnapshot(ctx, deps, repoRoot, targetPath, result, bc); err != nil {
+	var prevRoot string
+	if isArchiveFormat(cfg.Format) {
+		err = archiveRepoSnapshot(ctx, cfg, deps, repoRoot, targetPath, result, bc)
+	} else {
+		if sealer == nil {
+			bc.linkDest = newLinkDest(ctx, cfg, deps, repoDir, targetPath, bc)
+		}
+		err = copyRepoSnapshot(ctx, cfg, deps, repoRoot, targetPath, result, bc)
+		if err == nil {
+			err = copyTrackedChanges(ctx, deps, cfg.TrackedChanges, repoRoot, targetPath, result, bc)
+		}
+		if err == nil {
+			err = copyExternalPaths(ctx, cfg, deps, repoRoot, targetPath, result, bc)
+		}
+		result.LinkedFiles = bc.linkDest.linkedFiles()
+		prevRoot = bc.linkDest.previousRoot()
+		bc.linkDest = nil
+	}
+	if err != nil {
 		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 			return nil, ErrInterrupted
 		}
@@ -1264,6 +1351,20 @@ func handleBackupFlow(
 		return nil, fmt.Errorf("backup failed: %w", ErrCritical)
 	}
 
+	result.TotalFiles = result.CopiedFiles + result.ExternalFiles
+	if result.LinkedFiles > 0 {
+		bc.logf("✓ Linked %d unchanged file(s) from previous snapshot", result.LinkedFiles)
+	}
+	if err := recordSnapshotChecksums(ctx, deps, targetPath, prevRoot, bc); err != nil {
+		bc.warnf("record checksums: %v", err)
+	}
+	pinNewSnapshot(ctx, cfg, deps, targetPath, bc)
+
+	manifest := buildSnapshotManifest(ctx, cfg, deps, repoRoot, repoDir, targetPath, result, now, bc)
+	if err := writeSnapshotManifest(ctx, deps, targetPath, manifest); err != nil {
+		bc.warnf("write manifest: %v", err)
+	}
+
 	_ = deps.FileSystem.RemoveAll(ctx, partial)
 	if err := deps.FileSystem.WriteFile(ctx, done, []byte{}, 0o644); err != nil {
 		return nil, fmt.Errorf("mark done: %w", ErrCritical)
@@ -1282,6 +1383,7 @@ func handleBackupFlow(
 		}()
 		rotateRepo(ctx, deps, repoDir, cfg, false, bc)
 	}()
+	startRemotePush(cfg, deps, repoDir, bc)
 
 	bc.logf("✓ Backup finished → %s", targetPath)
 
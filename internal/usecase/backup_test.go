@@ -309,7 +309,7 @@ func TestShortHashN_Bounds(t *testing.T) {
 	}
 }
 
-func TestReadDevbackIgnoreAndShouldSkip(t *testing.T) {
+func TestIgnoreMatcher_RootDevbackIgnore(t *testing.T) {
 	ctx := context.Background()
 	fs := newTestFileSystem()
 	repoRoot := t.TempDir()
@@ -319,21 +319,24 @@ func TestReadDevbackIgnoreAndShouldSkip(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	excludes, err := readDevbackIgnore(ctx, &Dependencies{FileSystem: fs}, repoRoot, newTestBackupContext(true))
+	ignore, err := newIgnoreMatcher(ctx, &Dependencies{FileSystem: fs}, repoRoot, "", newTestBackupContext(true))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(excludes) != 4 {
-		t.Fatalf("expected 4 excludes, got %d", len(excludes))
+	if len(ignore.dirs[""]) != 4 {
+		t.Fatalf("expected 4 rules, got %d", len(ignore.dirs[""]))
 	}
-	if skip, ex := shouldSkip("build/output.bin", excludes); !skip || ex != "build" {
-		t.Fatalf("expected build to be skipped, got %v %s", skip, ex)
+	if skip, rule := ignore.excluded(ctx, "build/output.bin", false); !skip || rule.pattern != "build/" {
+		t.Fatalf("expected build to be skipped, got %v %+v", skip, rule)
 	}
-	if skip, ex := shouldSkip("notes.tmp", excludes); !skip || ex != "*.tmp" {
-		t.Fatalf("expected *.tmp to be skipped, got %v %s", skip, ex)
+	if skip, rule := ignore.excluded(ctx, "notes.tmp", false); !skip || rule.pattern != "*.tmp" {
+		t.Fatalf("expected *.tmp to be skipped, got %v %+v", skip, rule)
 	}
-	if skip, ex := shouldSkip("logs/app.log", excludes); !skip || ex != "logs/*.log" {
-		t.Fatalf("expected logs/*.log to be skipped, got %v %s", skip, ex)
+	if skip, rule := ignore.excluded(ctx, "logs/app.log", false); !skip || rule.pattern != "logs/*.log" {
+		t.Fatalf("expected logs/*.log to be skipped, got %v %+v", skip, rule)
+	}
+	if skip, rule := ignore.excluded(ctx, "foo/bar/baz.txt", false); !skip || rule.line != 3 {
+		t.Fatalf("expected foo/bar to be skipped by line 3, got %v %+v", skip, rule)
 	}
 }
 
@@ -926,7 +929,7 @@ func TestHandleBackupFlow_CopyError(t *testing.T) {
 
 func TestCopyRepoSnapshot_MissingGit(t *testing.T) {
 	ctx := context.Background()
-	err := copyRepoSnapshot(ctx, &Dependencies{}, "/repo", "/dst", &BackupResult{}, newTestBackupContext(false))
+	err := copyRepoSnapshot(ctx, &Config{}, &Dependencies{}, "/repo", "/dst", &BackupResult{}, newTestBackupContext(false))
 	if err == nil {
 		t.Fatal("expected error without git adapter")
 	}
@@ -939,7 +942,7 @@ func TestCopyRepoSnapshot_NoGitDir(t *testing.T) {
 	repoRoot := t.TempDir()
 	target := t.TempDir()
 
-	err := copyRepoSnapshot(ctx, deps, repoRoot, target, &BackupResult{}, newTestBackupContext(false))
+	err := copyRepoSnapshot(ctx, &Config{}, deps, repoRoot, target, &BackupResult{}, newTestBackupContext(false))
 	if err == nil {
 		t.Fatal("expected error when .git is missing")
 	}
@@ -981,7 +984,8 @@ func TestCopyRepoSnapshot_UsesCommonGitDir(t *testing.T) {
 	deps := &Dependencies{FileSystem: fs, Git: mock}
 	target := t.TempDir()
 
-	if err := copyRepoSnapshot(ctx, deps, repoRoot, target, &BackupResult{}, newTestBackupContext(false)); err != nil {
+	err := copyRepoSnapshot(ctx, &Config{}, deps, repoRoot, target, &BackupResult{}, newTestBackupContext(false))
+	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
@@ -1023,7 +1027,7 @@ func TestCopyRepoSnapshot_ListIgnoredError(t *testing.T) {
 	}
 
 	deps := &Dependencies{FileSystem: fs, Git: mock}
-	err := copyRepoSnapshot(ctx, deps, repoRoot, target, &BackupResult{}, newTestBackupContext(false))
+	err := copyRepoSnapshot(ctx, &Config{}, deps, repoRoot, target, &BackupResult{}, newTestBackupContext(false))
 	if !errors.Is(err, ErrCritical) {
 		t.Fatalf("expected critical error, got %v", err)
 	}
@@ -1044,7 +1048,7 @@ func TestCopyRepoSnapshot_DevbackIgnoreError(t *testing.T) {
 	}
 
 	deps := &Dependencies{FileSystem: failingReadFS{testFileSystem: newTestFileSystem()}, Git: mock}
-	err := copyRepoSnapshot(ctx, deps, repoRoot, target, &BackupResult{}, newTestBackupContext(false))
+	err := copyRepoSnapshot(ctx, &Config{}, deps, repoRoot, target, &BackupResult{}, newTestBackupContext(false))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
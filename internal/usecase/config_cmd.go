@@ -0,0 +1,519 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// configEditFile is the draft devback config edit works on next to config.toml.
+const configEditFile = "config.edit.toml"
+
+// configKeyPart matches one part of a config key: a TOML name with an optional entry number.
+//
+//nolint:gochecknoglobals // compiled once.
+var configKeyPart = regexp.MustCompile(`^([a-z_]+)(?:\[(\d+)\])?$`)
+
+// ConfigOptions describes a devback config subcommand. Key is a dotted path such as
+// backup.keep_count or targets[0].bucket.
+type ConfigOptions struct {
+	HomeDir   string
+	Key       string
+	Values    []string
+	Effective bool
+}
+
+// ConfigPath returns the path of config.toml for homeDir.
+func ConfigPath(fs FileSystemPort, homeDir string) string {
+	return buildInitPaths(fs, homeDir).configPath
+}
+
+// ConfigGet returns the value of opts.Key in config.toml, with defaults for keys the file does
+// not set. List values are returned one per line; an unset override yields "".
+func ConfigGet(ctx context.Context, opts ConfigOptions, deps *Dependencies) (string, error) {
+	cfg, _, err := loadConfigForCommand(ctx, opts, deps)
+	if err != nil {
+		return "", err
+	}
+	field, err := lookupConfigKey(&cfg, opts.Key)
+	if err != nil {
+		return "", err
+	}
+	if field.Kind() == reflect.Slice {
+		return strings.Join(field.Interface().([]string), "\n"), nil
+	}
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return "", nil
+		}
+		field = field.Elem()
+	}
+	return fmt.Sprint(field.Interface()), nil
+}
+
+// ConfigSet sets opts.Key to opts.Values and saves config.toml when the result is valid. List
+// keys take any number of values, other keys exactly one.
+func ConfigSet(ctx context.Context, opts ConfigOptions, deps *Dependencies, logger *slog.Logger) error {
+	cfg, path, err := loadConfigForCommand(ctx, opts, deps)
+	if err != nil {
+		return err
+	}
+	field, err := lookupConfigKey(&cfg, opts.Key)
+	if err != nil {
+		return err
+	}
+	if err := setConfigValue(field, opts.Key, opts.Values); err != nil {
+		return err
+	}
+	return saveConfigForCommand(ctx, deps, logger, path, cfg)
+}
+
+// ConfigUnset restores the default of opts.Key and saves config.toml. A key naming a whole
+// [[targets]] or [[repo]] entry, such as targets[1], removes the entry.
+func ConfigUnset(ctx context.Context, opts ConfigOptions, deps *Dependencies, logger *slog.Logger) error {
+	cfg, path, err := loadConfigForCommand(ctx, opts, deps)
+	if err != nil {
+		return err
+	}
+	if name, idx, ok := configEntryKey(opts.Key); ok {
+		if err := removeConfigEntry(&cfg, name, idx); err != nil {
+			return err
+		}
+		return saveConfigForCommand(ctx, deps, logger, path, cfg)
+	}
+	field, err := lookupConfigKey(&cfg, opts.Key)
+	if err != nil {
+		return err
+	}
+	defaults := DefaultConfigFile()
+	if def, err := lookupConfigKey(&defaults, opts.Key); err == nil {
+		field.Set(def)
+	} else {
+		field.Set(reflect.Zero(field.Type()))
+	}
+	return saveConfigForCommand(ctx, deps, logger, path, cfg)
+}
+
+// ConfigValidate checks config.toml and returns its path. Problems are returned as a
+// *ConfigError; a missing file is valid and exists is false.
+func ConfigValidate(ctx context.Context, opts ConfigOptions, deps *Dependencies) (path string, exists bool, err error) {
+	if err := validateConfigDependencies(deps); err != nil {
+		return "", false, err
+	}
+	if strings.TrimSpace(opts.HomeDir) == "" {
+		return "", false, fmt.Errorf("home directory is empty: %w", ErrCritical)
+	}
+	path = ConfigPath(deps.FileSystem, opts.HomeDir)
+	exists, err = pathExists(ctx, deps.FileSystem, path)
+	if err != nil {
+		return path, false, fmt.Errorf("check config path: %w", ErrCritical)
+	}
+	cfg, err := deps.Config.Load(ctx, path)
+	if err != nil {
+		return path, exists, ConfigLoadError(err)
+	}
+	if issues := validateRecipients(cfg, deps.Encryption); len(issues) > 0 {
+		return path, exists, &ConfigError{Path: path, Issues: issues}
+	}
+	return path, exists, nil
+}
+
+// ConfigShow renders every setting of config.toml as TOML, with defaults for keys the file does
+// not set. With opts.Effective, the per-repository overrides of the current repository are
+// applied and annotated with their source.
+func ConfigShow(ctx context.Context, opts ConfigOptions, deps *Dependencies, logger *slog.Logger) (string, error) {
+	cfg, _, err := loadConfigForCommand(ctx, opts, deps)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	sources := map[string]string{}
+	if opts.Effective {
+		repoRoot, settings, err := effectiveRepoSettings(ctx, cfg, opts.HomeDir, deps, logger)
+		if err != nil {
+			return "", err
+		}
+		if repoRoot == "" {
+			b.WriteString("# Not in a git repository: no per-repository overrides apply.\n\n")
+		} else {
+			fmt.Fprintf(&b, "# Effective settings for %s\n\n", contractHomeDir(repoRoot, opts.HomeDir,
+				deps.FileSystem.PathSeparator()))
+			applyEffectiveSettings(&cfg, settings, opts.HomeDir, deps.FileSystem.PathSeparator(), sources)
+		}
+	}
+	renderConfigShow(&b, reflect.ValueOf(cfg), sources)
+	return b.String(), nil
+}
+
+// ConfigEdit lets edit change a draft copy of config.toml and replaces the file only when the
+// draft is valid. An invalid draft is kept and edited again by the next call.
+func ConfigEdit(
+	ctx context.Context,
+	opts ConfigOptions,
+	deps *Dependencies,
+	logger *slog.Logger,
+	edit func(path string) error,
+) error {
+	if logger == nil {
+		panic("logger is required")
+	}
+	if err := validateConfigDependencies(deps); err != nil {
+		return err
+	}
+	if strings.TrimSpace(opts.HomeDir) == "" {
+		return fmt.Errorf("home directory is empty: %w", ErrCritical)
+	}
+	paths := buildInitPaths(deps.FileSystem, opts.HomeDir)
+	draft := deps.FileSystem.Join(paths.configDir, configEditFile)
+
+	draftExists, err := pathExists(ctx, deps.FileSystem, draft)
+	if err != nil {
+		return fmt.Errorf("check %s: %w", draft, ErrCritical)
+	}
+	configExists, err := pathExists(ctx, deps.FileSystem, paths.configPath)
+	if err != nil {
+		return fmt.Errorf("check config path: %w", ErrCritical)
+	}
+	switch {
+	case draftExists:
+		logger.Info("Resuming unsaved changes", "path", draft)
+	case configExists:
+		if err := deps.FileSystem.Copy(ctx, paths.configPath, draft); err != nil {
+			return fmt.Errorf("copy config: %w", ErrCritical)
+		}
+	default:
+		if err := deps.FileSystem.CreateDir(ctx, paths.configDir, 0o755); err != nil {
+			return fmt.Errorf("create config dir: %w", ErrCritical)
+		}
+		if err := deps.Config.Save(ctx, draft, DefaultConfigFile()); err != nil {
+			return fmt.Errorf("save config: %w", ErrCritical)
+		}
+	}
+
+	if err := edit(draft); err != nil {
+		return fmt.Errorf("run editor: %v (changes kept in %s): %w", err, draft, ErrCritical)
+	}
+	cfg, err := deps.Config.Load(ctx, draft)
+	if err == nil {
+		if issues := validateRecipients(cfg, deps.Encryption); len(issues) > 0 {
+			err = &ConfigError{Path: draft, Issues: issues}
+		}
+	}
+	if err != nil {
+		var cfgErr *ConfigError
+		if errors.As(err, &cfgErr) {
+			return fmt.Errorf("%w\nconfig.toml was not changed; run devback config edit again to fix %s",
+				cfgErr, draft)
+		}
+		return ConfigLoadError(err)
+	}
+	if err := deps.FileSystem.Move(ctx, draft, paths.configPath); err != nil {
+		return fmt.Errorf("replace config: %w", ErrCritical)
+	}
+	logger.Info("Config saved", "path", paths.configPath)
+	return nil
+}
+
+func validateConfigDependencies(deps *Dependencies) error {
+	if deps == nil {
+		return fmt.Errorf("dependencies are required: %w", ErrCritical)
+	}
+	if deps.FileSystem == nil {
+		return fmt.Errorf("filesystem adapter not available: %w", ErrCritical)
+	}
+	if deps.Config == nil {
+		return fmt.Errorf("config adapter not available: %w", ErrCritical)
+	}
+	return nil
+}
+
+func loadConfigForCommand(ctx context.Context, opts ConfigOptions, deps *Dependencies) (ConfigFile, string, error) {
+	if err := validateConfigDependencies(deps); err != nil {
+		return ConfigFile{}, "", err
+	}
+	if strings.TrimSpace(opts.HomeDir) == "" {
+		return ConfigFile{}, "", fmt.Errorf("home directory is empty: %w", ErrCritical)
+	}
+	path := ConfigPath(deps.FileSystem, opts.HomeDir)
+	cfg, err := deps.Config.Load(ctx, path)
+	if err != nil {
+		return ConfigFile{}, "", ConfigLoadError(err)
+	}
+	return cfg, path, nil
+}
+
+// saveConfigForCommand validates cfg and writes it with the standard comments.
+func saveConfigForCommand(
+	ctx context.Context,
+	deps *Dependencies,
+	logger *slog.Logger,
+	path string,
+	cfg ConfigFile,
+) error {
+	issues := append(ValidateConfigFile(cfg), validateRecipients(cfg, deps.Encryption)...)
+	if len(issues) > 0 {
+		return &ConfigError{Path: path, Issues: issues}
+	}
+	if err := deps.FileSystem.CreateDir(ctx, deps.FileSystem.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create config dir: %w", ErrCritical)
+	}
+	if err := deps.Config.Save(ctx, path, cfg); err != nil {
+		return fmt.Errorf("save config: %w", ErrCritical)
+	}
+	if logger != nil {
+		logger.Debug("Config saved", "path", path)
+	}
+	return nil
+}
+
+// lookupConfigKey returns the settable field of cfg named by key. Entries of [[targets]] and
+// [[repo]] are addressed by number, e.g. targets[0].bucket.
+func lookupConfigKey(cfg *ConfigFile, key string) (reflect.Value, error) {
+	unknown := fmt.Errorf("unknown config key %q: %w", key, ErrUsage)
+	v := reflect.ValueOf(cfg).Elem()
+	parts := strings.Split(strings.TrimSpace(key), ".")
+	for i, part := range parts {
+		m := configKeyPart.FindStringSubmatch(part)
+		if m == nil || v.Kind() != reflect.Struct {
+			return reflect.Value{}, unknown
+		}
+		field, ok := configStructField(v, m[1])
+		if !ok {
+			return reflect.Value{}, unknown
+		}
+		isEntries := field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Struct
+		switch {
+		case isEntries && m[2] != "":
+			idx, _ := strconv.Atoi(m[2])
+			if idx >= field.Len() {
+				return reflect.Value{}, fmt.Errorf(
+					"%s[%d] does not exist (add entries with devback config edit): %w", m[1], idx, ErrUsage)
+			}
+			field = field.Index(idx)
+		case isEntries || m[2] != "":
+			return reflect.Value{}, unknown
+		}
+		last := i == len(parts)-1
+		if last != (field.Kind() != reflect.Struct) {
+			return reflect.Value{}, unknown
+		}
+		v = field
+	}
+	return v, nil
+}
+
+// configEntryKey reports whether key names a whole [[targets]] or [[repo]] entry.
+func configEntryKey(key string) (string, int, bool) {
+	m := configKeyPart.FindStringSubmatch(strings.TrimSpace(key))
+	if m == nil || m[2] == "" {
+		return "", 0, false
+	}
+	idx, _ := strconv.Atoi(m[2])
+	return m[1], idx, true
+}
+
+func removeConfigEntry(cfg *ConfigFile, name string, idx int) error {
+	entries, ok := configStructField(reflect.ValueOf(cfg).Elem(), name)
+	if !ok || entries.Kind() != reflect.Slice {
+		return fmt.Errorf("unknown config key %q: %w", fmt.Sprintf("%s[%d]", name, idx), ErrUsage)
+	}
+	if idx >= entries.Len() {
+		return fmt.Errorf("%s[%d] does not exist: %w", name, idx, ErrUsage)
+	}
+	rest := reflect.AppendSlice(entries.Slice(0, idx), entries.Slice(idx+1, entries.Len()))
+	entries.Set(rest)
+	return nil
+}
+
+// configStructField returns the field of struct v with the TOML name, looking into embedded
+// structs.
+func configStructField(v reflect.Value, name string) (reflect.Value, bool) {
+	for _, f := range configFields(v) {
+		if f.name == name {
+			return f.value, true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+type configFieldValue struct {
+	name  string
+	value reflect.Value
+}
+
+// configFields lists the TOML fields of struct v in declaration order, flattening embedded
+// structs.
+func configFields(v reflect.Value) []configFieldValue {
+	var fields []configFieldValue
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if f.Anonymous {
+			fields = append(fields, configFields(v.Field(i))...)
+			continue
+		}
+		if name := f.Tag.Get("toml"); name != "" {
+			fields = append(fields, configFieldValue{name: name, value: v.Field(i)})
+		}
+	}
+	return fields
+}
+
+func setConfigValue(field reflect.Value, key string, values []string) error {
+	if field.Kind() == reflect.Slice {
+		list := []string{}
+		for _, v := range values {
+			if v = strings.TrimSpace(v); v != "" {
+				list = append(list, v)
+			}
+		}
+		field.Set(reflect.ValueOf(list))
+		return nil
+	}
+	if len(values) != 1 {
+		return fmt.Errorf("%s takes exactly one value: %w", key, ErrUsage)
+	}
+	target := field
+	if field.Kind() == reflect.Pointer {
+		target = reflect.New(field.Type().Elem()).Elem()
+	}
+	value := strings.TrimSpace(values[0])
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a number: %w", key, value, ErrUsage)
+		}
+		target.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not true or false: %w", key, value, ErrUsage)
+		}
+		target.SetBool(b)
+	default:
+		return fmt.Errorf("%s cannot be set: %w", key, ErrUsage)
+	}
+	if field.Kind() == reflect.Pointer {
+		field.Set(target.Addr())
+	}
+	return nil
+}
+
+// effectiveRepoSettings resolves the per-repository overrides of the current repository; the
+// returned root is empty outside a repository.
+func effectiveRepoSettings(
+	ctx context.Context,
+	cfg ConfigFile,
+	homeDir string,
+	deps *Dependencies,
+	logger *slog.Logger,
+) (string, []RepoSetting, error) {
+	if deps.Git == nil {
+		return "", nil, nil
+	}
+	repoRoot, err := resolveRepoRoot(ctx, deps)
+	if err != nil {
+		return "", nil, nil
+	}
+	if err := ensureGitRepo(ctx, deps, repoRoot); err != nil {
+		return "", nil, nil
+	}
+	runtimeCfg, err := RuntimeConfigFromFile(cfg, homeDir)
+	if err != nil {
+		return "", nil, err
+	}
+	_, settings, err := resolveRepoConfig(ctx, runtimeCfg, deps, repoRoot, newBackupContext(logger, false))
+	if err != nil {
+		return "", nil, err
+	}
+	return repoRoot, settings, nil
+}
+
+// applyEffectiveSettings copies the overridden settings into cfg.Backup and records their
+// sources by key.
+func applyEffectiveSettings(
+	cfg *ConfigFile,
+	settings []RepoSetting,
+	homeDir string,
+	sep byte,
+	sources map[string]string,
+) {
+	for _, s := range settings {
+		if s.Source == SettingSourceConfig {
+			continue
+		}
+		value := s.Value
+		if s.Key == "base_dir" {
+			value = contractHomeDir(value, homeDir, sep)
+		}
+		key := "backup." + s.Key
+		field, err := lookupConfigKey(cfg, key)
+		if err != nil || setConfigValue(field, key, []string{value}) != nil {
+			continue
+		}
+		sources[key] = s.Source
+	}
+}
+
+// renderConfigShow writes the tables of cfg; array tables follow the plain ones and list only
+// the fields an entry sets.
+func renderConfigShow(b *strings.Builder, cfg reflect.Value, sources map[string]string) {
+	var arrays []configFieldValue
+	for _, table := range configFields(cfg) {
+		if table.value.Kind() == reflect.Slice {
+			arrays = append(arrays, table)
+			continue
+		}
+		fmt.Fprintf(b, "[%s]\n", table.name)
+		for _, f := range configFields(table.value) {
+			value, _ := formatConfigValue(f.value)
+			b.WriteString(f.name + " = " + value)
+			if source := sources[table.name+"."+f.name]; source != "" {
+				b.WriteString(" # " + source)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	for _, table := range arrays {
+		for i := range table.value.Len() {
+			fmt.Fprintf(b, "[[%s]]\n", table.name)
+			for _, f := range configFields(table.value.Index(i)) {
+				if value, ok := formatConfigValue(f.value); ok && !f.value.IsZero() {
+					b.WriteString(f.name + " = " + value + "\n")
+				}
+			}
+			b.WriteString("\n")
+		}
+	}
+}
+
+// formatConfigValue renders v as a TOML value; ok is false for an unset override.
+func formatConfigValue(v reflect.Value) (string, bool) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String()), true
+	case reflect.Slice:
+		items := make([]string, 0, v.Len())
+		for i := range v.Len() {
+			items = append(items, strconv.Quote(v.Index(i).String()))
+		}
+		return "[" + strings.Join(items, ", ") + "]", true
+	default:
+		return fmt.Sprint(v.Interface()), true
+	}
+}
@@ -0,0 +1,269 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newConfigTestDeps(t *testing.T) (*Dependencies, *fakeConfigPort, string) {
+	t.Helper()
+	fs := newTestFileSystem()
+	configPort := newFakeConfigPort(fs)
+	return &Dependencies{FileSystem: fs, Config: configPort, Encryption: testEncryption{}}, configPort, t.TempDir()
+}
+
+func TestConfigSetGetUnset(t *testing.T) {
+	ctx := context.Background()
+	deps, configPort, homeDir := newConfigTestDeps(t)
+	path := ConfigPath(deps.FileSystem, homeDir)
+	base := DefaultConfigFile()
+	base.Targets = []TargetConfig{{Name: "usb", Type: "dir", Path: "/mnt/usb"}, {Type: "s3", Bucket: "b"}}
+	base.Repos = []RepoConfig{{Match: "github.com/acme/*"}}
+	configPort.data[path] = base
+
+	set := func(key string, values ...string) error {
+		return ConfigSet(ctx, ConfigOptions{HomeDir: homeDir, Key: key, Values: values}, deps, slog.Default())
+	}
+	get := func(key string) string {
+		t.Helper()
+		value, err := ConfigGet(ctx, ConfigOptions{HomeDir: homeDir, Key: key}, deps)
+		if err != nil {
+			t.Fatalf("get %s: %v", key, err)
+		}
+		return value
+	}
+
+	for _, tc := range []struct {
+		key    string
+		values []string
+		want   string
+	}{
+		{"backup.keep_count", []string{"12"}, "12"},
+		{"backup.dedup", []string{"false"}, "false"},
+		{"logging.level", []string{"debug"}, "debug"},
+		{"sweep.roots", []string{"~/src", "/work"}, "~/src\n/work"},
+		{"targets[1].prefix", []string{"laptop"}, "laptop"},
+		{"repo[0].keep_count", []string{"0"}, "0"},
+		{"encryption.recipients", []string{"age1abc"}, "age1abc"},
+	} {
+		if err := set(tc.key, tc.values...); err != nil {
+			t.Fatalf("set %s: %v", tc.key, err)
+		}
+		if got := get(tc.key); got != tc.want {
+			t.Fatalf("%s: expected %q, got %q", tc.key, tc.want, got)
+		}
+	}
+	if got := get("repo[0].keep_days"); got != "" {
+		t.Fatalf("unset override must be empty, got %q", got)
+	}
+
+	for _, tc := range []struct {
+		key    string
+		values []string
+	}{
+		{"backup.keep_count", []string{"-5"}},
+		{"backup.keep_count", []string{"many"}},
+		{"backup.keep_count", []string{"1", "2"}},
+		{"logging.level", []string{"verbose"}},
+		{"backup.nope", []string{"1"}},
+		{"backup", []string{"1"}},
+		{"targets.bucket", []string{"b"}},
+		{"targets[2].bucket", []string{"b"}},
+		{"targets[1].bucket", []string{""}},
+		{"encryption.recipients", []string{"age1def", "bad-key"}},
+	} {
+		if err := set(tc.key, tc.values...); !errors.Is(err, ErrUsage) {
+			t.Fatalf("set %s %v: expected ErrUsage, got %v", tc.key, tc.values, err)
+		}
+	}
+	if got := get("backup.keep_count"); got != "12" {
+		t.Fatalf("a rejected set must not be saved, got %q", got)
+	}
+	if got := get("encryption.recipients"); got != "age1abc" {
+		t.Fatalf("a rejected recipient must not be saved, got %q", got)
+	}
+
+	unset := func(key string) {
+		t.Helper()
+		if err := ConfigUnset(ctx, ConfigOptions{HomeDir: homeDir, Key: key}, deps, slog.Default()); err != nil {
+			t.Fatalf("unset %s: %v", key, err)
+		}
+	}
+	unset("backup.keep_count")
+	unset("targets[0]")
+	unset("repo[0].keep_count")
+	if got := get("backup.keep_count"); got != "30" {
+		t.Fatalf("expected default keep_count, got %q", got)
+	}
+	cfg := configPort.data[path]
+	if len(cfg.Targets) != 1 || cfg.Targets[0].Bucket != "b" {
+		t.Fatalf("expected targets[0] removed, got %+v", cfg.Targets)
+	}
+	if cfg.Repos[0].KeepCount != nil {
+		t.Fatalf("expected repo[0].keep_count unset, got %d", *cfg.Repos[0].KeepCount)
+	}
+}
+
+func TestConfigShow(t *testing.T) {
+	ctx := context.Background()
+	deps, configPort, homeDir := newConfigTestDeps(t)
+	cfg := DefaultConfigFile()
+	cfg.Backup.KeepCount = 7
+	cfg.Targets = []TargetConfig{{Name: "usb", Type: "dir", Path: "/mnt/usb"}}
+	cfg.Repos = []RepoConfig{{Match: "*", BackupOverrides: BackupOverrides{KeepDays: intPtr(0)}}}
+	configPort.data[ConfigPath(deps.FileSystem, homeDir)] = cfg
+
+	out, err := ConfigShow(ctx, ConfigOptions{HomeDir: homeDir}, deps, slog.Default())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"[backup]\nbase_dir = \"\"\nkeep_count = 7\n",
+		"skip_binary_extensions = []\n",
+		"[[targets]]\nname = \"usb\"\ntype = \"dir\"\npath = \"/mnt/usb\"\n\n",
+		"[[repo]]\nmatch = \"*\"\nkeep_days = 0\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestConfigShow_Effective(t *testing.T) {
+	ctx := context.Background()
+	deps, configPort, homeDir := newConfigTestDeps(t)
+	repoRoot := filepath.Join(homeDir, "repo")
+	runGitForTest(t, homeDir, "init", "-q", repoRoot)
+	runGitForTest(t, repoRoot, "config", "backup.keepCount", "4")
+	t.Chdir(repoRoot)
+	deps.Git = newTestGitAdapter()
+	cfg := DefaultConfigFile()
+	cfg.Repos = []RepoConfig{{Match: filepath.Join(homeDir, "*"), BackupOverrides: BackupOverrides{
+		BaseDir: strPtr(filepath.Join(homeDir, "big")),
+	}}}
+	configPort.data[ConfigPath(deps.FileSystem, homeDir)] = cfg
+
+	out, err := ConfigShow(ctx, ConfigOptions{HomeDir: homeDir, Effective: true}, deps, slog.Default())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"# Effective settings for ~/repo\n",
+		`base_dir = "~/big" # [[repo]] match = "` + filepath.Join(homeDir, "*") + `"`,
+		"keep_count = 4 # git config backup.keepCount\n",
+		"keep_days = 90\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+// editConfigPort rejects drafts containing "invalid", like the TOML adapter rejects bad values.
+type editConfigPort struct {
+	*fakeConfigPort
+}
+
+func (p *editConfigPort) Load(ctx context.Context, path string) (ConfigFile, error) {
+	if data, err := os.ReadFile(path); err == nil && strings.Contains(string(data), "invalid") { // #nosec G304
+		return ConfigFile{}, &ConfigError{Path: path, Issues: []ConfigIssue{{Line: 1, Message: "bad value"}}}
+	}
+	return p.fakeConfigPort.Load(ctx, path)
+}
+
+func TestConfigValidate_Recipients(t *testing.T) {
+	ctx := context.Background()
+	deps, configPort, homeDir := newConfigTestDeps(t)
+	path := ConfigPath(deps.FileSystem, homeDir)
+	cfg := DefaultConfigFile()
+	cfg.Encryption.Recipients = []string{"age1abc", "bad-key"}
+	configPort.data[path] = cfg
+
+	_, _, err := ConfigValidate(ctx, ConfigOptions{HomeDir: homeDir}, deps)
+	want := `encryption.recipients[1]: invalid recipient "bad-key"`
+	if !errors.Is(err, ErrUsage) || !strings.Contains(err.Error(), want) {
+		t.Fatalf("expected the bad recipient to be reported, got %v", err)
+	}
+	cfg.Encryption.Recipients = []string{"age1abc"}
+	configPort.data[path] = cfg
+	if _, _, err := ConfigValidate(ctx, ConfigOptions{HomeDir: homeDir}, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestConfigEdit(t *testing.T) {
+	ctx := context.Background()
+	deps, configPort, homeDir := newConfigTestDeps(t)
+	deps.Config = &editConfigPort{configPort}
+	path := ConfigPath(deps.FileSystem, homeDir)
+	draft := filepath.Join(filepath.Dir(path), configEditFile)
+	opts := ConfigOptions{HomeDir: homeDir}
+
+	err := ConfigEdit(ctx, opts, deps, slog.Default(), func(p string) error {
+		return os.WriteFile(p, []byte("invalid"), 0o600)
+	})
+	if !errors.Is(err, ErrUsage) || !strings.Contains(err.Error(), "line 1: bad value") {
+		t.Fatalf("expected the issues of the draft, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("config.toml must not be written for an invalid draft: %v", err)
+	}
+
+	var edited string
+	err = ConfigEdit(ctx, opts, deps, slog.Default(), func(p string) error {
+		data, _ := os.ReadFile(p) // #nosec G304 -- test path.
+		edited = string(data)
+		return os.WriteFile(p, []byte("keep_count = 5"), 0o600)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if edited != "invalid" {
+		t.Fatalf("expected the kept draft to be edited again, got %q", edited)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "keep_count = 5" { // #nosec G304 -- test path.
+		t.Fatalf("expected the draft to replace config.toml, got %q", data)
+	}
+	if _, err := os.Stat(draft); !os.IsNotExist(err) {
+		t.Fatalf("draft must be removed after saving: %v", err)
+	}
+}
+
+func TestValidateConfigFile(t *testing.T) {
+	if issues := ValidateConfigFile(DefaultConfigFile()); len(issues) != 0 {
+		t.Fatalf("default config must be valid, got %v", issues)
+	}
+
+	cfg := DefaultConfigFile()
+	cfg.Backup.KeepCount = -5
+	cfg.Watch.KeepHours = -1
+	cfg.RepoKey.RemoteHashLen = 65
+	cfg.RepoKey.Style = "short"
+	cfg.Logging.Level = "verbose"
+	cfg.Backup.Format = "zip"
+	cfg.Targets = []TargetConfig{{Type: "dir", Path: "/a"}, {Type: "dir", Path: "/b"}, {Type: "ftp"}}
+	cfg.Repos = []RepoConfig{{Match: "~/work/*", BackupOverrides: BackupOverrides{BaseDir: strPtr("~/big")}}, {}}
+	keys := map[string]bool{}
+	for _, issue := range ValidateConfigFile(cfg) {
+		keys[issue.Key] = true
+		if strings.HasSuffix(issue.Message, ErrUsage.Error()) {
+			t.Fatalf("issue message must not end with the error class: %q", issue.Message)
+		}
+	}
+	for _, key := range []string{
+		"backup.keep_count", "watch.keep_hours", "repo_key.remote_hash_len", "repo_key.style",
+		"logging.level", "backup.format", "targets[1].name", "targets[2]", "repo[1]",
+	} {
+		if !keys[key] {
+			t.Fatalf("expected an issue for %s, got %v", key, keys)
+		}
+	}
+	if len(keys) != 9 {
+		t.Fatalf("unexpected issues: %v", keys)
+	}
+}
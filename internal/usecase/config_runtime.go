@@ -22,15 +22,177 @@ func RuntimeConfigFromFile(cfg ConfigFile, homeDir string) (*Config, error) {
 		style = repoKeyStyleAuto
 	}
 
+	format, err := normalizeSnapshotFormat(cfg.Backup.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	trackedChanges, err := normalizeTrackedChanges(cfg.Backup.TrackedChanges)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Backup.MaxFileMB < 0 || cfg.Backup.MaxUntrackedTotalMB < 0 {
+		return nil, fmt.Errorf(
+			"backup.max_file_mb and backup.max_untracked_total_mb must not be negative: %w", ErrUsage)
+	}
+	skipExtensions, err := normalizeBinaryExtensions(cfg.Backup.SkipBinaryExtensions)
+	if err != nil {
+		return nil, err
+	}
+
+	encryption, err := encryptionSettingsFromFile(cfg.Encryption, cleanHome)
+	if err != nil {
+		return nil, err
+	}
+
+	targets, err := targetSettingsFromFile(cfg.Targets, cleanHome)
+	if err != nil {
+		return nil, err
+	}
+
+	repoRules, err := repoRulesFromFile(cfg.Repos, cleanHome)
+	if err != nil {
+		return nil, err
+	}
+
+	var sweepRoots []string
+	for _, root := range cfg.Sweep.Roots {
+		if root = strings.TrimSpace(root); root != "" {
+			sweepRoots = append(sweepRoots, expandHomeDir(root, cleanHome))
+		}
+	}
+
 	return &Config{
 		BackupDir:         baseDir,
 		KeepCount:         cfg.Backup.KeepCount,
 		KeepDays:          cfg.Backup.KeepDays,
+		KeepHourly:        cfg.Backup.KeepHourly,
+		KeepDaily:         cfg.Backup.KeepDaily,
+		KeepWeekly:        cfg.Backup.KeepWeekly,
+		KeepMonthly:       cfg.Backup.KeepMonthly,
+		KeepYearly:        cfg.Backup.KeepYearly,
 		MaxTotalGBPerRepo: cfg.Backup.MaxTotalGB,
 		SizeMarginMB:      cfg.Backup.SizeMarginMB,
 		RepoKeyStyle:      style,
 		AutoRemoteMerge:   cfg.RepoKey.AutoRemoteMerge,
 		RemoteHashLen:     cfg.RepoKey.RemoteHashLen,
 		NoSize:            cfg.Backup.NoSize,
+		Dedup:             cfg.Backup.Dedup,
+		Format:            format,
+		TrackedChanges:    trackedChanges,
+		MaxFileMB:         cfg.Backup.MaxFileMB,
+		MaxUntrackedMB:    cfg.Backup.MaxUntrackedTotalMB,
+		SkipExtensions:    skipExtensions,
+		Encryption:        encryption,
+		SweepRoots:        sweepRoots,
+		SweepConcurrency:  cfg.Sweep.Concurrency,
+		SweepMaxDepth:     cfg.Sweep.MaxDepth,
+		WatchDebounce:     cfg.Watch.DebounceSeconds,
+		WatchMinInterval:  cfg.Watch.MinIntervalMinutes,
+		WatchKeepCount:    cfg.Watch.KeepCount,
+		WatchKeepHours:    cfg.Watch.KeepHours,
+		Targets:           targets,
+		RepoRules:         repoRules,
+		GlobalIgnoreFile:  expandHomeDir(defaultGlobalIgnoreFile, cleanHome),
+		HomeDir:           cleanHome,
 	}, nil
 }
+
+func encryptionSettingsFromFile(cfg EncryptionConfig, homeDir string) (EncryptionSettings, error) {
+	settings := EncryptionSettings{Enabled: cfg.Enabled}
+	for _, r := range cfg.Recipients {
+		if r = strings.TrimSpace(r); r != "" {
+			settings.Recipients = append(settings.Recipients, r)
+		}
+	}
+	if p := strings.TrimSpace(cfg.PassphraseFile); p != "" {
+		settings.PassphraseFile = expandHomeDir(p, homeDir)
+	}
+	if p := strings.TrimSpace(cfg.IdentityFile); p != "" {
+		settings.IdentityFile = expandHomeDir(p, homeDir)
+	}
+	if settings.Enabled && len(settings.Recipients) == 0 && settings.PassphraseFile == "" {
+		return EncryptionSettings{}, fmt.Errorf(
+			"encryption.enabled requires encryption.recipients or encryption.passphrase_file: %w", ErrUsage)
+	}
+	return settings, nil
+}
+
+func targetSettingsFromFile(targets []TargetConfig, homeDir string) ([]TargetSettings, error) {
+	var settings []TargetSettings
+	seen := map[string]bool{}
+	for i, t := range targets {
+		target, err := targetSettingFromFile(i, t, homeDir)
+		if err != nil {
+			return nil, err
+		}
+		if seen[target.Name] {
+			return nil, fmt.Errorf("targets[%d]: duplicate target name %q: %w", i, target.Name, ErrUsage)
+		}
+		seen[target.Name] = true
+		settings = append(settings, target)
+	}
+	return settings, nil
+}
+
+// targetSettingFromFile validates the [[targets]] entry at index i.
+func targetSettingFromFile(i int, t TargetConfig, homeDir string) (TargetSettings, error) {
+	target := TargetSettings{
+		Name:      strings.TrimSpace(t.Name),
+		Type:      strings.ToLower(strings.TrimSpace(t.Type)),
+		Endpoint:  strings.TrimRight(strings.TrimSpace(t.Endpoint), "/"),
+		Bucket:    strings.TrimSpace(t.Bucket),
+		Prefix:    strings.Trim(strings.TrimSpace(t.Prefix), "/"),
+		Region:    strings.TrimSpace(t.Region),
+		URL:       strings.TrimSpace(t.URL),
+		Verify:    strings.ToLower(strings.TrimSpace(t.Verify)),
+		KeepCount: t.KeepCount,
+		KeepDays:  t.KeepDays,
+	}
+	if p := strings.TrimSpace(t.CredentialsFile); p != "" {
+		target.CredentialsFile = expandHomeDir(p, homeDir)
+	}
+	if p := strings.TrimSpace(t.IdentityFile); p != "" {
+		target.IdentityFile = expandHomeDir(p, homeDir)
+	}
+	if p := strings.TrimSpace(t.KnownHostsFile); p != "" {
+		target.KnownHostsFile = expandHomeDir(p, homeDir)
+	}
+	if p := strings.TrimSpace(t.Path); p != "" {
+		target.Path = expandHomeDir(p, homeDir)
+	}
+	if target.Name == "" {
+		target.Name = target.Type
+	}
+	switch target.Type {
+	case TargetTypeS3:
+		if target.Bucket == "" {
+			return target, fmt.Errorf("targets[%d]: type %q requires bucket: %w", i, target.Type, ErrUsage)
+		}
+	case TargetTypeSFTP:
+		if target.URL == "" {
+			return target, fmt.Errorf("targets[%d]: type %q requires url: %w", i, target.Type, ErrUsage)
+		}
+	case TargetTypeDir:
+		if target.Path == "" {
+			return target, fmt.Errorf("targets[%d]: type %q requires path: %w", i, target.Type, ErrUsage)
+		}
+	case "":
+		return target, fmt.Errorf("targets[%d]: type is required: %w", i, ErrUsage)
+	default:
+		return target, fmt.Errorf("targets[%d]: unsupported type %q: %w", i, target.Type, ErrUsage)
+	}
+	switch target.Verify {
+	case "":
+		target.Verify = TargetVerifySize
+	case TargetVerifySize, TargetVerifyHash:
+	default:
+		return target, fmt.Errorf("targets[%d]: verify must be %q or %q: %w",
+			i, TargetVerifySize, TargetVerifyHash, ErrUsage)
+	}
+	if target.KeepCount < 0 || target.KeepDays < 0 {
+		return target, fmt.Errorf("targets[%d]: keep_count and keep_days must not be negative: %w", i, ErrUsage)
+	}
+	return target, nil
+}
@@ -1,6 +1,10 @@
 package usecase
 
-import "testing"
+import (
+	"errors"
+	"strings"
+	"testing"
+)
 
 func TestRuntimeConfigFromFile_Defaults(t *testing.T) {
 	cfg := DefaultConfigFile()
@@ -35,6 +39,37 @@ func TestRuntimeConfigFromFile_Defaults(t *testing.T) {
 	if got.NoSize != cfg.Backup.NoSize {
 		t.Fatalf("unexpected no size flag: %t", got.NoSize)
 	}
+	if got.Dedup {
+		t.Fatal("expected dedup to be disabled by default")
+	}
+	if got.MaxFileMB != defaultMaxFileMB || got.MaxUntrackedMB != 0 || len(got.SkipExtensions) != 0 {
+		t.Fatalf("unexpected guards: %d/%d/%v", got.MaxFileMB, got.MaxUntrackedMB, got.SkipExtensions)
+	}
+}
+
+func TestRuntimeConfigFromFile_Guards(t *testing.T) {
+	cfg := DefaultConfigFile()
+	cfg.Backup.SkipBinaryExtensions = []string{"QCOW2", " .iso ", "", ".qcow2", ".sql.gz"}
+	got, err := RuntimeConfigFromFile(cfg, "/home/test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(got.SkipExtensions, " ") != ".qcow2 .iso .sql.gz" {
+		t.Fatalf("unexpected extensions: %v", got.SkipExtensions)
+	}
+
+	for _, mutate := range []func(*ConfigFile){
+		func(c *ConfigFile) { c.Backup.MaxFileMB = -1 },
+		func(c *ConfigFile) { c.Backup.MaxUntrackedTotalMB = -1 },
+		func(c *ConfigFile) { c.Backup.SkipBinaryExtensions = []string{"."} },
+		func(c *ConfigFile) { c.Backup.SkipBinaryExtensions = []string{"dumps/*.sql"} },
+	} {
+		invalid := DefaultConfigFile()
+		mutate(&invalid)
+		if _, err := RuntimeConfigFromFile(invalid, "/home/test"); !errors.Is(err, ErrUsage) {
+			t.Fatalf("expected usage error for %+v, got %v", invalid.Backup, err)
+		}
+	}
 }
 
 func TestRuntimeConfigFromFile_EmptyHome(t *testing.T) {
@@ -43,3 +78,57 @@ func TestRuntimeConfigFromFile_EmptyHome(t *testing.T) {
 		t.Fatal("expected error")
 	}
 }
+
+func TestRuntimeConfigFromFile_Targets(t *testing.T) {
+	cfg := DefaultConfigFile()
+	cfg.Targets = []TargetConfig{{Type: " s3 ", Bucket: "b", Prefix: "/laptop/", CredentialsFile: "~/.s3"}}
+	got, err := RuntimeConfigFromFile(cfg, "/home/test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := TargetSettings{
+		Name: "s3", Type: "s3", Bucket: "b", Prefix: "laptop", CredentialsFile: "/home/test/.s3",
+		Verify: TargetVerifySize,
+	}
+	if len(got.Targets) != 1 || got.Targets[0] != want {
+		t.Fatalf("unexpected targets: %+v", got.Targets)
+	}
+
+	cfg.Targets = []TargetConfig{{Type: "sftp", URL: " me@nas:/srv ", IdentityFile: "~/.ssh/id_backup"}}
+	got, err = RuntimeConfigFromFile(cfg, "/home/test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want = TargetSettings{
+		Name: "sftp", Type: "sftp", URL: "me@nas:/srv", IdentityFile: "/home/test/.ssh/id_backup",
+		Verify: TargetVerifySize,
+	}
+	if got.Targets[0] != want {
+		t.Fatalf("unexpected sftp target: %+v", got.Targets[0])
+	}
+
+	cfg.Targets = []TargetConfig{{Name: "usb", Type: "dir", Path: "~/mnt/usb", Verify: "HASH"}}
+	got, err = RuntimeConfigFromFile(cfg, "/home/test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want = TargetSettings{Name: "usb", Type: "dir", Path: "/home/test/mnt/usb", Verify: TargetVerifyHash}
+	if got.Targets[0] != want {
+		t.Fatalf("unexpected dir target: %+v", got.Targets[0])
+	}
+
+	for _, targets := range [][]TargetConfig{
+		{{Type: "s3"}},
+		{{Type: "ftp", Bucket: "b"}},
+		{{Type: "sftp"}},
+		{{Type: "dir"}},
+		{{Type: "dir", Path: "/mnt", Verify: "crc"}},
+		{{Type: "s3", Bucket: "b", KeepCount: -1}},
+		{{Type: "s3", Bucket: "a"}, {Type: "s3", Bucket: "b"}},
+	} {
+		cfg.Targets = targets
+		if _, err := RuntimeConfigFromFile(cfg, "/home/test"); !errors.Is(err, ErrUsage) {
+			t.Fatalf("expected usage error for %+v, got %v", targets, err)
+		}
+	}
+}
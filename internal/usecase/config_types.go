@@ -6,16 +6,97 @@ type ConfigFile struct {
 	Notifications NotificationsConfig `toml:"notifications"`
 	Logging       LoggingConfig       `toml:"logging"`
 	RepoKey       RepoKeyConfig       `toml:"repo_key"`
+	Encryption    EncryptionConfig    `toml:"encryption"`
+	Sweep         SweepConfig         `toml:"sweep"`
+	Watch         WatchConfig         `toml:"watch"`
+	Targets       []TargetConfig      `toml:"targets"`
+	Repos         []RepoConfig        `toml:"repo"`
 }
 
 // BackupConfig holds backup-related settings.
 type BackupConfig struct {
-	BaseDir      string `toml:"base_dir"`
-	KeepCount    int    `toml:"keep_count"`
-	KeepDays     int    `toml:"keep_days"`
-	MaxTotalGB   int    `toml:"max_total_gb"`
-	SizeMarginMB int    `toml:"size_margin_mb"`
-	NoSize       bool   `toml:"no_size"`
+	BaseDir        string `toml:"base_dir"`
+	KeepCount      int    `toml:"keep_count"`
+	KeepDays       int    `toml:"keep_days"`
+	KeepHourly     int    `toml:"keep_hourly"`
+	KeepDaily      int    `toml:"keep_daily"`
+	KeepWeekly     int    `toml:"keep_weekly"`
+	KeepMonthly    int    `toml:"keep_monthly"`
+	KeepYearly     int    `toml:"keep_yearly"`
+	MaxTotalGB     int    `toml:"max_total_gb"`
+	SizeMarginMB   int    `toml:"size_margin_mb"`
+	NoSize         bool   `toml:"no_size"`
+	Dedup          bool   `toml:"dedup"`
+	Format         string `toml:"format"`
+	TrackedChanges string `toml:"tracked_changes"`
+	// Guards for ignored/untracked files; 0 or an empty list disables a guard.
+	MaxFileMB            int      `toml:"max_file_mb"`
+	MaxUntrackedTotalMB  int      `toml:"max_untracked_total_mb"`
+	SkipBinaryExtensions []string `toml:"skip_binary_extensions"`
+}
+
+// BackupOverrides holds the backup settings a repository may override; nil fields are not set.
+type BackupOverrides struct {
+	BaseDir    *string `toml:"base_dir"`
+	KeepCount  *int    `toml:"keep_count"`
+	KeepDays   *int    `toml:"keep_days"`
+	MaxTotalGB *int    `toml:"max_total_gb"`
+	Format     *string `toml:"format"`
+}
+
+// RepoConfig describes one [[repo]] entry: overrides for the repositories whose origin remote
+// (host/owner/repo) or root path matches Match.
+type RepoConfig struct {
+	Match string `toml:"match"`
+	BackupOverrides
+}
+
+// RepoConfigFile describes the optional .devback.toml in a repository root.
+type RepoConfigFile struct {
+	Backup BackupOverrides `toml:"backup"`
+}
+
+// EncryptionConfig holds client-side encryption settings. Only public recipients and
+// paths to secret files are stored here, never key material itself.
+type EncryptionConfig struct {
+	Enabled        bool     `toml:"enabled"`
+	Recipients     []string `toml:"recipients"`
+	PassphraseFile string   `toml:"passphrase_file"`
+	IdentityFile   string   `toml:"identity_file"`
+}
+
+// SweepConfig holds settings for backup-all, which backs up every enabled repository under Roots.
+type SweepConfig struct {
+	Roots       []string `toml:"roots"`
+	Concurrency int      `toml:"concurrency"`
+	MaxDepth    int      `toml:"max_depth"`
+}
+
+// WatchConfig holds settings for devback watch and the rotation of the snapshots it takes.
+type WatchConfig struct {
+	DebounceSeconds    int `toml:"debounce_seconds"`
+	MinIntervalMinutes int `toml:"min_interval_minutes"`
+	KeepCount          int `toml:"keep_count"`
+	KeepHours          int `toml:"keep_hours"`
+}
+
+// TargetConfig describes one [[targets]] entry, a remote copy of every completed snapshot
+// with its own retention. Only the path to a credentials file is stored, never the keys.
+type TargetConfig struct {
+	Name            string `toml:"name"`
+	Type            string `toml:"type"`
+	Endpoint        string `toml:"endpoint"`
+	Bucket          string `toml:"bucket"`
+	Prefix          string `toml:"prefix"`
+	Region          string `toml:"region"`
+	CredentialsFile string `toml:"credentials_file"`
+	URL             string `toml:"url"`
+	IdentityFile    string `toml:"identity_file"`
+	KnownHostsFile  string `toml:"known_hosts_file"`
+	Path            string `toml:"path"`
+	Verify          string `toml:"verify"`
+	KeepCount       int    `toml:"keep_count"`
+	KeepDays        int    `toml:"keep_days"`
 }
 
 // NotificationsConfig holds notification settings.
@@ -41,6 +122,12 @@ const defaultTemplatesDir = "~/.local/share/devback/templates/hooks"
 
 const defaultRepoTemplatesDir = "~/.local/share/devback/repo-templates"
 
+// defaultGlobalIgnoreFile holds .devbackignore patterns applied to every repository.
+const defaultGlobalIgnoreFile = "~/.config/devback/ignore"
+
+// defaultMaxFileMB keeps stray VM images and database dumps out of snapshots.
+const defaultMaxFileMB = 1024
+
 // SuggestedBackupDir is the recommended default for backup.base_dir.
 const SuggestedBackupDir = "~/.local/share/devback/backups"
 
@@ -58,12 +145,16 @@ func DefaultRepoTemplatesDir() string {
 func DefaultConfigFile() ConfigFile {
 	return ConfigFile{
 		Backup: BackupConfig{
-			BaseDir:      "",
-			KeepCount:    30,
-			KeepDays:     90,
-			MaxTotalGB:   10,
-			SizeMarginMB: 0,
-			NoSize:       true,
+			BaseDir:        "",
+			KeepCount:      30,
+			KeepDays:       90,
+			MaxTotalGB:     10,
+			SizeMarginMB:   0,
+			NoSize:         true,
+			Dedup:          false,
+			Format:         SnapshotFormatDir,
+			TrackedChanges: TrackedChangesOff,
+			MaxFileMB:      defaultMaxFileMB,
 		},
 		Notifications: NotificationsConfig{
 			Enabled: true,
@@ -76,7 +167,22 @@ func DefaultConfigFile() ConfigFile {
 		RepoKey: RepoKeyConfig{
 			Style:           repoKeyStyleAuto,
 			AutoRemoteMerge: false,
-			RemoteHashLen:   8,
+			RemoteHashLen:   defaultRemoteHashLen,
+		},
+		Encryption: EncryptionConfig{
+			Enabled:    false,
+			Recipients: []string{},
+		},
+		Sweep: SweepConfig{
+			Roots:       []string{},
+			Concurrency: defaultSweepConcurrency,
+			MaxDepth:    defaultSweepMaxDepth,
+		},
+		Watch: WatchConfig{
+			DebounceSeconds:    defaultWatchDebounceSeconds,
+			MinIntervalMinutes: defaultWatchMinIntervalMinutes,
+			KeepCount:          defaultWatchKeepCount,
+			KeepHours:          defaultWatchKeepHours,
 		},
 	}
 }
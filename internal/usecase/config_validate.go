@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxRemoteHashLen is the length of a hex-encoded SHA-256 hash.
+const maxRemoteHashLen = 64
+
+// validateHomeDir stands in for the home directory while validating; it only has to make ~ paths
+// absolute.
+const validateHomeDir = "/home"
+
+// ConfigIssue is one problem found in a config file. Key is a dotted path such as
+// backup.keep_count or targets[1]; Line is 0 when the position is unknown.
+type ConfigIssue struct {
+	Key     string
+	Line    int
+	Message string
+}
+
+func (i ConfigIssue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("line %d: %s", i.Line, i.Message)
+	}
+	return i.Message
+}
+
+// ConfigError lists every problem found in a config file. It matches ErrUsage.
+type ConfigError struct {
+	Path   string
+	Issues []ConfigIssue
+}
+
+func (e *ConfigError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid config %s:", e.Path)
+	for _, issue := range e.Issues {
+		b.WriteString("\n  ")
+		b.WriteString(issue.String())
+	}
+	return b.String()
+}
+
+func (e *ConfigError) Unwrap() error {
+	return ErrUsage
+}
+
+// SortConfigIssues orders issues by line; issues without a line come last.
+func SortConfigIssues(issues []ConfigIssue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		li, lj := issues[i].Line, issues[j].Line
+		if li == 0 || lj == 0 {
+			return li != 0 && lj == 0
+		}
+		return li < lj
+	})
+}
+
+// ValidateConfigFile checks the ranges and allowed values of every setting in cfg. Unknown keys
+// and wrong types are reported by the ConfigPort, which also fills in the lines; the config
+// commands check encryption.recipients with validateRecipients.
+func ValidateConfigFile(cfg ConfigFile) []ConfigIssue {
+	var issues []ConfigIssue
+	add := func(key string, err error) {
+		if err != nil {
+			issues = append(issues, ConfigIssue{Key: key, Message: issueMessage(err)})
+		}
+	}
+
+	for _, v := range []struct {
+		key   string
+		value int
+	}{
+		{"backup.keep_count", cfg.Backup.KeepCount},
+		{"backup.keep_days", cfg.Backup.KeepDays},
+		{"backup.keep_hourly", cfg.Backup.KeepHourly},
+		{"backup.keep_daily", cfg.Backup.KeepDaily},
+		{"backup.keep_weekly", cfg.Backup.KeepWeekly},
+		{"backup.keep_monthly", cfg.Backup.KeepMonthly},
+		{"backup.keep_yearly", cfg.Backup.KeepYearly},
+		{"backup.max_total_gb", cfg.Backup.MaxTotalGB},
+		{"backup.size_margin_mb", cfg.Backup.SizeMarginMB},
+		{"backup.max_file_mb", cfg.Backup.MaxFileMB},
+		{"backup.max_untracked_total_mb", cfg.Backup.MaxUntrackedTotalMB},
+		{"sweep.concurrency", cfg.Sweep.Concurrency},
+		{"sweep.max_depth", cfg.Sweep.MaxDepth},
+		{"watch.debounce_seconds", cfg.Watch.DebounceSeconds},
+		{"watch.min_interval_minutes", cfg.Watch.MinIntervalMinutes},
+		{"watch.keep_count", cfg.Watch.KeepCount},
+		{"watch.keep_hours", cfg.Watch.KeepHours},
+	} {
+		if v.value < 0 {
+			add(v.key, fmt.Errorf("%s must not be negative, got %d", v.key, v.value))
+		}
+	}
+	if n := cfg.RepoKey.RemoteHashLen; n < 0 || n > maxRemoteHashLen {
+		add("repo_key.remote_hash_len",
+			fmt.Errorf("repo_key.remote_hash_len must be between 0 and %d, got %d", maxRemoteHashLen, n))
+	}
+
+	_, err := normalizeSnapshotFormat(cfg.Backup.Format)
+	add("backup.format", err)
+	_, err = normalizeTrackedChanges(cfg.Backup.TrackedChanges)
+	add("backup.tracked_changes", err)
+	_, err = normalizeBinaryExtensions(cfg.Backup.SkipBinaryExtensions)
+	add("backup.skip_binary_extensions", err)
+
+	switch style := strings.TrimSpace(cfg.RepoKey.Style); style {
+	case "", repoKeyStyleAuto, repoKeyStyleNameHash, repoKeyStyleRemoteHierarchy, repoKeyStyleCustom:
+	default:
+		add("repo_key.style", fmt.Errorf("repo_key.style %q is not supported (use %s, %s, %s or %s)",
+			style, repoKeyStyleAuto, repoKeyStyleNameHash, repoKeyStyleRemoteHierarchy, repoKeyStyleCustom))
+	}
+	switch level := strings.ToLower(strings.TrimSpace(cfg.Logging.Level)); level {
+	case "", "debug", "info", "warn", "warning", "error":
+	default:
+		add("logging.level", fmt.Errorf("logging.level %q is not supported (use debug, info, warn or error)",
+			cfg.Logging.Level))
+	}
+
+	_, err = encryptionSettingsFromFile(cfg.Encryption, validateHomeDir)
+	add("encryption.enabled", err)
+
+	seen := map[string]bool{}
+	for i, t := range cfg.Targets {
+		key := fmt.Sprintf("targets[%d]", i)
+		target, err := targetSettingFromFile(i, t, validateHomeDir)
+		if err != nil {
+			add(key, err)
+			continue
+		}
+		if seen[target.Name] {
+			add(key+".name", fmt.Errorf("targets[%d]: duplicate target name %q", i, target.Name))
+		}
+		seen[target.Name] = true
+	}
+	for i, r := range cfg.Repos {
+		_, err := repoRuleFromFile(i, r, validateHomeDir)
+		add(fmt.Sprintf("repo[%d]", i), err)
+	}
+	return issues
+}
+
+// validateRecipients parses every encryption.recipients entry with enc, which owns the key
+// format. ValidateConfigFile leaves them out because it has no adapter to parse them with.
+func validateRecipients(cfg ConfigFile, enc EncryptionPort) []ConfigIssue {
+	if enc == nil {
+		return nil
+	}
+	var issues []ConfigIssue
+	for i, r := range cfg.Encryption.Recipients {
+		if strings.TrimSpace(r) == "" {
+			continue
+		}
+		if err := enc.ValidateRecipient(r); err != nil {
+			issues = append(issues, ConfigIssue{
+				Key:     "encryption.recipients",
+				Message: fmt.Sprintf("encryption.recipients[%d]: %s", i, issueMessage(err)),
+			})
+		}
+	}
+	return issues
+}
+
+// issueMessage drops the error class from err, which is implied by the issue list.
+func issueMessage(err error) string {
+	msg := err.Error()
+	for _, class := range []error{ErrUsage, ErrCritical} {
+		if errors.Is(err, class) {
+			msg = strings.TrimSuffix(msg, ": "+class.Error())
+		}
+	}
+	return msg
+}
+
+// ConfigLoadError converts an error of ConfigPort.Load: invalid settings stay usage errors that
+// list the problems, anything else is critical.
+func ConfigLoadError(err error) error {
+	var cfgErr *ConfigError
+	if errors.As(err, &cfgErr) {
+		return cfgErr
+	}
+	return fmt.Errorf("load config: %w", ErrCritical)
+}
@@ -6,3 +6,10 @@ const (
 	repoKeyStyleRemoteHierarchy = "remote-hierarchy"
 	repoKeyStyleNameHash        = "name+hash"
 )
+
+// Snapshot formats accepted in backup.format.
+const (
+	SnapshotFormatDir    = "dir"
+	SnapshotFormatTar    = "tar"
+	SnapshotFormatTarZst = "tar.zst"
+)
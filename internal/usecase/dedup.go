@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+)
+
+// linkDest hardlinks unchanged files from the previous completed snapshot
+// instead of copying them (rsync --link-dest style).
+type linkDest struct {
+	prevRoot string
+	newRoot  string
+	linked   atomic.Int64
+}
+
+// newLinkDest picks the latest completed directory snapshot of repoDir as link source.
+// It returns nil when dedup is disabled; without a previous snapshot nothing is
+// linked, but copies still keep source mtimes for the next run.
+func newLinkDest(
+	ctx context.Context,
+	cfg *Config,
+	deps *Dependencies,
+	repoDir,
+	targetPath string,
+	bc *backupContext,
+) *linkDest {
+	if !cfg.Dedup {
+		return nil
+	}
+	snaps, err := listSnapshots(ctx, deps, repoDir)
+	if err != nil {
+		bc.warnf("dedup(list): %v", err)
+		return nil
+	}
+	for i := len(snaps) - 1; i >= 0; i-- {
+		if snaps[i].TimeDir == targetPath || snaps[i].Archive != "" || snaps[i].Quarantined {
+			continue
+		}
+		bc.vlogf("→ Link unchanged files from %s", snaps[i].TimeDir)
+		return &linkDest{prevRoot: snaps[i].TimeDir, newRoot: targetPath}
+	}
+	return &linkDest{newRoot: targetPath}
+}
+
+// tryLink hardlinks dst to the same path in the previous snapshot when that file
+// has the same size, mtime and permissions as info. It reports whether a link was made.
+func (ld *linkDest) tryLink(ctx context.Context, deps *Dependencies, dst string, info FileInfo) bool {
+	if ld == nil || ld.prevRoot == "" || info == nil || !info.IsRegular() {
+		return false
+	}
+	rel, err := deps.FileSystem.Rel(ld.newRoot, dst)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(deps.FileSystem.PathSeparator())) {
+		return false
+	}
+	prev, err := deps.FileSystem.Lstat(ctx, deps.FileSystem.Join(ld.prevRoot, rel))
+	if err != nil || !prev.IsRegular() {
+		return false
+	}
+	if prev.Size() != info.Size() || !prev.ModTime().Equal(info.ModTime()) || prev.Mode()&0o777 != info.Mode()&0o777 {
+		return false
+	}
+	if err := deps.FileSystem.Link(ctx, deps.FileSystem.Join(ld.prevRoot, rel), dst); err != nil {
+		return false
+	}
+	ld.linked.Add(1)
+	return true
+}
+
+// linkedFiles returns how many files were hardlinked so far.
+func (ld *linkDest) linkedFiles() int {
+	if ld == nil {
+		return 0
+	}
+	return int(ld.linked.Load())
+}
+
+// previousRoot returns the snapshot files were linked against, or "" without one.
+func (ld *linkDest) previousRoot() string {
+	if ld == nil {
+		return ""
+	}
+	return ld.prevRoot
+}
+
+// copyOrLinkFile links dst against the previous snapshot when possible and copies it otherwise;
+// with encryption configured on bc the file is sealed or opened instead.
+// Copies keep the source mtime so the next snapshot can recognise unchanged files.
+func copyOrLinkFile(ctx context.Context, deps *Dependencies, src, dst string, info FileInfo, bc *backupContext) error {
+	if handled, err := transformSnapshotFile(ctx, deps, src, dst, info, bc); handled {
+		return err
+	}
+	if bc != nil && bc.linkDest.tryLink(ctx, deps, dst, info) {
+		return nil
+	}
+	if err := copyFile(ctx, deps, src, dst, info.Mode()); err != nil {
+		return err
+	}
+	if bc != nil && bc.linkDest != nil {
+		_ = deps.FileSystem.Chtimes(ctx, dst, info.ModTime(), info.ModTime())
+	}
+	return nil
+}
+
+// diskUsage accounts file bytes across snapshots, counting hardlinked data once.
+type diskUsage struct {
+	fs    FileSystemPort
+	refs  map[FileID]int
+	sizes map[FileID]int64
+	ids   map[int][]FileID
+	plain map[int]int64
+	total int64
+}
+
+func newDiskUsage(fs FileSystemPort) *diskUsage {
+	return &diskUsage{
+		fs:    fs,
+		refs:  make(map[FileID]int),
+		sizes: make(map[FileID]int64),
+		ids:   make(map[int][]FileID),
+		plain: make(map[int]int64),
+	}
+}
+
+// addBytes records bytes of snapshot idx that are known not to be shared.
+func (u *diskUsage) addBytes(idx int, bytes int64) {
+	u.plain[idx] += bytes
+	u.total += bytes
+}
+
+// addTree walks root and records its regular files under snapshot idx.
+func (u *diskUsage) addTree(ctx context.Context, idx int, root string, bc *backupContext) error {
+	seen := make(map[FileID]bool)
+	return u.fs.Walk(ctx, root, func(path string, info FileInfo, err error) error {
+		if err != nil {
+			bc.warnf("walk '%s': %v", path, err)
+			return nil
+		}
+		if info == nil || !info.IsRegular() {
+			return nil
+		}
+		id, ok := u.fs.FileID(info)
+		if !ok {
+			u.addBytes(idx, info.Size())
+			return nil
+		}
+		if seen[id] {
+			return nil
+		}
+		seen[id] = true
+		u.ids[idx] = append(u.ids[idx], id)
+		if u.refs[id] == 0 {
+			u.sizes[id] = info.Size()
+			u.total += info.Size()
+		}
+		u.refs[id]++
+		return nil
+	})
+}
+
+// release drops snapshot idx and subtracts the bytes no other snapshot references.
+func (u *diskUsage) release(idx int) {
+	u.total -= u.plain[idx]
+	delete(u.plain, idx)
+	for _, id := range u.ids[idx] {
+		u.refs[id]--
+		if u.refs[id] == 0 {
+			u.total -= u.sizes[id]
+			delete(u.refs, id)
+			delete(u.sizes, id)
+		}
+	}
+	delete(u.ids, idx)
+}
+
+func (u *diskUsage) totalKB() int64 {
+	return (u.total + 1023) / 1024
+}
+
+// measureSnapshots builds disk usage for snapshots marked alive (all when alive is nil).
+// Manifest sizes are used unless some snapshot was deduplicated, in which case
+// trees are walked so shared files are counted once.
+func measureSnapshots(
+	ctx context.Context,
+	deps *Dependencies,
+	snaps []snapshot,
+	alive []bool,
+	bc *backupContext,
+) (*diskUsage, error) {
+	usage := newDiskUsage(deps.FileSystem)
+	manifests := make([]*SnapshotManifest, len(snaps))
+	shared := false
+	for i, s := range snaps {
+		if alive != nil && !alive[i] {
+			continue
+		}
+		if m, err := readSnapshotManifest(ctx, deps, s.TimeDir); err == nil {
+			manifests[i] = m
+			shared = shared || m.Result.LinkedFiles > 0
+		}
+	}
+	for i, s := range snaps {
+		if alive != nil && !alive[i] {
+			continue
+		}
+		if m := manifests[i]; !shared && m != nil && m.FileCount > 0 {
+			usage.addBytes(i, m.Bytes)
+			continue
+		}
+		if err := usage.addTree(ctx, i, s.TimeDir, bc); err != nil {
+			return usage, err
+		}
+	}
+	return usage, nil
+}
@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func sameFile(t *testing.T, a, b string) bool {
+	t.Helper()
+	ai, err := os.Stat(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return os.SameFile(ai, bi)
+}
+
+func TestHandleBackupFlow_LinksUnchangedFiles(t *testing.T) {
+	ctx := context.Background()
+	repoRoot := t.TempDir()
+	runGitForTest(t, repoRoot, "init", "-q")
+	writeTestFile(t, filepath.Join(repoRoot, "tracked.txt"), "tracked")
+	writeTestFile(t, filepath.Join(repoRoot, ".gitignore"), "*.local\n")
+	runGitForTest(t, repoRoot, "add", "tracked.txt", ".gitignore")
+	runGitForTest(t, repoRoot, "commit", "-q", "-m", "init")
+	writeTestFile(t, filepath.Join(repoRoot, "same.local"), "same")
+	writeTestFile(t, filepath.Join(repoRoot, "changed.local"), "v1")
+
+	repoDir := filepath.Join(t.TempDir(), "repo--deadbeef")
+	cfg := &Config{BackupDir: filepath.Dir(repoDir), NoSize: true, Dedup: true}
+	deps := &Dependencies{
+		FileSystem: newTestFileSystem(),
+		Git:        newTestGitAdapter(),
+		Lock:       &mockLock{},
+		Process:    &mockProcess{},
+	}
+
+	first, err := handleBackupFlow(ctx, cfg, deps, repoRoot, repoDir, newTestBackupContext(false))
+	if err != nil {
+		t.Fatalf("first backup: %v", err)
+	}
+	if first.LinkedFiles != 0 {
+		t.Fatalf("expected no links in first snapshot, got %d", first.LinkedFiles)
+	}
+
+	writeTestFile(t, filepath.Join(repoRoot, "changed.local"), "v2 longer")
+	second, err := handleBackupFlow(ctx, cfg, deps, repoRoot, repoDir, newTestBackupContext(false))
+	if err != nil {
+		t.Fatalf("second backup: %v", err)
+	}
+	if second.LinkedFiles == 0 || second.LinkedFiles > second.CopiedFiles {
+		t.Fatalf("unexpected linked files: %d of %d", second.LinkedFiles, second.CopiedFiles)
+	}
+
+	snaps, err := listSnapshots(ctx, deps, repoDir)
+	if err != nil || len(snaps) != 2 {
+		t.Fatalf("expected two snapshots, got %d (%v)", len(snaps), err)
+	}
+	prev, cur := snaps[0].TimeDir, snaps[1].TimeDir
+	if !sameFile(t, filepath.Join(prev, "same.local"), filepath.Join(cur, "same.local")) {
+		t.Fatal("expected unchanged file to be hardlinked")
+	}
+	if sameFile(t, filepath.Join(prev, "changed.local"), filepath.Join(cur, "changed.local")) {
+		t.Fatal("expected changed file to be copied")
+	}
+	if sameFile(t, filepath.Join(prev, manifestFileName), filepath.Join(cur, manifestFileName)) {
+		t.Fatal("expected manifest to be written per snapshot")
+	}
+
+	m, err := readSnapshotManifest(ctx, deps, cur)
+	if err != nil || m.Result.LinkedFiles != second.LinkedFiles {
+		t.Fatalf("expected linked files in manifest: %+v (%v)", m, err)
+	}
+}
+
+func TestMeasureSnapshots_CountsSharedFilesOnce(t *testing.T) {
+	ctx := context.Background()
+	repoDir := t.TempDir()
+	snaps := []snapshot{
+		{TimeDir: filepath.Join(repoDir, "2026-01-01", "100000-000000001")},
+		{TimeDir: filepath.Join(repoDir, "2026-01-02", "100000-000000002")},
+	}
+	data := make([]byte, 4096)
+	for _, s := range snaps {
+		if err := os.MkdirAll(s.TimeDir, 0o750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	shared := filepath.Join(snaps[0].TimeDir, "shared.bin")
+	if err := os.WriteFile(shared, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Link(shared, filepath.Join(snaps[1].TimeDir, "shared.bin")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(snaps[1].TimeDir, "new.bin"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	deps := &Dependencies{FileSystem: newTestFileSystem()}
+	bc := newTestBackupContext(false)
+
+	usage, err := measureSnapshots(ctx, deps, snaps, nil, bc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.totalKB() != 8 {
+		t.Fatalf("expected 8 KiB of unique data, got %d", usage.totalKB())
+	}
+	usage.release(0)
+	if usage.totalKB() != 8 {
+		t.Fatalf("expected shared file to survive release, got %d", usage.totalKB())
+	}
+	usage.release(1)
+	if usage.totalKB() != 0 {
+		t.Fatalf("expected no usage after releasing all, got %d", usage.totalKB())
+	}
+
+	kb, err := dirSizeKB(ctx, deps, repoDir, bc)
+	if err != nil || kb != 8 {
+		t.Fatalf("expected dirSizeKB to count links once, got %d (%v)", kb, err)
+	}
+}
@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// newSnapshotSealer prepares encryption for a new snapshot, or returns nil when it is disabled.
+func newSnapshotSealer(ctx context.Context, cfg *Config, deps *Dependencies) (Sealer, error) {
+	if !cfg.Encryption.Enabled {
+		return nil, nil
+	}
+	if deps.Encryption == nil {
+		return nil, fmt.Errorf("encryption adapter not available: %w", ErrCritical)
+	}
+	sealer, err := deps.Encryption.NewSealer(ctx, cfg.Encryption)
+	if err != nil {
+		return nil, fmt.Errorf("encryption: %v: %w", err, ErrUsage)
+	}
+	return sealer, nil
+}
+
+// transformSnapshotFile seals (backup) or opens (decrypt) src into dst when bc carries
+// encryption state. It reports whether the file was handled.
+func transformSnapshotFile(
+	ctx context.Context,
+	deps *Dependencies,
+	src,
+	dst string,
+	info FileInfo,
+	bc *backupContext,
+) (bool, error) {
+	if bc == nil {
+		return false, nil
+	}
+	var err error
+	switch {
+	case bc.sealer != nil:
+		err = bc.sealer.SealFile(ctx, src, dst)
+	case bc.opener != nil:
+		err = bc.opener.OpenFile(ctx, src, dst)
+	default:
+		return false, nil
+	}
+	if err == nil && info != nil && info.Mode() != 0 {
+		_ = deps.FileSystem.Chmod(ctx, dst, info.Mode()&0o777)
+	}
+	return true, err
+}
+
+// snapshotEncryption returns the encryption scheme recorded in the snapshot manifest,
+// or an empty string for plain snapshots.
+func snapshotEncryption(ctx context.Context, deps *Dependencies, snapDir string) string {
+	m, err := readSnapshotManifest(ctx, deps, snapDir)
+	if err != nil {
+		return ""
+	}
+	return m.Encryption
+}
+
+// prepareSnapshotOpener makes sure restore and decrypt are used for the right kind of
+// snapshot and, for decrypt, sets up bc.opener and checks the key against the snapshot.
+func prepareSnapshotOpener(
+	ctx context.Context,
+	cfg *Config,
+	deps *Dependencies,
+	snap snapshot,
+	id string,
+	decrypt bool,
+	bc *backupContext,
+) error {
+	var scheme, passphraseKDF string
+	if m, err := readSnapshotManifest(ctx, deps, snap.TimeDir); err == nil {
+		scheme, passphraseKDF = m.Encryption, m.PassphraseKDF
+	}
+	if !decrypt {
+		if scheme != "" {
+			return fmt.Errorf("snapshot %s is encrypted (%s); use 'devback decrypt': %w", id, scheme, ErrUsage)
+		}
+		return nil
+	}
+	if scheme == "" {
+		return fmt.Errorf("snapshot %s is not encrypted; use 'devback restore': %w", id, ErrUsage)
+	}
+	if deps.Encryption == nil {
+		return fmt.Errorf("encryption adapter not available: %w", ErrCritical)
+	}
+	settings := cfg.Encryption
+	settings.PassphraseKDF = passphraseKDF
+	opener, err := deps.Encryption.NewOpener(ctx, settings)
+	if err != nil {
+		return fmt.Errorf("decrypt: %v: %w", err, ErrUsage)
+	}
+	if err := checkSnapshotKey(ctx, deps, snap, opener); err != nil {
+		return fmt.Errorf("cannot decrypt snapshot %s: %v: %w", id, err, ErrUsage)
+	}
+	bc.opener = opener
+	return nil
+}
+
+// checkSnapshotKey decrypts .git/HEAD of a directory snapshot so a wrong key fails
+// before anything is written. Archive snapshots fail on the first read anyway.
+func checkSnapshotKey(ctx context.Context, deps *Dependencies, snap snapshot, opener Opener) error {
+	if snap.Archive != "" {
+		return nil
+	}
+	data, err := deps.FileSystem.ReadFile(ctx, deps.FileSystem.Join(snap.TimeDir, ".git", "HEAD"))
+	if err != nil {
+		return err
+	}
+	r, err := opener.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	_, err = io.ReadAll(r)
+	return err
+}
+
+// Decrypt materializes an encrypted snapshot into a working repository at opts.Target.
+// Keys come from cfg.Encryption (identity file and/or passphrase).
+func Decrypt(
+	ctx context.Context,
+	cfg *Config,
+	opts RestoreOptions,
+	deps *Dependencies,
+	logger *slog.Logger,
+) (*RestoreResult, error) {
+	if logger == nil {
+		panic("logger is required")
+	}
+	return restoreSnapshot(ctx, cfg, opts, deps, logger, true)
+}
+
+// KeygenOptions describes identity generation.
+type KeygenOptions struct {
+	Output string
+	Force  bool
+}
+
+// Keygen writes a new X25519 identity to opts.Output (mode 0600) and returns its public recipient,
+// which goes into encryption.recipients.
+func Keygen(ctx context.Context, opts KeygenOptions, deps *Dependencies, logger *slog.Logger) (string, error) {
+	if logger == nil {
+		panic("logger is required")
+	}
+	if deps == nil || deps.FileSystem == nil || deps.Encryption == nil {
+		return "", fmt.Errorf("dependencies not available: %w", ErrCritical)
+	}
+	bc := newBackupContext(logger, false)
+	out := strings.TrimSpace(opts.Output)
+	if out == "" {
+		return "", fmt.Errorf("--output is required: %w", ErrUsage)
+	}
+	if _, err := deps.FileSystem.Stat(ctx, out); err == nil && !opts.Force {
+		return "", fmt.Errorf("%s already exists (use --force to overwrite): %w", out, ErrUsage)
+	}
+	recipient, identity, err := deps.Encryption.GenerateIdentity()
+	if err != nil {
+		return "", fmt.Errorf("generate identity: %v: %w", err, ErrCritical)
+	}
+	if err := deps.FileSystem.CreateDir(ctx, deps.FileSystem.Dir(out), 0o700); err != nil {
+		return "", fmt.Errorf("create %s: %w", deps.FileSystem.Dir(out), ErrCritical)
+	}
+	content := fmt.Sprintf("# devback identity, created %s\n# public key: %s\n%s\n",
+		time.Now().Format(time.RFC3339), recipient, identity)
+	if err := deps.FileSystem.WriteFile(ctx, out, []byte(content), 0o600); err != nil {
+		return "", fmt.Errorf("write identity: %w", ErrCritical)
+	}
+	_ = deps.FileSystem.Chmod(ctx, out, 0o600)
+	bc.logf("✓ Identity written → %s", out)
+	return recipient, nil
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandleBackupFlow_EncryptsSnapshotFiles(t *testing.T) {
+	ctx := context.Background()
+	repoRoot := t.TempDir()
+	runGitForTest(t, repoRoot, "init", "-q")
+	writeTestFile(t, filepath.Join(repoRoot, "tracked.txt"), "tracked")
+	writeTestFile(t, filepath.Join(repoRoot, ".gitignore"), "secret.env\n")
+	runGitForTest(t, repoRoot, "add", "tracked.txt", ".gitignore")
+	runGitForTest(t, repoRoot, "commit", "-q", "-m", "init")
+	writeTestFile(t, filepath.Join(repoRoot, "secret.env"), "TOKEN=abc")
+
+	backupDir := t.TempDir()
+	repoKey := "repo--deadbeef"
+	repoDir := filepath.Join(backupDir, repoKey)
+	cfg := &Config{
+		BackupDir:  backupDir,
+		NoSize:     true,
+		Dedup:      true,
+		Encryption: EncryptionSettings{Enabled: true, Recipients: []string{"k1"}},
+	}
+	deps := &Dependencies{
+		FileSystem: newTestFileSystem(),
+		Git:        newTestGitAdapter(),
+		Lock:       &mockLock{},
+		Process:    &mockProcess{},
+		Encryption: testEncryption{},
+	}
+	result, err := handleBackupFlow(ctx, cfg, deps, repoRoot, repoDir, newTestBackupContext(false))
+	if err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	if result.LinkedFiles != 0 {
+		t.Fatalf("dedup must be off for encrypted snapshots, linked %d", result.LinkedFiles)
+	}
+	snaps, err := listSnapshots(ctx, deps, repoDir)
+	if err != nil || len(snaps) != 1 {
+		t.Fatalf("expected one snapshot, got %d (%v)", len(snaps), err)
+	}
+	data, err := os.ReadFile(filepath.Join(snaps[0].TimeDir, "secret.env"))
+	if err != nil || !strings.HasPrefix(string(data), "testenc:k1\n") {
+		t.Fatalf("expected sealed file, got %q (%v)", data, err)
+	}
+	data, err = os.ReadFile(filepath.Join(snaps[0].TimeDir, manifestFileName))
+	manifest := string(data)
+	if err != nil || !strings.Contains(manifest, `"encryption": "test"`) || strings.Contains(manifest, "k1") {
+		t.Fatalf("manifest must record the scheme only: %s (%v)", manifest, err)
+	}
+
+	logger := newTestBackupContext(false).logger
+	target := filepath.Join(t.TempDir(), "restored")
+	opts := RestoreOptions{RepoKey: repoKey, Target: target}
+	if _, err := Restore(ctx, cfg, opts, deps, logger); !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected restore of encrypted snapshot to be refused, got %v", err)
+	}
+	cfg.Encryption.Passphrase = "wrong"
+	if _, err := Decrypt(ctx, cfg, opts, deps, logger); !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected wrong key to be rejected, got %v", err)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Fatalf("nothing must be written with a wrong key: %v", err)
+	}
+	cfg.Encryption.Passphrase = "k1"
+	if _, err := Decrypt(ctx, cfg, opts, deps, logger); err != nil {
+		t.Fatalf("decrypt failed: %v", err)
+	}
+	for name, want := range map[string]string{"tracked.txt": "tracked", "secret.env": "TOKEN=abc"} {
+		data, err := os.ReadFile(filepath.Join(target, name))
+		if err != nil || string(data) != want {
+			t.Fatalf("decrypted %s = %q (%v), want %q", name, data, err, want)
+		}
+	}
+}
+
+func TestDecrypt_RejectsPlainSnapshot(t *testing.T) {
+	cfg, deps, repoKey := newRestoreFixture(t)
+	deps.Encryption = testEncryption{}
+	cfg.Encryption.Passphrase = "k1"
+	_, err := Decrypt(context.Background(), cfg, RestoreOptions{RepoKey: repoKey, Target: t.TempDir()}, deps,
+		newTestBackupContext(false).logger)
+	if !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+}
+
+func TestRuntimeConfigFromFile_EncryptionRequiresKey(t *testing.T) {
+	cfg := DefaultConfigFile()
+	cfg.Encryption.Enabled = true
+	if _, err := RuntimeConfigFromFile(cfg, "/home/u"); !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+	cfg.Encryption.PassphraseFile = "~/.config/devback/pass"
+	rt, err := RuntimeConfigFromFile(cfg, "/home/u")
+	if err != nil || rt.Encryption.PassphraseFile != "/home/u/.config/devback/pass" {
+		t.Fatalf("unexpected settings %+v (%v)", rt.Encryption, err)
+	}
+}
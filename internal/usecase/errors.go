@@ -11,4 +11,9 @@ var (
 	ErrLockBusy = errors.New("lock busy")
 	// ErrInterrupted indicates a canceled or interrupted operation.
 	ErrInterrupted = errors.New("interrupted")
+	// ErrVerifyFailed indicates a snapshot with missing or corrupt data.
+	ErrVerifyFailed = errors.New("verification failed")
+	// ErrTargetUnavailable indicates a remote target that cannot be reached right now
+	// (an unmounted disk, no network); its pending snapshots are retried by the next push.
+	ErrTargetUnavailable = errors.New("target unavailable")
 )
@@ -0,0 +1,289 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Layout of paths captured from outside the repository. Paths below the home directory are stored
+// relative to it, so a snapshot restores them into the home directory of whoever restores it;
+// other absolute paths keep their full path below root.
+const (
+	devbackIncludeFile = ".devbackinclude"
+	externalDir        = "_external"
+	externalHomeDir    = "home"
+	externalRootDir    = "root"
+)
+
+// externalPath is an existing path listed in .devbackinclude.
+type externalPath struct {
+	src string // absolute path on disk
+	rel string // destination below _external
+}
+
+// resolveExternalPaths reads .devbackinclude in repoRoot: one absolute or home-relative ("~/")
+// path per line, glob patterns allowed, "#" comments. Missing paths are skipped; paths inside the
+// repository or overlapping backup.base_dir are rejected with a warning.
+func resolveExternalPaths(
+	ctx context.Context,
+	cfg *Config,
+	deps *Dependencies,
+	repoRoot string,
+	bc *backupContext,
+) ([]externalPath, error) {
+	fs := deps.FileSystem
+	data, err := fs.ReadFile(ctx, fs.Join(repoRoot, devbackIncludeFile))
+	if err != nil {
+		if fs.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", devbackIncludeFile, err)
+	}
+
+	var paths []externalPath
+	seen := map[string]bool{}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pattern := line
+		if line == "~" || strings.HasPrefix(line, "~/") {
+			if cfg.HomeDir == "" {
+				bc.warnf("%s:%d: home directory unknown, skipping %s", devbackIncludeFile, i+1, line)
+				continue
+			}
+			pattern = expandHomeDir(line, cfg.HomeDir)
+		} else if !fs.IsAbs(line) {
+			bc.warnf("%s:%d: %s is not absolute or home-relative, skipping", devbackIncludeFile, i+1, line)
+			continue
+		}
+		matches := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") {
+			if matches, err = fs.Glob(ctx, pattern); err != nil {
+				bc.warnf("%s:%d: %v", devbackIncludeFile, i+1, err)
+				continue
+			}
+		}
+		for _, src := range matches {
+			src = fs.Clean(src)
+			if seen[src] {
+				continue
+			}
+			if _, err := fs.Lstat(ctx, src); err != nil {
+				bc.vlogf("   MISSING: %s (%s:%d)", src, devbackIncludeFile, i+1)
+				continue
+			}
+			if pathWithin(fs, repoRoot, src) {
+				bc.warnf("%s:%d: %s is inside the repository, skipping", devbackIncludeFile, i+1, src)
+				continue
+			}
+			if cfg.BackupDir != "" && (pathWithin(fs, src, cfg.BackupDir) || pathWithin(fs, cfg.BackupDir, src)) {
+				bc.warnf("%s:%d: %s overlaps backup.base_dir, skipping", devbackIncludeFile, i+1, src)
+				continue
+			}
+			seen[src] = true
+			paths = append(paths, externalPath{src: src, rel: externalRel(fs, cfg.HomeDir, src)})
+		}
+	}
+	return paths, nil
+}
+
+// externalRel maps src to its location below _external.
+func externalRel(fs FileSystemPort, homeDir, src string) string {
+	if homeDir != "" && pathWithin(fs, homeDir, src) {
+		rel, _ := fs.Rel(homeDir, src)
+		return fs.Join(externalHomeDir, rel)
+	}
+	trimmed := strings.TrimLeft(strings.TrimPrefix(src, fs.VolumeName(src)), `/\`)
+	return fs.Join(externalRootDir, trimmed)
+}
+
+// pathWithin reports whether p is dir or lies below it.
+func pathWithin(fs FileSystemPort, dir, p string) bool {
+	rel, err := fs.Rel(dir, p)
+	if err != nil {
+		return false
+	}
+	rel = strings.ReplaceAll(rel, string(fs.PathSeparator()), "/")
+	return rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
+// copyExternalPaths copies the paths listed in .devbackinclude into targetPath/_external. They
+// are counted in result.ExternalFiles instead of CopiedFiles.
+func copyExternalPaths(
+	ctx context.Context,
+	cfg *Config,
+	deps *Dependencies,
+	repoRoot,
+	targetPath string,
+	result *BackupResult,
+	bc *backupContext,
+) error {
+	paths, err := resolveExternalPaths(ctx, cfg, deps, repoRoot, bc)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	external := &BackupResult{}
+	var firstErr error
+	for _, p := range paths {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		dst := deps.FileSystem.Join(targetPath, externalDir, p.rel)
+		if err := deps.FileSystem.CreateDir(ctx, deps.FileSystem.Dir(dst), 0o755); err != nil {
+			return fmt.Errorf("mkdir %s: %w", deps.FileSystem.Dir(dst), err)
+		}
+		bc.vlogf("   EXTERNAL: %s", p.src)
+		if err := copyDirRecursive(ctx, deps, p.src, dst, external, bc); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("copy %s: %w", p.src, err)
+		}
+		result.ExternalPaths = append(result.ExternalPaths, p.src)
+	}
+	mergeExternalResult(result, external)
+	if firstErr != nil {
+		return firstErr
+	}
+	bc.logf("✓ Copied external: %d path(s), %d file(s)", len(paths), result.ExternalFiles)
+	return nil
+}
+
+// mergeExternalResult adds the files and errors of an external copy to result.
+func mergeExternalResult(result, external *BackupResult) {
+	result.ExternalFiles += external.CopiedFiles
+	result.SkippedFiles += external.SkippedFiles
+	result.PermissionErrs = append(result.PermissionErrs, external.PermissionErrs...)
+	result.OtherErrors = append(result.OtherErrors, external.OtherErrors...)
+	result.PartialSuccess = result.PartialSuccess || external.PartialSuccess
+}
+
+// archiveExternalPaths adds the paths listed in .devbackinclude to an archive snapshot.
+func archiveExternalPaths(
+	ctx context.Context,
+	deps *Dependencies,
+	w ArchiveWriter,
+	paths []externalPath,
+	result *BackupResult,
+	copyErrors *[]string,
+) error {
+	external := &BackupResult{}
+	for _, p := range paths {
+		err := deps.FileSystem.Walk(ctx, p.src, func(path string, info FileInfo, err error) error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err != nil {
+				recordCopyError(deps.FileSystem, path, err, external, copyErrors)
+				return nil
+			}
+			rel, err := deps.FileSystem.Rel(p.src, path)
+			if err != nil || info == nil {
+				return nil
+			}
+			name := archiveName(deps.FileSystem, deps.FileSystem.Join(externalDir, p.rel, rel))
+			archiveEntry(ctx, deps, w, path, name, info, external, copyErrors)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		result.ExternalPaths = append(result.ExternalPaths, p.src)
+	}
+	mergeExternalResult(result, external)
+	return nil
+}
+
+// planExternalPaths reports what copyExternalPaths would capture.
+func planExternalPaths(ctx context.Context, cfg *Config, deps *Dependencies, repoRoot string, bc *backupContext) error {
+	paths, err := resolveExternalPaths(ctx, cfg, deps, repoRoot, bc)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	bc.logf("Dry run: would copy %d external path(s) to %s", len(paths), externalDir)
+	for _, p := range paths {
+		bc.vlogf("   EXTERNAL: %s", p.src)
+	}
+	return nil
+}
+
+// applyExternalPaths copies target/_external back to the original locations, then removes the
+// directory. Files that already exist there are never replaced: they are counted as kept and the
+// directory stays in target so they can be compared by hand. The number of restored and kept
+// files is returned.
+func applyExternalPaths(
+	ctx context.Context,
+	cfg *Config,
+	deps *Dependencies,
+	target string,
+	bc *backupContext,
+) (int, int, error) {
+	fs := deps.FileSystem
+	dir := fs.Join(target, externalDir)
+	if exists, err := pathExists(ctx, fs, dir); err != nil || !exists {
+		return 0, 0, err
+	}
+	roots := []struct{ src, dst string }{{fs.Join(dir, externalRootDir), string(fs.PathSeparator())}}
+	if cfg.HomeDir != "" {
+		roots = append(roots, struct{ src, dst string }{fs.Join(dir, externalHomeDir), cfg.HomeDir})
+	} else if exists, _ := pathExists(ctx, fs, fs.Join(dir, externalHomeDir)); exists {
+		return 0, 0, fmt.Errorf("home directory unknown")
+	}
+	restored, kept := 0, 0
+	for _, root := range roots {
+		if exists, _ := pathExists(ctx, fs, root.src); !exists {
+			continue
+		}
+		err := fs.Walk(ctx, root.src, func(path string, info FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := fs.Rel(root.src, path)
+			if err != nil {
+				return err
+			}
+			dst := fs.Join(root.dst, rel)
+			existing, lerr := fs.Lstat(ctx, dst)
+			if info.IsDir() {
+				if lerr == nil && !existing.IsDir() {
+					return fmt.Errorf("%s exists and is not a directory", dst)
+				}
+				return fs.CreateDir(ctx, dst, info.Mode()&0o777)
+			}
+			if lerr == nil {
+				bc.vlogf("   KEPT: %s", dst)
+				kept++
+				return nil
+			}
+			if info.IsSymlink() {
+				link, err := fs.Readlink(ctx, path)
+				if err != nil {
+					return err
+				}
+				return fs.Symlink(ctx, link, dst)
+			}
+			if err := copyFile(ctx, deps, path, dst, info.Mode()); err != nil {
+				return err
+			}
+			restored++
+			return nil
+		})
+		if err != nil {
+			return restored, kept, err
+		}
+	}
+	if restored > 0 {
+		bc.logf("✓ External paths restored: %d file(s)", restored)
+	}
+	if kept > 0 {
+		bc.warnf("Kept %d existing external file(s); the snapshot copies stay in %s", kept, dir)
+		return restored, kept, nil
+	}
+	return restored, kept, fs.RemoveAll(ctx, dir)
+}
@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBackupRestore_ExternalPaths(t *testing.T) {
+	for _, format := range []string{SnapshotFormatDir, SnapshotFormatTar} {
+		t.Run(format, func(t *testing.T) {
+			ctx := context.Background()
+			homeDir := t.TempDir()
+			outside := t.TempDir()
+			repoRoot := t.TempDir()
+			runGitForTest(t, repoRoot, "init", "-q")
+			writeTestFile(t, filepath.Join(homeDir, ".config", "ide", "workspace.xml"), "<ide/>")
+			writeTestFile(t, filepath.Join(homeDir, ".local", "share", "direnv", "allow", "abc"), "allowed")
+			writeTestFile(t, filepath.Join(outside, "tfstate", "terraform.tfstate"), "{}")
+			writeTestFile(t, filepath.Join(repoRoot, devbackIncludeFile), strings.Join([]string{
+				"# extra state",
+				"~/.config/ide",
+				"~/.local/share/direnv/allow/*",
+				filepath.Join(outside, "tfstate"),
+				"~/missing",
+				"relative/path",
+				repoRoot,
+			}, "\n"))
+			runGitForTest(t, repoRoot, "add", ".")
+			runGitForTest(t, repoRoot, "commit", "-q", "-m", "init")
+
+			backupDir := t.TempDir()
+			repoKey := "repo--deadbeef"
+			repoDir := filepath.Join(backupDir, repoKey)
+			cfg := &Config{BackupDir: backupDir, NoSize: true, Format: format, HomeDir: homeDir}
+			deps := &Dependencies{
+				FileSystem: newTestFileSystem(),
+				Git:        newTestGitAdapter(),
+				Lock:       &mockLock{},
+				Process:    &mockProcess{},
+				Archive:    testArchive{},
+			}
+			result, err := handleBackupFlow(ctx, cfg, deps, repoRoot, repoDir, newTestBackupContext(false))
+			if err != nil {
+				t.Fatalf("backup failed: %v", err)
+			}
+			if len(result.ExternalPaths) != 3 || result.ExternalFiles != 3 {
+				t.Fatalf("expected 3 external paths and files, got %v (%d files)", result.ExternalPaths, result.ExternalFiles)
+			}
+			if format == SnapshotFormatDir {
+				snaps, err := listSnapshots(ctx, deps, repoDir)
+				if err != nil || len(snaps) != 1 {
+					t.Fatalf("expected one snapshot, got %d (%v)", len(snaps), err)
+				}
+				for _, rel := range []string{
+					filepath.Join(externalHomeDir, ".config", "ide", "workspace.xml"),
+					filepath.Join(externalRootDir, strings.TrimPrefix(outside, "/"), "tfstate", "terraform.tfstate"),
+				} {
+					if _, err := os.Stat(filepath.Join(snaps[0].TimeDir, externalDir, rel)); err != nil {
+						t.Fatalf("expected %s in snapshot: %v", rel, err)
+					}
+				}
+			}
+
+			// By default the external paths stay inside the target.
+			writeTestFile(t, filepath.Join(outside, "tfstate", "terraform.tfstate"), "changed")
+			restoreCfg := *cfg
+			restoreCfg.HomeDir = t.TempDir()
+			inside := filepath.Join(t.TempDir(), "inside")
+			restored, err := Restore(ctx, &restoreCfg, RestoreOptions{RepoKey: repoKey, Target: inside},
+				deps, newTestBackupContext(false).logger)
+			if err != nil {
+				t.Fatalf("restore failed: %v", err)
+			}
+			if restored.ExternalFiles != 0 {
+				t.Fatalf("external paths must not leave the target without --restore-external, got %+v", restored)
+			}
+			kept := filepath.Join(inside, externalDir, externalHomeDir, ".config", "ide", "workspace.xml")
+			if _, err := os.Stat(kept); err != nil {
+				t.Fatalf("expected %s in the target: %v", kept, err)
+			}
+			if _, err := os.Stat(filepath.Join(restoreCfg.HomeDir, ".config")); !os.IsNotExist(err) {
+				t.Fatalf("home directory must stay untouched: %v", err)
+			}
+
+			// Restore as a different user: home paths follow the new home directory.
+			newHome := restoreCfg.HomeDir
+			target := filepath.Join(t.TempDir(), "restored")
+			restored, err = Restore(ctx, &restoreCfg,
+				RestoreOptions{RepoKey: repoKey, Target: target, RestoreExternal: true}, deps,
+				newTestBackupContext(false).logger)
+			if err != nil {
+				t.Fatalf("restore failed: %v", err)
+			}
+			if restored.ExternalFiles != 2 || restored.ExternalKept != 1 {
+				t.Fatalf("expected 2 restored and 1 kept external file(s), got %+v", restored)
+			}
+			for path, want := range map[string]string{
+				filepath.Join(newHome, ".config", "ide", "workspace.xml"):           "<ide/>",
+				filepath.Join(newHome, ".local", "share", "direnv", "allow", "abc"): "allowed",
+				filepath.Join(outside, "tfstate", "terraform.tfstate"):              "changed",
+			} {
+				data, err := os.ReadFile(path) // #nosec G304 -- test paths.
+				if err != nil || string(data) != want {
+					t.Fatalf("expected %s = %q, got %q (%v)", path, want, data, err)
+				}
+			}
+			state := filepath.Join(target, externalDir, externalRootDir, strings.TrimPrefix(outside, "/"), "tfstate")
+			if data, _ := os.ReadFile(filepath.Join(state, "terraform.tfstate")); string(data) != "{}" {
+				t.Fatalf("the snapshot copy of a kept file must stay in %s, got %q", externalDir, data)
+			}
+
+			// Only missing files are restored, so a second run keeps everything.
+			again := filepath.Join(t.TempDir(), "again")
+			restored, err = Restore(ctx, &restoreCfg, RestoreOptions{RepoKey: repoKey, Target: again, RestoreExternal: true},
+				deps, newTestBackupContext(false).logger)
+			if err != nil || restored.ExternalFiles != 0 || restored.ExternalKept != 3 {
+				t.Fatalf("expected all external files to be kept, got %+v (%v)", restored, err)
+			}
+		})
+	}
+}
+
+func TestResolveExternalPaths_RejectsOverlapWithBackupDir(t *testing.T) {
+	ctx := context.Background()
+	homeDir := t.TempDir()
+	repoRoot := t.TempDir()
+	backupDir := filepath.Join(homeDir, "backups")
+	writeTestFile(t, filepath.Join(backupDir, "x"), "x")
+	writeTestFile(t, filepath.Join(homeDir, "notes.txt"), "n")
+	writeTestFile(t, filepath.Join(repoRoot, devbackIncludeFile), "~\n~/backups\n~/notes.txt\n")
+	cfg := &Config{BackupDir: backupDir, HomeDir: homeDir}
+
+	paths, err := resolveExternalPaths(ctx, cfg, &Dependencies{FileSystem: newTestFileSystem()}, repoRoot,
+		newTestBackupContext(false))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(paths) != 1 || paths[0].rel != filepath.Join(externalHomeDir, "notes.txt") {
+		t.Fatalf("expected only notes.txt, got %+v", paths)
+	}
+}
@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// maxGuardWarnings limits how many skipped paths are listed outside verbose mode.
+const maxGuardWarnings = 10
+
+// guardSkip is an ignored/untracked file left out by the size and type guards.
+type guardSkip struct {
+	rel    string
+	reason string
+	binary bool
+}
+
+// guardsEnabled reports whether any of the backup size and type guards is configured.
+func guardsEnabled(cfg *Config) bool {
+	return cfg.MaxFileMB > 0 || cfg.MaxUntrackedMB > 0 || len(cfg.SkipExtensions) > 0
+}
+
+// normalizeBinaryExtensions validates backup.skip_binary_extensions and returns lowercase
+// extensions with a leading dot; "qcow2" and ".QCOW2" are the same entry.
+func normalizeBinaryExtensions(exts []string) ([]string, error) {
+	var out []string
+	seen := map[string]bool{}
+	for _, ext := range exts {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if ext == "." || strings.ContainsAny(ext, `/\`) {
+			return nil, fmt.Errorf("invalid backup.skip_binary_extensions entry %q: %w", ext, ErrUsage)
+		}
+		if !seen[ext] {
+			seen[ext] = true
+			out = append(out, ext)
+		}
+	}
+	return out, nil
+}
+
+// binaryExtension returns the entry of exts that rel ends with, or "". Entries may span several
+// dots, e.g. ".tar.gz".
+func binaryExtension(rel string, exts []string) string {
+	name := strings.ToLower(path.Base(strings.ReplaceAll(rel, `\`, "/")))
+	for _, ext := range exts {
+		if strings.HasSuffix(name, ext) && len(name) > len(ext) {
+			return ext
+		}
+	}
+	return ""
+}
+
+// applyUntrackedGuards drops ignored/untracked files that match backup.skip_binary_extensions or
+// exceed backup.max_file_mb, then the largest remaining files until their total fits
+// backup.max_untracked_total_mb. Skipped files are counted in result and listed in a warning;
+// cfg.AllowLarge (--allow-large) bypasses the guards.
+func applyUntrackedGuards(
+	ctx context.Context,
+	cfg *Config,
+	deps *Dependencies,
+	repoRoot string,
+	paths []string,
+	result *BackupResult,
+	bc *backupContext,
+) []string {
+	if !guardsEnabled(cfg) {
+		return paths
+	}
+	if cfg.AllowLarge {
+		bc.vlogf("→ Size and type guards bypassed (--allow-large)")
+		return paths
+	}
+	maxFile := int64(cfg.MaxFileMB) * 1024 * 1024
+	sizes := make(map[string]int64, len(paths))
+	var skipped []guardSkip
+	keep := make([]string, 0, len(paths))
+	for _, rel := range paths {
+		if ext := binaryExtension(rel, cfg.SkipExtensions); ext != "" {
+			skipped = append(skipped, guardSkip{rel: rel, reason: "extension " + ext, binary: true})
+			continue
+		}
+		info, err := deps.FileSystem.Lstat(ctx, deps.FileSystem.Join(repoRoot, rel))
+		if err != nil || !info.IsRegular() {
+			keep = append(keep, rel)
+			continue
+		}
+		if maxFile > 0 && info.Size() > maxFile {
+			reason := fmt.Sprintf("%s > max_file_mb = %d", humanKB(info.Size()/1024), cfg.MaxFileMB)
+			skipped = append(skipped, guardSkip{rel: rel, reason: reason})
+			continue
+		}
+		sizes[rel] = info.Size()
+		keep = append(keep, rel)
+	}
+	if cfg.MaxUntrackedMB > 0 {
+		keep, skipped = applyUntrackedTotalLimit(keep, sizes, cfg.MaxUntrackedMB, skipped)
+	}
+	if len(skipped) == 0 {
+		return keep
+	}
+
+	bc.warnf("Skipped %d ignored/untracked file(s) by size/type guards (use --allow-large to include them):",
+		len(skipped))
+	for i, s := range skipped {
+		if s.binary {
+			result.SkippedBinary++
+		} else {
+			result.SkippedLarge++
+		}
+		result.GuardSkipped = append(result.GuardSkipped, s.rel)
+		if i < maxGuardWarnings || bc.verbose {
+			bc.warnf("  - %s (%s)", s.rel, s.reason)
+		}
+	}
+	if len(skipped) > maxGuardWarnings && !bc.verbose {
+		bc.warnf("  ... and %d more (see --verbose)", len(skipped)-maxGuardWarnings)
+	}
+	return keep
+}
+
+// applyUntrackedTotalLimit drops the largest files from keep until the total size of the rest
+// fits limitMB, so small files such as local configs survive a single oversized dump.
+func applyUntrackedTotalLimit(
+	keep []string,
+	sizes map[string]int64,
+	limitMB int,
+	skipped []guardSkip,
+) ([]string, []guardSkip) {
+	limit := int64(limitMB) * 1024 * 1024
+	var total int64
+	for _, size := range sizes {
+		total += size
+	}
+	if total <= limit {
+		return keep, skipped
+	}
+	bySize := make([]string, 0, len(sizes))
+	for rel := range sizes {
+		bySize = append(bySize, rel)
+	}
+	sort.Slice(bySize, func(i, j int) bool {
+		if sizes[bySize[i]] != sizes[bySize[j]] {
+			return sizes[bySize[i]] > sizes[bySize[j]]
+		}
+		return bySize[i] < bySize[j]
+	})
+	drop := map[string]bool{}
+	for _, rel := range bySize {
+		if total <= limit {
+			break
+		}
+		drop[rel] = true
+		total -= sizes[rel]
+		reason := fmt.Sprintf("%s, total over max_untracked_total_mb = %d",
+			humanKB(sizes[rel]/1024), limitMB)
+		skipped = append(skipped, guardSkip{rel: rel, reason: reason})
+	}
+	filtered := keep[:0]
+	for _, rel := range keep {
+		if !drop[rel] {
+			filtered = append(filtered, rel)
+		}
+	}
+	return filtered, skipped
+}
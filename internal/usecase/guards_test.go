@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyUntrackedGuards(t *testing.T) {
+	ctx := context.Background()
+	repoRoot := t.TempDir()
+	for rel, size := range map[string]int{
+		"vm/disk.qcow2":    10,
+		"dumps/big.bin":    3 << 20,
+		"data/a.csv":       600 << 10,
+		"data/b.csv":       600 << 10,
+		".env.local":       10,
+		"backup.SQL.GZ":    10,
+		"notes.sql.gz.txt": 10,
+	} {
+		writeTestFile(t, filepath.Join(repoRoot, rel), strings.Repeat("x", size))
+	}
+	paths := []string{"vm/disk.qcow2", "dumps/big.bin", "data/a.csv", "data/b.csv", ".env.local",
+		"backup.SQL.GZ", "notes.sql.gz.txt"}
+	cfg := &Config{MaxFileMB: 2, MaxUntrackedMB: 1, SkipExtensions: []string{".qcow2", ".sql.gz"}}
+	deps := &Dependencies{FileSystem: newTestFileSystem()}
+
+	result := &BackupResult{}
+	keep := applyUntrackedGuards(ctx, cfg, deps, repoRoot, paths, result, newTestBackupContext(false))
+	if want := []string{"data/b.csv", ".env.local", "notes.sql.gz.txt"}; !reflect.DeepEqual(keep, want) {
+		t.Fatalf("unexpected kept paths: %v, want %v", keep, want)
+	}
+	if result.SkippedBinary != 2 || result.SkippedLarge != 2 {
+		t.Fatalf("unexpected skip counts: binary=%d large=%d", result.SkippedBinary, result.SkippedLarge)
+	}
+	want := []string{"vm/disk.qcow2", "dumps/big.bin", "backup.SQL.GZ", "data/a.csv"}
+	if !reflect.DeepEqual(result.GuardSkipped, want) {
+		t.Fatalf("unexpected skipped paths: %v, want %v", result.GuardSkipped, want)
+	}
+
+	cfg.AllowLarge = true
+	result = &BackupResult{}
+	keep = applyUntrackedGuards(ctx, cfg, deps, repoRoot, paths, result, newTestBackupContext(false))
+	if len(keep) != len(paths) {
+		t.Fatalf("--allow-large must keep every path, got %v", keep)
+	}
+	if len(result.GuardSkipped) != 0 {
+		t.Fatalf("--allow-large must not record skipped paths: %v", result.GuardSkipped)
+	}
+}
@@ -0,0 +1,321 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"sync"
+)
+
+// ignoreRule is one pattern of a .devbackignore file or of the global ignore file.
+type ignoreRule struct {
+	source   string // file the rule was read from, relative to the repository root unless global
+	line     int
+	pattern  string // pattern as written, reported by check-ignore
+	negate   bool
+	dirOnly  bool
+	anchored bool
+	segments []string
+}
+
+// matches reports whether parts (a path relative to the directory of the rule's file) matches.
+func (r *ignoreRule) matches(parts []string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if !r.anchored {
+		ok, _ := path.Match(r.segments[0], parts[len(parts)-1])
+		return ok
+	}
+	return matchIgnoreSegments(r.segments, parts)
+}
+
+// matchIgnoreSegments matches slash-separated pattern segments against path segments; "**"
+// matches any number of directories, a trailing "**" everything inside a directory.
+func matchIgnoreSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			if len(pattern) == 1 {
+				return len(parts) > 0
+			}
+			for i := 0; i <= len(parts); i++ {
+				if matchIgnoreSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+// parseIgnoreRules parses gitignore syntax: "#" comments, "!" negation, a trailing "/" for
+// directories only, and a "/" at the start or in the middle anchoring the pattern to the
+// directory of the file. Patterns without a slash match a name at any depth.
+func parseIgnoreRules(data []byte, source string) []ignoreRule {
+	var rules []ignoreRule
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+			line = line[:len(line)-1]
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{source: source, line: i + 1, pattern: line}
+		p := line
+		if strings.HasPrefix(p, "!") {
+			rule.negate = true
+			p = p[1:]
+		}
+		if strings.HasSuffix(p, "/") {
+			rule.dirOnly = true
+			p = strings.TrimRight(p, "/")
+		}
+		if strings.Contains(p, "/") {
+			rule.anchored = true
+			p = strings.TrimPrefix(p, "/")
+		}
+		if p == "" {
+			continue
+		}
+		rule.segments = strings.Split(p, "/")
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// ignoreMatcher applies .devbackignore files with gitignore semantics. Within a file the last
+// matching pattern wins; a .devbackignore in a subdirectory overrides its parents, which override
+// the global ignore file. Like git, nothing below an excluded directory can be re-included, so
+// "node_modules/*" with "!node_modules/.patches" keeps the patches while "node_modules" does not.
+// Files in subdirectories are read on first use and never below an excluded directory.
+type ignoreMatcher struct {
+	fs     FileSystemPort
+	root   string
+	global []ignoreRule
+	bc     *backupContext
+
+	mu   sync.Mutex
+	dirs map[string][]ignoreRule // keyed by slash-separated directory relative to root
+}
+
+// newIgnoreMatcher reads the global ignore file and the .devbackignore in repoRoot. Read errors
+// are returned together with a usable matcher that skips the unreadable file.
+func newIgnoreMatcher(
+	ctx context.Context,
+	deps *Dependencies,
+	repoRoot,
+	globalFile string,
+	bc *backupContext,
+) (*ignoreMatcher, error) {
+	m := &ignoreMatcher{fs: deps.FileSystem, root: repoRoot, bc: bc, dirs: map[string][]ignoreRule{}}
+	var errs []error
+	if globalFile != "" {
+		data, err := m.fs.ReadFile(ctx, globalFile)
+		switch {
+		case err == nil:
+			m.global = parseIgnoreRules(data, globalFile)
+			bc.vlogf("→ Found global ignore file %s:", globalFile)
+			logIgnoreRules(bc, m.global)
+		case !m.fs.IsNotExist(err):
+			errs = append(errs, fmt.Errorf("%s: %w", globalFile, err))
+		}
+	}
+	rules, found, err := m.readRules(ctx, "")
+	switch {
+	case err != nil:
+		errs = append(errs, err)
+	case !found:
+		bc.vlogf("→ No .devbackignore in repo")
+	default:
+		bc.vlogf("→ Found .devbackignore:")
+		logIgnoreRules(bc, rules)
+	}
+	m.dirs[""] = rules
+	return m, errors.Join(errs...)
+}
+
+func logIgnoreRules(bc *backupContext, rules []ignoreRule) {
+	for _, r := range rules {
+		bc.vlogf("   exclude='%s'", r.pattern)
+	}
+}
+
+func (m *ignoreMatcher) readRules(ctx context.Context, dir string) ([]ignoreRule, bool, error) {
+	source := path.Join(dir, devbackIgnoreFile)
+	elems := append([]string{m.root}, strings.Split(source, "/")...)
+	data, err := m.fs.ReadFile(ctx, m.fs.Join(elems...))
+	if err != nil {
+		if m.fs.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("%s: %w", source, err)
+	}
+	return parseIgnoreRules(data, source), true, nil
+}
+
+func (m *ignoreMatcher) rulesFor(ctx context.Context, dir string) []ignoreRule {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rules, ok := m.dirs[dir]
+	if !ok {
+		var found bool
+		var err error
+		rules, found, err = m.readRules(ctx, dir)
+		if err != nil {
+			m.bc.warnf(".devbackignore: %v", err)
+		} else if found {
+			m.bc.vlogf("→ Found %s/%s", dir, devbackIgnoreFile)
+		}
+		m.dirs[dir] = rules
+	}
+	return rules
+}
+
+// match returns the rule deciding about rel (slash-separated, relative to the repository root),
+// or nil when no pattern applies. rel is excluded when the returned rule is not a negation.
+func (m *ignoreMatcher) match(ctx context.Context, rel string, isDir bool) *ignoreRule {
+	parts := strings.Split(strings.Trim(rel, "/"), "/")
+	for i := 1; i < len(parts); i++ {
+		if r := m.matchParts(ctx, parts[:i], true); r != nil && !r.negate {
+			return r
+		}
+	}
+	return m.matchParts(ctx, parts, isDir)
+}
+
+// excluded reports whether rel is excluded and the rule that decided it.
+func (m *ignoreMatcher) excluded(ctx context.Context, rel string, isDir bool) (bool, *ignoreRule) {
+	r := m.match(ctx, rel, isDir)
+	return r != nil && !r.negate, r
+}
+
+func (m *ignoreMatcher) matchParts(ctx context.Context, parts []string, isDir bool) *ignoreRule {
+	for depth := len(parts) - 1; depth >= 0; depth-- {
+		rules := m.rulesFor(ctx, strings.Join(parts[:depth], "/"))
+		if r := lastMatchingRule(rules, parts[depth:], isDir); r != nil {
+			return r
+		}
+	}
+	return lastMatchingRule(m.global, parts, isDir)
+}
+
+func lastMatchingRule(rules []ignoreRule, parts []string, isDir bool) *ignoreRule {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].matches(parts, isDir) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// CheckIgnoreOptions configures CheckIgnore.
+type CheckIgnoreOptions struct {
+	Paths   []string
+	HomeDir string
+}
+
+// CheckIgnoreResult explains the decision for one path. Source, Line and Pattern are empty when
+// no pattern matched; a matching negated pattern keeps the path in backups.
+type CheckIgnoreResult struct {
+	Path    string
+	Ignored bool
+	Source  string
+	Line    int
+	Pattern string
+}
+
+// CheckIgnoreReport lists the results in argument order.
+type CheckIgnoreReport struct {
+	Results []CheckIgnoreResult
+}
+
+// CheckIgnore reports which .devbackignore pattern decides about each path of the current
+// repository, like git check-ignore -v.
+func CheckIgnore(
+	ctx context.Context,
+	opts CheckIgnoreOptions,
+	deps *Dependencies,
+	logger *slog.Logger,
+) (*CheckIgnoreReport, error) {
+	if logger == nil {
+		panic("logger is required")
+	}
+	if len(opts.Paths) == 0 {
+		return nil, fmt.Errorf("no paths given: %w", ErrUsage)
+	}
+	if deps == nil || deps.FileSystem == nil {
+		return nil, fmt.Errorf("filesystem adapter not available: %w", ErrCritical)
+	}
+	repoRoot, err := resolveRepoRoot(ctx, deps)
+	if err != nil {
+		return nil, fmt.Errorf("resolve repo root: %w", ErrCritical)
+	}
+	if err := ensureGitRepo(ctx, deps, repoRoot); err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrUsage)
+	}
+	cwd, err := deps.FileSystem.GetWorkingDir(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get working dir: %w", ErrCritical)
+	}
+	bc := newBackupContext(logger, false)
+	globalFile := ""
+	if home := strings.TrimSpace(opts.HomeDir); home != "" {
+		globalFile = expandHomeDir(defaultGlobalIgnoreFile, home)
+	}
+	matcher, err := newIgnoreMatcher(ctx, deps, repoRoot, globalFile, bc)
+	if err != nil {
+		bc.warnf(".devbackignore: %v", err)
+	}
+
+	report := &CheckIgnoreReport{}
+	for _, p := range opts.Paths {
+		abs := p
+		if !deps.FileSystem.IsAbs(abs) {
+			abs = deps.FileSystem.Join(cwd, p)
+		}
+		rel, err := deps.FileSystem.Rel(repoRoot, abs)
+		rel = strings.ReplaceAll(rel, string(deps.FileSystem.PathSeparator()), "/")
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+			return nil, fmt.Errorf("%s is not inside repository %s: %w", p, repoRoot, ErrUsage)
+		}
+		isDir := strings.HasSuffix(p, "/")
+		if info, err := deps.FileSystem.Lstat(ctx, abs); err == nil && info.IsDir() {
+			isDir = true
+		}
+		result := CheckIgnoreResult{Path: p}
+		if rule := matcher.match(ctx, rel, isDir); rule != nil {
+			result.Ignored = !rule.negate
+			result.Source = rule.source
+			result.Line = rule.line
+			result.Pattern = rule.pattern
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+// FormatCheckIgnore renders the report like git check-ignore -v -n:
+// "<source>:<line>:<pattern><TAB><path>", with "::" for paths no pattern matched.
+func FormatCheckIgnore(report *CheckIgnoreReport) string {
+	var b strings.Builder
+	for _, r := range report.Results {
+		if r.Pattern == "" {
+			fmt.Fprintf(&b, "::\t%s\n", r.Path)
+			continue
+		}
+		fmt.Fprintf(&b, "%s:%d:%s\t%s\n", r.Source, r.Line, r.Pattern, r.Path)
+	}
+	return b.String()
+}
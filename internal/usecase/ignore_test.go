@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIgnoreRule_GitignoreSemantics(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		want    bool
+	}{
+		{"build", "build", true, true},
+		{"build", "src/build", true, true},
+		{"/build", "src/build", true, false},
+		{"/build", "build", false, true},
+		{"build/", "build", false, false},
+		{"build/", "src/build", true, true},
+		{"*.log", "logs/app.log", false, true},
+		{"logs/*.log", "logs/app.log", false, true},
+		{"logs/*.log", "src/logs/app.log", false, false},
+		{"logs/*.log", "logs/sub/app.log", false, false},
+		{"**/logs", "a/b/logs", true, true},
+		{"**/logs/*.log", "logs/app.log", false, true},
+		{"a/**/b", "a/b", true, true},
+		{"a/**/b", "a/x/y/b", true, true},
+		{"a/**", "a", true, false},
+		{"a/**", "a/x/y", false, true},
+		{"doc/*.txt", "doc/notes.txt", false, true},
+		{"file?.txt", "file1.txt", false, true},
+		{"[ab].txt", "c.txt", false, false},
+		{"\\#literal", "#literal", false, true},
+		{"\\!literal", "!literal", false, true},
+		{"space\\ ", "space ", false, true},
+	}
+	for _, tt := range tests {
+		rules := parseIgnoreRules([]byte(tt.pattern+"\n"), ".devbackignore")
+		if len(rules) != 1 {
+			t.Fatalf("%q: expected one rule, got %d", tt.pattern, len(rules))
+		}
+		if got := rules[0].matches(strings.Split(tt.path, "/"), tt.isDir); got != tt.want {
+			t.Errorf("%q against %q (dir=%v): got %v, want %v", tt.pattern, tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestParseIgnoreRules_SkipsCommentsAndTrailingSpaces(t *testing.T) {
+	rules := parseIgnoreRules([]byte("# comment\n\n  \n*.tmp   \r\n!keep.tmp\n/\n"), "x")
+	if len(rules) != 2 {
+		t.Fatalf("expected two rules, got %+v", rules)
+	}
+	if rules[0].pattern != "*.tmp" || rules[0].line != 4 {
+		t.Fatalf("unexpected first rule: %+v", rules[0])
+	}
+	if !rules[1].negate || rules[1].segments[0] != "keep.tmp" {
+		t.Fatalf("expected negated rule: %+v", rules[1])
+	}
+}
+
+func TestIgnoreMatcher_NegationNestingAndGlobal(t *testing.T) {
+	ctx := context.Background()
+	repoRoot := t.TempDir()
+	writeTestFile(t, filepath.Join(repoRoot, ".devbackignore"),
+		"/node_modules/*\n!/node_modules/.patches/\nvendor\n!vendor/keep.txt\n*.log\n!important.log\n")
+	writeTestFile(t, filepath.Join(repoRoot, "sub", ".devbackignore"), "!debug.log\ncache/\n")
+	writeTestFile(t, filepath.Join(repoRoot, "vendor", ".devbackignore"), "!*\n")
+	global := filepath.Join(t.TempDir(), "ignore")
+	writeTestFile(t, global, ".DS_Store\n*.log\n")
+
+	ignore, err := newIgnoreMatcher(ctx, &Dependencies{FileSystem: newTestFileSystem()}, repoRoot, global,
+		newTestBackupContext(false))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		path   string
+		want   bool
+		source string
+	}{
+		{"node_modules/left-pad/index.js", true, ".devbackignore"},
+		{"node_modules/.patches/fix.patch", false, ""},
+		{"vendor/keep.txt", true, ".devbackignore"}, // a file in an excluded directory cannot be re-included
+		{"app.log", true, ".devbackignore"},
+		{"important.log", false, ".devbackignore"},
+		{"sub/debug.log", false, "sub/.devbackignore"},
+		{"sub/other.log", true, ".devbackignore"},
+		{"sub/cache/blob", true, "sub/.devbackignore"},
+		{"cache/blob", false, ""},
+		{"a/.DS_Store", true, global},
+		{"src/main.go", false, ""},
+	}
+	for _, tt := range tests {
+		got, rule := ignore.excluded(ctx, tt.path, false)
+		if got != tt.want {
+			t.Errorf("%s: excluded=%v, want %v (rule %+v)", tt.path, got, tt.want, rule)
+			continue
+		}
+		source := ""
+		if rule != nil {
+			source = rule.source
+		}
+		if source != tt.source {
+			t.Errorf("%s: decided by %q, want %q", tt.path, source, tt.source)
+		}
+	}
+	if _, ok := ignore.dirs["vendor"]; ok {
+		t.Fatal(".devbackignore below an excluded directory must not be read")
+	}
+}
+
+func TestCheckIgnore_ExplainsMatchingRule(t *testing.T) {
+	ctx := context.Background()
+	repoRoot := t.TempDir()
+	runGitForTest(t, repoRoot, "init", "-q")
+	writeTestFile(t, filepath.Join(repoRoot, ".devbackignore"), "# deps\nnode_modules/\n*.log\n!keep.log\n")
+	if err := os.MkdirAll(filepath.Join(repoRoot, "web", "node_modules"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	homeDir := t.TempDir()
+	writeTestFile(t, filepath.Join(homeDir, ".config", "devback", "ignore"), "*.swp\n")
+	t.Chdir(filepath.Join(repoRoot, "web"))
+	deps := &Dependencies{FileSystem: newTestFileSystem(), Git: newTestGitAdapter()}
+	logger := newTestBackupContext(false).logger
+
+	opts := CheckIgnoreOptions{
+		Paths:   []string{"node_modules", "../debug.log", "../keep.log", "main.go", ".main.go.swp"},
+		HomeDir: homeDir,
+	}
+	report, err := CheckIgnore(ctx, opts, deps, logger)
+	if err != nil {
+		t.Fatalf("check-ignore failed: %v", err)
+	}
+	globalFile := filepath.Join(homeDir, ".config", "devback", "ignore")
+	want := ".devbackignore:2:node_modules/\tnode_modules\n" +
+		".devbackignore:3:*.log\t../debug.log\n" +
+		".devbackignore:4:!keep.log\t../keep.log\n" +
+		"::\tmain.go\n" +
+		globalFile + ":1:*.swp\t.main.go.swp\n"
+	if got := FormatCheckIgnore(report); got != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+	if !report.Results[0].Ignored || report.Results[2].Ignored {
+		t.Fatalf("unexpected ignore decisions: %+v", report.Results)
+	}
+
+	opts.Paths = []string{"../../outside"}
+	if _, err := CheckIgnore(ctx, opts, deps, logger); !errors.Is(err, ErrUsage) {
+		t.Fatal("expected usage error for a path outside the repository")
+	}
+}
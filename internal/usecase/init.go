@@ -23,6 +23,7 @@ type InitOptions struct {
 	Force         bool
 	NoGitConfig   bool
 	TemplatesOnly bool
+	Uninstall     bool
 	DryRun        bool
 	HomeDir       string
 	BinaryPath    string
@@ -45,6 +46,10 @@ func Init(ctx context.Context, opts InitOptions, deps *Dependencies, logger *slo
 		return err
 	}
 
+	if opts.Uninstall {
+		return uninstallInit(ctx, opts, deps, homeDir, logger)
+	}
+
 	paths := buildInitPaths(deps.FileSystem, homeDir)
 
 	cfg := DefaultConfigFile()
@@ -74,6 +79,97 @@ func Init(ctx context.Context, opts InitOptions, deps *Dependencies, logger *slo
 	return nil
 }
 
+// uninstallInit removes the hook templates installed by init and unsets init.templateDir when it
+// still points at them. The config, logs, backups and the registry are kept.
+func uninstallInit(
+	ctx context.Context, opts InitOptions, deps *Dependencies, homeDir string, logger *slog.Logger,
+) error {
+	templatesDir, err := normalizeTemplatesDir(DefaultTemplatesDir(), homeDir)
+	if err != nil {
+		return err
+	}
+	repoTemplatesDir, err := normalizeTemplatesDir(DefaultRepoTemplatesDir(), homeDir)
+	if err != nil {
+		return err
+	}
+	templateRoot := deps.FileSystem.Dir(templatesDir)
+
+	if !opts.NoGitConfig {
+		if deps.Git == nil {
+			return fmt.Errorf("git adapter not available: %w", ErrCritical)
+		}
+		err := unsetGitTemplateDir(ctx, deps.FileSystem, deps.Git, homeDir, templateRoot, opts.DryRun, logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, dir := range []string{templateRoot, repoTemplatesDir} {
+		if ctx.Err() != nil {
+			return ErrInterrupted
+		}
+		exists, err := pathExists(ctx, deps.FileSystem, dir)
+		if err != nil {
+			return fmt.Errorf("check templates dir: %w", ErrCritical)
+		}
+		if !exists {
+			continue
+		}
+		logger.InfoContext(ctx, teardownVerb(opts.DryRun, "Removed", "remove")+" templates", "path", dir)
+		if opts.DryRun {
+			continue
+		}
+		if err := deps.FileSystem.RemoveAll(ctx, dir); err != nil {
+			return fmt.Errorf("remove templates dir: %w", ErrCritical)
+		}
+	}
+
+	registryPath := normalizePath(deps.FileSystem, defaultRegistryPath, homeDir)
+	if entries, err := loadRegistry(ctx, deps.FileSystem, registryPath); err == nil && len(entries) > 0 {
+		logger.WarnContext(ctx, "Repositories still have devback hooks; run devback teardown in each",
+			"count", len(entries), "list", "devback repos")
+	}
+	if opts.DryRun {
+		logger.InfoContext(ctx, "Dry run: uninstall planned, nothing changed")
+		return nil
+	}
+	logger.InfoContext(ctx, "Uninstall completed; config and backups were kept")
+	return nil
+}
+
+// unsetGitTemplateDir unsets init.templateDir only if it still points at expected, so a template
+// dir configured by the user or another tool is never touched.
+func unsetGitTemplateDir(
+	ctx context.Context, fs FileSystemPort, git GitPort,
+	homeDir, expected string, dryRun bool, logger *slog.Logger,
+) error {
+	if ctx.Err() != nil {
+		return ErrInterrupted
+	}
+	current, err := git.ConfigGetGlobal(ctx, "init.templateDir")
+	if err != nil {
+		return fmt.Errorf("read git config init.templateDir: %w", ErrCritical)
+	}
+	currentNorm := normalizePath(fs, current, homeDir)
+	if currentNorm == "" {
+		return nil
+	}
+	if currentNorm != normalizePath(fs, expected, homeDir) {
+		logger.WarnContext(ctx, "git config init.templateDir points elsewhere, leaving it unchanged",
+			"value", strings.TrimSpace(current))
+		return nil
+	}
+	logger.InfoContext(ctx, teardownVerb(dryRun, "Unset", "unset")+" git config", "key", "init.templateDir",
+		"scope", "global")
+	if dryRun {
+		return nil
+	}
+	if err := git.ConfigUnsetGlobal(ctx, "init.templateDir"); err != nil {
+		return fmt.Errorf("unset git config init.templateDir: %w", ErrCritical)
+	}
+	return nil
+}
+
 type initPaths struct {
 	configDir  string
 	configPath string
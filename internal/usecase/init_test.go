@@ -16,6 +16,7 @@ type mockGitInit struct {
 	globalValues map[string]string
 	setCalled    bool
 	setValue     string
+	unsetCalled  bool
 }
 
 func (m *mockGitInit) RepoRoot(ctx context.Context) (string, error) {
@@ -37,6 +38,10 @@ func (m *mockGitInit) ConfigGetGlobal(ctx context.Context, key string) (string,
 	return m.globalValues[key], nil
 }
 
+func (m *mockGitInit) ConfigGetLocal(ctx context.Context, repoPath, key string) (string, error) {
+	return "", nil
+}
+
 func (m *mockGitInit) ConfigGetWorktree(ctx context.Context, repoPath, key string) (string, error) {
 	return "", nil
 }
@@ -45,6 +50,20 @@ func (m *mockGitInit) ConfigSetWorktree(ctx context.Context, repoPath, key, valu
 	return nil
 }
 
+func (m *mockGitInit) ConfigUnset(ctx context.Context, repoPath, key string) error {
+	return nil
+}
+
+func (m *mockGitInit) ConfigUnsetWorktree(ctx context.Context, repoPath, key string) error {
+	return nil
+}
+
+func (m *mockGitInit) ConfigUnsetGlobal(ctx context.Context, key string) error {
+	delete(m.globalValues, key)
+	m.unsetCalled = true
+	return nil
+}
+
 func (m *mockGitInit) ConfigSetGlobal(ctx context.Context, key, value string) error {
 	if m.globalValues == nil {
 		m.globalValues = make(map[string]string)
@@ -71,9 +90,42 @@ func (m *mockGitInit) ListIgnoredUntracked(ctx context.Context, repoPath string)
 	return nil, nil
 }
 
+func (m *mockGitInit) GetStatus(ctx context.Context, repoPath string) (GitStatus, error) {
+	return GitStatus{}, nil
+}
+
+func (m *mockGitInit) Diff(ctx context.Context, repoPath string, staged bool) ([]byte, error) {
+	return nil, nil
+}
+
+func (m *mockGitInit) ApplyPatch(ctx context.Context, repoPath, patchPath string) error {
+	return nil
+}
+
+func (m *mockGitInit) RestoreWorktree(ctx context.Context, repoPath string) error {
+	return nil
+}
+
+func (m *mockGitInit) FsckConnectivity(ctx context.Context, gitDir string) error {
+	return nil
+}
+
+func (m *mockGitInit) GetCommitHash(ctx context.Context, repoPath string) (string, error) {
+	return "", nil
+}
+
+func (m *mockGitInit) GetCurrentBranch(ctx context.Context, repoPath string) (string, error) {
+	return "", nil
+}
+
+func (m *mockGitInit) GetRemotes(ctx context.Context, repoPath string) ([]Remote, error) {
+	return nil, nil
+}
+
 type fakeConfigPort struct {
 	fs        FileSystemPort
 	data      map[string]ConfigFile
+	repos     map[string]RepoConfigFile
 	saveCalls int
 }
 
@@ -86,6 +138,9 @@ func newFakeConfigPort(fs FileSystemPort) *fakeConfigPort {
 
 func (f *fakeConfigPort) Load(ctx context.Context, path string) (ConfigFile, error) {
 	if cfg, ok := f.data[path]; ok {
+		// Like a decoded file, the result must not share entries with the stored config.
+		cfg.Targets = append([]TargetConfig(nil), cfg.Targets...)
+		cfg.Repos = append([]RepoConfig(nil), cfg.Repos...)
 		return cfg, nil
 	}
 	return DefaultConfigFile(), nil
@@ -100,6 +155,10 @@ func (f *fakeConfigPort) Save(ctx context.Context, path string, cfg ConfigFile)
 	return f.fs.WriteFile(ctx, path, []byte("config"), 0o644)
 }
 
+func (f *fakeConfigPort) LoadRepo(ctx context.Context, path string) (RepoConfigFile, error) {
+	return f.repos[path], nil
+}
+
 type fakeTemplatesPort struct {
 	entries      []TemplateEntry
 	contents     map[string][]byte
@@ -181,6 +240,10 @@ func (r *recordingConfigPort) Save(ctx context.Context, path string, cfg ConfigF
 	return nil
 }
 
+func (r *recordingConfigPort) LoadRepo(ctx context.Context, path string) (RepoConfigFile, error) {
+	return RepoConfigFile{}, nil
+}
+
 func TestInit_Success(t *testing.T) {
 	t.Helper()
 
@@ -569,3 +632,66 @@ func TestInit_DryRun_NoRepoTemplates(t *testing.T) {
 		t.Fatalf("expected no repo templates in dry-run, got %v", err)
 	}
 }
+
+func TestInit_Uninstall_RemovesTemplatesAndOwnTemplateDir(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	homeDir := t.TempDir()
+	git := &mockGitInit{}
+	fs := newTestFileSystem()
+	deps := &Dependencies{
+		FileSystem: fs,
+		Config:     newFakeConfigPort(fs),
+		Templates:  newFakeTemplatesPort(),
+		Git:        git,
+	}
+	opts := InitOptions{HomeDir: homeDir, BinaryPath: "/usr/local/bin/devback", BackupDir: "~/backup"}
+	if err := Init(ctx, opts, deps, logger); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	templateRoot := filepath.Join(homeDir, ".local", "share", "devback", "templates")
+	repoTemplates := filepath.Join(homeDir, ".local", "share", "devback", "repo-templates")
+
+	uninstall := InitOptions{HomeDir: homeDir, Uninstall: true, DryRun: true}
+	if err := Init(ctx, uninstall, deps, logger); err != nil {
+		t.Fatalf("dry-run uninstall failed: %v", err)
+	}
+	if _, err := os.Stat(templateRoot); err != nil || git.unsetCalled {
+		t.Fatalf("dry run must not change anything (stat err %v, unset %v)", err, git.unsetCalled)
+	}
+
+	uninstall.DryRun = false
+	if err := Init(ctx, uninstall, deps, logger); err != nil {
+		t.Fatalf("uninstall failed: %v", err)
+	}
+	for _, dir := range []string{templateRoot, repoTemplates} {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be removed, got %v", dir, err)
+		}
+	}
+	if _, ok := git.globalValues["init.templateDir"]; ok || !git.unsetCalled {
+		t.Fatal("expected init.templateDir to be unset")
+	}
+	if _, err := os.Stat(filepath.Join(homeDir, ".config", "devback", "config.toml")); err != nil {
+		t.Fatalf("config must be kept: %v", err)
+	}
+}
+
+func TestInit_Uninstall_KeepsForeignTemplateDir(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	git := &mockGitInit{globalValues: map[string]string{"init.templateDir": "/some/other/path"}}
+	fs := newTestFileSystem()
+	deps := &Dependencies{
+		FileSystem: fs,
+		Config:     newFakeConfigPort(fs),
+		Templates:  newFakeTemplatesPort(),
+		Git:        git,
+	}
+	if err := Init(ctx, InitOptions{HomeDir: t.TempDir(), Uninstall: true}, deps, logger); err != nil {
+		t.Fatalf("uninstall failed: %v", err)
+	}
+	if git.unsetCalled || git.globalValues["init.templateDir"] != "/some/other/path" {
+		t.Fatal("init.templateDir set by someone else must be kept")
+	}
+}
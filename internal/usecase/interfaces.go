@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"io"
 	"time"
 )
 
@@ -14,6 +15,11 @@ type Dependencies struct {
 	Config       ConfigPort
 	Templates    TemplatesPort
 	Notification NotificationPort
+	Archive      ArchivePort
+	Encryption   EncryptionPort
+	Watcher      WatcherPort
+	Scheduler    SchedulerPort
+	Storage      StoragePort
 }
 
 // Ports define the interfaces that use cases need (hexagonal architecture)
@@ -39,8 +45,10 @@ type FileSystemPort interface {
 	Move(ctx context.Context, src, dst string) error
 	Readlink(ctx context.Context, path string) (string, error)
 	Symlink(ctx context.Context, target, path string) error
+	Link(ctx context.Context, oldname, newname string) error
 	Chmod(ctx context.Context, path string, perm int) error
 	Chtimes(ctx context.Context, path string, atime, mtime time.Time) error
+	HashFile(ctx context.Context, path string) (string, error)
 
 	// Path operations
 	GetWorkingDir(ctx context.Context) (string, error)
@@ -60,10 +68,37 @@ type FileSystemPort interface {
 	IsExist(err error) bool
 	IsPermission(err error) bool
 
+	// FileID returns the device/inode identity of info when the platform exposes it
+	FileID(info FileInfo) (FileID, bool)
+
 	// Temp operations
 	TempDir(ctx context.Context, dir, prefix string) (string, error)
 }
 
+// StoragePort defines access to the remote snapshot targets configured in [[targets]]
+type StoragePort interface {
+	// Open connects to target; the caller must close the returned store.
+	Open(ctx context.Context, target TargetSettings) (SnapshotStore, error)
+}
+
+// SnapshotStore keeps snapshot files as objects addressed by slash-separated keys relative
+// to the target root. Implementations must be safe for concurrent use.
+type SnapshotStore interface {
+	// List returns the objects whose keys start with prefix.
+	List(ctx context.Context, prefix string) ([]StoredObject, error)
+
+	// Put uploads the local file src to key, replacing an existing object.
+	Put(ctx context.Context, key, src string) error
+
+	// Get downloads key into the local file dst.
+	Get(ctx context.Context, key, dst string) error
+
+	// Delete removes key; a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+
+	Close() error
+}
+
 // GitPort defines git operations needed by use cases
 type GitPort interface {
 	// RepoRoot returns repository root path
@@ -72,6 +107,9 @@ type GitPort interface {
 	// ConfigGet reads git config value
 	ConfigGet(ctx context.Context, repoPath, key string) (string, error)
 
+	// ConfigGetLocal reads a value from the repository config only, ignoring global and system config
+	ConfigGetLocal(ctx context.Context, repoPath, key string) (string, error)
+
 	// ConfigSet sets git config value
 	ConfigSet(ctx context.Context, repoPath, key, value string) error
 
@@ -87,6 +125,15 @@ type GitPort interface {
 	// ConfigSetGlobal sets global git config value
 	ConfigSetGlobal(ctx context.Context, key, value string) error
 
+	// ConfigUnset removes a local git config value; a missing key is not an error
+	ConfigUnset(ctx context.Context, repoPath, key string) error
+
+	// ConfigUnsetWorktree removes a worktree git config value; a missing key is not an error
+	ConfigUnsetWorktree(ctx context.Context, repoPath, key string) error
+
+	// ConfigUnsetGlobal removes a global git config value; a missing key is not an error
+	ConfigUnsetGlobal(ctx context.Context, key string) error
+
 	// GitDir returns the git directory for the repo
 	GitDir(ctx context.Context, repoPath string) (string, error)
 
@@ -98,12 +145,90 @@ type GitPort interface {
 
 	// ListIgnoredUntracked returns ignored/untracked files
 	ListIgnoredUntracked(ctx context.Context, repoPath string) ([]string, error)
+
+	// GetStatus returns staged, modified and untracked paths of the working tree.
+	GetStatus(ctx context.Context, repoPath string) (GitStatus, error)
+
+	// Diff returns a binary patch of unstaged changes, or of staged changes when staged is set.
+	Diff(ctx context.Context, repoPath string, staged bool) ([]byte, error)
+
+	// ApplyPatch applies a patch produced by Diff to the working tree.
+	ApplyPatch(ctx context.Context, repoPath, patchPath string) error
+
+	// RestoreWorktree rebuilds working tree files from the index.
+	RestoreWorktree(ctx context.Context, repoPath string) error
+
+	// FsckConnectivity runs `git fsck --connectivity-only` against a bare git directory.
+	FsckConnectivity(ctx context.Context, gitDir string) error
+
+	// GetCommitHash returns current HEAD commit hash.
+	GetCommitHash(ctx context.Context, repoPath string) (string, error)
+
+	// GetCurrentBranch returns current branch name ("HEAD" when detached).
+	GetCurrentBranch(ctx context.Context, repoPath string) (string, error)
+
+	// GetRemotes returns configured remotes with their fetch URLs.
+	GetRemotes(ctx context.Context, repoPath string) ([]Remote, error)
+}
+
+// ArchivePort defines snapshot archive operations needed by use cases
+type ArchivePort interface {
+	// Create starts a new archive at path in the given format (tar or tar.zst).
+	// A non-nil sealer encrypts the whole archive stream.
+	Create(ctx context.Context, path, format string, sealer Sealer) (ArchiveWriter, error)
+
+	// Extract unpacks an archive into dir and returns the number of regular files written.
+	// A non-nil opener decrypts the archive stream first.
+	Extract(ctx context.Context, path, format, dir string, opener Opener) (int, error)
+}
+
+// ArchiveWriter appends entries to an archive; names use forward slashes.
+// Implementations must be safe for concurrent use.
+type ArchiveWriter interface {
+	AddDir(name string, info FileInfo) error
+	AddFile(ctx context.Context, name, src string, info FileInfo) error
+	AddSymlink(name, target string, info FileInfo) error
+	Close() error
+}
+
+// EncryptionPort defines snapshot encryption operations needed by use cases
+type EncryptionPort interface {
+	// NewSealer prepares encryption to the configured recipients and/or passphrase.
+	NewSealer(ctx context.Context, settings EncryptionSettings) (Sealer, error)
+
+	// NewOpener prepares decryption with the configured identity file and/or passphrase.
+	NewOpener(ctx context.Context, settings EncryptionSettings) (Opener, error)
+
+	// GenerateIdentity returns a new public recipient and the matching secret identity.
+	GenerateIdentity() (recipient, identity string, err error)
+
+	// ValidateRecipient reports whether recipient is a public key NewSealer accepts.
+	ValidateRecipient(recipient string) error
+}
+
+// Sealer encrypts snapshot files. Implementations must be safe for concurrent use.
+type Sealer interface {
+	// Scheme names the key types in use; it is safe to store in snapshot metadata.
+	Scheme() string
+	// PassphraseKDF returns the salt and work factor that derive the key of the run from the
+	// passphrase, or "" without a passphrase. It holds no key material and goes into the manifest.
+	PassphraseKDF() string
+	SealFile(ctx context.Context, src, dst string) error
+	SealWriter(w io.Writer) (io.WriteCloser, error)
+}
+
+// Opener decrypts snapshot files. Implementations must be safe for concurrent use.
+type Opener interface {
+	OpenFile(ctx context.Context, src, dst string) error
+	OpenReader(r io.Reader) (io.Reader, error)
 }
 
 // ConfigPort defines configuration operations needed by use cases
 type ConfigPort interface {
 	Load(ctx context.Context, path string) (ConfigFile, error)
 	Save(ctx context.Context, path string, cfg ConfigFile) error
+	// LoadRepo reads a repository .devback.toml; a missing file yields an empty RepoConfigFile.
+	LoadRepo(ctx context.Context, path string) (RepoConfigFile, error)
 }
 
 // TemplatesPort defines access to embedded templates
@@ -125,6 +250,39 @@ type LockPort interface {
 // ProcessPort defines process operations needed by use cases
 type ProcessPort interface {
 	GetPID() int
+
+	// Hostname returns the host name of the machine.
+	Hostname() string
+
+	// StartBackground runs the current executable with args detached from this process
+	// and its terminal; it does not wait for the child to finish.
+	StartBackground(args []string) error
+}
+
+// WatcherPort defines filesystem change notification needed by use cases
+type WatcherPort interface {
+	// Watch reports changes below root until ctx is done, then closes the channel.
+	// Paths (relative to root, slash-separated) for which skip returns true are not reported,
+	// and skipped directories are not descended into.
+	Watch(ctx context.Context, root string, skip func(rel string) bool) (<-chan WatchEvent, error)
+}
+
+// SchedulerPort defines installation of the periodic backup-all job with the platform service manager
+type SchedulerPort interface {
+	// Backend names the service manager in use ("systemd" or "launchd"), or "" when unsupported.
+	Backend() string
+
+	// Render returns the files Install would write for spec without changing the system.
+	Render(spec ScheduleSpec) ([]ScheduleFile, error)
+
+	// Install writes the job files and (re)loads the job; an existing job is replaced.
+	Install(ctx context.Context, spec ScheduleSpec) error
+
+	// Status reports whether the job is installed and loaded.
+	Status(ctx context.Context) (ScheduleInfo, error)
+
+	// Remove unloads the job and deletes its files, returning the deleted paths.
+	Remove(ctx context.Context) ([]string, error)
 }
 
 // NotificationPort defines desktop notification operations needed by use cases
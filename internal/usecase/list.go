@@ -0,0 +1,405 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const repoDiscoveryMaxDepth = 8
+
+// ListOptions describes snapshot listing behavior.
+type ListOptions struct {
+	AllRepos bool
+	RepoKey  string
+}
+
+// ListReport contains snapshots grouped by repository key.
+type ListReport struct {
+	BackupDir string     `json:"backup_dir"`
+	Repos     []ListRepo `json:"repos"`
+}
+
+// ListRepo contains snapshots and leftovers of a single repository key.
+type ListRepo struct {
+	RepoKey   string             `json:"repo_key"`
+	Snapshots []SnapshotInfo     `json:"snapshots"`
+	Leftovers []SnapshotLeftover `json:"leftovers"`
+}
+
+// SnapshotInfo describes a completed snapshot.
+type SnapshotInfo struct {
+	ID          string    `json:"id"`
+	Path        string    `json:"path"`
+	Head        string    `json:"head,omitempty"`
+	Branch      string    `json:"branch,omitempty"`
+	Trigger     string    `json:"trigger,omitempty"`
+	Format      string    `json:"format"`
+	Encryption  string    `json:"encryption,omitempty"`
+	Quarantined bool      `json:"quarantined,omitempty"`
+	Pinned      bool      `json:"pinned,omitempty"`
+	Label       string    `json:"label,omitempty"`
+	SizeKB      int64     `json:"size_kb"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SnapshotLeftover describes an unfinished snapshot directory (.partial/.reserve or no .done).
+type SnapshotLeftover struct {
+	ID      string   `json:"id"`
+	Path    string   `json:"path"`
+	Markers []string `json:"markers"`
+}
+
+// List collects completed snapshots and unfinished leftovers for one or all repositories.
+func List(
+	ctx context.Context,
+	cfg *Config,
+	opts ListOptions,
+	deps *Dependencies,
+	logger *slog.Logger,
+) (*ListReport, error) {
+	if logger == nil {
+		panic("logger is required")
+	}
+	if deps == nil || deps.FileSystem == nil {
+		return nil, fmt.Errorf("filesystem adapter not available: %w", ErrCritical)
+	}
+	if strings.TrimSpace(cfg.BackupDir) == "" {
+		return nil, fmt.Errorf("backup.base_dir is not configured: %w", ErrUsage)
+	}
+	bc := newBackupContext(logger, cfg.Verbose)
+	report := &ListReport{BackupDir: cfg.BackupDir, Repos: []ListRepo{}}
+
+	var keys []string
+	if opts.AllRepos {
+		found, err := discoverRepoKeys(ctx, deps, cfg.BackupDir)
+		if err != nil {
+			return nil, err
+		}
+		keys = found
+	} else {
+		if deps.Git == nil {
+			return nil, fmt.Errorf("git adapter not available: %w", ErrCritical)
+		}
+		key, err := resolveCommandRepoKey(ctx, cfg, deps, opts.RepoKey, bc)
+		if err != nil {
+			return nil, err
+		}
+		keys = []string{key}
+	}
+
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			return nil, ErrInterrupted
+		}
+		repo, err := listRepoSnapshots(ctx, deps, cfg.BackupDir, key, bc)
+		if err != nil {
+			return nil, err
+		}
+		report.Repos = append(report.Repos, repo)
+	}
+	return report, nil
+}
+
+// discoverRepoKeys finds repository directories (those holding date directories) under baseDir.
+func discoverRepoKeys(ctx context.Context, deps *Dependencies, baseDir string) ([]string, error) {
+	exists, err := pathExists(ctx, deps.FileSystem, baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("check backup dir: %w", ErrCritical)
+	}
+	if !exists {
+		return nil, nil
+	}
+	var keys []string
+	var walk func(dir, key string, depth int) error
+	walk = func(dir, key string, depth int) error {
+		if ctx.Err() != nil {
+			return ErrInterrupted
+		}
+		entries, err := deps.FileSystem.ReadDir(ctx, dir)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", dir, ErrCritical)
+		}
+		var children []string
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			name := entry.Name()
+			if matchDateDir(name) {
+				if key != "" {
+					keys = append(keys, key)
+				}
+				return nil
+			}
+			if !strings.HasPrefix(name, ".") {
+				children = append(children, name)
+			}
+		}
+		if depth >= repoDiscoveryMaxDepth {
+			return nil
+		}
+		for _, name := range children {
+			childKey := name
+			if key != "" {
+				childKey = key + "/" + name
+			}
+			if err := walk(deps.FileSystem.Join(dir, name), childKey, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(baseDir, "", 0); err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func listRepoSnapshots(
+	ctx context.Context,
+	deps *Dependencies,
+	baseDir,
+	repoKey string,
+	bc *backupContext,
+) (ListRepo, error) {
+	repo := ListRepo{RepoKey: repoKey, Snapshots: []SnapshotInfo{}, Leftovers: []SnapshotLeftover{}}
+	repoDir := deps.FileSystem.Join(baseDir, repoKey)
+	exists, err := pathExists(ctx, deps.FileSystem, repoDir)
+	if err != nil {
+		return repo, fmt.Errorf("check repo dir: %w", ErrCritical)
+	}
+	if !exists {
+		return repo, nil
+	}
+	snaps, err := listSnapshots(ctx, deps, repoDir)
+	if err != nil {
+		return repo, fmt.Errorf("list snapshots: %w", ErrCritical)
+	}
+	for _, s := range snaps {
+		if ctx.Err() != nil {
+			return repo, ErrInterrupted
+		}
+		repo.Snapshots = append(repo.Snapshots, describeSnapshot(ctx, deps, repoDir, s, bc))
+	}
+	leftovers, err := listLeftovers(ctx, deps, repoDir)
+	if err != nil {
+		return repo, fmt.Errorf("list leftovers: %w", ErrCritical)
+	}
+	repo.Leftovers = leftovers
+	return repo, nil
+}
+
+func describeSnapshot(
+	ctx context.Context,
+	deps *Dependencies,
+	repoDir string,
+	s snapshot,
+	bc *backupContext,
+) SnapshotInfo {
+	info := SnapshotInfo{
+		ID:          snapshotID(deps.FileSystem, repoDir, s),
+		Path:        s.TimeDir,
+		Format:      s.format(),
+		CreatedAt:   snapshotTime(ctx, deps, s),
+		Quarantined: s.Quarantined,
+		Pinned:      s.Pinned,
+	}
+	if m, err := readSnapshotManifest(ctx, deps, s.TimeDir); err == nil {
+		info.Head, info.Branch = m.Head, m.Branch
+		info.Trigger = m.Trigger
+		info.Encryption = m.Encryption
+		info.Label = m.Label
+		if !m.StartedAt.IsZero() {
+			info.CreatedAt = m.StartedAt
+		}
+	} else {
+		info.Head, info.Branch = readSnapshotHead(ctx, deps, deps.FileSystem.Join(s.TimeDir, ".git"))
+	}
+	if label, ok := readPinLabel(ctx, deps, s.TimeDir); ok && label != "" {
+		info.Label = label
+	}
+	if kb, err := snapshotSizeKB(ctx, deps, s.TimeDir, bc); err == nil {
+		info.SizeKB = kb
+	}
+	return info
+}
+
+// snapshotTime derives the snapshot creation time from its directory names,
+// falling back to the .done marker mtime.
+func snapshotTime(ctx context.Context, deps *Dependencies, s snapshot) time.Time {
+	date := deps.FileSystem.Base(s.DateDir)
+	clock := deps.FileSystem.Base(s.TimeDir)
+	if len(clock) >= 6 {
+		if t, err := time.ParseInLocation("2006-01-02 150405", date+" "+clock[:6], time.Local); err == nil {
+			return t
+		}
+	}
+	if s.Done != "" {
+		if st, err := deps.FileSystem.Stat(ctx, s.Done); err == nil && st != nil {
+			return st.ModTime()
+		}
+	}
+	return time.Time{}
+}
+
+// readSnapshotHead resolves HEAD of a copied .git directory without invoking git.
+func readSnapshotHead(ctx context.Context, deps *Dependencies, gitDir string) (string, string) {
+	data, err := deps.FileSystem.ReadFile(ctx, deps.FileSystem.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", ""
+	}
+	head := strings.TrimSpace(string(data))
+	ref, ok := strings.CutPrefix(head, "ref:")
+	if !ok {
+		return head, ""
+	}
+	ref = strings.TrimSpace(ref)
+	branch := strings.TrimPrefix(ref, "refs/heads/")
+	if data, err := deps.FileSystem.ReadFile(ctx, deps.FileSystem.Join(gitDir, ref)); err == nil {
+		return strings.TrimSpace(string(data)), branch
+	}
+	packed, err := deps.FileSystem.ReadFile(ctx, deps.FileSystem.Join(gitDir, "packed-refs"))
+	if err != nil {
+		return "", branch
+	}
+	for _, line := range strings.Split(string(packed), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == ref {
+			return fields[0], branch
+		}
+	}
+	return "", branch
+}
+
+// listLeftovers returns snapshot directories that never completed.
+func listLeftovers(ctx context.Context, deps *Dependencies, repoDir string) ([]SnapshotLeftover, error) {
+	leftovers := []SnapshotLeftover{}
+	dateDirs, err := deps.FileSystem.ReadDir(ctx, repoDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dateDirs {
+		if !d.IsDir() || !matchDateDir(d.Name()) {
+			continue
+		}
+		dd := deps.FileSystem.Join(repoDir, d.Name())
+		timeDirs, err := deps.FileSystem.ReadDir(ctx, dd)
+		if err != nil {
+			continue
+		}
+		for _, t := range timeDirs {
+			if !t.IsDir() || !matchTimeDir(t.Name()) {
+				continue
+			}
+			td := deps.FileSystem.Join(dd, t.Name())
+			markers, done := snapshotMarkers(ctx, deps, td)
+			if done {
+				continue
+			}
+			if len(markers) == 0 {
+				markers = []string{"incomplete"}
+			}
+			leftovers = append(leftovers, SnapshotLeftover{
+				ID:      d.Name() + "/" + t.Name(),
+				Path:    td,
+				Markers: markers,
+			})
+		}
+	}
+	sort.Slice(leftovers, func(i, j int) bool { return leftovers[i].ID < leftovers[j].ID })
+	return leftovers, nil
+}
+
+func snapshotMarkers(ctx context.Context, deps *Dependencies, snapDir string) ([]string, bool) {
+	var markers []string
+	done := false
+	for _, name := range []string{".done", ".partial", ".reserve"} {
+		if _, err := deps.FileSystem.Stat(ctx, deps.FileSystem.Join(snapDir, name)); err != nil {
+			continue
+		}
+		if name == ".done" {
+			done = true
+			continue
+		}
+		markers = append(markers, strings.TrimPrefix(name, "."))
+	}
+	return markers, done
+}
+
+// FormatList renders the list report as human-readable tables.
+func FormatList(report *ListReport, now time.Time) string {
+	var b strings.Builder
+	if report == nil || len(report.Repos) == 0 {
+		b.WriteString("No snapshots found\n")
+		return b.String()
+	}
+	for i, repo := range report.Repos {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s (%d snapshots)\n", repo.RepoKey, len(repo.Snapshots))
+		if len(repo.Snapshots) > 0 {
+			tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "  ID\tHEAD\tBRANCH\tSIZE\tAGE")
+			for _, s := range repo.Snapshots {
+				id := s.ID
+				if s.Pinned {
+					id += " [pinned" + strings.TrimSuffix(": "+s.Label, ": ") + "]"
+				}
+				if s.Quarantined {
+					id += " [quarantined]"
+				}
+				fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n",
+					id, shortCommit(s.Head), valueOrDash(s.Branch), humanKB(s.SizeKB), formatAge(now, s.CreatedAt))
+			}
+			_ = tw.Flush()
+		}
+		if len(repo.Leftovers) > 0 {
+			fmt.Fprintf(&b, "  Leftovers (%d):\n", len(repo.Leftovers))
+			for _, l := range repo.Leftovers {
+				fmt.Fprintf(&b, "    %s  [%s]\n", l.ID, strings.Join(l.Markers, ", "))
+			}
+		}
+	}
+	return b.String()
+}
+
+func shortCommit(sha string) string {
+	if sha == "" {
+		return "-"
+	}
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+func valueOrDash(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "-"
+	}
+	return s
+}
+
+func formatAge(now, t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	}
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestList_AllReposWithLeftovers(t *testing.T) {
+	cfg, deps, repoKey := newRestoreFixture(t)
+	leftover := filepath.Join(cfg.BackupDir, repoKey, "2024-01-02", "030405-1")
+	writeTestFile(t, filepath.Join(leftover, ".partial"), "")
+	nested := filepath.Join(cfg.BackupDir, "github.com", "acme", "tool--cafe", "2024-01-02", "030405-2")
+	writeTestFile(t, filepath.Join(nested, ".reserve", "x"), "")
+
+	report, err := List(context.Background(), cfg, ListOptions{AllRepos: true}, deps, newTestBackupContext(false).logger)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(report.Repos) != 2 {
+		t.Fatalf("expected 2 repos, got %+v", report.Repos)
+	}
+	if report.Repos[0].RepoKey != "github.com/acme/tool--cafe" || report.Repos[1].RepoKey != repoKey {
+		t.Fatalf("unexpected repo keys: %s, %s", report.Repos[0].RepoKey, report.Repos[1].RepoKey)
+	}
+
+	repo := report.Repos[1]
+	if len(repo.Snapshots) != 1 {
+		t.Fatalf("expected 1 snapshot, got %d", len(repo.Snapshots))
+	}
+	snap := repo.Snapshots[0]
+	if len(snap.Head) != 40 || snap.Branch == "" || snap.SizeKB == 0 || snap.CreatedAt.IsZero() {
+		t.Fatalf("unexpected snapshot info: %+v", snap)
+	}
+	if len(repo.Leftovers) != 1 || repo.Leftovers[0].ID != "2024-01-02/030405-1" ||
+		repo.Leftovers[0].Markers[0] != "partial" {
+		t.Fatalf("unexpected leftovers: %+v", repo.Leftovers)
+	}
+	if markers := report.Repos[0].Leftovers[0].Markers; len(markers) != 1 || markers[0] != "reserve" {
+		t.Fatalf("unexpected nested leftovers: %+v", markers)
+	}
+}
+
+func TestList_MissingRepoDir(t *testing.T) {
+	cfg := &Config{BackupDir: t.TempDir()}
+	deps := &Dependencies{FileSystem: newTestFileSystem(), Git: newTestGitAdapter()}
+
+	report, err := List(context.Background(), cfg, ListOptions{RepoKey: "missing"}, deps,
+		newTestBackupContext(false).logger)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(report.Repos) != 1 || len(report.Repos[0].Snapshots) != 0 {
+		t.Fatalf("expected empty repo, got %+v", report.Repos)
+	}
+}
+
+func TestReadSnapshotHead_PackedRefs(t *testing.T) {
+	gitDir := t.TempDir()
+	writeTestFile(t, filepath.Join(gitDir, "HEAD"), "ref: refs/heads/main\n")
+	writeTestFile(t, filepath.Join(gitDir, "packed-refs"), "# pack-refs\nabc123 refs/heads/main\n")
+	deps := &Dependencies{FileSystem: newTestFileSystem()}
+
+	head, branch := readSnapshotHead(context.Background(), deps, gitDir)
+	if head != "abc123" || branch != "main" {
+		t.Fatalf("unexpected head=%q branch=%q", head, branch)
+	}
+
+	if err := os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte("deadbeef\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	head, branch = readSnapshotHead(context.Background(), deps, gitDir)
+	if head != "deadbeef" || branch != "" {
+		t.Fatalf("unexpected detached head=%q branch=%q", head, branch)
+	}
+}
+
+func TestFormatList(t *testing.T) {
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.Local)
+	report := &ListReport{Repos: []ListRepo{{
+		RepoKey: "repo--abc",
+		Snapshots: []SnapshotInfo{{
+			ID:        "2024-01-02/090000-1",
+			Head:      "0123456789abcdef",
+			Branch:    "main",
+			SizeKB:    2048,
+			CreatedAt: now.Add(-3 * time.Hour),
+		}},
+		Leftovers: []SnapshotLeftover{{ID: "2024-01-02/100000-1", Markers: []string{"partial", "reserve"}}},
+	}}}
+
+	out := FormatList(report, now)
+	for _, want := range []string{"repo--abc (1 snapshots)", "01234567", "main", "2.00 MiB", "3h",
+		"Leftovers (1):", "2024-01-02/100000-1  [partial, reserve]"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if got := FormatList(&ListReport{}, now); got != "No snapshots found\n" {
+		t.Fatalf("unexpected empty output: %q", got)
+	}
+}
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	manifestFileName      = "manifest.json"
+	manifestSchemaVersion = 1
+)
+
+// SnapshotManifest describes a snapshot; it is written as manifest.json before the .done marker.
+type SnapshotManifest struct {
+	Schema         int      `json:"schema"`
+	RepoRoot       string   `json:"repo_root"`
+	RepoKey        string   `json:"repo_key"`
+	Head           string   `json:"head"`
+	Branch         string   `json:"branch"`
+	Remotes        []Remote `json:"remotes"`
+	Hostname       string   `json:"hostname"`
+	DevbackVersion string   `json:"devback_version"`
+	Trigger        string   `json:"trigger"`
+	Label          string   `json:"label,omitempty"`
+	Format         string   `json:"format"`
+	Encryption     string   `json:"encryption,omitempty"`
+	// PassphraseKDF holds the scrypt salt and work factor; see Sealer.PassphraseKDF.
+	PassphraseKDF string       `json:"passphrase_kdf,omitempty"`
+	FileCount     int          `json:"file_count"`
+	Bytes         int64        `json:"bytes"`
+	Result        BackupResult `json:"result"`
+	StartedAt     time.Time    `json:"started_at"`
+	FinishedAt    time.Time    `json:"finished_at"`
+}
+
+// buildSnapshotManifest collects repository metadata and snapshot usage for manifest.json.
+func buildSnapshotManifest(
+	ctx context.Context,
+	cfg *Config,
+	deps *Dependencies,
+	repoRoot,
+	repoDir,
+	snapDir string,
+	result *BackupResult,
+	started time.Time,
+	bc *backupContext,
+) SnapshotManifest {
+	m := SnapshotManifest{
+		Schema:         manifestSchemaVersion,
+		RepoRoot:       repoRoot,
+		DevbackVersion: cfg.Version,
+		Trigger:        cfg.Trigger,
+		Label:          strings.TrimSpace(cfg.Label),
+		Format:         cfg.Format,
+		Remotes:        []Remote{},
+		StartedAt:      started,
+	}
+	if rel, err := deps.FileSystem.Rel(cfg.BackupDir, repoDir); err == nil {
+		m.RepoKey = strings.ReplaceAll(rel, string(deps.FileSystem.PathSeparator()), "/")
+	}
+	if m.Trigger == "" {
+		m.Trigger = "manual"
+	}
+	if m.Format == "" {
+		m.Format = SnapshotFormatDir
+	}
+	if bc.sealer != nil {
+		m.Encryption = bc.sealer.Scheme()
+		m.PassphraseKDF = bc.sealer.PassphraseKDF()
+	}
+	if deps.Process != nil {
+		m.Hostname = deps.Process.Hostname()
+	}
+	if head, err := deps.Git.GetCommitHash(ctx, repoRoot); err == nil {
+		m.Head = strings.TrimSpace(head)
+	}
+	if branch, err := deps.Git.GetCurrentBranch(ctx, repoRoot); err == nil && branch != "HEAD" {
+		m.Branch = strings.TrimSpace(branch)
+	}
+	if remotes, err := deps.Git.GetRemotes(ctx, repoRoot); err == nil && len(remotes) > 0 {
+		sort.Slice(remotes, func(i, j int) bool { return remotes[i].Name < remotes[j].Name })
+		m.Remotes = remotes
+	}
+	if result != nil {
+		m.Result = *result
+	}
+	files, bytes, err := snapshotUsage(ctx, deps, snapDir)
+	if err != nil {
+		bc.warnf("manifest usage: %v", err)
+	}
+	m.FileCount = files
+	m.Bytes = bytes
+	m.FinishedAt = time.Now()
+	return m
+}
+
+func writeSnapshotManifest(ctx context.Context, deps *Dependencies, snapDir string, m SnapshotManifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode manifest: %w", err)
+	}
+	data = append(data, '\n')
+	return deps.FileSystem.WriteFile(ctx, deps.FileSystem.Join(snapDir, manifestFileName), data, 0o644)
+}
+
+// readSnapshotManifest loads manifest.json of a snapshot directory.
+func readSnapshotManifest(ctx context.Context, deps *Dependencies, snapDir string) (*SnapshotManifest, error) {
+	data, err := deps.FileSystem.ReadFile(ctx, deps.FileSystem.Join(snapDir, manifestFileName))
+	if err != nil {
+		return nil, err
+	}
+	var m SnapshotManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// snapshotUsage counts regular files and their total size under snapDir.
+func snapshotUsage(ctx context.Context, deps *Dependencies, snapDir string) (int, int64, error) {
+	var files int
+	var total int64
+	err := deps.FileSystem.Walk(ctx, snapDir, func(path string, info FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info != nil && info.IsRegular() {
+			files++
+			total += info.Size()
+		}
+		return nil
+	})
+	return files, total, err
+}
+
+// snapshotSizeKB returns the snapshot size from its manifest, walking the tree when no manifest exists.
+func snapshotSizeKB(ctx context.Context, deps *Dependencies, snapDir string, bc *backupContext) (int64, error) {
+	if m, err := readSnapshotManifest(ctx, deps, snapDir); err == nil && m.FileCount > 0 {
+		return (m.Bytes + 1023) / 1024, nil
+	}
+	return dirSizeKB(ctx, deps, snapDir, bc)
+}
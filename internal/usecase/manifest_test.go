@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHandleBackupFlow_WritesManifest(t *testing.T) {
+	cfg, deps, repoKey := newRestoreFixture(t)
+	repoDir := filepath.Join(cfg.BackupDir, repoKey)
+	snaps, err := listSnapshots(context.Background(), deps, repoDir)
+	if err != nil || len(snaps) != 1 {
+		t.Fatalf("expected one snapshot, got %d (%v)", len(snaps), err)
+	}
+
+	m, err := readSnapshotManifest(context.Background(), deps, snaps[0].TimeDir)
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+	if m.Schema != manifestSchemaVersion || m.RepoKey != repoKey || m.RepoRoot == "" {
+		t.Fatalf("unexpected manifest identity: %+v", m)
+	}
+	if len(m.Head) != 40 || m.Branch == "" {
+		t.Fatalf("expected head and branch, got head=%q branch=%q", m.Head, m.Branch)
+	}
+	if m.Hostname != "test-host" || m.Trigger != "manual" {
+		t.Fatalf("unexpected hostname/trigger: %q/%q", m.Hostname, m.Trigger)
+	}
+	if m.FileCount == 0 || m.Bytes == 0 || m.Result.CopiedFiles == 0 {
+		t.Fatalf("unexpected usage: files=%d bytes=%d copied=%d", m.FileCount, m.Bytes, m.Result.CopiedFiles)
+	}
+	if m.Result.TotalFiles != m.Result.CopiedFiles+m.Result.ExternalFiles {
+		t.Fatalf("unexpected total files: %+v", m.Result)
+	}
+	if m.StartedAt.IsZero() || m.FinishedAt.Before(m.StartedAt) {
+		t.Fatalf("unexpected times: %v - %v", m.StartedAt, m.FinishedAt)
+	}
+}
+
+func TestSnapshotSizeKB_PrefersManifest(t *testing.T) {
+	snapDir := t.TempDir()
+	writeTestFile(t, filepath.Join(snapDir, "file.txt"), "data")
+	deps := &Dependencies{FileSystem: newTestFileSystem()}
+	bc := newTestBackupContext(false)
+
+	kb, err := snapshotSizeKB(context.Background(), deps, snapDir, bc)
+	if err != nil || kb != 1 {
+		t.Fatalf("expected walked size 1 KiB, got %d (%v)", kb, err)
+	}
+
+	data, err := json.Marshal(SnapshotManifest{FileCount: 1, Bytes: 10 * 1024, FinishedAt: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(snapDir, manifestFileName), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	kb, err = snapshotSizeKB(context.Background(), deps, snapDir, bc)
+	if err != nil || kb != 10 {
+		t.Fatalf("expected manifest size 10 KiB, got %d (%v)", kb, err)
+	}
+}
@@ -0,0 +1,351 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// MigrateOptions describes an explicit move of snapshots between repository keys.
+type MigrateOptions struct {
+	From   string
+	To     string
+	DryRun bool
+}
+
+// MigrateResult lists snapshot IDs that were (or, in dry-run, would be) moved or skipped.
+type MigrateResult struct {
+	From    string
+	To      string
+	Moved   []string
+	Skipped []string
+}
+
+// Migrate moves every completed snapshot from opts.From to opts.To under backup.base_dir,
+// holding the backup lock of both keys. Snapshots whose ID already exists under the
+// target key are skipped, and the source directory is removed once it is empty.
+func Migrate(
+	ctx context.Context,
+	cfg *Config,
+	opts MigrateOptions,
+	deps *Dependencies,
+	logger *slog.Logger,
+) (*MigrateResult, error) {
+	if logger == nil {
+		panic("logger is required")
+	}
+	if err := validateRestoreDependencies(deps, opts.DryRun); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(cfg.BackupDir) == "" {
+		return nil, fmt.Errorf("backup.base_dir is not configured: %w", ErrUsage)
+	}
+	from, to, err := validateMigrateKeys(opts.From, opts.To)
+	if err != nil {
+		return nil, err
+	}
+	bc := newBackupContext(logger, cfg.Verbose)
+	fromDir := deps.FileSystem.Join(cfg.BackupDir, from)
+	toDir := deps.FileSystem.Join(cfg.BackupDir, to)
+	snaps, err := listSnapshots(ctx, deps, fromDir)
+	if err != nil {
+		if deps.FileSystem.IsNotExist(err) {
+			return nil, fmt.Errorf("no snapshots in %s: %w", fromDir, ErrUsage)
+		}
+		return nil, fmt.Errorf("list snapshots in %s: %w", fromDir, ErrCritical)
+	}
+	result := &MigrateResult{From: from, To: to}
+	if opts.DryRun {
+		result.Moved, result.Skipped = moveSnapshots(ctx, deps, fromDir, toDir, to, snaps, true, bc)
+		bc.logf("ℹ️ Migration was DRY-RUN (no snapshots moved).")
+		return result, nil
+	}
+
+	if err := deps.FileSystem.CreateDir(ctx, toDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create %s: %w", toDir, ErrCritical)
+	}
+	_, releaseTo, err := acquireBackupLock(ctx, deps, toDir, toDir, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseTo()
+	defer pruneEmptyRepoDir(ctx, deps, cfg.BackupDir, fromDir)
+	_, releaseFrom, err := acquireBackupLock(ctx, deps, fromDir, fromDir, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseFrom()
+
+	// Re-list under the lock: a backup may have finished in between.
+	if snaps, err = listSnapshots(ctx, deps, fromDir); err != nil {
+		return nil, fmt.Errorf("list snapshots in %s: %w", fromDir, ErrCritical)
+	}
+	result.Moved, result.Skipped = moveSnapshots(ctx, deps, fromDir, toDir, to, snaps, false, bc)
+	forgetMigration(ctx, deps, fromDir)
+	if ctx.Err() != nil {
+		return result, ErrInterrupted
+	}
+	bc.logf("✓ Migrated %d snapshot(s) %s → %s", len(result.Moved), from, to)
+	if len(result.Skipped) > 0 {
+		return result, fmt.Errorf("%d snapshot(s) already exist under %s: %w", len(result.Skipped), to, ErrCritical)
+	}
+	return result, nil
+}
+
+func validateMigrateKeys(from, to string) (string, string, error) {
+	from = strings.Trim(strings.ReplaceAll(strings.TrimSpace(from), "\\", "/"), "/")
+	to = strings.Trim(strings.ReplaceAll(strings.TrimSpace(to), "\\", "/"), "/")
+	if from == "" || to == "" {
+		return "", "", fmt.Errorf("--from and --to are required: %w", ErrUsage)
+	}
+	for _, key := range []string{from, to} {
+		if err := validateRepoKey(key); err != nil {
+			return "", "", err
+		}
+	}
+	if from == to {
+		return "", "", fmt.Errorf("--from and --to are the same key: %w", ErrUsage)
+	}
+	return from, to, nil
+}
+
+// migratedMarker in a repository key directory records that the other keys were searched
+// for snapshots of the repository. The search reads the manifests of every snapshot under
+// backup.base_dir, so it runs once per key: when the key of a repository changes, the new
+// key directory has no marker yet.
+const migratedMarker = ".migrated"
+
+// migrateLegacySnapshots moves snapshots of this repository that were written under a
+// previous repository key (after a change of repo_key.style, backup.slug or
+// auto_remote_merge) into repoDir, so they are listed and rotated again.
+// The caller holds the lock of repoDir; each source key is locked while it is drained.
+func migrateLegacySnapshots(
+	ctx context.Context,
+	cfg *Config,
+	deps *Dependencies,
+	repoRoot,
+	repoKey string,
+	dryRun bool,
+	bc *backupContext,
+) {
+	repoDir := deps.FileSystem.Join(cfg.BackupDir, repoKey)
+	marker := deps.FileSystem.Join(repoDir, migratedMarker)
+	if _, err := deps.FileSystem.Stat(ctx, marker); err == nil {
+		return
+	}
+	keys, err := discoverRepoKeys(ctx, deps, cfg.BackupDir)
+	if err != nil {
+		bc.vlogf("migrate(discover): %v", err)
+		return
+	}
+	owner := newLegacyOwnership(ctx, cfg, deps, repoRoot)
+	current := strings.ReplaceAll(repoKey, string(deps.FileSystem.PathSeparator()), "/")
+	complete := true
+	for _, key := range keys {
+		if key == current || ctx.Err() != nil {
+			continue
+		}
+		fromDir := deps.FileSystem.Join(cfg.BackupDir, key)
+		snaps := owner.ownedSnapshots(ctx, deps, key, fromDir)
+		if len(snaps) == 0 {
+			continue
+		}
+		bc.logf("→ Found %d snapshot(s) of this repository under previous key %s", len(snaps), key)
+		if dryRun {
+			moveSnapshots(ctx, deps, fromDir, repoDir, repoKey, snaps, true, bc)
+			continue
+		}
+		if !migrateLegacyKey(ctx, cfg, deps, fromDir, repoDir, repoKey, key, owner, bc) {
+			complete = false
+		}
+	}
+	if dryRun || !complete || ctx.Err() != nil {
+		return
+	}
+	if err := deps.FileSystem.WriteFile(ctx, marker, nil, 0o644); err != nil {
+		bc.vlogf("migrate(marker): %v", err)
+	}
+}
+
+func migrateLegacyKey(
+	ctx context.Context,
+	cfg *Config,
+	deps *Dependencies,
+	fromDir,
+	repoDir,
+	repoKey,
+	key string,
+	owner *legacyOwnership,
+	bc *backupContext,
+) bool {
+	defer pruneEmptyRepoDir(ctx, deps, cfg.BackupDir, fromDir)
+	_, release, err := acquireBackupLock(ctx, deps, fromDir, fromDir, cfg, bc.logger)
+	if err != nil {
+		bc.warnf("migrate %s: lock busy, will retry on the next backup", key)
+		return false
+	}
+	defer release()
+	snaps := owner.ownedSnapshots(ctx, deps, key, fromDir)
+	moved, skipped := moveSnapshots(ctx, deps, fromDir, repoDir, repoKey, snaps, false, bc)
+	forgetMigration(ctx, deps, fromDir)
+	bc.logf("✓ Migrated %d snapshot(s) from %s", len(moved), key)
+	if len(skipped) > 0 {
+		bc.warnf("migrate %s: %d snapshot(s) left in place (same ID exists)", key, len(skipped))
+	}
+	return true
+}
+
+// forgetMigration removes the marker of a key that snapshots were moved away from, so a
+// repository that switches back to it searches the other keys again.
+func forgetMigration(ctx context.Context, deps *Dependencies, repoDir string) {
+	_ = deps.FileSystem.RemoveAll(ctx, deps.FileSystem.Join(repoDir, migratedMarker))
+}
+
+// legacyOwnership decides which snapshots under other keys belong to the current repository.
+type legacyOwnership struct {
+	repoRoot  string
+	origin    string
+	byRemote  bool
+	ownedKeys map[string]bool
+}
+
+func newLegacyOwnership(ctx context.Context, cfg *Config, deps *Dependencies, repoRoot string) *legacyOwnership {
+	o := &legacyOwnership{repoRoot: repoRoot, byRemote: cfg.AutoRemoteMerge, ownedKeys: make(map[string]bool)}
+	// Keys embedding a hash of repoRoot can only have been written by this repository,
+	// so their snapshots are claimed even when they predate manifest.json.
+	o.ownedKeys[repoKeyNameHash(deps.FileSystem, repoRoot)] = true
+	if remote, err := deps.Git.ConfigGet(ctx, repoRoot, "remote.origin.url"); err == nil && remote != "" {
+		o.origin = strings.TrimSpace(remote)
+		for _, n := range []int{cfg.RemoteHashLen, defaultRemoteHashLen} {
+			unmerged := *cfg
+			unmerged.AutoRemoteMerge, unmerged.RemoteHashLen = false, n
+			if key, ok := repoKeyFromRemote(deps.FileSystem, o.origin, repoRoot, &unmerged); ok {
+				o.ownedKeys[strings.ReplaceAll(key, string(deps.FileSystem.PathSeparator()), "/")] = true
+			}
+		}
+	}
+	return o
+}
+
+// ownedSnapshots returns the snapshots under key that belong to the current repository: those
+// whose manifest names the same repository root or, with auto_remote_merge, the same origin URL.
+func (o *legacyOwnership) ownedSnapshots(ctx context.Context, deps *Dependencies, key, dir string) []snapshot {
+	snaps, err := listSnapshots(ctx, deps, dir)
+	if err != nil || len(snaps) == 0 {
+		return nil
+	}
+	var owned []snapshot
+	for _, s := range snaps {
+		m, err := readSnapshotManifest(ctx, deps, s.TimeDir)
+		switch {
+		case err != nil:
+			if o.ownedKeys[key] {
+				owned = append(owned, s)
+			}
+		case m.RepoRoot == o.repoRoot:
+			owned = append(owned, s)
+		case o.byRemote && o.origin != "" && manifestHasRemote(m, o.origin):
+			owned = append(owned, s)
+		}
+	}
+	return owned
+}
+
+func manifestHasRemote(m *SnapshotManifest, url string) bool {
+	for _, r := range m.Remotes {
+		if r.Name == "origin" && strings.TrimSpace(r.URL) == url {
+			return true
+		}
+	}
+	return false
+}
+
+// moveSnapshots moves snaps from fromDir into toDir keeping their date/time IDs and
+// updates manifest.json with the new key. It returns moved and skipped snapshot IDs.
+func moveSnapshots(
+	ctx context.Context,
+	deps *Dependencies,
+	fromDir,
+	toDir,
+	toKey string,
+	snaps []snapshot,
+	dryRun bool,
+	bc *backupContext,
+) ([]string, []string) {
+	moved, skipped := []string{}, []string{}
+	newKey := strings.ReplaceAll(toKey, string(deps.FileSystem.PathSeparator()), "/")
+	for _, s := range snaps {
+		if ctx.Err() != nil {
+			break
+		}
+		id := snapshotID(deps.FileSystem, fromDir, s)
+		dateDir := deps.FileSystem.Join(toDir, deps.FileSystem.Base(s.DateDir))
+		dst := deps.FileSystem.Join(dateDir, deps.FileSystem.Base(s.TimeDir))
+		if _, err := deps.FileSystem.Lstat(ctx, dst); err == nil {
+			bc.warnf("migrate %s: %s already exists", id, dst)
+			skipped = append(skipped, id)
+			continue
+		}
+		if dryRun {
+			bc.logf("Dry run: would move %s → %s", s.TimeDir, dst)
+			moved = append(moved, id)
+			continue
+		}
+		if err := deps.FileSystem.CreateDir(ctx, dateDir, 0o755); err != nil {
+			bc.warnf("migrate %s: %v", id, err)
+			skipped = append(skipped, id)
+			continue
+		}
+		if err := deps.FileSystem.Move(ctx, s.TimeDir, dst); err != nil {
+			bc.warnf("migrate %s: %v", id, err)
+			skipped = append(skipped, id)
+			continue
+		}
+		if m, err := readSnapshotManifest(ctx, deps, dst); err == nil && m.RepoKey != newKey {
+			m.RepoKey = newKey
+			if err := writeSnapshotManifest(ctx, deps, dst, *m); err != nil {
+				bc.warnf("migrate %s: update manifest: %v", id, err)
+			}
+		}
+		bc.vlogf("   MOVE: %s → %s", s.TimeDir, dst)
+		moved = append(moved, id)
+		removeEmptyDir(ctx, deps, s.DateDir)
+	}
+	sort.Strings(moved)
+	return moved, skipped
+}
+
+// pruneEmptyRepoDir removes a drained repository directory (ignoring a stale lock file)
+// and then any empty parents of a hierarchical key, stopping at baseDir.
+func pruneEmptyRepoDir(ctx context.Context, deps *Dependencies, baseDir, repoDir string) {
+	entries, err := deps.FileSystem.ReadDir(ctx, repoDir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.Name() != ".backup.lock" && e.Name() != migratedMarker {
+			return
+		}
+	}
+	if locked, _, err := deps.Lock.IsLocked(ctx, deps.FileSystem.Join(repoDir, ".backup.lock")); err != nil || locked {
+		return
+	}
+	_ = deps.FileSystem.RemoveAll(ctx, repoDir)
+	fs := deps.FileSystem
+	for dir := fs.Dir(repoDir); dir != baseDir && strings.HasPrefix(dir, baseDir); dir = fs.Dir(dir) {
+		if !removeEmptyDir(ctx, deps, dir) {
+			return
+		}
+	}
+}
+
+// removeEmptyDir removes dir when it has no entries and reports whether it did.
+func removeEmptyDir(ctx context.Context, deps *Dependencies, dir string) bool {
+	entries, err := deps.FileSystem.ReadDir(ctx, dir)
+	if err != nil || len(entries) > 0 {
+		return false
+	}
+	return deps.FileSystem.RemoveAll(ctx, dir) == nil
+}
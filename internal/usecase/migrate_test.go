@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrate_MovesSnapshotsBetweenKeys(t *testing.T) {
+	ctx := context.Background()
+	backupDir := t.TempDir()
+	cfg := &Config{BackupDir: backupDir}
+	deps := &Dependencies{FileSystem: newTestFileSystem(), Lock: &mockLock{}, Process: &mockProcess{}}
+	deps.Git = newTestGitAdapter()
+	fromDir := filepath.Join(backupDir, "github.com", "acme", "app--1234abcd")
+	toDir := filepath.Join(backupDir, "github.com", "acme", "app")
+	makeDoneSnapshots(t, fromDir, "2024-01-01/100000", "2024-01-02/100000")
+	makeDoneSnapshots(t, toDir, "2024-01-02/100000")
+	logger := newTestBackupContext(false).logger
+
+	opts := MigrateOptions{From: "github.com/acme/app--1234abcd", To: "github.com/acme/app", DryRun: true}
+	result, err := Migrate(ctx, cfg, opts, deps, logger)
+	if err != nil || len(result.Moved) != 1 || len(result.Skipped) != 1 {
+		t.Fatalf("unexpected dry-run result: %+v (%v)", result, err)
+	}
+	if got := len(remainingSnapshotIDs(t, deps, fromDir)); got != 2 {
+		t.Fatalf("dry run must not move snapshots, %d left", got)
+	}
+
+	opts.DryRun = false
+	result, err = Migrate(ctx, cfg, opts, deps, logger)
+	if !errors.Is(err, ErrCritical) {
+		t.Fatalf("expected a conflict error, got %v", err)
+	}
+	if strings.Join(result.Moved, ",") != "2024-01-01/100000" || strings.Join(result.Skipped, ",") != "2024-01-02/100000" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if got := strings.Join(remainingSnapshotIDs(t, deps, toDir), " "); got != "2024-01-01/100000 2024-01-02/100000" {
+		t.Fatalf("unexpected target snapshots: %q", got)
+	}
+
+	if err := os.RemoveAll(filepath.Join(toDir, "2024-01-02")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Migrate(ctx, cfg, opts, deps, logger); err != nil {
+		t.Fatalf("second migration failed: %v", err)
+	}
+	if _, err := os.Stat(fromDir); !os.IsNotExist(err) {
+		t.Fatalf("drained source key must be removed: %v", err)
+	}
+}
+
+func TestMigrate_RejectsInvalidKeys(t *testing.T) {
+	cfg := &Config{BackupDir: t.TempDir()}
+	deps := &Dependencies{FileSystem: newTestFileSystem(), Lock: &mockLock{}, Process: &mockProcess{}}
+	deps.Git = newTestGitAdapter()
+	logger := newTestBackupContext(false).logger
+	for _, opts := range []MigrateOptions{
+		{From: "a", To: "a"},
+		{From: "../a", To: "b"},
+		{From: "a", To: ""},
+		{From: "missing", To: "b"},
+	} {
+		if _, err := Migrate(context.Background(), cfg, opts, deps, logger); !errors.Is(err, ErrUsage) {
+			t.Fatalf("%+v: expected usage error, got %v", opts, err)
+		}
+	}
+}
+
+func TestMigrateLegacySnapshots_ClaimsOwnSnapshotsOnly(t *testing.T) {
+	ctx := context.Background()
+	cfg, deps, oldKey := newRestoreFixture(t)
+	oldDir := filepath.Join(cfg.BackupDir, oldKey)
+	manifest, err := readSnapshotManifest(ctx, deps, latestSnapshotDir(t, deps, oldDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreignDir := filepath.Join(cfg.BackupDir, "other--cafebabe")
+	makeDoneSnapshots(t, foreignDir, "2024-01-01/100000")
+	foreign := SnapshotManifest{RepoRoot: "/somewhere/else", RepoKey: "other--cafebabe"}
+	if err := writeSnapshotManifest(ctx, deps, filepath.Join(foreignDir, "2024-01-01", "100000"), foreign); err != nil {
+		t.Fatal(err)
+	}
+
+	newKey := filepath.Join("acme", "repo")
+	newDir := filepath.Join(cfg.BackupDir, newKey)
+	if err := os.MkdirAll(newDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	migrateLegacySnapshots(ctx, cfg, deps, manifest.RepoRoot, newKey, true, newTestBackupContext(false))
+	if got := len(remainingSnapshotIDs(t, deps, newDir)); got != 0 {
+		t.Fatalf("dry run must not move snapshots, %d moved", got)
+	}
+
+	migrateLegacySnapshots(ctx, cfg, deps, manifest.RepoRoot, newKey, false, newTestBackupContext(false))
+	moved := latestSnapshotDir(t, deps, newDir)
+	if m, err := readSnapshotManifest(ctx, deps, moved); err != nil || m.RepoKey != "acme/repo" {
+		t.Fatalf("expected manifest to record the new key: %+v (%v)", m, err)
+	}
+	if _, err := os.Stat(oldDir); !os.IsNotExist(err) {
+		t.Fatalf("drained legacy key must be removed: %v", err)
+	}
+	if got := len(remainingSnapshotIDs(t, deps, foreignDir)); got != 1 {
+		t.Fatalf("snapshots of other repositories must stay, %d left", got)
+	}
+
+	// The other keys are searched once per key, not on every backup.
+	if _, err := os.Stat(filepath.Join(newDir, migratedMarker)); err != nil {
+		t.Fatalf("expected the migration marker: %v", err)
+	}
+	lateDir := filepath.Join(cfg.BackupDir, "repo--0badf00d")
+	makeDoneSnapshots(t, lateDir, "2024-01-01/100000")
+	late := SnapshotManifest{RepoRoot: manifest.RepoRoot, RepoKey: "repo--0badf00d"}
+	if err := writeSnapshotManifest(ctx, deps, filepath.Join(lateDir, "2024-01-01", "100000"), late); err != nil {
+		t.Fatal(err)
+	}
+	migrateLegacySnapshots(ctx, cfg, deps, manifest.RepoRoot, newKey, false, newTestBackupContext(false))
+	if got := len(remainingSnapshotIDs(t, deps, lateDir)); got != 1 {
+		t.Fatalf("a migrated key must not be searched again, %d moved", 1-got)
+	}
+
+	if err := os.Remove(filepath.Join(newDir, migratedMarker)); err != nil {
+		t.Fatal(err)
+	}
+	migrateLegacySnapshots(ctx, cfg, deps, manifest.RepoRoot, newKey, false, newTestBackupContext(false))
+	if _, err := os.Stat(lateDir); !os.IsNotExist(err) {
+		t.Fatalf("expected a search without the marker to drain the key: %v", err)
+	}
+}
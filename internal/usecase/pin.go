@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// pinnedMarker keeps a snapshot out of every rotation pass; its content is the optional label.
+const pinnedMarker = ".pinned"
+
+// PinOptions describes which snapshot to pin or unpin.
+type PinOptions struct {
+	RepoKey  string
+	Snapshot string
+	Label    string
+}
+
+// PinResult describes a pinned or unpinned snapshot.
+type PinResult struct {
+	SnapshotID  string
+	SnapshotDir string
+	Label       string
+	Changed     bool
+}
+
+// Pin marks a completed snapshot (the latest when opts.Snapshot is empty) so rotation never removes it.
+// Pinning an already pinned snapshot updates its label when one is given.
+func Pin(
+	ctx context.Context,
+	cfg *Config,
+	opts PinOptions,
+	deps *Dependencies,
+	logger *slog.Logger,
+) (*PinResult, error) {
+	if logger == nil {
+		panic("logger is required")
+	}
+	return updatePin(ctx, cfg, opts, deps, logger, true)
+}
+
+// Unpin removes the pin from a snapshot; it is subject to rotation again.
+func Unpin(
+	ctx context.Context,
+	cfg *Config,
+	opts PinOptions,
+	deps *Dependencies,
+	logger *slog.Logger,
+) (*PinResult, error) {
+	if logger == nil {
+		panic("logger is required")
+	}
+	return updatePin(ctx, cfg, opts, deps, logger, false)
+}
+
+func updatePin(
+	ctx context.Context,
+	cfg *Config,
+	opts PinOptions,
+	deps *Dependencies,
+	logger *slog.Logger,
+	pin bool,
+) (*PinResult, error) {
+	if err := validateRestoreDependencies(deps, false); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(cfg.BackupDir) == "" {
+		return nil, fmt.Errorf("backup.base_dir is not configured: %w", ErrUsage)
+	}
+	bc := newBackupContext(logger, cfg.Verbose)
+	repoKey, err := resolveCommandRepoKey(ctx, cfg, deps, opts.RepoKey, bc)
+	if err != nil {
+		return nil, err
+	}
+	repoDir := deps.FileSystem.Join(cfg.BackupDir, repoKey)
+	lockPath, releaseLock, err := acquireBackupLock(ctx, deps, repoDir, repoDir, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseLock()
+	stopRefresh := startLockRefresh(ctx, deps, lockPath, logger)
+	defer stopRefresh()
+
+	snap, err := findSnapshot(ctx, deps, repoDir, opts.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	result := &PinResult{SnapshotID: snapshotID(deps.FileSystem, repoDir, snap), SnapshotDir: snap.TimeDir}
+	marker := deps.FileSystem.Join(snap.TimeDir, pinnedMarker)
+	label, pinned := readPinLabel(ctx, deps, snap.TimeDir)
+
+	if !pin {
+		result.Label = label
+		if !pinned {
+			bc.logf("ℹ️ %s is not pinned", result.SnapshotID)
+			return result, nil
+		}
+		if err := deps.FileSystem.RemoveAll(ctx, marker); err != nil {
+			return nil, fmt.Errorf("unpin %s: %w", result.SnapshotID, ErrCritical)
+		}
+		result.Changed = true
+		bc.logf("✓ Unpinned %s", result.SnapshotID)
+		return result, nil
+	}
+
+	if l := strings.TrimSpace(opts.Label); l != "" {
+		label = l
+	}
+	result.Label = label
+	if err := writePinMarker(ctx, deps, snap.TimeDir, label); err != nil {
+		return nil, fmt.Errorf("pin %s: %w", result.SnapshotID, ErrCritical)
+	}
+	result.Changed = !pinned
+	bc.logf("✓ Pinned %s%s", result.SnapshotID, formatPinLabel(label))
+	return result, nil
+}
+
+// pinNewSnapshot pins a snapshot created with --pin before it is marked done,
+// so the rotation that follows the backup already skips it.
+func pinNewSnapshot(ctx context.Context, cfg *Config, deps *Dependencies, snapDir string, bc *backupContext) {
+	if !cfg.Pin {
+		return
+	}
+	if err := writePinMarker(ctx, deps, snapDir, cfg.Label); err != nil {
+		bc.warnf("pin snapshot: %v", err)
+		return
+	}
+	bc.logf("✓ Snapshot pinned%s", formatPinLabel(cfg.Label))
+}
+
+func writePinMarker(ctx context.Context, deps *Dependencies, snapDir, label string) error {
+	content := ""
+	if label = strings.TrimSpace(label); label != "" {
+		content = label + "\n"
+	}
+	return deps.FileSystem.WriteFile(ctx, deps.FileSystem.Join(snapDir, pinnedMarker), []byte(content), 0o644)
+}
+
+// readPinLabel reports whether snapDir is pinned and returns the label stored in the marker.
+func readPinLabel(ctx context.Context, deps *Dependencies, snapDir string) (string, bool) {
+	data, err := deps.FileSystem.ReadFile(ctx, deps.FileSystem.Join(snapDir, pinnedMarker))
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(data)), true
+}
+
+func formatPinLabel(label string) string {
+	if label == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%q)", label)
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPin_SurvivesRotationUntilUnpinned(t *testing.T) {
+	ctx := context.Background()
+	cfg, deps, repoKey := newRestoreFixture(t)
+	repoDir := filepath.Join(cfg.BackupDir, repoKey)
+	logger := newTestBackupContext(false).logger
+
+	result, err := Pin(ctx, cfg, PinOptions{RepoKey: repoKey, Label: "pre-rebase"}, deps, logger)
+	if err != nil {
+		t.Fatalf("pin failed: %v", err)
+	}
+	if !result.Changed || result.Label != "pre-rebase" {
+		t.Fatalf("unexpected pin result: %+v", result)
+	}
+	pinned := result.SnapshotDir
+
+	makeDoneSnapshots(t, repoDir, "2999-01-01/000000", "2999-01-02/000000")
+	cfg.KeepCount = 1
+	cfg.KeepDays = 1
+	cfg.MaxTotalGBPerRepo = 1
+	cfg.SizeMarginMB = -1024
+	cfg.NoSize = false
+	rotateRepo(ctx, deps, repoDir, cfg, false, newTestBackupContext(false))
+	if _, err := os.Stat(pinned); err != nil {
+		t.Fatalf("pinned snapshot was removed: %v", err)
+	}
+
+	report, err := List(ctx, cfg, ListOptions{RepoKey: repoKey}, deps, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := report.Repos[0].Snapshots[0]
+	if !first.Pinned || first.Label != "pre-rebase" {
+		t.Fatalf("expected pinned snapshot with label in list: %+v", first)
+	}
+
+	if _, err := Unpin(ctx, cfg, PinOptions{RepoKey: repoKey, Snapshot: result.SnapshotID}, deps, logger); err != nil {
+		t.Fatalf("unpin failed: %v", err)
+	}
+	rotateRepo(ctx, deps, repoDir, cfg, false, newTestBackupContext(false))
+	if _, err := os.Stat(pinned); !os.IsNotExist(err) {
+		t.Fatalf("unpinned snapshot must be rotated away: %v", err)
+	}
+}
+
+func TestHandleBackupFlow_PinsNewSnapshot(t *testing.T) {
+	ctx := context.Background()
+	cfg, deps, repoKey := newRestoreFixture(t)
+	repoDir := filepath.Join(cfg.BackupDir, repoKey)
+	repoRoot := t.TempDir()
+	runGitForTest(t, repoRoot, "init", "-q")
+	writeTestFile(t, filepath.Join(repoRoot, "tracked.txt"), "tracked")
+	runGitForTest(t, repoRoot, "add", "tracked.txt")
+	runGitForTest(t, repoRoot, "commit", "-q", "-m", "init")
+
+	cfg.Pin, cfg.Label = true, "release"
+	if _, err := handleBackupFlow(ctx, cfg, deps, repoRoot, repoDir, newTestBackupContext(false)); err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	snapDir := latestSnapshotDir(t, deps, repoDir)
+	if label, ok := readPinLabel(ctx, deps, snapDir); !ok || label != "release" {
+		t.Fatalf("expected pinned snapshot, got %q (%v)", label, ok)
+	}
+	manifest, err := readSnapshotManifest(ctx, deps, snapDir)
+	if err != nil || manifest.Label != "release" {
+		t.Fatalf("expected label in manifest: %+v (%v)", manifest, err)
+	}
+
+	backups, err := scanBackups(ctx, deps, cfg.BackupDir, repoKey, newTestBackupContext(false).logger)
+	if err != nil || backups.SnapshotCount != 2 || backups.PinnedCount != 1 {
+		t.Fatalf("unexpected scan: %+v (%v)", backups, err)
+	}
+}
@@ -0,0 +1,336 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const defaultRegistryPath = "~/.local/share/devback/repos.json"
+
+const registryVersion = 1
+
+// Health of a registered repository as reported by devback repos.
+const (
+	RepoHealthOK           = "ok"
+	RepoHealthStale        = "stale"
+	RepoHealthDisabled     = "disabled"
+	RepoHealthHooksMissing = "hooks missing"
+)
+
+// RegistryEntry records a repository configured by devback setup.
+type RegistryEntry struct {
+	Path         string    `json:"path"`
+	RepoKey      string    `json:"repo_key"`
+	Worktree     bool      `json:"worktree"`
+	Hooks        bool      `json:"hooks"`
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+type registryFile struct {
+	Version int             `json:"version"`
+	Repos   []RegistryEntry `json:"repos"`
+}
+
+// ReposOptions describes how registered repositories are checked.
+type ReposOptions struct {
+	HomeDir string
+	Prune   bool
+	DryRun  bool
+}
+
+// ReposReport lists registered repositories with the result of re-checking each one.
+type ReposReport struct {
+	RegistryPath string
+	Repos        []RegisteredRepo
+	Pruned       []string
+	DryRun       bool
+}
+
+// RegisteredRepo is a registry entry together with its current health.
+type RegisteredRepo struct {
+	RegistryEntry
+	Health     string
+	Problem    string
+	CurrentKey string
+	LastBackup time.Time
+}
+
+// Stale returns how many entries point at a path that is no longer a git repository.
+func (r *ReposReport) Stale() int {
+	n := 0
+	for _, repo := range r.Repos {
+		if repo.Health == RepoHealthStale {
+			n++
+		}
+	}
+	return n
+}
+
+// Repos re-checks every repository registered by setup. With opts.Prune, stale entries are
+// removed and the recorded keys of the others are refreshed.
+func Repos(ctx context.Context, opts ReposOptions, deps *Dependencies, logger *slog.Logger) (*ReposReport, error) {
+	if logger == nil {
+		panic("logger is required")
+	}
+	if err := validateStatusDependencies(deps); err != nil {
+		return nil, err
+	}
+	homeDir := strings.TrimSpace(opts.HomeDir)
+	if homeDir == "" {
+		return nil, fmt.Errorf("home directory is empty: %w", ErrCritical)
+	}
+	cfg, err := deps.Config.Load(ctx, buildInitPaths(deps.FileSystem, homeDir).configPath)
+	if err != nil {
+		return nil, ConfigLoadError(err)
+	}
+	registryPath := normalizePath(deps.FileSystem, defaultRegistryPath, homeDir)
+	entries, err := loadRegistry(ctx, deps.FileSystem, registryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	backupBase := normalizePath(deps.FileSystem, cfg.Backup.BaseDir, homeDir)
+	report := &ReposReport{RegistryPath: registryPath, DryRun: opts.DryRun}
+	var kept []RegistryEntry
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return nil, ErrInterrupted
+		}
+		repo := checkRegisteredRepo(ctx, deps, cfg, backupBase, entry, logger)
+		report.Repos = append(report.Repos, repo)
+		if repo.Health == RepoHealthStale {
+			report.Pruned = append(report.Pruned, entry.Path)
+			continue
+		}
+		if repo.CurrentKey != "" {
+			entry.RepoKey = repo.CurrentKey
+		}
+		kept = append(kept, entry)
+	}
+	if !opts.Prune {
+		report.Pruned = nil
+		return report, nil
+	}
+	if opts.DryRun {
+		return report, nil
+	}
+	if err := saveRegistry(ctx, deps.FileSystem, registryPath, kept); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func checkRegisteredRepo(
+	ctx context.Context,
+	deps *Dependencies,
+	cfg ConfigFile,
+	backupBase string,
+	entry RegistryEntry,
+	logger *slog.Logger,
+) RegisteredRepo {
+	repo := RegisteredRepo{RegistryEntry: entry, Health: RepoHealthOK}
+	exists, err := pathExists(ctx, deps.FileSystem, entry.Path)
+	if err != nil || !exists {
+		repo.Health, repo.Problem = RepoHealthStale, "path not found"
+		return repo
+	}
+	if err := ensureGitRepo(ctx, deps, entry.Path); err != nil {
+		repo.Health, repo.Problem = RepoHealthStale, "not a git repository"
+		return repo
+	}
+	setup, err := resolveSetupRepo(ctx, deps, entry.Path)
+	if err != nil {
+		repo.Health, repo.Problem = RepoHealthStale, "cannot resolve git dir"
+		return repo
+	}
+
+	slug := readRepoConfig(ctx, deps.Git, entry.Path, setup.isWorktree, "backup.slug")
+	if key := deriveRepoKeyStatus(ctx, cfg, deps, entry.Path, slug, logger); key != entry.RepoKey {
+		repo.CurrentKey = key
+	}
+	if backupBase != "" {
+		key := entry.RepoKey
+		if repo.CurrentKey != "" {
+			key = repo.CurrentKey
+		}
+		if snaps, err := listSnapshots(ctx, deps, deps.FileSystem.Join(backupBase, key)); err == nil && len(snaps) > 0 {
+			repo.LastBackup = snapshotTime(ctx, deps, snaps[len(snaps)-1])
+		}
+	}
+
+	if !sweepBackupEnabled(ctx, deps.Git, entry.Path) {
+		repo.Health, repo.Problem = RepoHealthDisabled, "backup.enabled is not true"
+		return repo
+	}
+	if entry.Hooks {
+		hookFiles := statusHookFiles()
+		hooksDir, err := resolveStatusHooksDir(ctx, deps.FileSystem, deps.Git, setup, hookFiles)
+		if err == nil {
+			installed, _, err := countHookFiles(ctx, deps.FileSystem, hooksDir, hookFiles)
+			if err == nil && installed < len(hookFiles) {
+				repo.Health = RepoHealthHooksMissing
+				repo.Problem = fmt.Sprintf("%d/%d hooks installed (run: devback setup)", installed, len(hookFiles))
+			}
+		}
+	}
+	return repo
+}
+
+// registerSetupRepo records repo in the registry; an existing entry for the same path is replaced.
+func registerSetupRepo(
+	ctx context.Context,
+	deps *Dependencies,
+	repo setupRepo,
+	homeDir string,
+	hooks bool,
+	logger *slog.Logger,
+) error {
+	var cfg ConfigFile
+	if deps.Config != nil {
+		loaded, err := deps.Config.Load(ctx, buildInitPaths(deps.FileSystem, homeDir).configPath)
+		if err != nil {
+			return ConfigLoadError(err)
+		}
+		cfg = loaded
+	}
+	slug := readRepoConfig(ctx, deps.Git, repo.repoRoot, repo.isWorktree, "backup.slug")
+	entry := RegistryEntry{
+		Path:         normalizeRepoPath(deps.FileSystem, repo.repoRoot),
+		RepoKey:      deriveRepoKeyStatus(ctx, cfg, deps, repo.repoRoot, slug, logger),
+		Worktree:     repo.isWorktree,
+		Hooks:        hooks,
+		RegisteredAt: time.Now().UTC(),
+	}
+
+	registryPath := normalizePath(deps.FileSystem, defaultRegistryPath, homeDir)
+	entries, err := loadRegistry(ctx, deps.FileSystem, registryPath)
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range entries {
+		if entries[i].Path == entry.Path {
+			entries[i] = entry
+			replaced = true
+		}
+	}
+	if !replaced {
+		entries = append(entries, entry)
+	}
+	if err := saveRegistry(ctx, deps.FileSystem, registryPath, entries); err != nil {
+		return err
+	}
+	logger.InfoContext(ctx, "Repository registered", "path", entry.Path, "repo_key", entry.RepoKey)
+	return nil
+}
+
+// unregisterRepo drops the entry for path and reports whether one was found.
+func unregisterRepo(ctx context.Context, fs FileSystemPort, registryPath, path string, dryRun bool) (bool, error) {
+	entries, err := loadRegistry(ctx, fs, registryPath)
+	if err != nil {
+		return false, err
+	}
+	kept := entries[:0]
+	for _, entry := range entries {
+		if entry.Path != path {
+			kept = append(kept, entry)
+		}
+	}
+	if len(kept) == len(entries) {
+		return false, nil
+	}
+	if dryRun {
+		return true, nil
+	}
+	if err := saveRegistry(ctx, fs, registryPath, kept); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func loadRegistry(ctx context.Context, fs FileSystemPort, path string) ([]RegistryEntry, error) {
+	data, err := fs.ReadFile(ctx, path)
+	if err != nil {
+		if fs.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read registry %s: %w", path, ErrCritical)
+	}
+	var file registryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse registry %s: %v: %w", path, err, ErrCritical)
+	}
+	return file.Repos, nil
+}
+
+// saveRegistry writes the registry sorted by path through a temporary file, so a crash never
+// leaves a truncated registry behind.
+func saveRegistry(ctx context.Context, fs FileSystemPort, path string, entries []RegistryEntry) error {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	if entries == nil {
+		entries = []RegistryEntry{}
+	}
+	data, err := json.MarshalIndent(registryFile{Version: registryVersion, Repos: entries}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode registry: %w", ErrCritical)
+	}
+	if err := fs.CreateDir(ctx, fs.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create registry dir: %w", ErrCritical)
+	}
+	tmp := path + ".tmp"
+	if err := fs.WriteFile(ctx, tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write registry: %w", ErrCritical)
+	}
+	if err := fs.Move(ctx, tmp, path); err != nil {
+		_ = fs.RemoveAll(ctx, tmp)
+		return fmt.Errorf("write registry: %w", ErrCritical)
+	}
+	return nil
+}
+
+// FormatRepos renders registered repositories and their health.
+func FormatRepos(report *ReposReport) string {
+	var b strings.Builder
+	if report == nil || len(report.Repos) == 0 {
+		b.WriteString("No repositories registered (run: devback setup)\n")
+		return b.String()
+	}
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PATH\tREPO KEY\tHEALTH\tLAST BACKUP")
+	for _, repo := range report.Repos {
+		key := repo.RepoKey
+		if repo.CurrentKey != "" {
+			key = repo.CurrentKey + " (was " + repo.RepoKey + ")"
+		}
+		path := repo.Path
+		if repo.Worktree {
+			path += " [worktree]"
+		}
+		last := "-"
+		if !repo.LastBackup.IsZero() {
+			last = repo.LastBackup.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", path, key, repo.Health, last)
+	}
+	_ = tw.Flush()
+	for _, repo := range report.Repos {
+		if repo.Problem != "" {
+			fmt.Fprintf(&b, "  %s: %s\n", repo.Path, repo.Problem)
+		}
+	}
+	switch stale := report.Stale(); {
+	case len(report.Pruned) > 0 && report.DryRun:
+		fmt.Fprintf(&b, "Would prune %d stale entries\n", len(report.Pruned))
+	case len(report.Pruned) > 0:
+		fmt.Fprintf(&b, "Pruned %d stale entries\n", len(report.Pruned))
+	case stale > 0:
+		fmt.Fprintf(&b, "%d stale entries (run: devback repos --prune)\n", stale)
+	}
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRepos_SetupRegistersAndPruneRemovesStale(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	env := newSetupEnv(t)
+	templatesDir := expandHomeDir(DefaultTemplatesDir(), env.homeDir)
+	for _, name := range statusHookFiles() {
+		writeTestFile(t, filepath.Join(templatesDir, name), hookTemplateContent(name))
+	}
+	env.deps.Config = newFakeConfigPort(env.deps.FileSystem)
+
+	if err := Setup(ctx, SetupOptions{HomeDir: env.homeDir}, env.deps, logger); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	if err := Setup(ctx, SetupOptions{HomeDir: env.homeDir}, env.deps, logger); err != nil {
+		t.Fatalf("repeated setup failed: %v", err)
+	}
+
+	report, err := Repos(ctx, ReposOptions{HomeDir: env.homeDir}, env.deps, logger)
+	if err != nil {
+		t.Fatalf("repos failed: %v", err)
+	}
+	if len(report.Repos) != 1 {
+		t.Fatalf("expected one registered repo, got %+v", report.Repos)
+	}
+	repo := report.Repos[0]
+	if repo.Path != env.repoRoot || repo.RepoKey == "" || repo.Health != RepoHealthOK {
+		t.Fatalf("unexpected entry: %+v", repo)
+	}
+
+	if err := os.RemoveAll(env.repoRoot); err != nil {
+		t.Fatal(err)
+	}
+	report, err = Repos(ctx, ReposOptions{HomeDir: env.homeDir, Prune: true, DryRun: true}, env.deps, logger)
+	if err != nil || report.Stale() != 1 || len(report.Pruned) != 1 {
+		t.Fatalf("expected stale entry: %+v (%v)", report, err)
+	}
+	if out := FormatRepos(report); !strings.Contains(out, "Would prune 1 stale entries") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+
+	if _, err := Repos(ctx, ReposOptions{HomeDir: env.homeDir, Prune: true}, env.deps, logger); err != nil {
+		t.Fatalf("prune failed: %v", err)
+	}
+	report, err = Repos(ctx, ReposOptions{HomeDir: env.homeDir}, env.deps, logger)
+	if err != nil || len(report.Repos) != 0 {
+		t.Fatalf("expected empty registry after prune: %+v (%v)", report, err)
+	}
+}
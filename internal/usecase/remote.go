@@ -0,0 +1,1192 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Target types accepted in [[targets]] type.
+const (
+	TargetTypeS3   = "s3"
+	TargetTypeSFTP = "sftp"
+	TargetTypeDir  = "dir"
+)
+
+// Copy checks accepted in [[targets]] verify.
+const (
+	TargetVerifySize = "size"
+	TargetVerifyHash = "hash"
+)
+
+const (
+	remoteLockName      = ".remote.lock"
+	remoteStateName     = ".remote-state.json"
+	remoteLayoutObject  = ".layout.json"
+	remoteTransferJobs  = 4
+	remotePushMaxRounds = 3
+)
+
+// RemoteOptions selects the repositories, target and snapshot of a remote command.
+type RemoteOptions struct {
+	RepoKey  string
+	AllRepos bool
+	Target   string
+	Snapshot string
+}
+
+// RemotePushReport aggregates the uploads of one push over every selected target.
+type RemotePushReport struct {
+	Results []RemotePushResult
+}
+
+// RemotePushResult describes the push of one repository to one target. An unavailable
+// target is not a failure: its snapshots stay queued for the next push.
+type RemotePushResult struct {
+	Target      string
+	RepoKey     string
+	Uploaded    []string
+	Removed     []string
+	Locked      bool
+	Unavailable bool
+	Err         error
+}
+
+// RemoteListReport contains the snapshots stored on each selected target.
+type RemoteListReport struct {
+	Repos []RemoteListRepo `json:"repos"`
+}
+
+// RemoteListRepo contains the snapshots of one repository on one target.
+type RemoteListRepo struct {
+	Target    string               `json:"target"`
+	RepoKey   string               `json:"repo_key"`
+	Snapshots []RemoteSnapshotInfo `json:"snapshots"`
+	Error     string               `json:"error,omitempty"`
+}
+
+// RemoteSnapshotInfo describes one snapshot stored on a target.
+type RemoteSnapshotInfo struct {
+	ID         string    `json:"id"`
+	Complete   bool      `json:"complete"`
+	Local      bool      `json:"local"`
+	SizeBytes  int64     `json:"size_bytes"`
+	UploadedAt time.Time `json:"uploaded_at"`
+}
+
+// RemotePullResult describes a snapshot copied from a target into the local store.
+type RemotePullResult struct {
+	Target      string
+	RepoKey     string
+	SnapshotID  string
+	SnapshotDir string
+	Files       int
+}
+
+// remoteSnapshot groups the objects stored under one snapshot ID on a target.
+type remoteSnapshot struct {
+	ID      string
+	Done    bool
+	DoneAt  time.Time
+	Objects map[string]int64 // size by key relative to the snapshot
+}
+
+func (rs *remoteSnapshot) size() int64 {
+	var total int64
+	for _, size := range rs.Objects {
+		total += size
+	}
+	return total
+}
+
+// remoteLayout records what plain objects cannot hold: directories (empty ones included),
+// symlinks and file modes other than 0644.
+type remoteLayout struct {
+	Dirs     []string          `json:"dirs"`
+	Symlinks map[string]string `json:"symlinks,omitempty"`
+	Modes    map[string]int    `json:"modes,omitempty"`
+}
+
+// remoteFile is a regular snapshot file to transfer.
+type remoteFile struct {
+	rel  string
+	path string
+	size int64
+}
+
+// RemotePush uploads completed snapshots that are missing on the configured targets, oldest
+// first, and applies each target's retention. An interrupted upload is resumed by the next push:
+// objects already stored with the expected size are not uploaded again.
+func RemotePush(
+	ctx context.Context,
+	cfg *Config,
+	opts RemoteOptions,
+	deps *Dependencies,
+	logger *slog.Logger,
+) (*RemotePushReport, error) {
+	if logger == nil {
+		panic("logger is required")
+	}
+	if err := validateRemoteDependencies(deps, true); err != nil {
+		return nil, err
+	}
+	bc := newBackupContext(logger, cfg.Verbose)
+	targets, err := selectTargets(cfg, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := remoteRepoKeys(ctx, cfg, opts, deps, bc)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RemotePushReport{}
+	stores, openErrs, closeStores := openTargetStores(ctx, deps, targets, bc)
+	defer closeStores()
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			return report, ErrInterrupted
+		}
+		report.Results = append(report.Results, pushRepo(ctx, cfg, deps, targets, stores, openErrs, key, bc)...)
+	}
+	if ctx.Err() != nil {
+		return report, ErrInterrupted
+	}
+	if failed := report.failed(); failed > 0 {
+		return report, fmt.Errorf("remote push failed for %d repository target(s): %w", failed, ErrCritical)
+	}
+	return report, nil
+}
+
+func (r *RemotePushReport) failed() int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// RemoteList lists the snapshots stored on the configured targets.
+func RemoteList(
+	ctx context.Context,
+	cfg *Config,
+	opts RemoteOptions,
+	deps *Dependencies,
+	logger *slog.Logger,
+) (*RemoteListReport, error) {
+	if logger == nil {
+		panic("logger is required")
+	}
+	if err := validateRemoteDependencies(deps, false); err != nil {
+		return nil, err
+	}
+	bc := newBackupContext(logger, cfg.Verbose)
+	targets, err := selectTargets(cfg, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := remoteRepoKeys(ctx, cfg, opts, deps, bc)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RemoteListReport{Repos: []RemoteListRepo{}}
+	stores, _, closeStores := openTargetStores(ctx, deps, targets, bc)
+	defer closeStores()
+	for i, target := range targets {
+		for _, key := range keys {
+			repo := RemoteListRepo{Target: target.Name, RepoKey: key, Snapshots: []RemoteSnapshotInfo{}}
+			if stores[i] == nil {
+				repo.Error = "target unavailable"
+				report.Repos = append(report.Repos, repo)
+				continue
+			}
+			snaps, err := listRemoteSnapshots(ctx, stores[i], key)
+			if err != nil {
+				repo.Error = err.Error()
+				report.Repos = append(report.Repos, repo)
+				continue
+			}
+			local := localSnapshotIDs(ctx, deps, deps.FileSystem.Join(cfg.BackupDir, key))
+			for _, rs := range snaps {
+				repo.Snapshots = append(repo.Snapshots, RemoteSnapshotInfo{
+					ID: rs.ID, Complete: rs.Done, Local: local[rs.ID], SizeBytes: rs.size(), UploadedAt: rs.DoneAt,
+				})
+			}
+			report.Repos = append(report.Repos, repo)
+		}
+	}
+	return report, nil
+}
+
+// RemotePull downloads a completed snapshot from the first target holding it into the local
+// store, where it becomes a regular snapshot that restore and verify accept.
+func RemotePull(
+	ctx context.Context,
+	cfg *Config,
+	opts RemoteOptions,
+	deps *Dependencies,
+	logger *slog.Logger,
+) (*RemotePullResult, error) {
+	if logger == nil {
+		panic("logger is required")
+	}
+	if err := validateRemoteDependencies(deps, true); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(cfg.BackupDir) == "" {
+		return nil, fmt.Errorf("backup.base_dir is not configured: %w", ErrUsage)
+	}
+	id := strings.Trim(strings.ReplaceAll(strings.TrimSpace(opts.Snapshot), "\\", "/"), "/")
+	if id == "" {
+		return nil, fmt.Errorf("snapshot is required: %w", ErrUsage)
+	}
+	bc := newBackupContext(logger, cfg.Verbose)
+	targets, err := selectTargets(cfg, opts.Target)
+	if err != nil {
+		return nil, err
+	}
+	repoKey, err := resolveCommandRepoKey(ctx, cfg, deps, opts.RepoKey, bc)
+	if err != nil {
+		return nil, err
+	}
+
+	stores, _, closeStores := openTargetStores(ctx, deps, targets, bc)
+	defer closeStores()
+	for i, target := range targets {
+		if stores[i] == nil {
+			continue
+		}
+		snaps, err := listRemoteSnapshots(ctx, stores[i], repoKey)
+		if err != nil {
+			bc.warnf("[remote:%s] list: %v", target.Name, err)
+			continue
+		}
+		if rs := matchRemoteSnapshot(snaps, id); rs != nil {
+			return pullSnapshot(ctx, cfg, deps, stores[i], target, repoKey, rs, bc)
+		}
+	}
+	return nil, fmt.Errorf("snapshot %q of %s not found on any target: %w", id, repoKey, ErrUsage)
+}
+
+func validateRemoteDependencies(deps *Dependencies, locking bool) error {
+	if deps == nil || deps.FileSystem == nil {
+		return fmt.Errorf("filesystem adapter not available: %w", ErrCritical)
+	}
+	if deps.Storage == nil {
+		return fmt.Errorf("storage adapter not available: %w", ErrCritical)
+	}
+	if locking && (deps.Lock == nil || deps.Process == nil) {
+		return fmt.Errorf("lock adapter not available: %w", ErrCritical)
+	}
+	return nil
+}
+
+// selectTargets returns the configured targets, or only the one called name.
+func selectTargets(cfg *Config, name string) ([]TargetSettings, error) {
+	if len(cfg.Targets) == 0 {
+		return nil, fmt.Errorf("no [[targets]] configured in config.toml: %w", ErrUsage)
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return cfg.Targets, nil
+	}
+	for _, target := range cfg.Targets {
+		if target.Name == name {
+			return []TargetSettings{target}, nil
+		}
+	}
+	return nil, fmt.Errorf("target %q is not configured: %w", name, ErrUsage)
+}
+
+func remoteRepoKeys(
+	ctx context.Context,
+	cfg *Config,
+	opts RemoteOptions,
+	deps *Dependencies,
+	bc *backupContext,
+) ([]string, error) {
+	if strings.TrimSpace(cfg.BackupDir) == "" {
+		return nil, fmt.Errorf("backup.base_dir is not configured: %w", ErrUsage)
+	}
+	if !opts.AllRepos {
+		key, err := resolveCommandRepoKey(ctx, cfg, deps, opts.RepoKey, bc)
+		if err != nil {
+			return nil, err
+		}
+		return []string{key}, nil
+	}
+	return discoverRepoKeys(ctx, deps, cfg.BackupDir)
+}
+
+// openTargetStores opens every target; a target that fails to open is warned about and left
+// nil, with the reason at the same index of the returned errors.
+func openTargetStores(
+	ctx context.Context,
+	deps *Dependencies,
+	targets []TargetSettings,
+	bc *backupContext,
+) ([]SnapshotStore, []error, func()) {
+	stores := make([]SnapshotStore, len(targets))
+	errs := make([]error, len(targets))
+	for i, target := range targets {
+		store, err := deps.Storage.Open(ctx, target)
+		if err != nil {
+			errs[i] = err
+			if errors.Is(err, ErrTargetUnavailable) {
+				bc.warnf("[remote:%s] %v; pending snapshots are queued for the next push", target.Name, err)
+			} else {
+				bc.warnf("[remote:%s] open: %v", target.Name, err)
+			}
+			continue
+		}
+		stores[i] = store
+	}
+	return stores, errs, func() {
+		for _, store := range stores {
+			if store != nil {
+				_ = store.Close()
+			}
+		}
+	}
+}
+
+// pushRepo uploads the pending snapshots of one repository to every target while holding the
+// repository's remote lock. Snapshots completed during the push are picked up by another round.
+func pushRepo(
+	ctx context.Context,
+	cfg *Config,
+	deps *Dependencies,
+	targets []TargetSettings,
+	stores []SnapshotStore,
+	openErrs []error,
+	repoKey string,
+	bc *backupContext,
+) []RemotePushResult {
+	results := make([]RemotePushResult, len(targets))
+	for i, target := range targets {
+		results[i] = RemotePushResult{Target: target.Name, RepoKey: repoKey}
+		switch {
+		case stores[i] != nil:
+		case errors.Is(openErrs[i], ErrTargetUnavailable):
+			results[i].Unavailable = true
+		default:
+			results[i].Err = fmt.Errorf("open: %w", openErrs[i])
+		}
+	}
+	repoDir := deps.FileSystem.Join(cfg.BackupDir, repoKey)
+	lockPath := deps.FileSystem.Join(repoDir, remoteLockName)
+	lockInfo := LockInfo{PID: deps.Process.GetPID(), StartTime: time.Now(), BackupDir: cfg.BackupDir}
+	if err := deps.Lock.AcquireLock(ctx, lockPath, lockInfo); err != nil {
+		locked := strings.Contains(err.Error(), "lock is held")
+		for i := range results {
+			if locked {
+				results[i].Locked = true
+			} else if results[i].Err == nil {
+				results[i].Err = fmt.Errorf("acquire remote lock: %w", err)
+			}
+		}
+		return results
+	}
+	defer func() { _ = deps.Lock.ReleaseLock(ctx, lockPath) }()
+
+	state := readRemoteState(ctx, deps, repoDir)
+	for i, target := range targets {
+		res := &results[i]
+		st := remoteTargetState{Newest: state[target.Name].Newest, PushedAt: time.Now(), Unavailable: res.Unavailable}
+		if stores[i] != nil {
+			var newest string
+			res.Uploaded, newest, res.Err = pushPendingSnapshots(ctx, deps, stores[i], target, repoKey, repoDir, bc)
+			if res.Err == nil {
+				res.Removed, res.Err = rotateRemote(ctx, deps, stores[i], target, repoKey, repoDir, bc)
+			}
+			if newest != "" {
+				st.Newest = newest
+			}
+			if res.Err != nil {
+				bc.warnf("[remote:%s] %s: %v", target.Name, repoKey, res.Err)
+			}
+		}
+		if res.Err != nil {
+			st.Error = res.Err.Error()
+		}
+		state[target.Name] = st
+	}
+	if err := writeRemoteState(ctx, deps, repoDir, state); err != nil {
+		bc.warnf("[remote] %s: %v", repoKey, err)
+	}
+	return results
+}
+
+// pushPendingSnapshots uploads the pending snapshots oldest first and stops at the first
+// failure, so the complete snapshots on a target are always a prefix of the local history
+// (minus remote rotation). It returns the uploaded IDs and the newest complete remote ID.
+func pushPendingSnapshots(
+	ctx context.Context,
+	deps *Dependencies,
+	store SnapshotStore,
+	target TargetSettings,
+	repoKey,
+	repoDir string,
+	bc *backupContext,
+) ([]string, string, error) {
+	var (
+		uploaded []string
+		newest   string
+	)
+	for round := 0; round < remotePushMaxRounds; round++ {
+		local, err := listSnapshots(ctx, deps, repoDir)
+		if err != nil {
+			if deps.FileSystem.IsNotExist(err) {
+				return uploaded, newest, nil
+			}
+			return uploaded, newest, fmt.Errorf("list local snapshots: %w", err)
+		}
+		remote, err := listRemoteSnapshots(ctx, store, repoKey)
+		if err != nil {
+			return uploaded, newest, err
+		}
+		byID := make(map[string]*remoteSnapshot, len(remote))
+		for _, rs := range remote {
+			byID[rs.ID] = rs
+		}
+		newest = newestCompleteID(remote)
+		pending := pendingSnapshots(deps.FileSystem, repoDir, local, newest, target.KeepCount)
+		if len(pending) == 0 {
+			break
+		}
+		for _, s := range pending {
+			if ctx.Err() != nil {
+				return uploaded, newest, ctx.Err()
+			}
+			id := snapshotID(deps.FileSystem, repoDir, s)
+			bc.logf("[remote:%s] upload %s/%s", target.Name, repoKey, id)
+			if err := uploadSnapshot(ctx, deps, store, target, repoKey+"/"+id+"/", s, byID[id]); err != nil {
+				return uploaded, newest, fmt.Errorf("upload %s: %w", id, err)
+			}
+			uploaded = append(uploaded, id)
+			newest = id
+		}
+	}
+	return uploaded, newest, nil
+}
+
+// pendingSnapshots returns the local snapshots newer than the newest complete snapshot on a
+// target. With keep_count only the newest keepCount of them are returned, since remote
+// rotation would delete the others right away.
+func pendingSnapshots(fs FileSystemPort, repoDir string, local []snapshot, newest string, keepCount int) []snapshot {
+	var pending []snapshot
+	for _, s := range local {
+		if !s.Quarantined && snapshotID(fs, repoDir, s) > newest {
+			pending = append(pending, s)
+		}
+	}
+	if keepCount > 0 && len(pending) > keepCount {
+		pending = pending[len(pending)-keepCount:]
+	}
+	return pending
+}
+
+func newestCompleteID(snaps []*remoteSnapshot) string {
+	newest := ""
+	for _, rs := range snaps {
+		if rs.Done && rs.ID > newest {
+			newest = rs.ID
+		}
+	}
+	return newest
+}
+
+// uploadSnapshot stores the snapshot under prefix using the same protocol as local backups:
+// a .partial object first, the content, and .done only after every object size was checked.
+func uploadSnapshot(
+	ctx context.Context,
+	deps *Dependencies,
+	store SnapshotStore,
+	target TargetSettings,
+	prefix string,
+	s snapshot,
+	existing *remoteSnapshot,
+) error {
+	have := map[string]int64{}
+	if existing != nil {
+		have = existing.Objects
+	}
+	// Markers are empty objects; the local .done marker is an empty file as well.
+	if _, ok := have[".partial"]; !ok {
+		if err := store.Put(ctx, prefix+".partial", s.Done); err != nil {
+			return fmt.Errorf("mark partial: %w", err)
+		}
+	}
+	layout, files, err := scanSnapshotForUpload(ctx, deps, s.TimeDir)
+	if err != nil {
+		return err
+	}
+	var todo []remoteFile
+	for _, f := range files {
+		if size, ok := have[f.rel]; !ok || size != f.size {
+			todo = append(todo, f)
+		}
+	}
+	err = forEachParallel(ctx, len(todo), func(i int) error {
+		if err := store.Put(ctx, prefix+todo[i].rel, todo[i].path); err != nil {
+			return fmt.Errorf("put %s: %w", todo[i].rel, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := putRemoteLayout(ctx, deps, store, prefix, layout); err != nil {
+		return err
+	}
+
+	stored, err := store.List(ctx, prefix)
+	if err != nil {
+		return fmt.Errorf("list uploaded objects: %w", err)
+	}
+	sizes := make(map[string]int64, len(stored))
+	for _, obj := range stored {
+		sizes[strings.TrimPrefix(obj.Key, prefix)] = obj.Size
+	}
+	for _, f := range files {
+		if size, ok := sizes[f.rel]; !ok || size != f.size {
+			return fmt.Errorf("verify %s: stored %d bytes, expected %d", f.rel, size, f.size)
+		}
+	}
+	if target.Verify == TargetVerifyHash {
+		if err := verifyRemoteHashes(ctx, deps, store, prefix, files); err != nil {
+			return err
+		}
+	}
+	if err := store.Put(ctx, prefix+".done", s.Done); err != nil {
+		return fmt.Errorf("mark done: %w", err)
+	}
+	if err := store.Delete(ctx, prefix+".partial"); err != nil {
+		return fmt.Errorf("remove partial marker: %w", err)
+	}
+	return nil
+}
+
+// scanSnapshotForUpload lists the regular files of a snapshot and records its directories,
+// symlinks and file modes. Local markers (.done, .pinned, ...) are not uploaded.
+func scanSnapshotForUpload(
+	ctx context.Context,
+	deps *Dependencies,
+	snapDir string,
+) (remoteLayout, []remoteFile, error) {
+	layout := remoteLayout{Dirs: []string{}, Symlinks: map[string]string{}, Modes: map[string]int{}}
+	var files []remoteFile
+	err := deps.FileSystem.Walk(ctx, snapDir, func(path string, info FileInfo, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			return err
+		}
+		rel, err := deps.FileSystem.Rel(snapDir, path)
+		if err != nil {
+			return err
+		}
+		rel = archiveName(deps.FileSystem, rel)
+		if rel == "." || isRemoteSkipped(rel) {
+			return nil
+		}
+		switch {
+		case info.IsDir():
+			layout.Dirs = append(layout.Dirs, rel)
+		case info.IsSymlink():
+			target, err := deps.FileSystem.Readlink(ctx, path)
+			if err != nil {
+				return err
+			}
+			layout.Symlinks[rel] = target
+		case info.IsRegular():
+			files = append(files, remoteFile{rel: rel, path: path, size: info.Size()})
+			if perm := info.Mode() & 0o777; perm != 0o644 {
+				layout.Modes[rel] = perm
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return remoteLayout{}, nil, fmt.Errorf("scan %s: %w", snapDir, err)
+	}
+	return layout, files, nil
+}
+
+// verifyRemoteHashes reads every stored file back and compares its hash with the local file.
+func verifyRemoteHashes(
+	ctx context.Context,
+	deps *Dependencies,
+	store SnapshotStore,
+	prefix string,
+	files []remoteFile,
+) error {
+	tmpDir, err := deps.FileSystem.TempDir(ctx, "", "devback-verify-*")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	defer func() { _ = deps.FileSystem.RemoveAll(ctx, tmpDir) }()
+	return forEachParallel(ctx, len(files), func(i int) error {
+		f := files[i]
+		copyPath := deps.FileSystem.Join(tmpDir, strconv.Itoa(i))
+		defer func() { _ = deps.FileSystem.RemoveAll(ctx, copyPath) }()
+		if err := store.Get(ctx, prefix+f.rel, copyPath); err != nil {
+			return fmt.Errorf("verify %s: %w", f.rel, err)
+		}
+		want, err := deps.FileSystem.HashFile(ctx, f.path)
+		if err != nil {
+			return fmt.Errorf("hash %s: %w", f.rel, err)
+		}
+		got, err := deps.FileSystem.HashFile(ctx, copyPath)
+		if err != nil {
+			return fmt.Errorf("hash stored %s: %w", f.rel, err)
+		}
+		if got != want {
+			return fmt.Errorf("verify %s: stored copy differs", f.rel)
+		}
+		return nil
+	})
+}
+
+// isRemoteSkipped reports whether rel is local snapshot state that is not uploaded.
+func isRemoteSkipped(rel string) bool {
+	switch rel {
+	case ".done", ".partial", ".reserve", remoteLayoutObject, pinnedMarker, quarantineMarker:
+		return true
+	default:
+		return strings.HasPrefix(rel, ".reserve/")
+	}
+}
+
+func putRemoteLayout(
+	ctx context.Context,
+	deps *Dependencies,
+	store SnapshotStore,
+	prefix string,
+	layout remoteLayout,
+) error {
+	data, err := json.MarshalIndent(layout, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode layout: %w", err)
+	}
+	tmpDir, err := deps.FileSystem.TempDir(ctx, "", "devback-remote-*")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	defer func() { _ = deps.FileSystem.RemoveAll(ctx, tmpDir) }()
+	path := deps.FileSystem.Join(tmpDir, remoteLayoutObject)
+	if err := deps.FileSystem.WriteFile(ctx, path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write layout: %w", err)
+	}
+	if err := store.Put(ctx, prefix+remoteLayoutObject, path); err != nil {
+		return fmt.Errorf("put layout: %w", err)
+	}
+	return nil
+}
+
+// listRemoteSnapshots groups the objects under repoKey by snapshot ID, oldest first.
+func listRemoteSnapshots(ctx context.Context, store SnapshotStore, repoKey string) ([]*remoteSnapshot, error) {
+	objects, err := store.List(ctx, repoKey+"/")
+	if err != nil {
+		return nil, fmt.Errorf("list remote snapshots: %w", err)
+	}
+	byID := map[string]*remoteSnapshot{}
+	for _, obj := range objects {
+		parts := strings.SplitN(strings.TrimPrefix(obj.Key, repoKey+"/"), "/", 3)
+		if len(parts) != 3 || !matchDateDir(parts[0]) || !matchTimeDir(parts[1]) || parts[2] == "" {
+			continue
+		}
+		id := parts[0] + "/" + parts[1]
+		rs := byID[id]
+		if rs == nil {
+			rs = &remoteSnapshot{ID: id, Objects: map[string]int64{}}
+			byID[id] = rs
+		}
+		rs.Objects[parts[2]] = obj.Size
+		if parts[2] == ".done" {
+			rs.Done = true
+			rs.DoneAt = obj.ModTime
+		}
+	}
+	snaps := make([]*remoteSnapshot, 0, len(byID))
+	for _, rs := range byID {
+		snaps = append(snaps, rs)
+	}
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].ID < snaps[j].ID })
+	return snaps, nil
+}
+
+// matchRemoteSnapshot finds a complete snapshot by "<date>/<time>" or by its time directory.
+func matchRemoteSnapshot(snaps []*remoteSnapshot, id string) *remoteSnapshot {
+	for _, rs := range snaps {
+		if !rs.Done {
+			continue
+		}
+		if rs.ID == id || (!strings.Contains(id, "/") && strings.HasSuffix(rs.ID, "/"+id)) {
+			return rs
+		}
+	}
+	return nil
+}
+
+func localSnapshotIDs(ctx context.Context, deps *Dependencies, repoDir string) map[string]bool {
+	ids := map[string]bool{}
+	snaps, err := listSnapshots(ctx, deps, repoDir)
+	if err != nil {
+		return ids
+	}
+	for _, s := range snaps {
+		ids[snapshotID(deps.FileSystem, repoDir, s)] = true
+	}
+	return ids
+}
+
+// rotateRemote applies the target's keep_count and keep_days to its complete snapshots (the
+// newest one is always kept) and removes unfinished uploads that will not be resumed: their
+// local snapshot is gone or older than the newest complete one.
+func rotateRemote(
+	ctx context.Context,
+	deps *Dependencies,
+	store SnapshotStore,
+	target TargetSettings,
+	repoKey,
+	repoDir string,
+	bc *backupContext,
+) ([]string, error) {
+	snaps, err := listRemoteSnapshots(ctx, store, repoKey)
+	if err != nil {
+		return nil, err
+	}
+	local := localSnapshotIDs(ctx, deps, repoDir)
+	newest := newestCompleteID(snaps)
+	var complete []*remoteSnapshot
+	var removed []string
+	for _, rs := range snaps {
+		if rs.Done {
+			complete = append(complete, rs)
+			continue
+		}
+		if !local[rs.ID] || rs.ID < newest {
+			bc.logf("[remote:%s] remove unfinished upload %s/%s", target.Name, repoKey, rs.ID)
+			if err := deleteRemoteSnapshot(ctx, store, repoKey, rs); err != nil {
+				return removed, err
+			}
+			removed = append(removed, rs.ID)
+		}
+	}
+
+	now := time.Now()
+	remaining := len(complete)
+	for i := 0; i < len(complete)-1; i++ {
+		rs := complete[i]
+		var reason string
+		switch {
+		case target.KeepDays > 0 && now.Sub(rs.DoneAt) > time.Duration(target.KeepDays)*24*time.Hour:
+			reason = fmt.Sprintf("older than %dd", target.KeepDays)
+		case target.KeepCount > 0 && remaining > target.KeepCount:
+			reason = fmt.Sprintf("exceeds %d", target.KeepCount)
+		default:
+			continue
+		}
+		bc.logf("[remote:%s] remove %s/%s (%s)", target.Name, repoKey, rs.ID, reason)
+		if err := deleteRemoteSnapshot(ctx, store, repoKey, rs); err != nil {
+			return removed, err
+		}
+		removed = append(removed, rs.ID)
+		remaining--
+	}
+	return removed, nil
+}
+
+// deleteRemoteSnapshot removes .done first so that a snapshot never looks complete while it
+// is only partly deleted.
+func deleteRemoteSnapshot(ctx context.Context, store SnapshotStore, repoKey string, rs *remoteSnapshot) error {
+	prefix := repoKey + "/" + rs.ID + "/"
+	if rs.Done {
+		if err := store.Delete(ctx, prefix+".done"); err != nil {
+			return fmt.Errorf("delete %s: %w", rs.ID, err)
+		}
+	}
+	for key := range rs.Objects {
+		if key == ".done" {
+			continue
+		}
+		if err := store.Delete(ctx, prefix+key); err != nil {
+			return fmt.Errorf("delete %s: %w", rs.ID, err)
+		}
+	}
+	return nil
+}
+
+// remoteTargetState is what the last push recorded about one target in <repoDir>/.remote-state.json,
+// so that status can report the lag of a target without connecting to it.
+type remoteTargetState struct {
+	Newest      string    `json:"newest,omitempty"`
+	PushedAt    time.Time `json:"pushed_at"`
+	Unavailable bool      `json:"unavailable,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+func readRemoteState(ctx context.Context, deps *Dependencies, repoDir string) map[string]remoteTargetState {
+	state := map[string]remoteTargetState{}
+	data, err := deps.FileSystem.ReadFile(ctx, deps.FileSystem.Join(repoDir, remoteStateName))
+	if err != nil {
+		return state
+	}
+	if err := json.Unmarshal(data, &state); err != nil || state == nil {
+		return map[string]remoteTargetState{}
+	}
+	return state
+}
+
+func writeRemoteState(
+	ctx context.Context,
+	deps *Dependencies,
+	repoDir string,
+	state map[string]remoteTargetState,
+) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode remote state: %w", err)
+	}
+	path := deps.FileSystem.Join(repoDir, remoteStateName)
+	tmp := path + ".tmp"
+	if err := deps.FileSystem.WriteFile(ctx, tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write remote state: %w", err)
+	}
+	if err := deps.FileSystem.Move(ctx, tmp, path); err != nil {
+		_ = deps.FileSystem.RemoveAll(ctx, tmp)
+		return fmt.Errorf("write remote state: %w", err)
+	}
+	return nil
+}
+
+// remoteTargetStatus reports, per configured target, how many local snapshots of repoDir the
+// next push would upload, based on the state recorded by the last push.
+func remoteTargetStatus(
+	ctx context.Context,
+	deps *Dependencies,
+	repoDir string,
+	targets []TargetSettings,
+) []StatusTarget {
+	if len(targets) == 0 {
+		return nil
+	}
+	local, err := listSnapshots(ctx, deps, repoDir)
+	if err != nil {
+		local = nil
+	}
+	state := readRemoteState(ctx, deps, repoDir)
+	result := make([]StatusTarget, 0, len(targets))
+	for _, target := range targets {
+		st, pushed := state[target.Name]
+		result = append(result, StatusTarget{
+			Name:        target.Name,
+			Type:        target.Type,
+			Pending:     len(pendingSnapshots(deps.FileSystem, repoDir, local, st.Newest, target.KeepCount)),
+			Pushed:      pushed,
+			LastPush:    st.PushedAt,
+			Unavailable: st.Unavailable,
+			Error:       st.Error,
+		})
+	}
+	return result
+}
+
+// pullSnapshot downloads rs into <base_dir>/<repoKey>/<date>/<time> under the repository lock,
+// following the local .partial/.done protocol.
+func pullSnapshot(
+	ctx context.Context,
+	cfg *Config,
+	deps *Dependencies,
+	store SnapshotStore,
+	target TargetSettings,
+	repoKey string,
+	rs *remoteSnapshot,
+	bc *backupContext,
+) (*RemotePullResult, error) {
+	repoDir := deps.FileSystem.Join(cfg.BackupDir, repoKey)
+	dateID, timeID, _ := strings.Cut(rs.ID, "/")
+	snapDir := deps.FileSystem.Join(repoDir, dateID, timeID)
+	if exists, err := pathExists(ctx, deps.FileSystem, snapDir); err != nil {
+		return nil, fmt.Errorf("check %s: %w", snapDir, ErrCritical)
+	} else if exists {
+		return nil, fmt.Errorf("snapshot %s already exists locally at %s: %w", rs.ID, snapDir, ErrUsage)
+	}
+	if err := deps.FileSystem.CreateDir(ctx, deps.FileSystem.Join(repoDir, dateID), 0o755); err != nil {
+		return nil, fmt.Errorf("create %s: %w", repoDir, ErrCritical)
+	}
+	_, releaseLock, err := acquireBackupLock(ctx, deps, repoDir, "", cfg, bc.logger)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseLock()
+	if err := deps.FileSystem.CreateDirExclusive(ctx, snapDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create %s: %w", snapDir, ErrCritical)
+	}
+
+	bc.logf("[remote:%s] pull %s/%s -> %s", target.Name, repoKey, rs.ID, snapDir)
+	files, err := downloadSnapshot(ctx, deps, store, repoKey+"/"+rs.ID+"/", rs, snapDir)
+	if err != nil {
+		_ = deps.FileSystem.RemoveAll(ctx, snapDir)
+		if ctx.Err() != nil {
+			return nil, ErrInterrupted
+		}
+		bc.warnf("[remote:%s] pull %s: %v", target.Name, rs.ID, err)
+		return nil, fmt.Errorf("pull %s from %s: %w", rs.ID, target.Name, ErrCritical)
+	}
+	return &RemotePullResult{
+		Target: target.Name, RepoKey: repoKey, SnapshotID: rs.ID, SnapshotDir: snapDir, Files: files,
+	}, nil
+}
+
+func downloadSnapshot(
+	ctx context.Context,
+	deps *Dependencies,
+	store SnapshotStore,
+	prefix string,
+	rs *remoteSnapshot,
+	snapDir string,
+) (int, error) {
+	fs := deps.FileSystem
+	partial := fs.Join(snapDir, ".partial")
+	if err := fs.WriteFile(ctx, partial, []byte{}, 0o644); err != nil {
+		return 0, fmt.Errorf("mark partial: %w", err)
+	}
+	layout, err := getRemoteLayout(ctx, deps, store, prefix, snapDir)
+	if err != nil {
+		return 0, err
+	}
+	for _, dir := range layout.Dirs {
+		if err := fs.CreateDir(ctx, remoteLocalPath(fs, snapDir, dir), 0o755); err != nil {
+			return 0, fmt.Errorf("create %s: %w", dir, err)
+		}
+	}
+
+	var rels []string
+	for rel := range rs.Objects {
+		if !isRemoteSkipped(rel) {
+			rels = append(rels, rel)
+		}
+	}
+	sort.Strings(rels)
+	err = forEachParallel(ctx, len(rels), func(i int) error {
+		dst := remoteLocalPath(fs, snapDir, rels[i])
+		if err := fs.CreateDir(ctx, fs.Dir(dst), 0o755); err != nil {
+			return fmt.Errorf("create %s: %w", fs.Dir(dst), err)
+		}
+		if err := store.Get(ctx, prefix+rels[i], dst); err != nil {
+			return fmt.Errorf("get %s: %w", rels[i], err)
+		}
+		info, err := fs.Stat(ctx, dst)
+		if err != nil {
+			return fmt.Errorf("stat %s: %w", rels[i], err)
+		}
+		if info.Size() != rs.Objects[rels[i]] {
+			return fmt.Errorf("verify %s: got %d bytes, expected %d", rels[i], info.Size(), rs.Objects[rels[i]])
+		}
+		if perm, ok := layout.Modes[rels[i]]; ok {
+			return fs.Chmod(ctx, dst, perm)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	for rel, target := range layout.Symlinks {
+		link := remoteLocalPath(fs, snapDir, rel)
+		if err := fs.CreateDir(ctx, fs.Dir(link), 0o755); err != nil {
+			return 0, fmt.Errorf("create %s: %w", fs.Dir(link), err)
+		}
+		if err := fs.Symlink(ctx, target, link); err != nil {
+			return 0, fmt.Errorf("symlink %s: %w", rel, err)
+		}
+	}
+
+	_ = fs.RemoveAll(ctx, partial)
+	if err := fs.WriteFile(ctx, fs.Join(snapDir, ".done"), []byte{}, 0o644); err != nil {
+		return 0, fmt.Errorf("mark done: %w", err)
+	}
+	return len(rels), nil
+}
+
+// getRemoteLayout reads the snapshot's layout object; snapshots without one get an empty layout.
+func getRemoteLayout(
+	ctx context.Context,
+	deps *Dependencies,
+	store SnapshotStore,
+	prefix,
+	snapDir string,
+) (remoteLayout, error) {
+	var layout remoteLayout
+	path := deps.FileSystem.Join(snapDir, remoteLayoutObject)
+	if err := store.Get(ctx, prefix+remoteLayoutObject, path); err != nil {
+		return layout, fmt.Errorf("get layout: %w", err)
+	}
+	defer func() { _ = deps.FileSystem.RemoveAll(ctx, path) }()
+	data, err := deps.FileSystem.ReadFile(ctx, path)
+	if err != nil {
+		return layout, fmt.Errorf("read layout: %w", err)
+	}
+	if err := json.Unmarshal(data, &layout); err != nil {
+		return layout, fmt.Errorf("parse layout: %w", err)
+	}
+	for _, rel := range layout.Dirs {
+		if !isSafeRemoteRel(rel) {
+			return layout, fmt.Errorf("layout: unsafe path %q", rel)
+		}
+	}
+	for rel := range layout.Symlinks {
+		if !isSafeRemoteRel(rel) {
+			return layout, fmt.Errorf("layout: unsafe path %q", rel)
+		}
+	}
+	return layout, nil
+}
+
+// isSafeRemoteRel rejects object keys that would escape the snapshot directory.
+func isSafeRemoteRel(rel string) bool {
+	if rel == "" || strings.HasPrefix(rel, "/") || strings.Contains(rel, "\\") {
+		return false
+	}
+	for _, seg := range strings.Split(rel, "/") {
+		if seg == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+func remoteLocalPath(fs FileSystemPort, snapDir, rel string) string {
+	return fs.Join(append([]string{snapDir}, strings.Split(rel, "/")...)...)
+}
+
+// forEachParallel calls fn for 0..n-1 on up to remoteTransferJobs goroutines and returns
+// the first error; no new calls start after an error.
+func forEachParallel(ctx context.Context, n int, fn func(i int) error) error {
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	jobs := make(chan int)
+	for w := 0; w < remoteTransferJobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := fn(i); err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed || ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return firstErr
+}
+
+// startRemotePush hands the snapshots of repoDir to a background `devback remote push`, so a
+// slow upload never holds up the hook. Whatever it misses is picked up by the next push.
+func startRemotePush(cfg *Config, deps *Dependencies, repoDir string, bc *backupContext) {
+	if len(cfg.Targets) == 0 || deps.Process == nil {
+		return
+	}
+	rel, err := deps.FileSystem.Rel(cfg.BackupDir, repoDir)
+	if err != nil {
+		bc.warnf("remote push: %v", err)
+		return
+	}
+	key := archiveName(deps.FileSystem, rel)
+	args := []string{"remote", "push", "--repo-key", key, "--base-dir", cfg.BackupDir}
+	if err := deps.Process.StartBackground(args); err != nil {
+		bc.warnf("start remote push: %v", err)
+		return
+	}
+	bc.vlogf("[remote] upload to %d target(s) started in the background", len(cfg.Targets))
+}
+
+// FormatRemotePush renders a push report as a short per-target summary.
+func FormatRemotePush(report *RemotePushReport) string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "TARGET\tREPOSITORY\tUPLOADED\tREMOVED\tSTATUS")
+	for _, res := range report.Results {
+		status := "ok"
+		switch {
+		case res.Err != nil:
+			status = "failed: " + res.Err.Error()
+		case res.Locked:
+			status = "locked"
+		case res.Unavailable:
+			status = "unavailable, queued"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\n",
+			res.Target, res.RepoKey, len(res.Uploaded), len(res.Removed), status)
+	}
+	_ = tw.Flush()
+	return b.String()
+}
+
+// FormatRemoteList renders the snapshots stored on each target.
+func FormatRemoteList(report *RemoteListReport, now time.Time) string {
+	var b strings.Builder
+	for i, repo := range report.Repos {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s: %s\n", repo.Target, repo.RepoKey)
+		if repo.Error != "" {
+			fmt.Fprintf(&b, "  error: %s\n", repo.Error)
+			continue
+		}
+		if len(repo.Snapshots) == 0 {
+			b.WriteString("  no snapshots\n")
+			continue
+		}
+		tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "  SNAPSHOT\tSIZE\tUPLOADED\tLOCAL\tSTATE")
+		for _, s := range repo.Snapshots {
+			state, uploaded := "complete", formatAge(now, s.UploadedAt)
+			if !s.Complete {
+				state, uploaded = "unfinished", "-"
+			}
+			local := "no"
+			if s.Local {
+				local = "yes"
+			}
+			_, _ = fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n",
+				s.ID, humanKB((s.SizeBytes+1023)/1024), uploaded, local, state)
+		}
+		_ = tw.Flush()
+	}
+	return b.String()
+}
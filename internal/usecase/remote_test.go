@@ -0,0 +1,357 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memStore is an in-memory SnapshotStore; failPut makes Put fail for keys with that suffix
+// and corrupt makes Get return damaged content of the stored size.
+type memStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	modTime map[string]time.Time
+	puts    []string
+	failPut string
+	corrupt bool
+}
+
+func newMemStore() *memStore {
+	return &memStore{objects: map[string][]byte{}, modTime: map[string]time.Time{}}
+}
+
+func (m *memStore) List(ctx context.Context, prefix string) ([]StoredObject, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []StoredObject
+	for key, data := range m.objects {
+		if strings.HasPrefix(key, prefix) {
+			out = append(out, StoredObject{Key: key, Size: int64(len(data)), ModTime: m.modTime[key]})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out, nil
+}
+
+func (m *memStore) Put(ctx context.Context, key, src string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failPut != "" && strings.HasSuffix(key, m.failPut) {
+		return errors.New("connection reset")
+	}
+	// #nosec G304 -- test paths are controlled by the test harness.
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	m.objects[key] = data
+	m.modTime[key] = time.Now()
+	m.puts = append(m.puts, key)
+	return nil
+}
+
+func (m *memStore) Get(ctx context.Context, key, dst string) error {
+	m.mu.Lock()
+	data, ok := m.objects[key]
+	corrupt := m.corrupt
+	m.mu.Unlock()
+	if !ok {
+		return errors.New("not found")
+	}
+	if corrupt && len(data) > 0 {
+		data = append([]byte{data[0] ^ 0xff}, data[1:]...)
+	}
+	return os.WriteFile(dst, data, 0o600)
+}
+
+func (m *memStore) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+func (m *memStore) Close() error { return nil }
+
+// memStorage opens stores by target name; targets listed in down behave like an unmounted disk.
+type memStorage struct {
+	stores map[string]*memStore
+	down   map[string]bool
+}
+
+func (s *memStorage) Open(ctx context.Context, target TargetSettings) (SnapshotStore, error) {
+	if s.down[target.Name] {
+		return nil, fmt.Errorf("%s not mounted: %w", target.Name, ErrTargetUnavailable)
+	}
+	store, ok := s.stores[target.Name]
+	if !ok {
+		return nil, errors.New("unreachable")
+	}
+	return store, nil
+}
+
+func newRemoteFixture(t *testing.T) (*Config, *Dependencies, string, *memStore) {
+	t.Helper()
+	cfg, deps, repoKey := newRestoreFixture(t)
+	store := newMemStore()
+	deps.Storage = &memStorage{stores: map[string]*memStore{"offsite": store}}
+	cfg.Targets = []TargetSettings{{Name: "offsite", Type: TargetTypeS3, Bucket: "b"}}
+	return cfg, deps, repoKey, store
+}
+
+func TestRemotePush_UploadsCompletedSnapshots(t *testing.T) {
+	ctx := context.Background()
+	cfg, deps, repoKey, store := newRemoteFixture(t)
+	repoDir := filepath.Join(cfg.BackupDir, repoKey)
+	snapDir := latestSnapshotDir(t, deps, repoDir)
+	id := snapshotID(deps.FileSystem, repoDir, snapshot{TimeDir: snapDir})
+	makeDoneSnapshots(t, repoDir, "2000-01-01/000000")
+	if err := os.WriteFile(filepath.Join(repoDir, "2000-01-01", "000000", pinnedMarker), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	logger := newTestBackupContext(false).logger
+
+	report, err := RemotePush(ctx, cfg, RemoteOptions{RepoKey: repoKey}, deps, logger)
+	if err != nil {
+		t.Fatalf("push failed: %v", err)
+	}
+	if len(report.Results) != 1 || len(report.Results[0].Uploaded) != 2 {
+		t.Fatalf("expected two uploads: %+v", report.Results)
+	}
+	snaps, err := listRemoteSnapshots(ctx, store, repoKey)
+	if err != nil || len(snaps) != 2 || !snaps[0].Done || !snaps[1].Done {
+		t.Fatalf("expected two complete remote snapshots: %+v (%v)", snaps, err)
+	}
+	latest := snaps[1]
+	if latest.ID != id {
+		t.Fatalf("unexpected remote id %q, want %q", latest.ID, id)
+	}
+	for _, marker := range []string{".partial", pinnedMarker} {
+		if _, ok := snaps[0].Objects[marker]; ok {
+			t.Fatalf("marker %s must not be stored remotely", marker)
+		}
+	}
+	if _, ok := latest.Objects[remoteLayoutObject]; !ok {
+		t.Fatal("expected layout object")
+	}
+
+	store.puts = nil
+	if _, err := RemotePush(ctx, cfg, RemoteOptions{RepoKey: repoKey}, deps, logger); err != nil {
+		t.Fatalf("second push failed: %v", err)
+	}
+	if len(store.puts) != 0 {
+		t.Fatalf("nothing must be uploaded twice: %v", store.puts)
+	}
+}
+
+func TestRemotePush_ResumesInterruptedUpload(t *testing.T) {
+	ctx := context.Background()
+	cfg, deps, repoKey, store := newRemoteFixture(t)
+	logger := newTestBackupContext(false).logger
+
+	store.failPut = remoteLayoutObject
+	report, err := RemotePush(ctx, cfg, RemoteOptions{RepoKey: repoKey}, deps, logger)
+	if !errors.Is(err, ErrCritical) || report.Results[0].Err == nil {
+		t.Fatalf("expected failed push, got %v", err)
+	}
+	snaps, _ := listRemoteSnapshots(ctx, store, repoKey)
+	if len(snaps) != 1 || snaps[0].Done {
+		t.Fatalf("expected one unfinished upload: %+v", snaps)
+	}
+	if _, ok := snaps[0].Objects[".partial"]; !ok {
+		t.Fatal("unfinished upload must keep its .partial marker")
+	}
+	if list, err := RemoteList(ctx, cfg, RemoteOptions{RepoKey: repoKey}, deps, logger); err != nil ||
+		list.Repos[0].Snapshots[0].Complete {
+		t.Fatalf("list must report the upload as unfinished: %+v (%v)", list, err)
+	}
+
+	store.failPut, store.puts = "", nil
+	if _, err := RemotePush(ctx, cfg, RemoteOptions{RepoKey: repoKey}, deps, logger); err != nil {
+		t.Fatalf("resumed push failed: %v", err)
+	}
+	for _, key := range store.puts {
+		if !strings.HasSuffix(key, remoteLayoutObject) && !strings.HasSuffix(key, ".done") {
+			t.Fatalf("object uploaded again on resume: %s", key)
+		}
+	}
+	snaps, _ = listRemoteSnapshots(ctx, store, repoKey)
+	if len(snaps) != 1 || !snaps[0].Done {
+		t.Fatalf("expected completed upload: %+v", snaps)
+	}
+	if _, ok := snaps[0].Objects[".partial"]; ok {
+		t.Fatal(".partial must be removed once .done is stored")
+	}
+}
+
+func TestRemotePush_AppliesTargetRetention(t *testing.T) {
+	ctx := context.Background()
+	cfg, deps, repoKey, store := newRemoteFixture(t)
+	repoDir := filepath.Join(cfg.BackupDir, repoKey)
+	makeDoneSnapshots(t, repoDir, "2000-01-01/000000", "2000-01-02/000000")
+	cfg.Targets[0].KeepCount = 2
+	logger := newTestBackupContext(false).logger
+
+	report, err := RemotePush(ctx, cfg, RemoteOptions{RepoKey: repoKey}, deps, logger)
+	if err != nil {
+		t.Fatalf("push failed: %v", err)
+	}
+	if got := report.Results[0]; len(got.Uploaded) != 2 || got.Uploaded[0] != "2000-01-02/000000" {
+		t.Fatalf("expected only the two newest snapshots uploaded: %+v", got)
+	}
+
+	store.puts = nil
+	if _, err := RemotePush(ctx, cfg, RemoteOptions{RepoKey: repoKey}, deps, logger); err != nil {
+		t.Fatalf("second push failed: %v", err)
+	}
+	if len(store.puts) != 0 {
+		t.Fatalf("snapshots older than keep_count must not be uploaded later: %v", store.puts)
+	}
+
+	makeDoneSnapshots(t, repoDir, "2999-01-01/000000")
+	report, err = RemotePush(ctx, cfg, RemoteOptions{RepoKey: repoKey}, deps, logger)
+	if err != nil {
+		t.Fatalf("third push failed: %v", err)
+	}
+	if got := report.Results[0].Removed; len(got) != 1 || got[0] != "2000-01-02/000000" {
+		t.Fatalf("expected oldest snapshot removed remotely: %v", got)
+	}
+	snaps, _ := listRemoteSnapshots(ctx, store, repoKey)
+	if len(snaps) != 2 {
+		t.Fatalf("expected two remote snapshots: %+v", snaps)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, "2000-01-02", "000000")); err != nil {
+		t.Fatalf("remote retention must not touch local snapshots: %v", err)
+	}
+}
+
+func TestRemotePush_QueuesWhileTargetUnavailable(t *testing.T) {
+	ctx := context.Background()
+	cfg, deps, repoKey, store := newRemoteFixture(t)
+	repoDir := filepath.Join(cfg.BackupDir, repoKey)
+	storage := deps.Storage.(*memStorage)
+	storage.down = map[string]bool{"offsite": true}
+	logger := newTestBackupContext(false).logger
+
+	report, err := RemotePush(ctx, cfg, RemoteOptions{RepoKey: repoKey}, deps, logger)
+	if err != nil {
+		t.Fatalf("unavailable target must not fail the push: %v", err)
+	}
+	if got := report.Results[0]; !got.Unavailable || got.Err != nil || len(got.Uploaded) != 0 {
+		t.Fatalf("expected queued result: %+v", got)
+	}
+	status := remoteTargetStatus(ctx, deps, repoDir, cfg.Targets)
+	if len(status) != 1 || !status[0].Unavailable || status[0].Pending != 1 {
+		t.Fatalf("expected one pending snapshot on an unavailable target: %+v", status)
+	}
+
+	storage.down = nil
+	report, err = RemotePush(ctx, cfg, RemoteOptions{RepoKey: repoKey}, deps, logger)
+	if err != nil || len(report.Results[0].Uploaded) != 1 {
+		t.Fatalf("expected queued snapshot uploaded once the target is back: %+v (%v)", report.Results, err)
+	}
+	if len(store.objects) == 0 {
+		t.Fatal("expected objects on the target")
+	}
+	status = remoteTargetStatus(ctx, deps, repoDir, cfg.Targets)
+	if status[0].Unavailable || status[0].Pending != 0 || status[0].LastPush.IsZero() {
+		t.Fatalf("expected target up to date: %+v", status)
+	}
+}
+
+func TestRemotePush_HashVerifyDetectsCorruptCopy(t *testing.T) {
+	ctx := context.Background()
+	cfg, deps, repoKey, store := newRemoteFixture(t)
+	cfg.Targets[0].Verify = TargetVerifyHash
+	store.corrupt = true
+	logger := newTestBackupContext(false).logger
+
+	report, err := RemotePush(ctx, cfg, RemoteOptions{RepoKey: repoKey}, deps, logger)
+	if !errors.Is(err, ErrCritical) || report.Results[0].Err == nil {
+		t.Fatalf("expected verification failure, got %v", err)
+	}
+	if snaps, _ := listRemoteSnapshots(ctx, store, repoKey); len(snaps) != 1 || snaps[0].Done {
+		t.Fatalf("a copy that failed verification must not be marked done: %+v", snaps)
+	}
+
+	store.corrupt = false
+	if _, err := RemotePush(ctx, cfg, RemoteOptions{RepoKey: repoKey}, deps, logger); err != nil {
+		t.Fatalf("verified push failed: %v", err)
+	}
+}
+
+func TestRemotePull_RestoresSnapshotLayout(t *testing.T) {
+	ctx := context.Background()
+	cfg, deps, repoKey, _ := newRemoteFixture(t)
+	repoDir := filepath.Join(cfg.BackupDir, repoKey)
+	src := filepath.Join(repoDir, "2000-01-01", "000000")
+	makeDoneSnapshots(t, repoDir, "2000-01-01/000000")
+	writeTestFile(t, filepath.Join(src, "repo", "run.sh"), "#!/bin/sh\n")
+	if err := os.Chmod(filepath.Join(src, "repo", "run.sh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(src, "repo", "empty"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("run.sh", filepath.Join(src, "repo", "link")); err != nil {
+		t.Fatal(err)
+	}
+	logger := newTestBackupContext(false).logger
+	if _, err := RemotePush(ctx, cfg, RemoteOptions{RepoKey: repoKey}, deps, logger); err != nil {
+		t.Fatalf("push failed: %v", err)
+	}
+
+	opts := RemoteOptions{RepoKey: repoKey, Snapshot: "2000-01-01/000000"}
+	if _, err := RemotePull(ctx, cfg, opts, deps, logger); !errors.Is(err, ErrUsage) {
+		t.Fatalf("pull over an existing local snapshot must fail with usage error, got %v", err)
+	}
+	if err := os.RemoveAll(src); err != nil {
+		t.Fatal(err)
+	}
+	opts.Snapshot = "000000"
+	result, err := RemotePull(ctx, cfg, opts, deps, logger)
+	if err != nil {
+		t.Fatalf("pull failed: %v", err)
+	}
+	if result.SnapshotDir != src || result.Target != "offsite" {
+		t.Fatalf("unexpected pull result: %+v", result)
+	}
+	if info, err := os.Stat(filepath.Join(src, "repo", "run.sh")); err != nil || info.Mode().Perm() != 0o755 {
+		t.Fatalf("expected executable file: %v (%v)", info, err)
+	}
+	if info, err := os.Stat(filepath.Join(src, "repo", "empty")); err != nil || !info.IsDir() {
+		t.Fatalf("expected empty directory: %v", err)
+	}
+	if target, err := os.Readlink(filepath.Join(src, "repo", "link")); err != nil || target != "run.sh" {
+		t.Fatalf("expected symlink to run.sh, got %q (%v)", target, err)
+	}
+	for _, marker := range []string{".done", ".partial", remoteLayoutObject} {
+		_, err := os.Stat(filepath.Join(src, marker))
+		if exists := err == nil; exists != (marker == ".done") {
+			t.Fatalf("unexpected state of %s after pull: %v", marker, err)
+		}
+	}
+
+	opts.Snapshot = "2099-01-01/000000"
+	if _, err := RemotePull(ctx, cfg, opts, deps, logger); !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected usage error for unknown snapshot, got %v", err)
+	}
+}
+
+func TestRemotePush_RequiresTargets(t *testing.T) {
+	cfg, deps, repoKey, _ := newRemoteFixture(t)
+	cfg.Targets = nil
+	_, err := RemotePush(context.Background(), cfg, RemoteOptions{RepoKey: repoKey}, deps,
+		newTestBackupContext(false).logger)
+	if !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+}
@@ -0,0 +1,304 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// repoConfigFile is the optional per-repository settings file in the repository root.
+const repoConfigFile = ".devback.toml"
+
+// Sources of the effective per-repository settings, lowest precedence first. [[repo]] entries
+// are reported with their match pattern.
+const (
+	SettingSourceConfig    = "config.toml"
+	SettingSourceRepoFile  = repoConfigFile
+	SettingSourceGitConfig = "git config"
+)
+
+// RepoRule is a validated [[repo]] entry of config.toml.
+type RepoRule struct {
+	Match     string
+	Overrides BackupOverrides
+}
+
+// RepoSetting is the effective value of an overridable setting for one repository.
+type RepoSetting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// repoOverrideKeys lists the overridable [backup] keys with their git config names.
+//
+//nolint:gochecknoglobals // fixed lookup table.
+var repoOverrideKeys = []struct{ key, git string }{
+	{"base_dir", "backup.baseDir"},
+	{"keep_count", "backup.keepCount"},
+	{"keep_days", "backup.keepDays"},
+	{"max_total_gb", "backup.maxTotalGb"},
+	{"format", "backup.format"},
+}
+
+// repoRulesFromFile validates the [[repo]] entries of config.toml.
+func repoRulesFromFile(repos []RepoConfig, homeDir string) ([]RepoRule, error) {
+	var rules []RepoRule
+	for i, r := range repos {
+		rule, err := repoRuleFromFile(i, r, homeDir)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// repoRuleFromFile validates the [[repo]] entry at index i.
+func repoRuleFromFile(i int, r RepoConfig, homeDir string) (RepoRule, error) {
+	match := strings.TrimSpace(r.Match)
+	if match == "" {
+		return RepoRule{}, fmt.Errorf("repo[%d]: match is required: %w", i, ErrUsage)
+	}
+	match = expandHomeDir(match, homeDir)
+	if _, err := path.Match(match, ""); err != nil {
+		return RepoRule{}, fmt.Errorf("repo[%d]: invalid match %q: %w", i, r.Match, ErrUsage)
+	}
+	source := repoRuleSource(match)
+	if err := applyBackupOverrides(&Config{HomeDir: homeDir}, r.BackupOverrides, source, nil); err != nil {
+		return RepoRule{}, err
+	}
+	return RepoRule{Match: match, Overrides: r.BackupOverrides}, nil
+}
+
+func repoRuleSource(match string) string {
+	return fmt.Sprintf("[[repo]] match = %q", match)
+}
+
+// applyBackupOverrides validates o and applies the settings it sets to cfg, recording source for
+// every applied key in sources (when not nil).
+func applyBackupOverrides(cfg *Config, o BackupOverrides, source string, sources map[string]string) error {
+	set := func(key string) {
+		if sources != nil {
+			sources[key] = source
+		}
+	}
+	if o.BaseDir != nil {
+		dir := expandHomeDir(*o.BaseDir, cfg.HomeDir)
+		if !isAbsPath(dir) {
+			return fmt.Errorf("%s: base_dir %q must be an absolute or ~ path: %w", source, *o.BaseDir, ErrUsage)
+		}
+		cfg.BackupDir = dir
+		set("base_dir")
+	}
+	for _, v := range []struct {
+		key   string
+		value *int
+		dst   *int
+	}{
+		{"keep_count", o.KeepCount, &cfg.KeepCount},
+		{"keep_days", o.KeepDays, &cfg.KeepDays},
+		{"max_total_gb", o.MaxTotalGB, &cfg.MaxTotalGBPerRepo},
+	} {
+		if v.value == nil {
+			continue
+		}
+		if *v.value < 0 {
+			return fmt.Errorf("%s: %s must not be negative: %w", source, v.key, ErrUsage)
+		}
+		*v.dst = *v.value
+		set(v.key)
+	}
+	if o.Format != nil {
+		format, err := normalizeSnapshotFormat(*o.Format)
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		cfg.Format = format
+		set("format")
+	}
+	return nil
+}
+
+// repoFileOverrides keeps the settings .devback.toml may set and names the ignored ones. A
+// committed file must not delete snapshots through retention or redirect them through base_dir,
+// so only format is honored there.
+func repoFileOverrides(o BackupOverrides) (BackupOverrides, []string) {
+	var ignored []string
+	for _, v := range []struct {
+		key string
+		set bool
+	}{
+		{"base_dir", o.BaseDir != nil},
+		{"keep_count", o.KeepCount != nil},
+		{"keep_days", o.KeepDays != nil},
+		{"max_total_gb", o.MaxTotalGB != nil},
+	} {
+		if v.set {
+			ignored = append(ignored, v.key)
+		}
+	}
+	return BackupOverrides{Format: o.Format}, ignored
+}
+
+// resolveRepoConfig returns a copy of cfg with the overrides for repoRoot applied, along with
+// the effective value and source of every overridable setting. Precedence, lowest first:
+// config.toml [backup], .devback.toml in the repository root (format only, see repoFileOverrides),
+// matching [[repo]] entries in file order, and git config (worktree, local, global).
+func resolveRepoConfig(
+	ctx context.Context,
+	cfg *Config,
+	deps *Dependencies,
+	repoRoot string,
+	bc *backupContext,
+) (*Config, []RepoSetting, error) {
+	repoCfg := *cfg
+	sources := map[string]string{}
+
+	if deps.Config != nil {
+		file, err := deps.Config.LoadRepo(ctx, deps.FileSystem.Join(repoRoot, repoConfigFile))
+		if err != nil {
+			var cfgErr *ConfigError
+			if errors.As(err, &cfgErr) {
+				return nil, nil, cfgErr
+			}
+			return nil, nil, fmt.Errorf("%s: %v: %w", repoConfigFile, err, ErrUsage)
+		}
+		overrides, ignored := repoFileOverrides(file.Backup)
+		if len(ignored) > 0 {
+			bc.warnf("%s: %s ignored; set them with git config or a [[repo]] entry",
+				repoConfigFile, strings.Join(ignored, ", "))
+		}
+		if err := applyBackupOverrides(&repoCfg, overrides, SettingSourceRepoFile, sources); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(cfg.RepoRules) > 0 {
+		ids := repoMatchIDs(ctx, deps, repoRoot)
+		for _, rule := range cfg.RepoRules {
+			if !repoRuleMatches(rule.Match, ids) {
+				continue
+			}
+			if err := applyBackupOverrides(&repoCfg, rule.Overrides, repoRuleSource(rule.Match), sources); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	if deps.Git != nil {
+		overrides, err := gitConfigOverrides(ctx, deps.Git, repoRoot)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := applyBackupOverrides(&repoCfg, overrides, SettingSourceGitConfig, sources); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	settings := make([]RepoSetting, 0, len(repoOverrideKeys))
+	for _, k := range repoOverrideKeys {
+		source := sources[k.key]
+		switch {
+		case source == "":
+			source = SettingSourceConfig
+		case source == SettingSourceGitConfig:
+			source += " " + k.git
+		}
+		settings = append(settings, RepoSetting{Key: k.key, Value: repoSettingValue(&repoCfg, k.key), Source: source})
+	}
+	return &repoCfg, settings, nil
+}
+
+func repoSettingValue(cfg *Config, key string) string {
+	switch key {
+	case "base_dir":
+		return cfg.BackupDir
+	case "keep_count":
+		return strconv.Itoa(cfg.KeepCount)
+	case "keep_days":
+		return strconv.Itoa(cfg.KeepDays)
+	case "max_total_gb":
+		return strconv.Itoa(cfg.MaxTotalGBPerRepo)
+	case "format":
+		if cfg.Format == "" {
+			return SnapshotFormatDir
+		}
+		return cfg.Format
+	}
+	return ""
+}
+
+// gitConfigOverrides reads the backup.* override keys from git config.
+func gitConfigOverrides(ctx context.Context, git GitPort, repoRoot string) (BackupOverrides, error) {
+	var o BackupOverrides
+	for _, k := range repoOverrideKeys {
+		value := readRepoConfig(ctx, git, repoRoot, true, k.git)
+		if value == "" {
+			continue
+		}
+		switch k.key {
+		case "base_dir":
+			o.BaseDir = &value
+		case "format":
+			o.Format = &value
+		default:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return o, fmt.Errorf("git config %s: %q is not a number: %w", k.git, value, ErrUsage)
+			}
+			switch k.key {
+			case "keep_count":
+				o.KeepCount = &n
+			case "keep_days":
+				o.KeepDays = &n
+			case "max_total_gb":
+				o.MaxTotalGB = &n
+			}
+		}
+	}
+	return o, nil
+}
+
+// repoMatchIDs returns the names [[repo]] match patterns are tested against: the origin remote
+// as host/owner/repo and the repository root path.
+func repoMatchIDs(ctx context.Context, deps *Dependencies, repoRoot string) []string {
+	ids := []string{strings.ReplaceAll(repoRoot, `\`, "/")}
+	if deps.Git == nil {
+		return ids
+	}
+	if remote, err := deps.Git.ConfigGet(ctx, repoRoot, "remote.origin.url"); err == nil {
+		if host, owner, repo := parseRemote(strings.TrimSpace(remote)); host != "" {
+			ids = append(ids, host+"/"+owner+"/"+repo)
+		}
+	}
+	return ids
+}
+
+func repoRuleMatches(pattern string, ids []string) bool {
+	pattern = strings.ReplaceAll(pattern, `\`, "/")
+	for _, id := range ids {
+		if ok, _ := path.Match(pattern, id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// logRepoSettings lists the settings overridden for the current repository in verbose mode.
+func logRepoSettings(settings []RepoSetting, bc *backupContext) {
+	header := false
+	for _, s := range settings {
+		if s.Source == SettingSourceConfig {
+			continue
+		}
+		if !header {
+			bc.vlogf("→ Repository overrides:")
+			header = true
+		}
+		bc.vlogf("   %s = %s (%s)", s.Key, s.Value, s.Source)
+	}
+}
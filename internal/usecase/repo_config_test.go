@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func intPtr(v int) *int { return &v }
+
+func strPtr(v string) *string { return &v }
+
+func TestResolveRepoConfig_Precedence(t *testing.T) {
+	ctx := context.Background()
+	repoRoot := "/work/api"
+	fs := newTestFileSystem()
+	configPort := newFakeConfigPort(fs)
+	configPort.repos = map[string]RepoConfigFile{
+		filepath.Join(repoRoot, repoConfigFile): {Backup: BackupOverrides{
+			BaseDir:   strPtr("/ignored"),
+			KeepCount: intPtr(5),
+			KeepDays:  intPtr(7),
+			Format:    strPtr("tar"),
+		}},
+	}
+	gitValues := map[string]string{
+		"remote.origin.url": "git@github.com:acme/api.git",
+		"backup.keepDays":   "3",
+	}
+	deps := &Dependencies{
+		FileSystem: fs,
+		Config:     configPort,
+		Git: &mockGit{ConfigGetFunc: func(_ context.Context, _, key string) (string, error) {
+			if v, ok := gitValues[key]; ok {
+				return v, nil
+			}
+			return "", fmt.Errorf("not found")
+		}},
+	}
+	cfg := &Config{
+		BackupDir:         "/backup",
+		KeepCount:         30,
+		KeepDays:          90,
+		MaxTotalGBPerRepo: 10,
+		HomeDir:           "/home/test",
+		RepoRules: []RepoRule{
+			{Match: "github.com/acme/*", Overrides: BackupOverrides{
+				BaseDir:   strPtr("/mnt/acme"),
+				KeepCount: intPtr(8),
+			}},
+			{Match: "github.com/other/*", Overrides: BackupOverrides{MaxTotalGB: intPtr(1)}},
+			{Match: "/work/*", Overrides: BackupOverrides{MaxTotalGB: intPtr(2)}},
+		},
+	}
+
+	repoCfg, settings, err := resolveRepoConfig(ctx, cfg, deps, repoRoot, newTestBackupContext(false))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.BackupDir != "/backup" || cfg.KeepCount != 30 {
+		t.Fatalf("the global config must not change: %+v", cfg)
+	}
+	want := map[string]RepoSetting{
+		"base_dir":     {Value: "/mnt/acme", Source: `[[repo]] match = "github.com/acme/*"`},
+		"keep_count":   {Value: "8", Source: `[[repo]] match = "github.com/acme/*"`},
+		"keep_days":    {Value: "3", Source: "git config backup.keepDays"},
+		"max_total_gb": {Value: "2", Source: `[[repo]] match = "/work/*"`},
+		"format":       {Value: "tar", Source: SettingSourceRepoFile},
+	}
+	if len(settings) != len(want) {
+		t.Fatalf("expected %d settings, got %+v", len(want), settings)
+	}
+	for _, s := range settings {
+		w := want[s.Key]
+		if s.Value != w.Value || s.Source != w.Source {
+			t.Fatalf("%s: expected %q (%s), got %q (%s)", s.Key, w.Value, w.Source, s.Value, s.Source)
+		}
+	}
+	if repoCfg.BackupDir != "/mnt/acme" || repoCfg.KeepCount != 8 || repoCfg.KeepDays != 3 ||
+		repoCfg.MaxTotalGBPerRepo != 2 || repoCfg.Format != SnapshotFormatTar {
+		t.Fatalf("unexpected effective config: %+v", repoCfg)
+	}
+}
+
+func TestResolveRepoConfig_RepoFileCannotSetRetention(t *testing.T) {
+	repoRoot := "/work/api"
+	fs := newTestFileSystem()
+	configPort := newFakeConfigPort(fs)
+	configPort.repos = map[string]RepoConfigFile{
+		filepath.Join(repoRoot, repoConfigFile): {Backup: BackupOverrides{
+			BaseDir:    strPtr("/tmp/elsewhere"),
+			KeepCount:  intPtr(0),
+			KeepDays:   intPtr(1),
+			MaxTotalGB: intPtr(1),
+			Format:     strPtr("tar"),
+		}},
+	}
+	deps := &Dependencies{FileSystem: fs, Config: configPort, Git: &mockGit{}}
+	cfg := &Config{BackupDir: "/backup", KeepCount: 30, KeepDays: 90, MaxTotalGBPerRepo: 10}
+	var logs bytes.Buffer
+	bc := newBackupContext(slog.New(slog.NewTextHandler(&logs, nil)), false)
+
+	repoCfg, settings, err := resolveRepoConfig(context.Background(), cfg, deps, repoRoot, bc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repoCfg.BackupDir != "/backup" || repoCfg.KeepCount != 30 || repoCfg.KeepDays != 90 ||
+		repoCfg.MaxTotalGBPerRepo != 10 || repoCfg.Format != SnapshotFormatTar {
+		t.Fatalf("only format may come from %s, got %+v", repoConfigFile, repoCfg)
+	}
+	for _, s := range settings {
+		if s.Key != "format" && s.Source != SettingSourceConfig {
+			t.Fatalf("%s: expected source %s, got %s", s.Key, SettingSourceConfig, s.Source)
+		}
+	}
+	if !strings.Contains(logs.String(), "base_dir, keep_count, keep_days, max_total_gb ignored") {
+		t.Fatalf("expected a warning about the ignored keys, got:\n%s", logs.String())
+	}
+}
+
+func TestResolveRepoConfig_Defaults(t *testing.T) {
+	deps := &Dependencies{FileSystem: newTestFileSystem(), Git: &mockGit{}}
+	cfg := &Config{BackupDir: "/backup", KeepCount: 30}
+
+	repoCfg, settings, err := resolveRepoConfig(context.Background(), cfg, deps, "/repo", newTestBackupContext(false))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repoCfg.BackupDir != "/backup" || repoCfg.KeepCount != 30 {
+		t.Fatalf("unexpected effective config: %+v", repoCfg)
+	}
+	for _, s := range settings {
+		if s.Source != SettingSourceConfig {
+			t.Fatalf("%s: expected source %s, got %s", s.Key, SettingSourceConfig, s.Source)
+		}
+	}
+}
+
+func TestResolveRepoConfig_InvalidGitValue(t *testing.T) {
+	for key, value := range map[string]string{
+		"backup.keepCount": "many",
+		"backup.keepDays":  "-1",
+		"backup.format":    "zip",
+		"backup.baseDir":   "relative/dir",
+	} {
+		t.Run(key, func(t *testing.T) {
+			deps := &Dependencies{
+				FileSystem: newTestFileSystem(),
+				Git: &mockGit{ConfigGetFunc: func(_ context.Context, _, k string) (string, error) {
+					if k == key {
+						return value, nil
+					}
+					return "", fmt.Errorf("not found")
+				}},
+			}
+			_, _, err := resolveRepoConfig(context.Background(), &Config{}, deps, "/repo", newTestBackupContext(false))
+			if !errors.Is(err, ErrUsage) {
+				t.Fatalf("expected ErrUsage, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRepoRulesFromFile(t *testing.T) {
+	rules, err := repoRulesFromFile([]RepoConfig{
+		{Match: "~/work/*", BackupOverrides: BackupOverrides{BaseDir: strPtr("~/archive")}},
+	}, "/home/test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rules) != 1 || rules[0].Match != "/home/test/work/*" {
+		t.Fatalf("unexpected rules: %+v", rules)
+	}
+
+	for name, repo := range map[string]RepoConfig{
+		"missing match":  {BackupOverrides: BackupOverrides{KeepCount: intPtr(1)}},
+		"bad pattern":    {Match: "github.com/[acme"},
+		"negative value": {Match: "*", BackupOverrides: BackupOverrides{MaxTotalGB: intPtr(-1)}},
+		"bad format":     {Match: "*", BackupOverrides: BackupOverrides{Format: strPtr("zip")}},
+	} {
+		if _, err := repoRulesFromFile([]RepoConfig{repo}, "/home/test"); !errors.Is(err, ErrUsage) {
+			t.Fatalf("%s: expected ErrUsage, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// RestoreOptions describes snapshot restore behavior.
+type RestoreOptions struct {
+	RepoKey  string
+	Snapshot string
+	Latest   bool
+	Target   string
+	Force    bool
+	DryRun   bool
+	// RestoreExternal copies the snapshot's _external paths back to their original locations
+	// outside Target; without it they stay in Target/_external.
+	RestoreExternal bool
+}
+
+// RestoreResult describes a completed (or planned) restore.
+type RestoreResult struct {
+	SnapshotID  string
+	SnapshotDir string
+	Target      string
+	Copy        BackupResult
+	// ExternalFiles were copied back to their paths outside the repository; ExternalKept
+	// already existed there and were left alone.
+	ExternalFiles int
+	ExternalKept  int
+}
+
+// Restore materializes a completed snapshot into a working repository at opts.Target.
+func Restore(
+	ctx context.Context,
+	cfg *Config,
+	opts RestoreOptions,
+	deps *Dependencies,
+	logger *slog.Logger,
+) (*RestoreResult, error) {
+	if logger == nil {
+		panic("logger is required")
+	}
+	return restoreSnapshot(ctx, cfg, opts, deps, logger, false)
+}
+
+// restoreSnapshot implements Restore and Decrypt; decrypt selects which kind of snapshot is accepted.
+func restoreSnapshot(
+	ctx context.Context,
+	cfg *Config,
+	opts RestoreOptions,
+	deps *Dependencies,
+	logger *slog.Logger,
+	decrypt bool,
+) (*RestoreResult, error) {
+	if ctx.Err() != nil {
+		return nil, ErrInterrupted
+	}
+	if err := validateRestoreDependencies(deps, opts.DryRun); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(cfg.BackupDir) == "" {
+		return nil, fmt.Errorf("backup.base_dir is not configured: %w", ErrUsage)
+	}
+	if opts.Latest && strings.TrimSpace(opts.Snapshot) != "" {
+		return nil, fmt.Errorf("--snapshot and --latest are mutually exclusive: %w", ErrUsage)
+	}
+	bc := newBackupContext(logger, cfg.Verbose)
+
+	repoKey, err := resolveCommandRepoKey(ctx, cfg, deps, opts.RepoKey, bc)
+	if err != nil {
+		return nil, err
+	}
+	repoDir := deps.FileSystem.Join(cfg.BackupDir, repoKey)
+	snap, err := findSnapshot(ctx, deps, repoDir, opts.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	id := snapshotID(deps.FileSystem, repoDir, snap)
+	if err := prepareSnapshotOpener(ctx, cfg, deps, snap, id, decrypt, bc); err != nil {
+		return nil, err
+	}
+
+	target, err := prepareRestoreTarget(ctx, deps, opts)
+	if err != nil {
+		return nil, err
+	}
+	result := &RestoreResult{SnapshotID: id, SnapshotDir: snap.TimeDir, Target: target}
+
+	if opts.DryRun {
+		return result, planRestore(ctx, deps, snap, target, opts.RestoreExternal, bc)
+	}
+
+	lockPath, releaseLock, err := acquireBackupLock(ctx, deps, repoDir, target, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseLock()
+	stopRefresh := startLockRefresh(ctx, deps, lockPath, logger)
+	defer stopRefresh()
+
+	verb := "Restore"
+	if decrypt {
+		verb = "Decrypt"
+	}
+	bc.logf("→ %s %s -> %s", verb, id, target)
+	if err := restoreSnapshotContents(ctx, deps, snap, target, &result.Copy, bc); err != nil {
+		if ctx.Err() != nil {
+			return nil, ErrInterrupted
+		}
+		printBackupSummary(&result.Copy, bc)
+		return nil, fmt.Errorf("restore copy failed: %w", ErrCritical)
+	}
+	bc.logf("✓ Snapshot files copied")
+
+	if err := deps.Git.RestoreWorktree(ctx, target); err != nil {
+		bc.warnf("rebuild worktree: %v", err)
+		return nil, fmt.Errorf("rebuild worktree in %s: %w", target, ErrCritical)
+	}
+	bc.logf("✓ Worktree rebuilt from index")
+	if err := applyTrackedChanges(ctx, deps, target, bc); err != nil {
+		bc.warnf("reapply tracked changes: %v", err)
+		return nil, fmt.Errorf("reapply tracked changes in %s (kept in %s): %w", target, trackedChangesDir, ErrCritical)
+	}
+	external := deps.FileSystem.Join(target, externalDir)
+	if opts.RestoreExternal {
+		result.ExternalFiles, result.ExternalKept, err = applyExternalPaths(ctx, cfg, deps, target, bc)
+		if err != nil {
+			bc.warnf("restore external paths: %v", err)
+			return nil, fmt.Errorf("restore external paths (kept in %s): %w", external, ErrCritical)
+		}
+	} else if exists, _ := pathExists(ctx, deps.FileSystem, external); exists {
+		bc.logf("External paths kept in %s (use --restore-external to copy them back)", external)
+	}
+	bc.logf("✓ %s finished → %s", verb, target)
+	return result, nil
+}
+
+func validateRestoreDependencies(deps *Dependencies, dryRun bool) error {
+	if deps == nil {
+		return fmt.Errorf("dependencies are required: %w", ErrCritical)
+	}
+	if deps.FileSystem == nil {
+		return fmt.Errorf("filesystem adapter not available: %w", ErrCritical)
+	}
+	if deps.Git == nil {
+		return fmt.Errorf("git adapter not available: %w", ErrCritical)
+	}
+	if dryRun {
+		return nil
+	}
+	if deps.Lock == nil {
+		return fmt.Errorf("lock adapter not available: %w", ErrCritical)
+	}
+	if deps.Process == nil {
+		return fmt.Errorf("process adapter not available: %w", ErrCritical)
+	}
+	return nil
+}
+
+// resolveCommandRepoKey returns the explicit repo key or derives it from the current repository,
+// whose per-repository overrides (such as base_dir) are then applied to cfg.
+func resolveCommandRepoKey(
+	ctx context.Context,
+	cfg *Config,
+	deps *Dependencies,
+	explicit string,
+	bc *backupContext,
+) (string, error) {
+	if key := strings.TrimSpace(explicit); key != "" {
+		if err := validateRepoKey(key); err != nil {
+			return "", err
+		}
+		return key, nil
+	}
+	repoRoot, err := resolveRepoRoot(ctx, deps)
+	if err != nil {
+		return "", fmt.Errorf("resolve repository root: %w", ErrCritical)
+	}
+	if err := ensureGitRepo(ctx, deps, repoRoot); err != nil {
+		return "", fmt.Errorf("not a git repository (use --repo-key): %w", ErrUsage)
+	}
+	repoCfg, _, err := resolveRepoConfig(ctx, cfg, deps, repoRoot, bc)
+	if err != nil {
+		return "", err
+	}
+	*cfg = *repoCfg
+	return deriveRepoKey(ctx, cfg, deps, repoRoot, bc), nil
+}
+
+func validateRepoKey(key string) error {
+	clean := strings.ReplaceAll(key, "\\", "/")
+	if isAbsPath(key) {
+		return fmt.Errorf("repo key %q must be relative: %w", key, ErrUsage)
+	}
+	for _, seg := range strings.Split(clean, "/") {
+		if seg == ".." {
+			return fmt.Errorf("repo key %q must not contain '..': %w", key, ErrUsage)
+		}
+	}
+	return nil
+}
+
+func prepareRestoreTarget(ctx context.Context, deps *Dependencies, opts RestoreOptions) (string, error) {
+	raw := strings.TrimSpace(opts.Target)
+	if raw == "" {
+		return "", fmt.Errorf("--to is required: %w", ErrUsage)
+	}
+	target, err := deps.FileSystem.Abs(ctx, raw)
+	if err != nil {
+		return "", fmt.Errorf("resolve target %s: %w", raw, ErrCritical)
+	}
+	info, err := deps.FileSystem.Stat(ctx, target)
+	if err != nil {
+		if deps.FileSystem.IsNotExist(err) {
+			return target, nil
+		}
+		return "", fmt.Errorf("stat target %s: %w", target, ErrCritical)
+	}
+	if info != nil && !info.IsDir() {
+		return "", fmt.Errorf("target %s is not a directory: %w", target, ErrUsage)
+	}
+	entries, err := deps.FileSystem.ReadDir(ctx, target)
+	if err != nil {
+		return "", fmt.Errorf("read target %s: %w", target, ErrCritical)
+	}
+	if len(entries) > 0 && !opts.Force {
+		return "", fmt.Errorf("target %s is not empty (use --force to overwrite): %w", target, ErrUsage)
+	}
+	return target, nil
+}
+
+func planRestore(
+	ctx context.Context,
+	deps *Dependencies,
+	snap snapshot,
+	target string,
+	restoreExternal bool,
+	bc *backupContext,
+) error {
+	if snap.Archive != "" {
+		bc.logf("Dry run: restore skipped; would restore %s", snap.TimeDir)
+		bc.logf("Dry run: would extract %s to:%s", deps.FileSystem.Base(snap.Archive), target)
+		bc.logf("Dry run: would rebuild worktree from index in:%s", target)
+		return nil
+	}
+	entries, err := snapshotContentEntries(ctx, deps, snap.TimeDir)
+	if err != nil {
+		return fmt.Errorf("read snapshot %s: %w", snap.TimeDir, ErrCritical)
+	}
+	bc.logf("Dry run: restore skipped; would restore %s", snap.TimeDir)
+	bc.logf("Dry run: would copy %d top-level item(s) to:%s", len(entries), target)
+	tracked, external := false, false
+	for _, name := range entries {
+		bc.vlogf("   COPY: %s", name)
+		tracked = tracked || name == trackedChangesDir
+		external = external || name == externalDir
+	}
+	bc.logf("Dry run: would rebuild worktree from index in:%s", target)
+	if tracked {
+		bc.logf("Dry run: would reapply modified tracked files from %s", trackedChangesDir)
+	}
+	if external && restoreExternal {
+		bc.logf("Dry run: would copy %s back to the paths outside the repository", externalDir)
+	}
+	return nil
+}
+
+// snapshotContentEntries lists top-level snapshot entries excluding protocol markers.
+func snapshotContentEntries(ctx context.Context, deps *Dependencies, snapDir string) ([]string, error) {
+	entries, err := deps.FileSystem.ReadDir(ctx, snapDir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	hasGit := false
+	for _, entry := range entries {
+		name := entry.Name()
+		if isSnapshotMarker(name) {
+			continue
+		}
+		if name == ".git" {
+			hasGit = true
+		}
+		names = append(names, name)
+	}
+	if !hasGit {
+		return nil, fmt.Errorf("snapshot has no .git directory")
+	}
+	return names, nil
+}
+
+// restoreSnapshotContents copies a directory snapshot or extracts an archive snapshot into target.
+func restoreSnapshotContents(
+	ctx context.Context,
+	deps *Dependencies,
+	snap snapshot,
+	target string,
+	result *BackupResult,
+	bc *backupContext,
+) error {
+	if snap.Archive == "" {
+		return copySnapshotContents(ctx, deps, snap.TimeDir, target, result, bc)
+	}
+	if err := extractSnapshotArchive(ctx, deps, snap, target, result, bc.opener); err != nil {
+		return err
+	}
+	if _, err := deps.FileSystem.Stat(ctx, deps.FileSystem.Join(target, ".git")); err != nil {
+		return fmt.Errorf("snapshot archive has no .git directory")
+	}
+	return nil
+}
+
+func copySnapshotContents(
+	ctx context.Context,
+	deps *Dependencies,
+	snapDir,
+	target string,
+	result *BackupResult,
+	bc *backupContext,
+) error {
+	names, err := snapshotContentEntries(ctx, deps, snapDir)
+	if err != nil {
+		return err
+	}
+	if err := deps.FileSystem.CreateDir(ctx, target, 0o755); err != nil {
+		return fmt.Errorf("create target: %w", err)
+	}
+	var failed int
+	for _, name := range names {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		src := deps.FileSystem.Join(snapDir, name)
+		dst := deps.FileSystem.Join(target, name)
+		if err := copyDirRecursive(ctx, deps, src, dst, result, bc); err != nil {
+			bc.warnf("restore '%s': %v", name, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to restore %d item(s)", failed)
+	}
+	return nil
+}
+
+func isSnapshotMarker(name string) bool {
+	switch name {
+	case ".done", ".partial", ".reserve", manifestFileName, checksumsFileName, quarantineMarker, pinnedMarker:
+		return true
+	default:
+		return false
+	}
+}
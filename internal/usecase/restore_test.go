@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func runGitForTest(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	full := append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)
+	cmd := exec.Command("git", full...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newRestoreFixture creates a repository, backs it up once and returns the backup config.
+func newRestoreFixture(t *testing.T) (*Config, *Dependencies, string) {
+	t.Helper()
+	ctx := context.Background()
+	repoRoot := t.TempDir()
+	runGitForTest(t, repoRoot, "init", "-q")
+	writeTestFile(t, filepath.Join(repoRoot, "tracked.txt"), "tracked")
+	writeTestFile(t, filepath.Join(repoRoot, ".gitignore"), "ignored.txt\n")
+	runGitForTest(t, repoRoot, "add", "tracked.txt", ".gitignore")
+	runGitForTest(t, repoRoot, "commit", "-q", "-m", "init")
+	writeTestFile(t, filepath.Join(repoRoot, "ignored.txt"), "ignored")
+
+	backupDir := t.TempDir()
+	repoKey := "repo--deadbeef"
+	repoDir := filepath.Join(backupDir, repoKey)
+	if err := os.MkdirAll(repoDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{BackupDir: backupDir, NoSize: true}
+	deps := &Dependencies{
+		FileSystem: newTestFileSystem(),
+		Git:        newTestGitAdapter(),
+		Lock:       &mockLock{},
+		Process:    &mockProcess{},
+	}
+	if _, err := handleBackupFlow(ctx, cfg, deps, repoRoot, repoDir, newTestBackupContext(false)); err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	return cfg, deps, repoKey
+}
+
+func TestRestore_LatestSnapshot(t *testing.T) {
+	cfg, deps, repoKey := newRestoreFixture(t)
+	target := filepath.Join(t.TempDir(), "restored")
+
+	result, err := Restore(context.Background(), cfg, RestoreOptions{RepoKey: repoKey, Target: target}, deps,
+		newTestBackupContext(false).logger)
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if result.SnapshotID == "" {
+		t.Fatal("expected snapshot id")
+	}
+	for name, want := range map[string]string{
+		"tracked.txt":                 "tracked",
+		"ignored.txt":                 "ignored",
+		filepath.Join(".git", "HEAD"): "",
+	} {
+		data, err := os.ReadFile(filepath.Join(target, name))
+		if err != nil {
+			t.Fatalf("expected %s restored: %v", name, err)
+		}
+		if want != "" && string(data) != want {
+			t.Fatalf("unexpected %s content: %q", name, data)
+		}
+	}
+	for _, marker := range []string{".done", ".partial", ".reserve"} {
+		if _, err := os.Stat(filepath.Join(target, marker)); err == nil {
+			t.Fatalf("marker %s must not be restored", marker)
+		}
+	}
+}
+
+func TestRestore_NonEmptyTargetRequiresForce(t *testing.T) {
+	cfg, deps, repoKey := newRestoreFixture(t)
+	target := t.TempDir()
+	writeTestFile(t, filepath.Join(target, "existing.txt"), "keep")
+
+	_, err := Restore(context.Background(), cfg, RestoreOptions{RepoKey: repoKey, Target: target}, deps,
+		newTestBackupContext(false).logger)
+	if !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+
+	if _, err := Restore(context.Background(), cfg, RestoreOptions{RepoKey: repoKey, Target: target, Force: true},
+		deps, newTestBackupContext(false).logger); err != nil {
+		t.Fatalf("forced restore failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(target, "tracked.txt")); err != nil {
+		t.Fatalf("expected tracked file restored: %v", err)
+	}
+}
+
+func TestRestore_DryRunNoWrites(t *testing.T) {
+	cfg, deps, repoKey := newRestoreFixture(t)
+	target := filepath.Join(t.TempDir(), "restored")
+
+	_, err := Restore(context.Background(), cfg, RestoreOptions{RepoKey: repoKey, Target: target, DryRun: true},
+		deps, newTestBackupContext(false).logger)
+	if err != nil {
+		t.Fatalf("dry-run failed: %v", err)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Fatalf("dry-run must not create target, stat err=%v", err)
+	}
+}
+
+func TestRestore_UnknownSnapshot(t *testing.T) {
+	cfg, deps, repoKey := newRestoreFixture(t)
+
+	_, err := Restore(context.Background(), cfg,
+		RestoreOptions{RepoKey: repoKey, Snapshot: "2000-01-01/000000-1", Target: t.TempDir()},
+		deps, newTestBackupContext(false).logger)
+	if !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+}
+
+func TestValidateRepoKey(t *testing.T) {
+	for _, key := range []string{"/abs/key", "../escape", "a/../../b"} {
+		if err := validateRepoKey(key); !errors.Is(err, ErrUsage) {
+			t.Fatalf("expected usage error for %q, got %v", key, err)
+		}
+	}
+	if err := validateRepoKey("github.com/acme/repo"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// gfsRule keeps the newest snapshot of each of the last count periods.
+type gfsRule struct {
+	name   string
+	count  int
+	period func(time.Time) string
+}
+
+func gfsRules(cfg *Config) []gfsRule {
+	return []gfsRule{
+		{name: "hourly", count: cfg.KeepHourly, period: func(t time.Time) string { return t.Format("2006-01-02 15h") }},
+		{name: "daily", count: cfg.KeepDaily, period: func(t time.Time) string { return t.Format("2006-01-02") }},
+		{name: "weekly", count: cfg.KeepWeekly, period: func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{name: "monthly", count: cfg.KeepMonthly, period: func(t time.Time) string { return t.Format("2006-01") }},
+		{name: "yearly", count: cfg.KeepYearly, period: func(t time.Time) string { return t.Format("2006") }},
+	}
+}
+
+// gfsEnabled reports whether any generational retention rule is configured.
+func gfsEnabled(cfg *Config) bool {
+	for _, rule := range gfsRules(cfg) {
+		if rule.count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// applyGFS records on each alive snapshot which generational rules keep it. Walking from the
+// newest snapshot, a rule keeps the first snapshot it sees in a period until it has kept
+// count periods. Kept snapshots are skipped by the age and count passes.
+func applyGFS(ctx context.Context, deps *Dependencies, cfg *Config, snaps []snapshot, alive []bool) {
+	if !gfsEnabled(cfg) {
+		return
+	}
+	rules := gfsRules(cfg)
+	last := make([]string, len(rules))
+	kept := make([]int, len(rules))
+	for i := len(snaps) - 1; i >= 0; i-- {
+		if !alive[i] {
+			continue
+		}
+		t := snapshotTime(ctx, deps, snaps[i])
+		if t.IsZero() {
+			continue
+		}
+		for r, rule := range rules {
+			if rule.count <= 0 || kept[r] >= rule.count {
+				continue
+			}
+			key := rule.period(t)
+			if key == last[r] {
+				continue
+			}
+			last[r] = key
+			kept[r]++
+			snaps[i].KeptBy = append(snaps[i].KeptBy, fmt.Sprintf("keep_%s %s", rule.name, key))
+		}
+	}
+}
+
+// markWatchSnapshots flags snapshots whose manifest records trigger=watch.
+func markWatchSnapshots(ctx context.Context, deps *Dependencies, snaps []snapshot) {
+	for i := range snaps {
+		if m, err := readSnapshotManifest(ctx, deps, snaps[i].TimeDir); err == nil {
+			snaps[i].Watch = m.Trigger == watchTrigger
+		}
+	}
+}
+
+// applyWatchRetention removes watch snapshots beyond [watch] keep_hours and keep_count. Walking from
+// the newest snapshot, survivors are recorded in KeptBy so explainRetention can report them.
+func applyWatchRetention(
+	ctx context.Context,
+	deps *Dependencies,
+	cfg *Config,
+	dryRun bool,
+	bc *backupContext,
+	snaps []snapshot,
+	alive []bool,
+	now time.Time,
+) {
+	limits := watchRetentionLimits(cfg)
+	kept := 0
+	for i := len(snaps) - 1; i >= 0; i-- {
+		s := snaps[i]
+		if !alive[i] || !s.Watch || s.Pinned || s.Protected {
+			continue
+		}
+		reason := ""
+		if cfg.WatchKeepHours > 0 {
+			if t := snapshotTime(ctx, deps, s); !t.IsZero() && now.Sub(t) > time.Duration(cfg.WatchKeepHours)*time.Hour {
+				reason = fmt.Sprintf("watch snapshot older than %dh", cfg.WatchKeepHours)
+			}
+		}
+		if reason == "" && cfg.WatchKeepCount > 0 && kept >= cfg.WatchKeepCount {
+			reason = fmt.Sprintf("exceeds watch keep_count %d", cfg.WatchKeepCount)
+		}
+		if reason == "" {
+			kept++
+			snaps[i].KeptBy = append(snaps[i].KeptBy, limits)
+			continue
+		}
+		bc.logf("[rotate:watch] remove %s (%s)", s.TimeDir, reason)
+		if !dryRun {
+			removeSnapshot(ctx, deps, s, bc)
+		}
+		alive[i] = false
+	}
+}
+
+// regularRotationCandidates returns alive without watch snapshots, so they neither count toward
+// nor get removed by the generational, age and count passes.
+func regularRotationCandidates(snaps []snapshot, alive []bool) []bool {
+	regular := make([]bool, len(alive))
+	for i := range snaps {
+		regular[i] = alive[i] && !snaps[i].Watch
+	}
+	return regular
+}
+
+func watchRetentionLimits(cfg *Config) string {
+	var limits []string
+	if cfg.WatchKeepHours > 0 {
+		limits = append(limits, fmt.Sprintf("keep_hours %d", cfg.WatchKeepHours))
+	}
+	if cfg.WatchKeepCount > 0 {
+		limits = append(limits, fmt.Sprintf("keep_count %d", cfg.WatchKeepCount))
+	}
+	if len(limits) == 0 {
+		return "watch snapshot, no watch limit"
+	}
+	return "watch snapshot within " + strings.Join(limits, " and ")
+}
+
+// explainRetention logs why every snapshot that survived rotation was kept.
+// Removals are logged by the pass that removed them.
+func explainRetention(cfg *Config, dryRun bool, bc *backupContext, snaps []snapshot, alive []bool) {
+	logf := bc.vlogf
+	if dryRun {
+		logf = bc.logf
+	}
+	limits := retentionLimits(cfg)
+	for i, s := range snaps {
+		var reasons []string
+		switch {
+		case s.Quarantined:
+			reasons = append(reasons, "quarantined")
+		case !alive[i]:
+			continue
+		}
+		if s.Pinned {
+			reasons = append(reasons, "pinned")
+		}
+		if s.Protected {
+			reasons = append(reasons, "newest good snapshot")
+		}
+		reasons = append(reasons, s.KeptBy...)
+		if len(reasons) == 0 {
+			reasons = append(reasons, limits)
+		}
+		logf("[rotate:keep] keep %s (%s)", s.TimeDir, strings.Join(reasons, ", "))
+	}
+}
+
+func retentionLimits(cfg *Config) string {
+	var limits []string
+	if cfg.KeepDays > 0 {
+		limits = append(limits, fmt.Sprintf("keep_days %d", cfg.KeepDays))
+	}
+	if cfg.KeepCount > 0 {
+		limits = append(limits, fmt.Sprintf("keep_count %d", cfg.KeepCount))
+	}
+	if len(limits) == 0 {
+		return "no age or count limit"
+	}
+	return "within " + strings.Join(limits, " and ")
+}
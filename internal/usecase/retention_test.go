@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func makeDoneSnapshots(t *testing.T, repoDir string, ids ...string) {
+	t.Helper()
+	for _, id := range ids {
+		dir := filepath.Join(repoDir, filepath.FromSlash(id))
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, ".done"), []byte{}, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func remainingSnapshotIDs(t *testing.T, deps *Dependencies, repoDir string) []string {
+	t.Helper()
+	snaps, err := listSnapshots(context.Background(), deps, repoDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, 0, len(snaps))
+	for _, s := range snaps {
+		ids = append(ids, snapshotID(deps.FileSystem, repoDir, s))
+	}
+	return ids
+}
+
+func TestRotateRepo_GFSKeepsDailySnapshotsOverCount(t *testing.T) {
+	ctx := context.Background()
+	deps := &Dependencies{FileSystem: newTestFileSystem()}
+	repoDir := t.TempDir()
+	makeDoneSnapshots(t, repoDir,
+		"2024-05-01/120000", "2024-05-02/120000", "2024-05-03/120000", "2024-05-04/120000",
+		"2024-05-05/130000", "2024-05-05/131000", "2024-05-05/132000", "2024-05-05/140000",
+	)
+
+	cfg := &Config{KeepCount: 2, KeepDaily: 3}
+	rotateRepo(ctx, deps, repoDir, cfg, false, newTestBackupContext(false))
+
+	got := strings.Join(remainingSnapshotIDs(t, deps, repoDir), " ")
+	want := "2024-05-03/120000 2024-05-04/120000 2024-05-05/140000"
+	if got != want {
+		t.Fatalf("remaining snapshots = %q, want %q", got, want)
+	}
+}
+
+func TestRotateRepo_GFSPeriods(t *testing.T) {
+	ctx := context.Background()
+	deps := &Dependencies{FileSystem: newTestFileSystem()}
+	repoDir := t.TempDir()
+	makeDoneSnapshots(t, repoDir,
+		"2022-12-31/120000", "2023-06-01/120000", "2023-06-30/120000",
+		"2023-07-03/090000", "2023-07-09/090000", "2023-07-10/090000", "2023-07-10/091000",
+	)
+
+	cfg := &Config{KeepCount: 1, KeepHourly: 1, KeepWeekly: 2, KeepMonthly: 2, KeepYearly: 2}
+	rotateRepo(ctx, deps, repoDir, cfg, false, newTestBackupContext(false))
+
+	// weekly: 2023-W28 (07-10 09:10) and 2023-W27 (07-09); monthly: 2023-07 and 2023-06 (06-30);
+	// yearly: 2023 and 2022.
+	got := strings.Join(remainingSnapshotIDs(t, deps, repoDir), " ")
+	want := "2022-12-31/120000 2023-06-30/120000 2023-07-09/090000 2023-07-10/091000"
+	if got != want {
+		t.Fatalf("remaining snapshots = %q, want %q", got, want)
+	}
+}
+
+func TestRotateRepo_DryRunExplainsRules(t *testing.T) {
+	ctx := context.Background()
+	deps := &Dependencies{FileSystem: newTestFileSystem()}
+	repoDir := t.TempDir()
+	makeDoneSnapshots(t, repoDir, "2024-05-01/120000", "2024-05-02/120000", "2024-05-02/130000")
+
+	var logs bytes.Buffer
+	bc := newBackupContext(slog.New(slog.NewTextHandler(&logs, nil)), false)
+	rotateRepo(ctx, deps, repoDir, &Config{KeepCount: 1, KeepDaily: 1}, true, bc)
+
+	out := logs.String()
+	for _, want := range []string{
+		"[rotate:count] remove " + filepath.Join(repoDir, "2024-05-01", "120000"),
+		"[rotate:keep] keep " + filepath.Join(repoDir, "2024-05-02", "130000") +
+			" (newest good snapshot, keep_daily 2024-05-02)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in dry-run output:\n%s", want, out)
+		}
+	}
+	if got := len(remainingSnapshotIDs(t, deps, repoDir)); got != 3 {
+		t.Fatalf("dry run must not remove snapshots, %d left", got)
+	}
+}
+
+func TestRotateRepo_WatchSnapshotsRotatedSeparately(t *testing.T) {
+	ctx := context.Background()
+	deps := &Dependencies{FileSystem: newTestFileSystem()}
+	repoDir := t.TempDir()
+	makeDoneSnapshots(t, repoDir,
+		"2024-05-01/120000", "2024-05-01/121000", "2024-05-01/122000", "2024-05-01/123000",
+		"2024-05-01/124000", "2024-05-01/125000", "2024-05-01/130000",
+	)
+	for _, id := range []string{"2024-05-01/121000", "2024-05-01/123000", "2024-05-01/124000", "2024-05-01/125000"} {
+		writeTestFile(t, filepath.Join(repoDir, filepath.FromSlash(id), manifestFileName), `{"trigger":"watch"}`)
+	}
+
+	// Four watch snapshots must not push the commit snapshots out of keep_count.
+	cfg := &Config{KeepCount: 2, WatchKeepCount: 2}
+	rotateRepo(ctx, deps, repoDir, cfg, false, newTestBackupContext(false))
+
+	got := strings.Join(remainingSnapshotIDs(t, deps, repoDir), " ")
+	want := "2024-05-01/122000 2024-05-01/124000 2024-05-01/125000 2024-05-01/130000"
+	if got != want {
+		t.Fatalf("remaining snapshots = %q, want %q", got, want)
+	}
+}
@@ -202,26 +202,44 @@ func Backup(ctx context.Context, cfg *Config, deps *Dependencies, logger *slog.L
 		return nil, fmt.Errorf("not a git repository: %w", ErrCritical)
 	}
 
-	repoKey := deriveRepoKey(ctx, cfg, deps, repoRoot, bc)
+	repoCfg, settings, err := resolveRepoConfig(ctx, cfg, deps, repoRoot, bc)
+	if err != nil {
+		return nil, err
+	}
+	logRepoSettings(settings, bc)
+
+	repoKey := deriveRepoKey(ctx, repoCfg, deps, repoRoot, bc)
+	return backupRepo(ctx, repoCfg, deps, repoRoot, repoKey, bc)
+}
 
+// backupRepo creates one snapshot of repoRoot under repoKey while holding the repository lock.
+func backupRepo(
+	ctx context.Context,
+	cfg *Config,
+	deps *Dependencies,
+	repoRoot,
+	repoKey string,
+	bc *backupContext,
+) (*BackupResult, error) {
 	if cfg.DryRun {
 		return handleDryRun(ctx, cfg, deps, repoRoot, repoKey, bc)
 	}
 
-	repoDir, err := ensureBackupDirs(ctx, deps, cfg, repoKey, logger)
+	repoDir, err := ensureBackupDirs(ctx, deps, cfg, repoKey, bc.logger)
 	if err != nil {
 		return nil, err
 	}
 
-	lockPath, releaseLock, err := acquireBackupLock(ctx, deps, repoDir, repoRoot, cfg, logger)
+	lockPath, releaseLock, err := acquireBackupLock(ctx, deps, repoDir, repoRoot, cfg, bc.logger)
 	if err != nil {
 		return nil, err
 	}
 	defer releaseLock()
 
-	stopRefresh := startLockRefresh(ctx, deps, lockPath, logger)
+	stopRefresh := startLockRefresh(ctx, deps, lockPath, bc.logger)
 	defer stopRefresh()
 
+	migrateLegacySnapshots(ctx, cfg, deps, repoRoot, repoKey, false, bc)
 	return handleBackupFlow(ctx, cfg, deps, repoRoot, repoDir, bc)
 }
 
@@ -56,6 +56,8 @@ type mockFileSystem struct {
 	MoveFunc          func(ctx context.Context, src, dst string) error
 	ReadlinkFunc      func(ctx context.Context, path string) (string, error)
 	SymlinkFunc       func(ctx context.Context, target, path string) error
+	LinkFunc          func(ctx context.Context, oldname, newname string) error
+	HashFileFunc      func(ctx context.Context, path string) (string, error)
 	ChmodFunc         func(ctx context.Context, path string, perm int) error
 	ChtimesFunc       func(ctx context.Context, path string, atime, mtime time.Time) error
 	GetWorkingDirFunc func(ctx context.Context) (string, error)
@@ -174,6 +176,24 @@ func (m *mockFileSystem) Symlink(ctx context.Context, target, path string) error
 	return nil
 }
 
+func (m *mockFileSystem) Link(ctx context.Context, oldname, newname string) error {
+	if m.LinkFunc != nil {
+		return m.LinkFunc(ctx, oldname, newname)
+	}
+	return nil
+}
+
+func (m *mockFileSystem) HashFile(ctx context.Context, path string) (string, error) {
+	if m.HashFileFunc != nil {
+		return m.HashFileFunc(ctx, path)
+	}
+	return "", nil
+}
+
+func (m *mockFileSystem) FileID(info FileInfo) (FileID, bool) {
+	return FileID{}, false
+}
+
 func (m *mockFileSystem) Chmod(ctx context.Context, path string, perm int) error {
 	if m.ChmodFunc != nil {
 		return m.ChmodFunc(ctx, path, perm)
@@ -304,15 +324,19 @@ func (m *mockLock) RefreshLock(ctx context.Context, path string) error {
 type mockGit struct {
 	RepoRootFunc             func(ctx context.Context) (string, error)
 	ConfigGetFunc            func(ctx context.Context, repoPath, key string) (string, error)
+	ConfigGetLocalFunc       func(ctx context.Context, repoPath, key string) (string, error)
 	ConfigSetFunc            func(ctx context.Context, repoPath, key, value string) error
 	ConfigGetGlobalFunc      func(ctx context.Context, key string) (string, error)
 	ConfigGetWorktreeFunc    func(ctx context.Context, repoPath, key string) (string, error)
 	ConfigSetWorktreeFunc    func(ctx context.Context, repoPath, key, value string) error
 	ConfigSetGlobalFunc      func(ctx context.Context, key, value string) error
+	ConfigUnsetFunc          func(ctx context.Context, repoPath, key string) error
 	ListIgnoredUntrackedFunc func(ctx context.Context, repoPath string) ([]string, error)
 	GitDirFunc               func(ctx context.Context, repoPath string) (string, error)
 	GitCommonDirFunc         func(ctx context.Context, repoPath string) (string, error)
 	WorktreeListFunc         func(ctx context.Context, repoPath string) ([]WorktreeInfo, error)
+	RestoreWorktreeFunc      func(ctx context.Context, repoPath string) error
+	FsckConnectivityFunc     func(ctx context.Context, gitDir string) error
 }
 
 func (m *mockGit) Init(ctx context.Context, path string) error                    { return nil }
@@ -338,6 +362,14 @@ func (m *mockGit) GetStatus(ctx context.Context, repoPath string) (GitStatus, er
 	return GitStatus{}, nil
 }
 
+func (m *mockGit) Diff(ctx context.Context, repoPath string, staged bool) ([]byte, error) {
+	return nil, nil
+}
+
+func (m *mockGit) ApplyPatch(ctx context.Context, repoPath, patchPath string) error {
+	return nil
+}
+
 func (m *mockGit) GetLog(ctx context.Context, repoPath string, limit int) ([]GitCommit, error) {
 	return nil, nil
 }
@@ -356,6 +388,13 @@ func (m *mockGit) ConfigGet(ctx context.Context, repoPath, key string) (string,
 	return "", fmt.Errorf("not found")
 }
 
+func (m *mockGit) ConfigGetLocal(ctx context.Context, repoPath, key string) (string, error) {
+	if m.ConfigGetLocalFunc != nil {
+		return m.ConfigGetLocalFunc(ctx, repoPath, key)
+	}
+	return "", fmt.Errorf("not found")
+}
+
 func (m *mockGit) ConfigSet(ctx context.Context, repoPath, key, value string) error {
 	if m.ConfigSetFunc != nil {
 		return m.ConfigSetFunc(ctx, repoPath, key, value)
@@ -384,6 +423,21 @@ func (m *mockGit) ConfigSetWorktree(ctx context.Context, repoPath, key, value st
 	return nil
 }
 
+func (m *mockGit) ConfigUnset(ctx context.Context, repoPath, key string) error {
+	if m.ConfigUnsetFunc != nil {
+		return m.ConfigUnsetFunc(ctx, repoPath, key)
+	}
+	return nil
+}
+
+func (m *mockGit) ConfigUnsetWorktree(ctx context.Context, repoPath, key string) error {
+	return nil
+}
+
+func (m *mockGit) ConfigUnsetGlobal(ctx context.Context, key string) error {
+	return nil
+}
+
 func (m *mockGit) ConfigSetGlobal(ctx context.Context, key, value string) error {
 	if m.ConfigSetGlobalFunc != nil {
 		return m.ConfigSetGlobalFunc(ctx, key, value)
@@ -398,6 +452,20 @@ func (m *mockGit) ListIgnoredUntracked(ctx context.Context, repoPath string) ([]
 	return nil, nil
 }
 
+func (m *mockGit) RestoreWorktree(ctx context.Context, repoPath string) error {
+	if m.RestoreWorktreeFunc != nil {
+		return m.RestoreWorktreeFunc(ctx, repoPath)
+	}
+	return nil
+}
+
+func (m *mockGit) FsckConnectivity(ctx context.Context, gitDir string) error {
+	if m.FsckConnectivityFunc != nil {
+		return m.FsckConnectivityFunc(ctx, gitDir)
+	}
+	return nil
+}
+
 func (m *mockGit) GitDir(ctx context.Context, repoPath string) (string, error) {
 	if m.GitDirFunc != nil {
 		return m.GitDirFunc(ctx, repoPath)
@@ -427,6 +495,10 @@ type mockProcess struct{}
 
 func (m *mockProcess) GetPID() int { return 12345 }
 
+func (m *mockProcess) Hostname() string { return "test-host" }
+
+func (m *mockProcess) StartBackground(args []string) error { return nil }
+
 func TestHandleBackup_RefreshesLock(t *testing.T) {
 	originalInterval := lockRefreshInterval
 	lockRefreshInterval = 5 * time.Millisecond
@@ -0,0 +1,254 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultScheduleEvery = time.Hour
+	minScheduleEvery     = time.Minute
+)
+
+// ScheduleOptions describes how devback schedule install sets up periodic backups.
+type ScheduleOptions struct {
+	Every      string
+	Executable string
+	Path       string
+	HomeDir    string
+	DryRun     bool
+}
+
+// ScheduleResult describes an installed (or, with DryRun, rendered) backup-all job.
+type ScheduleResult struct {
+	Backend string
+	Every   time.Duration
+	Files   []ScheduleFile
+	DryRun  bool
+}
+
+// InstallSchedule installs a systemd user timer (Linux) or launchd agent (macOS) that runs
+// devback backup-all every opts.Every. Re-running it replaces the existing job.
+func InstallSchedule(
+	ctx context.Context,
+	opts ScheduleOptions,
+	deps *Dependencies,
+	logger *slog.Logger,
+) (*ScheduleResult, error) {
+	if logger == nil {
+		panic("logger is required")
+	}
+	backend, err := scheduleBackend(deps)
+	if err != nil {
+		return nil, err
+	}
+	every, err := parseScheduleInterval(opts.Every)
+	if err != nil {
+		return nil, err
+	}
+	exe := strings.TrimSpace(opts.Executable)
+	if !isAbsPath(exe) {
+		return nil, fmt.Errorf("devback executable path %q is not absolute: %w", exe, ErrUsage)
+	}
+	if err := requireSweepRoots(ctx, deps, opts.HomeDir); err != nil {
+		return nil, err
+	}
+
+	spec := ScheduleSpec{Executable: exe, Args: []string{"backup-all"}, Every: every, Path: opts.Path}
+	files, err := deps.Scheduler.Render(spec)
+	if err != nil {
+		return nil, fmt.Errorf("render %s job: %v: %w", backend, err, ErrCritical)
+	}
+	result := &ScheduleResult{Backend: backend, Every: every, Files: files, DryRun: opts.DryRun}
+	if opts.DryRun {
+		for _, f := range files {
+			logger.InfoContext(ctx, "Dry run: would write "+backend+" job file", "path", f.Path)
+		}
+		return result, nil
+	}
+	if err := deps.Scheduler.Install(ctx, spec); err != nil {
+		return nil, fmt.Errorf("install %s job: %v: %w", backend, err, ErrCritical)
+	}
+	logger.InfoContext(ctx, "Scheduled backup-all installed", "backend", backend, "every", formatScheduleInterval(every))
+	return result, nil
+}
+
+// ScheduleStatus reports the installed backup-all job.
+func ScheduleStatus(ctx context.Context, deps *Dependencies, logger *slog.Logger) (*ScheduleInfo, error) {
+	if logger == nil {
+		panic("logger is required")
+	}
+	backend, err := scheduleBackend(deps)
+	if err != nil {
+		return nil, err
+	}
+	info, err := deps.Scheduler.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("read %s job status: %v: %w", backend, err, ErrCritical)
+	}
+	return &info, nil
+}
+
+// RemoveSchedule unloads the backup-all job and deletes its files.
+func RemoveSchedule(ctx context.Context, deps *Dependencies, logger *slog.Logger) ([]string, error) {
+	if logger == nil {
+		panic("logger is required")
+	}
+	backend, err := scheduleBackend(deps)
+	if err != nil {
+		return nil, err
+	}
+	removed, err := deps.Scheduler.Remove(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("remove %s job: %v: %w", backend, err, ErrCritical)
+	}
+	if len(removed) == 0 {
+		logger.InfoContext(ctx, "No scheduled backup-all job installed")
+		return nil, nil
+	}
+	for _, p := range removed {
+		logger.InfoContext(ctx, "Removed "+backend+" job file", "path", p)
+	}
+	return removed, nil
+}
+
+func scheduleBackend(deps *Dependencies) (string, error) {
+	if deps == nil {
+		return "", fmt.Errorf("dependencies are required: %w", ErrCritical)
+	}
+	if deps.Scheduler == nil {
+		return "", fmt.Errorf("scheduler adapter not available: %w", ErrCritical)
+	}
+	backend := deps.Scheduler.Backend()
+	if backend == "" {
+		return "", fmt.Errorf("scheduled backups need systemd (Linux) or launchd (macOS): %w", ErrUsage)
+	}
+	return backend, nil
+}
+
+// requireSweepRoots refuses to schedule backup-all when it would fail for lack of [sweep] roots.
+func requireSweepRoots(ctx context.Context, deps *Dependencies, homeDir string) error {
+	if deps.FileSystem == nil || deps.Config == nil {
+		return fmt.Errorf("filesystem and config adapters are required: %w", ErrCritical)
+	}
+	homeDir = strings.TrimSpace(homeDir)
+	if homeDir == "" {
+		return fmt.Errorf("home directory is empty: %w", ErrCritical)
+	}
+	cfg, err := deps.Config.Load(ctx, buildInitPaths(deps.FileSystem, homeDir).configPath)
+	if err != nil {
+		return ConfigLoadError(err)
+	}
+	for _, root := range cfg.Sweep.Roots {
+		if strings.TrimSpace(root) != "" {
+			return nil
+		}
+	}
+	return fmt.Errorf("no sweep roots configured (set [sweep] roots in config.toml before scheduling backup-all): %w",
+		ErrUsage)
+}
+
+// parseScheduleInterval accepts Go durations ("30m", "1h30m") and whole days ("1d").
+func parseScheduleInterval(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return defaultScheduleEvery, nil
+	}
+	var every time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid interval %q: %w", value, ErrUsage)
+		}
+		every = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid interval %q (use e.g. 30m, 1h or 1d): %w", value, ErrUsage)
+		}
+		every = d
+	}
+	if every < minScheduleEvery {
+		return 0, fmt.Errorf("interval %q is shorter than %s: %w", value, minScheduleEvery, ErrUsage)
+	}
+	return every.Truncate(time.Second), nil
+}
+
+// formatScheduleInterval renders d without zero parts ("1d", "1h", "1h30m").
+func formatScheduleInterval(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	day := 24 * time.Hour
+	if d%day == 0 {
+		return strconv.Itoa(int(d/day)) + "d"
+	}
+	var b strings.Builder
+	for _, unit := range []struct {
+		size   time.Duration
+		suffix string
+	}{{time.Hour, "h"}, {time.Minute, "m"}, {time.Second, "s"}} {
+		if n := d / unit.size; n > 0 {
+			b.WriteString(strconv.FormatInt(int64(n), 10) + unit.suffix)
+			d -= n * unit.size
+		}
+	}
+	return b.String()
+}
+
+// FormatScheduleResult renders the outcome of devback schedule install. With DryRun the job
+// files are printed in full.
+func FormatScheduleResult(result *ScheduleResult) string {
+	var b strings.Builder
+	if result.DryRun {
+		for _, f := range result.Files {
+			fmt.Fprintf(&b, "# %s\n%s\n", f.Path, strings.TrimRight(f.Content, "\n"))
+			b.WriteString("\n")
+		}
+		return b.String()
+	}
+	fmt.Fprintf(&b, "Installed %s job: devback backup-all every %s\n", result.Backend,
+		formatScheduleInterval(result.Every))
+	for _, f := range result.Files {
+		fmt.Fprintf(&b, "  %s\n", f.Path)
+	}
+	return b.String()
+}
+
+// FormatSchedule renders devback schedule status.
+func FormatSchedule(info *ScheduleInfo) string {
+	var b strings.Builder
+	if info == nil || !info.Installed {
+		b.WriteString("No scheduled backup-all job (run: devback schedule install --every 1h)\n")
+		if info != nil && info.Detail != "" {
+			fmt.Fprintf(&b, "  %s\n", info.Detail)
+		}
+		return b.String()
+	}
+	state := "inactive"
+	if info.Active {
+		state = "active"
+	}
+	fmt.Fprintf(&b, "Backend:  %s (%s)\n", info.Backend, state)
+	fmt.Fprintf(&b, "Every:    %s\n", formatScheduleInterval(info.Every))
+	if info.Command != "" {
+		fmt.Fprintf(&b, "Command:  %s\n", info.Command)
+	}
+	if info.LastRun != "" {
+		fmt.Fprintf(&b, "Last run: %s\n", info.LastRun)
+	}
+	if info.NextRun != "" {
+		fmt.Fprintf(&b, "Next run: %s\n", info.NextRun)
+	}
+	for _, f := range info.Files {
+		fmt.Fprintf(&b, "File:     %s\n", f)
+	}
+	if info.Detail != "" {
+		fmt.Fprintf(&b, "Note:     %s\n", info.Detail)
+	}
+	return b.String()
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type fakeScheduler struct {
+	backend   string
+	installed []ScheduleSpec
+}
+
+func (f *fakeScheduler) Backend() string { return f.backend }
+
+func (f *fakeScheduler) Render(spec ScheduleSpec) ([]ScheduleFile, error) {
+	return []ScheduleFile{{Path: "/units/devback-backup.timer", Content: spec.Every.String()}}, nil
+}
+
+func (f *fakeScheduler) Install(ctx context.Context, spec ScheduleSpec) error {
+	f.installed = append(f.installed, spec)
+	return nil
+}
+
+func (f *fakeScheduler) Status(ctx context.Context) (ScheduleInfo, error) {
+	return ScheduleInfo{Backend: f.backend}, nil
+}
+
+func (f *fakeScheduler) Remove(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func TestParseScheduleInterval(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"":      time.Hour,
+		"30m":   30 * time.Minute,
+		"1h30m": 90 * time.Minute,
+		"2d":    48 * time.Hour,
+	} {
+		got, err := parseScheduleInterval(in)
+		if err != nil || got != want {
+			t.Fatalf("parseScheduleInterval(%q) = %v, %v; want %v", in, got, err, want)
+		}
+		if in != "" && formatScheduleInterval(got) != in {
+			t.Fatalf("formatScheduleInterval(%v) = %q; want %q", got, formatScheduleInterval(got), in)
+		}
+	}
+	for _, in := range []string{"10s", "hourly", "xd"} {
+		if _, err := parseScheduleInterval(in); !errors.Is(err, ErrUsage) {
+			t.Fatalf("expected usage error for %q, got %v", in, err)
+		}
+	}
+}
+
+func TestInstallSchedule(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	fs := newTestFileSystem()
+	homeDir := t.TempDir()
+	configPort := newFakeConfigPort(fs)
+	scheduler := &fakeScheduler{backend: "systemd"}
+	deps := &Dependencies{FileSystem: fs, Config: configPort, Scheduler: scheduler}
+	opts := ScheduleOptions{Every: "6h", Executable: "/usr/local/bin/devback", HomeDir: homeDir}
+
+	if _, err := InstallSchedule(ctx, opts, deps, logger); !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected usage error without sweep roots, got %v", err)
+	}
+
+	cfg := DefaultConfigFile()
+	cfg.Sweep.Roots = []string{"~/src"}
+	configPort.data[buildInitPaths(fs, homeDir).configPath] = cfg
+
+	opts.DryRun = true
+	result, err := InstallSchedule(ctx, opts, deps, logger)
+	if err != nil || len(result.Files) != 1 || len(scheduler.installed) != 0 {
+		t.Fatalf("dry run must render without installing: %+v, %v", result, err)
+	}
+
+	opts.DryRun = false
+	if _, err := InstallSchedule(ctx, opts, deps, logger); err != nil {
+		t.Fatalf("install failed: %v", err)
+	}
+	if len(scheduler.installed) != 1 {
+		t.Fatalf("expected one install, got %d", len(scheduler.installed))
+	}
+	spec := scheduler.installed[0]
+	if spec.Every != 6*time.Hour || spec.Executable != opts.Executable ||
+		len(spec.Args) != 1 || spec.Args[0] != "backup-all" {
+		t.Fatalf("unexpected spec: %+v", spec)
+	}
+
+	scheduler.backend = ""
+	if _, err := ScheduleStatus(ctx, deps, logger); !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected usage error on unsupported platform, got %v", err)
+	}
+}
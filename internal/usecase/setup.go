@@ -101,6 +101,11 @@ func Setup(ctx context.Context, opts SetupOptions, deps *Dependencies, logger *s
 	if err := applySetupSlug(ctx, deps, repo, inputs.slug, opts.DryRun); err != nil {
 		return err
 	}
+	if !opts.DryRun {
+		if err := registerSetupRepo(ctx, deps, repo, inputs.homeDir, !opts.NoHooks, logger); err != nil {
+			logger.WarnContext(ctx, "Failed to register repository", "error", err)
+		}
+	}
 
 	logger.InfoContext(ctx, "Setup completed")
 	return nil
@@ -31,16 +31,22 @@ type mockGitSetup struct {
 
 	local    map[string]string
 	worktree map[string]string
+	global   map[string]string
 }
 
 func (m *mockGitSetup) RepoRoot(ctx context.Context) (string, error) {
 	return m.repoRoot, nil
 }
 
+// ConfigGet resolves key like git: the repository config wins over the global one.
 func (m *mockGitSetup) ConfigGet(ctx context.Context, repoPath, key string) (string, error) {
-	if m.local == nil {
-		return "", nil
+	if value, ok := m.local[key]; ok {
+		return value, nil
 	}
+	return m.global[key], nil
+}
+
+func (m *mockGitSetup) ConfigGetLocal(ctx context.Context, repoPath, key string) (string, error) {
 	return m.local[key], nil
 }
 
@@ -53,7 +59,7 @@ func (m *mockGitSetup) ConfigSet(ctx context.Context, repoPath, key, value strin
 }
 
 func (m *mockGitSetup) ConfigGetGlobal(ctx context.Context, key string) (string, error) {
-	return "", nil
+	return m.global[key], nil
 }
 
 func (m *mockGitSetup) ConfigGetWorktree(ctx context.Context, repoPath, key string) (string, error) {
@@ -75,6 +81,20 @@ func (m *mockGitSetup) ConfigSetGlobal(ctx context.Context, key, value string) e
 	return nil
 }
 
+func (m *mockGitSetup) ConfigUnset(ctx context.Context, repoPath, key string) error {
+	delete(m.local, key)
+	return nil
+}
+
+func (m *mockGitSetup) ConfigUnsetWorktree(ctx context.Context, repoPath, key string) error {
+	delete(m.worktree, key)
+	return nil
+}
+
+func (m *mockGitSetup) ConfigUnsetGlobal(ctx context.Context, key string) error {
+	return nil
+}
+
 func (m *mockGitSetup) GitDir(ctx context.Context, repoPath string) (string, error) {
 	return m.gitDir, nil
 }
@@ -91,6 +111,38 @@ func (m *mockGitSetup) ListIgnoredUntracked(ctx context.Context, repoPath string
 	return nil, nil
 }
 
+func (m *mockGitSetup) GetStatus(ctx context.Context, repoPath string) (GitStatus, error) {
+	return GitStatus{}, nil
+}
+
+func (m *mockGitSetup) Diff(ctx context.Context, repoPath string, staged bool) ([]byte, error) {
+	return nil, nil
+}
+
+func (m *mockGitSetup) ApplyPatch(ctx context.Context, repoPath, patchPath string) error {
+	return nil
+}
+
+func (m *mockGitSetup) RestoreWorktree(ctx context.Context, repoPath string) error {
+	return nil
+}
+
+func (m *mockGitSetup) FsckConnectivity(ctx context.Context, gitDir string) error {
+	return nil
+}
+
+func (m *mockGitSetup) GetCommitHash(ctx context.Context, repoPath string) (string, error) {
+	return "", nil
+}
+
+func (m *mockGitSetup) GetCurrentBranch(ctx context.Context, repoPath string) (string, error) {
+	return "", nil
+}
+
+func (m *mockGitSetup) GetRemotes(ctx context.Context, repoPath string) ([]Remote, error) {
+	return nil, nil
+}
+
 type setupEnv struct {
 	homeDir  string
 	repoRoot string
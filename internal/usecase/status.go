@@ -63,6 +63,14 @@ type StatusGlobal struct {
 	BackupBase     StatusPath
 	LogDir         StatusPath
 	GitTemplateDir StatusGitTemplateDir
+	Registry       StatusRegistry
+}
+
+// StatusRegistry summarizes the repositories registered by setup.
+type StatusRegistry struct {
+	Path  string
+	Count int
+	Stale int
 }
 
 // StatusPath describes a path and its availability.
@@ -91,7 +99,12 @@ type StatusRepo struct {
 	BackupEnabled bool
 	BackupSlug    string
 	RepoKey       string
+	// Settings holds the effective overridable backup settings and their sources;
+	// SettingsError explains why they could not be resolved.
+	Settings      []RepoSetting
+	SettingsError string
 	Backups       StatusBackups
+	Targets       []StatusTarget
 }
 
 // StatusHooks contains hook checks summary.
@@ -112,10 +125,22 @@ type StatusCurrent struct {
 type StatusBackups struct {
 	Scanned       bool
 	SnapshotCount int
+	PinnedCount   int
 	TotalSizeKB   int64
 	LastBackup    time.Time
 }
 
+// StatusTarget describes how far a [[targets]] entry lags behind the local snapshots.
+type StatusTarget struct {
+	Name        string
+	Type        string
+	Pending     int
+	Pushed      bool
+	LastPush    time.Time
+	Unavailable bool
+	Error       string
+}
+
 type statusGlobalContext struct {
 	Report               StatusGlobal
 	Config               ConfigFile
@@ -151,11 +176,13 @@ func Status(ctx context.Context, opts StatusOptions, deps *Dependencies, logger
 		return report, nil
 	}
 
-	repoStatus, worktrees, err := buildStatusRepo(
+	repoStatus, backupBase, worktrees, err := buildStatusRepo(
 		ctx,
 		deps,
 		logger,
 		globalCtx.Config,
+		homeDir,
+		report.Global.ConfigFile.Exists,
 		globalCtx.TemplatesDirExpanded,
 		globalCtx.TemplatesExists,
 		globalCtx.BackupBaseExpanded,
@@ -164,6 +191,13 @@ func Status(ctx context.Context, opts StatusOptions, deps *Dependencies, logger
 	if err != nil {
 		return report, err
 	}
+	if repoStatus != nil && repoStatus.RepoKey != "" && backupBase != "" {
+		// Invalid [[targets]] are reported by the commands that use them.
+		if targets, err := targetSettingsFromFile(globalCtx.Config.Targets, homeDir); err == nil {
+			repoDir := deps.FileSystem.Join(backupBase, repoStatus.RepoKey)
+			repoStatus.Targets = remoteTargetStatus(ctx, deps, repoDir, targets)
+		}
+	}
 	report.Repo = repoStatus
 	report.Worktrees = worktrees
 	contractStatusPaths(&report, homeDir, deps.FileSystem.PathSeparator())
@@ -178,7 +212,7 @@ func buildStatusGlobal(ctx context.Context, deps *Dependencies, homeDir string)
 	}
 	cfg, err := deps.Config.Load(ctx, paths.configPath)
 	if err != nil {
-		return statusGlobalContext{}, fmt.Errorf("load config: %w", ErrCritical)
+		return statusGlobalContext{}, ConfigLoadError(err)
 	}
 
 	templatesDir := strings.TrimSpace(DefaultTemplatesDir())
@@ -233,6 +267,16 @@ func buildStatusGlobal(ctx context.Context, deps *Dependencies, homeDir string)
 	actualTemplateDirNorm := normalizePath(deps.FileSystem, gitTemplateDir, homeDir)
 	gitTemplateMatches := gitTemplateDir != "" && actualTemplateDirNorm == expectedTemplateDirNorm
 
+	registry := StatusRegistry{Path: normalizePath(deps.FileSystem, defaultRegistryPath, homeDir)}
+	if entries, err := loadRegistry(ctx, deps.FileSystem, registry.Path); err == nil {
+		registry.Count = len(entries)
+		for _, entry := range entries {
+			if exists, err := pathExists(ctx, deps.FileSystem, entry.Path); err == nil && !exists {
+				registry.Stale++
+			}
+		}
+	}
+
 	report := StatusGlobal{
 		ConfigFile: StatusPath{
 			Path:   paths.configPath,
@@ -260,6 +304,7 @@ func buildStatusGlobal(ctx context.Context, deps *Dependencies, homeDir string)
 			Matches:  gitTemplateMatches,
 			Hint:     "run: devback init",
 		},
+		Registry: registry,
 	}
 
 	return statusGlobalContext{
@@ -271,27 +316,31 @@ func buildStatusGlobal(ctx context.Context, deps *Dependencies, homeDir string)
 	}, nil
 }
 
+// buildStatusRepo reports on the current repository; the returned base directory includes its
+// base_dir override.
 func buildStatusRepo(
 	ctx context.Context,
 	deps *Dependencies,
 	logger *slog.Logger,
 	cfg ConfigFile,
+	homeDir string,
+	configExists bool,
 	templatesDirExpanded string,
 	templatesExists bool,
 	backupBaseExpanded string,
 	scanBackupsFlag bool,
-) (*StatusRepo, []WorktreeInfo, error) {
+) (*StatusRepo, string, []WorktreeInfo, error) {
 	repoRoot, err := resolveRepoRoot(ctx, deps)
 	if err != nil {
-		return nil, nil, fmt.Errorf("resolve repository root: %w", ErrCritical)
+		return nil, "", nil, fmt.Errorf("resolve repository root: %w", ErrCritical)
 	}
 	if err := ensureGitRepo(ctx, deps, repoRoot); err != nil {
-		return nil, nil, nil
+		return nil, "", nil, nil
 	}
 
 	repo, err := resolveSetupRepo(ctx, deps, repoRoot)
 	if err != nil {
-		return nil, nil, err
+		return nil, "", nil, err
 	}
 	repoType := RepoTypeRegular
 	if repo.isWorktree {
@@ -303,19 +352,19 @@ func buildStatusRepo(
 	if repo.isWorktree {
 		resolved, err := resolveStatusHooksDir(ctx, deps.FileSystem, deps.Git, repo, hookFiles)
 		if err != nil {
-			return nil, nil, err
+			return nil, "", nil, err
 		}
 		hooksDir = resolved
 	}
 	hookInstalled, hookExecutable, err := countHookFiles(ctx, deps.FileSystem, hooksDir, hookFiles)
 	if err != nil {
-		return nil, nil, err
+		return nil, "", nil, err
 	}
 	hooksCurrent := StatusCurrent{Known: templatesExists}
 	if templatesExists {
 		matches, err := compareHooks(ctx, deps.FileSystem, hooksDir, templatesDirExpanded, hookFiles)
 		if err != nil {
-			return nil, nil, err
+			return nil, "", nil, err
 		}
 		hooksCurrent.Matches = matches
 	}
@@ -323,19 +372,25 @@ func buildStatusRepo(
 	backupSlug := readRepoConfig(ctx, deps.Git, repo.repoRoot, repo.isWorktree, "backup.slug")
 	backupEnabled := parseBoolValue(readRepoConfig(ctx, deps.Git, repo.repoRoot, repo.isWorktree, "backup.enabled"))
 	repoKey := deriveRepoKeyStatus(ctx, cfg, deps, repo.repoRoot, backupSlug, logger)
+	settings, settingsErr := statusRepoSettings(ctx, deps, logger, cfg, homeDir, configExists, repo.repoRoot)
+	for _, s := range settings {
+		if s.Key == "base_dir" && s.Source != SettingSourceConfig && s.Source != statusSourceDefault {
+			backupBaseExpanded = s.Value
+		}
+	}
 
 	backups := StatusBackups{}
 	if scanBackupsFlag {
 		backupResult, err := scanBackups(ctx, deps, backupBaseExpanded, repoKey, logger)
 		if err != nil {
-			return nil, nil, err
+			return nil, "", nil, err
 		}
 		backups = backupResult
 	}
 
 	worktrees, err := deps.Git.WorktreeList(ctx, repo.repoRoot)
 	if err != nil {
-		return nil, nil, fmt.Errorf("list worktrees: %w", ErrCritical)
+		return nil, "", nil, fmt.Errorf("list worktrees: %w", ErrCritical)
 	}
 	currentBranch := findWorktreeBranch(deps.FileSystem, worktrees, repo.repoRoot)
 	mainRoot := ""
@@ -357,9 +412,49 @@ func buildStatusRepo(
 		BackupEnabled: backupEnabled,
 		BackupSlug:    backupSlug,
 		RepoKey:       repoKey,
+		Settings:      settings,
+		SettingsError: settingsErr,
 		Backups:       backups,
 	}
-	return repoStatus, worktrees, nil
+	return repoStatus, backupBaseExpanded, worktrees, nil
+}
+
+// statusErrorText renders err on one line, joining the issues of a *ConfigError.
+func statusErrorText(err error) string {
+	msg := strings.Replace(issueMessage(err), ":\n  ", ": ", 1)
+	return strings.ReplaceAll(msg, "\n  ", "; ")
+}
+
+// statusSourceDefault marks settings taken from the built-in defaults when config.toml is missing.
+const statusSourceDefault = "default"
+
+// statusRepoSettings resolves the per-repository overrides of repoRoot for display; failures are
+// returned as text so that status still renders.
+func statusRepoSettings(
+	ctx context.Context,
+	deps *Dependencies,
+	logger *slog.Logger,
+	cfg ConfigFile,
+	homeDir string,
+	configExists bool,
+	repoRoot string,
+) ([]RepoSetting, string) {
+	runtimeCfg, err := RuntimeConfigFromFile(cfg, homeDir)
+	if err != nil {
+		return nil, statusErrorText(err)
+	}
+	_, settings, err := resolveRepoConfig(ctx, runtimeCfg, deps, repoRoot, newBackupContext(logger, false))
+	if err != nil {
+		return nil, statusErrorText(err)
+	}
+	if !configExists {
+		for i := range settings {
+			if settings[i].Source == SettingSourceConfig {
+				settings[i].Source = statusSourceDefault
+			}
+		}
+	}
+	return settings, ""
 }
 
 func resolveStatusHooksDir(
@@ -440,6 +535,7 @@ func FormatStatus(report StatusReport, useColor bool) string {
 	appendStatusLine(&b, "Backup base:", formatPathStatus(report.Global.BackupBase, p))
 	appendStatusLine(&b, "Log dir:", formatPathStatus(report.Global.LogDir, p))
 	appendStatusLine(&b, "Git templateDir:", formatGitTemplateDir(report.Global.GitTemplateDir, p))
+	appendStatusLine(&b, "Registered repos:", formatRegistryStatus(report.Global.Registry, p))
 
 	if report.Repo == nil {
 		return b.String()
@@ -458,16 +554,27 @@ func FormatStatus(report StatusReport, useColor bool) string {
 	appendStatusLine(&b, "Backup enabled:", formatBoolStatus(report.Repo.BackupEnabled, p))
 	appendStatusLine(&b, "Backup slug:", formatTextValue(report.Repo.BackupSlug, p))
 	appendStatusLine(&b, "Repo key:", formatTextValue(report.Repo.RepoKey, p))
+	if report.Repo.SettingsError != "" {
+		appendStatusLine(&b, "Settings:", fmt.Sprintf("%s✗%s %s", p.red, p.reset, report.Repo.SettingsError))
+	}
+	for _, s := range report.Repo.Settings {
+		appendStatusLine(&b, statusSettingLabels[s.Key],
+			fmt.Sprintf("%s %s(%s)%s", formatTextValue(s.Value, p), p.dim, s.Source, p.reset))
+	}
 
 	if report.Repo.Backups.Scanned {
 		appendStatusLine(&b, "Last backup:", formatBackupTime(report.Repo.Backups.LastBackup, p))
 		appendStatusLine(&b, "Snapshots:", fmt.Sprintf("%d", report.Repo.Backups.SnapshotCount))
+		appendStatusLine(&b, "Pinned:", fmt.Sprintf("%d", report.Repo.Backups.PinnedCount))
 		appendStatusLine(&b, "Size:", formatBackupSize(report.Repo.Backups.TotalSizeKB))
 	} else {
 		appendStatusLine(&b, "Last backup:", fmt.Sprintf("%s(use --scan-backups)%s", p.dim, p.reset))
 		appendStatusLine(&b, "Snapshots:", fmt.Sprintf("%s(use --scan-backups)%s", p.dim, p.reset))
 		appendStatusLine(&b, "Size:", fmt.Sprintf("%s(use --scan-backups)%s", p.dim, p.reset))
 	}
+	for _, target := range report.Repo.Targets {
+		appendStatusLine(&b, "Target "+target.Name+":", formatTargetStatus(target, p))
+	}
 
 	b.WriteString("\n")
 	fmt.Fprintf(&b, "%sWorktrees:%s\n", p.boldCyan, p.reset)
@@ -490,6 +597,17 @@ func FormatStatus(report StatusReport, useColor bool) string {
 	return b.String()
 }
 
+// statusSettingLabels names the per-repository settings in the text output.
+//
+//nolint:gochecknoglobals // fixed lookup table.
+var statusSettingLabels = map[string]string{
+	"base_dir":     "Base dir:",
+	"keep_count":   "Keep count:",
+	"keep_days":    "Keep days:",
+	"max_total_gb": "Max total GB:",
+	"format":       "Format:",
+}
+
 func normalizeComparableStatusPath(p string) string {
 	clean := strings.TrimSpace(p)
 	if clean == "" {
@@ -506,11 +624,17 @@ func contractStatusPaths(report *StatusReport, homeDir string, sep byte) {
 	report.Global.TemplatesDir.Path = contractHomeDir(report.Global.TemplatesDir.Path, homeDir, sep)
 	report.Global.BackupBase.Path = contractHomeDir(report.Global.BackupBase.Path, homeDir, sep)
 	report.Global.LogDir.Path = contractHomeDir(report.Global.LogDir.Path, homeDir, sep)
+	report.Global.Registry.Path = contractHomeDir(report.Global.Registry.Path, homeDir, sep)
 	report.Global.GitTemplateDir.Expected = contractHomeDir(report.Global.GitTemplateDir.Expected, homeDir, sep)
 	report.Global.GitTemplateDir.Actual = contractHomeDir(report.Global.GitTemplateDir.Actual, homeDir, sep)
 	if report.Repo != nil {
 		report.Repo.Root = contractHomeDir(report.Repo.Root, homeDir, sep)
 		report.Repo.MainRoot = contractHomeDir(report.Repo.MainRoot, homeDir, sep)
+		for i, s := range report.Repo.Settings {
+			if s.Key == "base_dir" {
+				report.Repo.Settings[i].Value = contractHomeDir(s.Value, homeDir, sep)
+			}
+		}
 	}
 	for i := range report.Worktrees {
 		report.Worktrees[i].Path = contractHomeDir(report.Worktrees[i].Path, homeDir, sep)
@@ -711,20 +835,31 @@ func scanBackups(
 		return result, fmt.Errorf("list snapshots: %w", ErrCritical)
 	}
 	result.SnapshotCount = len(snaps)
+	for _, snap := range snaps {
+		if snap.Pinned {
+			result.PinnedCount++
+		}
+	}
 	if len(snaps) == 0 {
 		return result, nil
 	}
 	bc := newBackupContext(logger, false)
+	usage, err := measureSnapshots(ctx, deps, snaps, nil, bc)
+	if err != nil {
+		return result, fmt.Errorf("scan snapshot size: %w", ErrCritical)
+	}
+	result.TotalSizeKB = usage.totalKB()
 	var latest time.Time
 	for _, snap := range snaps {
 		if ctx.Err() != nil {
 			return result, ErrInterrupted
 		}
-		kb, err := dirSizeKB(ctx, deps, snap.TimeDir, bc)
-		if err != nil {
-			return result, fmt.Errorf("scan snapshot size: %w", ErrCritical)
+		if m, err := readSnapshotManifest(ctx, deps, snap.TimeDir); err == nil && !m.FinishedAt.IsZero() {
+			if m.FinishedAt.After(latest) {
+				latest = m.FinishedAt
+			}
+			continue
 		}
-		result.TotalSizeKB += kb
 		info, err := deps.FileSystem.Stat(ctx, snap.TimeDir)
 		if err != nil {
 			return result, fmt.Errorf("stat snapshot: %w", ErrCritical)
@@ -811,6 +946,35 @@ func formatBackupTime(value time.Time, p statusPalette) string {
 	return value.Format("2006-01-02 15:04:05")
 }
 
+func formatRegistryStatus(registry StatusRegistry, p statusPalette) string {
+	if registry.Count == 0 {
+		return fmt.Sprintf("0 %s(run: devback setup)%s", p.dim, p.reset)
+	}
+	if registry.Stale > 0 {
+		return fmt.Sprintf("%d %s(%d stale, run: devback repos --prune)%s", registry.Count, p.yellow, registry.Stale, p.reset)
+	}
+	return fmt.Sprintf("%d %s(list: devback repos)%s", registry.Count, p.dim, p.reset)
+}
+
+func formatTargetStatus(target StatusTarget, p statusPalette) string {
+	var value string
+	if target.Pending == 0 {
+		value = fmt.Sprintf("%s✓%s up to date", p.green, p.reset)
+	} else {
+		value = fmt.Sprintf("%s%d snapshot(s) not mirrored%s", p.yellow, target.Pending, p.reset)
+	}
+	lastPush := target.LastPush.Format("2006-01-02 15:04:05")
+	switch {
+	case !target.Pushed:
+		return value + fmt.Sprintf(" %s(%s, never pushed)%s", p.dim, target.Type, p.reset)
+	case target.Error != "":
+		return value + fmt.Sprintf(" %s(last push failed: %s)%s", p.red, target.Error, p.reset)
+	case target.Unavailable:
+		return value + fmt.Sprintf(" %s(unavailable at %s)%s", p.yellow, lastPush, p.reset)
+	}
+	return value + fmt.Sprintf(" %s(%s, last push %s)%s", p.dim, target.Type, lastPush, p.reset)
+}
+
 func formatBackupSize(kb int64) string {
 	return humanKB(kb)
 }
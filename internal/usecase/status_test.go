@@ -43,6 +43,10 @@ func (m *mockGitStatus) ConfigGet(ctx context.Context, repoPath, key string) (st
 	return value, nil
 }
 
+func (m *mockGitStatus) ConfigGetLocal(ctx context.Context, repoPath, key string) (string, error) {
+	return m.ConfigGet(ctx, repoPath, key)
+}
+
 func (m *mockGitStatus) ConfigSet(ctx context.Context, repoPath, key, value string) error {
 	return nil
 }
@@ -73,6 +77,18 @@ func (m *mockGitStatus) ConfigSetGlobal(ctx context.Context, key, value string)
 	return nil
 }
 
+func (m *mockGitStatus) ConfigUnset(ctx context.Context, repoPath, key string) error {
+	return nil
+}
+
+func (m *mockGitStatus) ConfigUnsetWorktree(ctx context.Context, repoPath, key string) error {
+	return nil
+}
+
+func (m *mockGitStatus) ConfigUnsetGlobal(ctx context.Context, key string) error {
+	return nil
+}
+
 func (m *mockGitStatus) GitDir(ctx context.Context, repoPath string) (string, error) {
 	if m.gitDirErr != nil {
 		return "", m.gitDirErr
@@ -92,6 +108,38 @@ func (m *mockGitStatus) ListIgnoredUntracked(ctx context.Context, repoPath strin
 	return nil, nil
 }
 
+func (m *mockGitStatus) GetStatus(ctx context.Context, repoPath string) (GitStatus, error) {
+	return GitStatus{}, nil
+}
+
+func (m *mockGitStatus) Diff(ctx context.Context, repoPath string, staged bool) ([]byte, error) {
+	return nil, nil
+}
+
+func (m *mockGitStatus) ApplyPatch(ctx context.Context, repoPath, patchPath string) error {
+	return nil
+}
+
+func (m *mockGitStatus) RestoreWorktree(ctx context.Context, repoPath string) error {
+	return nil
+}
+
+func (m *mockGitStatus) FsckConnectivity(ctx context.Context, gitDir string) error {
+	return nil
+}
+
+func (m *mockGitStatus) GetCommitHash(ctx context.Context, repoPath string) (string, error) {
+	return "", nil
+}
+
+func (m *mockGitStatus) GetCurrentBranch(ctx context.Context, repoPath string) (string, error) {
+	return "", nil
+}
+
+func (m *mockGitStatus) GetRemotes(ctx context.Context, repoPath string) ([]Remote, error) {
+	return nil, nil
+}
+
 const statusTestSlug = "company/app"
 
 func TestStatus_NoRepo_ConfigMissing(t *testing.T) {
@@ -430,7 +478,7 @@ func TestStatus_ScanBackups(t *testing.T) {
 	if report.Repo.Backups.SnapshotCount != 2 {
 		t.Fatalf("unexpected snapshot count: %d", report.Repo.Backups.SnapshotCount)
 	}
-	if report.Repo.Backups.TotalSizeKB != 4 {
+	if report.Repo.Backups.TotalSizeKB != 3 {
 		t.Fatalf("unexpected total size: %d", report.Repo.Backups.TotalSizeKB)
 	}
 	if !report.Repo.Backups.LastBackup.Equal(secondTime) {
@@ -510,6 +558,63 @@ func TestFormatStatus_MarksCurrentWorktreeWithNormalizedPaths(t *testing.T) {
 	}
 }
 
+func TestFormatStatus_TargetLag(t *testing.T) {
+	pushed := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	report := StatusReport{
+		Repo: &StatusRepo{
+			Root:          "/tmp/repo",
+			Type:          RepoTypeRegular,
+			BackupEnabled: true,
+			RepoKey:       "repo--hash",
+			Targets: []StatusTarget{
+				{Name: "offsite", Type: TargetTypeS3, Pushed: true, LastPush: pushed},
+				{Name: "usb", Type: TargetTypeDir, Pending: 2, Pushed: true, LastPush: pushed, Unavailable: true},
+				{Name: "nas", Type: TargetTypeSFTP, Pending: 1},
+			},
+		},
+	}
+
+	out := FormatStatus(report, false)
+
+	for _, want := range []string{
+		"Target offsite:",
+		"✓ up to date (s3, last push 2026-10-16 09:30:00)",
+		"2 snapshot(s) not mirrored (unavailable at 2026-10-16 09:30:00)",
+		"1 snapshot(s) not mirrored (sftp, never pushed)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in status output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestFormatStatus_RepoSettings(t *testing.T) {
+	report := StatusReport{
+		Repo: &StatusRepo{
+			Root:    "/tmp/repo",
+			Type:    RepoTypeRegular,
+			RepoKey: "repo--hash",
+			Settings: []RepoSetting{
+				{Key: "base_dir", Value: "", Source: statusSourceDefault},
+				{Key: "keep_count", Value: "100", Source: `[[repo]] match = "github.com/acme/*"`},
+				{Key: "keep_days", Value: "3", Source: "git config backup.keepDays"},
+			},
+		},
+	}
+
+	out := FormatStatus(report, false)
+
+	for _, want := range []string{
+		"Base dir:          – (not set) (default)",
+		`Keep count:        100 ([[repo]] match = "github.com/acme/*")`,
+		"Keep days:         3 (git config backup.keepDays)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in status output, got:\n%s", want, out)
+		}
+	}
+}
+
 func createSnapshot(t *testing.T, backupBase, repoKey, dateDir, timeDir string, size int) string {
 	t.Helper()
 
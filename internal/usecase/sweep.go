@@ -0,0 +1,278 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	defaultSweepConcurrency = 2
+	defaultSweepMaxDepth    = 4
+)
+
+// Sweep outcome of a single repository.
+const (
+	SweepStatusOK          = "ok"
+	SweepStatusPartial     = "partial"
+	SweepStatusLocked      = "locked"
+	SweepStatusFailed      = "failed"
+	SweepStatusInterrupted = "interrupted"
+)
+
+// BackupAllOptions overrides the [sweep] settings for one run.
+type BackupAllOptions struct {
+	Roots       []string
+	Concurrency int
+}
+
+// BackupAllReport aggregates the outcome of a sweep over all enabled repositories.
+type BackupAllReport struct {
+	Roots []string
+	Repos []SweepRepoResult
+}
+
+// SweepRepoResult describes the backup of one repository found by the sweep.
+type SweepRepoResult struct {
+	RepoRoot string
+	RepoKey  string
+	Status   string
+	Result   *BackupResult
+	Err      error
+	Duration time.Duration
+}
+
+// Count returns how many repositories finished with status.
+func (r *BackupAllReport) Count(status string) int {
+	n := 0
+	for _, repo := range r.Repos {
+		if repo.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// BackupAll discovers every repository with backup.enabled=true under the sweep roots and backs
+// them up with bounded concurrency. Repositories whose lock is held are skipped, not failed.
+func BackupAll(
+	ctx context.Context,
+	cfg *Config,
+	opts BackupAllOptions,
+	deps *Dependencies,
+	logger *slog.Logger,
+) (*BackupAllReport, error) {
+	if logger == nil {
+		panic("logger is required")
+	}
+	if err := validateBackupDependencies(ctx, cfg, deps, logger); err != nil {
+		return nil, err
+	}
+	bc := newBackupContext(logger, cfg.Verbose)
+
+	roots := opts.Roots
+	if len(roots) == 0 {
+		roots = cfg.SweepRoots
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no sweep roots configured (set [sweep] roots in config.toml): %w", ErrUsage)
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = cfg.SweepConcurrency
+	}
+	if concurrency <= 0 {
+		concurrency = defaultSweepConcurrency
+	}
+	maxDepth := cfg.SweepMaxDepth
+	if maxDepth <= 0 {
+		maxDepth = defaultSweepMaxDepth
+	}
+
+	report := &BackupAllReport{}
+	for _, root := range roots {
+		abs, err := deps.FileSystem.Abs(ctx, root)
+		if err != nil {
+			return nil, fmt.Errorf("resolve sweep root %s: %w", root, ErrUsage)
+		}
+		report.Roots = append(report.Roots, abs)
+	}
+
+	printConfig(cfg, bc)
+	repoRoots := discoverSweepRepos(ctx, cfg, deps, report.Roots, maxDepth, bc)
+	bc.logf("→ Sweep: %d enabled repositories, concurrency %d", len(repoRoots), concurrency)
+
+	report.Repos = make([]SweepRepoResult, len(repoRoots))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(repoRoots)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				report.Repos[i] = sweepRepo(ctx, cfg, deps, repoRoots[i], logger)
+			}
+		}()
+	}
+	next := 0
+schedule:
+	for ; next < len(repoRoots); next++ {
+		select {
+		case jobs <- next:
+		case <-ctx.Done():
+			break schedule
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	for i := next; i < len(repoRoots); i++ {
+		report.Repos[i] = SweepRepoResult{RepoRoot: repoRoots[i], Status: SweepStatusInterrupted, Err: ErrInterrupted}
+	}
+
+	if ctx.Err() != nil {
+		return report, ErrInterrupted
+	}
+	if failed := report.Count(SweepStatusFailed); failed > 0 {
+		return report, fmt.Errorf("%d of %d repositories failed: %w", failed, len(report.Repos), ErrCritical)
+	}
+	return report, nil
+}
+
+func sweepRepo(
+	ctx context.Context,
+	cfg *Config,
+	deps *Dependencies,
+	repoRoot string,
+	logger *slog.Logger,
+) SweepRepoResult {
+	started := time.Now()
+	res := SweepRepoResult{RepoRoot: repoRoot}
+	bc := newBackupContext(logger.With("repo", repoRoot), cfg.Verbose)
+
+	if err := ensureGitRepo(ctx, deps, repoRoot); err != nil {
+		res.Err = fmt.Errorf("not a git repository: %w", ErrCritical)
+	} else if repoCfg, _, err := resolveRepoConfig(ctx, cfg, deps, repoRoot, bc); err != nil {
+		res.Err = err
+	} else {
+		res.RepoKey = deriveRepoKey(ctx, repoCfg, deps, repoRoot, bc)
+		res.Result, res.Err = backupRepo(ctx, repoCfg, deps, repoRoot, res.RepoKey, bc)
+	}
+	res.Duration = time.Since(started)
+
+	switch {
+	case res.Result != nil && res.Result.PartialSuccess:
+		// handleBackupFlow reports a partial snapshot with ErrCritical; it is kept, not failed.
+		res.Status = SweepStatusPartial
+	case res.Err == nil:
+		res.Status = SweepStatusOK
+	case errors.Is(res.Err, ErrLockBusy):
+		res.Status = SweepStatusLocked
+		bc.logf("⏭ %s: backup already running, skipped", repoRoot)
+	case errors.Is(res.Err, ErrInterrupted):
+		res.Status = SweepStatusInterrupted
+	default:
+		res.Status = SweepStatusFailed
+		bc.warnf("backup of %s failed: %v", repoRoot, res.Err)
+	}
+	return res
+}
+
+// discoverSweepRepos returns the sorted roots of repositories below roots that have backup enabled.
+// Hidden directories, repository contents and the backup base directory are not searched.
+func discoverSweepRepos(
+	ctx context.Context,
+	cfg *Config,
+	deps *Dependencies,
+	roots []string,
+	maxDepth int,
+	bc *backupContext,
+) []string {
+	seen := make(map[string]bool)
+	var repos []string
+	var walk func(dir string, depth int)
+	walk = func(dir string, depth int) {
+		if ctx.Err() != nil || seen[dir] {
+			return
+		}
+		seen[dir] = true
+		entries, err := deps.FileSystem.ReadDir(ctx, dir)
+		if err != nil {
+			bc.warnf("sweep: read %s: %v", dir, err)
+			return
+		}
+		for _, e := range entries {
+			if e.Name() == ".git" {
+				if sweepBackupEnabled(ctx, deps.Git, dir) {
+					repos = append(repos, dir)
+				} else {
+					bc.vlogf("sweep: %s has backup disabled", dir)
+				}
+				return
+			}
+		}
+		if depth >= maxDepth {
+			return
+		}
+		for _, e := range entries {
+			if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+			child := deps.FileSystem.Join(dir, e.Name())
+			if child == deps.FileSystem.Clean(cfg.BackupDir) {
+				continue
+			}
+			walk(child, depth+1)
+		}
+	}
+	for _, root := range roots {
+		walk(deps.FileSystem.Clean(root), 0)
+	}
+	sort.Strings(repos)
+	return repos
+}
+
+// sweepBackupEnabled reads backup.enabled the way the hooks do: worktree, local, then global config.
+func sweepBackupEnabled(ctx context.Context, git GitPort, repoRoot string) bool {
+	value := readRepoConfig(ctx, git, repoRoot, true, "backup.enabled")
+	if value == "" {
+		if global, err := git.ConfigGetGlobal(ctx, "backup.enabled"); err == nil {
+			value = global
+		}
+	}
+	return parseBoolValue(value)
+}
+
+// FormatBackupAll renders the sweep summary; exitCode maps a repository's error to its exit status.
+func FormatBackupAll(report *BackupAllReport, exitCode func(error) int) string {
+	var b strings.Builder
+	if report == nil || len(report.Repos) == 0 {
+		b.WriteString("No repositories with backup enabled found\n")
+		return b.String()
+	}
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "REPOSITORY\tSTATUS\tEXIT\tFILES\tTIME")
+	for _, repo := range report.Repos {
+		files := "-"
+		if repo.Result != nil {
+			files = fmt.Sprint(repo.Result.TotalFiles)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n",
+			repo.RepoRoot, repo.Status, exitCode(repo.Err), files, repo.Duration.Round(time.Millisecond))
+	}
+	_ = tw.Flush()
+	for _, repo := range report.Repos {
+		if repo.Status == SweepStatusFailed {
+			fmt.Fprintf(&b, "  error  %s: %v\n", repo.RepoRoot, repo.Err)
+		}
+	}
+	fmt.Fprintf(&b, "%d repositories: %d ok, %d partial, %d locked, %d failed, %d interrupted\n",
+		len(report.Repos), report.Count(SweepStatusOK), report.Count(SweepStatusPartial),
+		report.Count(SweepStatusLocked), report.Count(SweepStatusFailed), report.Count(SweepStatusInterrupted))
+	return b.String()
+}
@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func initSweepRepo(t *testing.T, repoRoot string, enabled bool) {
+	t.Helper()
+	if err := os.MkdirAll(repoRoot, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	runGitForTest(t, repoRoot, "init", "-q")
+	writeTestFile(t, filepath.Join(repoRoot, "tracked.txt"), "tracked")
+	runGitForTest(t, repoRoot, "add", "tracked.txt")
+	runGitForTest(t, repoRoot, "commit", "-q", "-m", "init")
+	if enabled {
+		runGitForTest(t, repoRoot, "config", "backup.enabled", "true")
+	}
+}
+
+func TestBackupAll_BacksUpEnabledReposAndSkipsLocked(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	alpha := filepath.Join(root, "alpha")
+	beta := filepath.Join(root, "work", "beta")
+	busy := filepath.Join(root, "work", "busy")
+	initSweepRepo(t, alpha, true)
+	initSweepRepo(t, beta, true)
+	initSweepRepo(t, busy, true)
+	initSweepRepo(t, filepath.Join(root, "disabled"), false)
+	initSweepRepo(t, filepath.Join(root, ".hidden", "gamma"), true)
+
+	cfg := &Config{BackupDir: filepath.Join(root, "backups"), RepoKeyStyle: repoKeyStyleNameHash, NoSize: true}
+	lock := &mockLock{AcquireLockFunc: func(_ context.Context, path string, info LockInfo) error {
+		if info.RepoPath == busy {
+			return errors.New("lock is held by another process")
+		}
+		return nil
+	}}
+	deps := &Dependencies{FileSystem: newTestFileSystem(), Git: newTestGitAdapter(), Lock: lock, Process: &mockProcess{}}
+
+	report, err := BackupAll(ctx, cfg, BackupAllOptions{Roots: []string{root}, Concurrency: 2}, deps,
+		newTestBackupContext(false).logger)
+	if err != nil {
+		t.Fatalf("sweep failed: %v", err)
+	}
+	var got []string
+	for _, repo := range report.Repos {
+		got = append(got, filepath.Base(repo.RepoRoot)+"="+repo.Status)
+	}
+	if strings.Join(got, " ") != "alpha=ok beta=ok busy=locked" {
+		t.Fatalf("unexpected sweep result: %v", got)
+	}
+	for _, repo := range report.Repos[:2] {
+		repoDir := filepath.Join(cfg.BackupDir, repo.RepoKey)
+		if n := len(remainingSnapshotIDs(t, deps, repoDir)); n != 1 {
+			t.Fatalf("expected one snapshot for %s, got %d", repo.RepoRoot, n)
+		}
+	}
+
+	summary := FormatBackupAll(report, func(err error) int {
+		if errors.Is(err, ErrLockBusy) {
+			return 76
+		}
+		return 0
+	})
+	if !strings.Contains(summary, "3 repositories: 2 ok, 0 partial, 1 locked, 0 failed") {
+		t.Fatalf("unexpected summary:\n%s", summary)
+	}
+}
+
+// unreadableFS fails to copy files with the given base name, like a file the user cannot read.
+type unreadableFS struct {
+	*testFileSystem
+	name string
+}
+
+func (f unreadableFS) Copy(ctx context.Context, src, dst string) error {
+	if filepath.Base(src) == f.name {
+		return &os.PathError{Op: "open", Path: src, Err: syscall.EACCES}
+	}
+	return f.testFileSystem.Copy(ctx, src, dst)
+}
+
+func TestBackupAll_UnreadableFileIsPartial(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	alpha := filepath.Join(root, "alpha")
+	beta := filepath.Join(root, "beta")
+	initSweepRepo(t, alpha, true)
+	initSweepRepo(t, beta, true)
+	writeTestFile(t, filepath.Join(beta, ".gitignore"), "notes.txt\nsecret.txt\n")
+	writeTestFile(t, filepath.Join(beta, "notes.txt"), "notes")
+	writeTestFile(t, filepath.Join(beta, "secret.txt"), "secret")
+
+	cfg := &Config{BackupDir: filepath.Join(root, "backups"), RepoKeyStyle: repoKeyStyleNameHash, NoSize: true}
+	deps := &Dependencies{
+		FileSystem: unreadableFS{testFileSystem: newTestFileSystem(), name: "secret.txt"},
+		Git:        newTestGitAdapter(),
+		Lock:       &mockLock{},
+		Process:    &mockProcess{},
+	}
+
+	report, err := BackupAll(ctx, cfg, BackupAllOptions{Roots: []string{root}, Concurrency: 1}, deps,
+		newTestBackupContext(false).logger)
+	if err != nil {
+		t.Fatalf("a partial backup must not fail the sweep: %v", err)
+	}
+	var got []string
+	for _, repo := range report.Repos {
+		got = append(got, filepath.Base(repo.RepoRoot)+"="+repo.Status)
+	}
+	if strings.Join(got, " ") != "alpha=ok beta=partial" {
+		t.Fatalf("unexpected sweep result: %v", got)
+	}
+	partial := report.Repos[1]
+	if len(partial.Result.PermissionErrs) != 1 || !strings.Contains(partial.Result.PermissionErrs[0], "secret.txt") {
+		t.Fatalf("unexpected permission errors: %v", partial.Result.PermissionErrs)
+	}
+	ids := remainingSnapshotIDs(t, deps, filepath.Join(cfg.BackupDir, partial.RepoKey))
+	if len(ids) != 1 {
+		t.Fatalf("expected the partial snapshot to be kept, got %v", ids)
+	}
+	summary := FormatBackupAll(report, func(error) int { return 0 })
+	if !strings.Contains(summary, "2 repositories: 1 ok, 1 partial, 0 locked, 0 failed") {
+		t.Fatalf("unexpected summary:\n%s", summary)
+	}
+}
+
+func TestBackupAll_RequiresRoots(t *testing.T) {
+	deps := &Dependencies{FileSystem: newTestFileSystem(), Lock: &mockLock{}, Process: &mockProcess{}}
+	deps.Git = newTestGitAdapter()
+	_, err := BackupAll(context.Background(), &Config{BackupDir: t.TempDir()}, BackupAllOptions{}, deps,
+		newTestBackupContext(false).logger)
+	if !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+}
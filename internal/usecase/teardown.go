@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// TeardownOptions describes how setup is undone for the current repository.
+type TeardownOptions struct {
+	DryRun  bool
+	HomeDir string
+}
+
+// TeardownResult lists what teardown changed (or would change with DryRun).
+type TeardownResult struct {
+	RepoRoot      string
+	RestoredHooks []string
+	RemovedHooks  []string
+	UnsetConfig   []string
+	Unregistered  bool
+}
+
+// setupConfigKeys are the git config keys written by setup.
+var setupConfigKeys = []string{"backup.enabled", "backup.slug"} //nolint:gochecknoglobals // read-only list.
+
+// Teardown undoes devback setup in the current repository: merged hooks are replaced by the
+// originals kept in *.devback.orig, devback-only hooks are removed, backup.enabled and backup.slug
+// are unset and the repository is dropped from the registry. Snapshots are left untouched.
+func Teardown(
+	ctx context.Context,
+	opts TeardownOptions,
+	deps *Dependencies,
+	logger *slog.Logger,
+) (*TeardownResult, error) {
+	if logger == nil {
+		panic("logger is required")
+	}
+	if ctx.Err() != nil {
+		return nil, ErrInterrupted
+	}
+	if err := validateSetupDependencies(deps); err != nil {
+		return nil, err
+	}
+	homeDir := strings.TrimSpace(opts.HomeDir)
+	if homeDir == "" {
+		return nil, fmt.Errorf("home directory is empty: %w", ErrCritical)
+	}
+
+	repoRoot, err := resolveRepoRoot(ctx, deps)
+	if err != nil {
+		return nil, fmt.Errorf("resolve repository root: %w", ErrCritical)
+	}
+	if err := ensureGitRepo(ctx, deps, repoRoot); err != nil {
+		return nil, fmt.Errorf("not a git repository: %w", ErrUsage)
+	}
+	repo, err := resolveSetupRepo(ctx, deps, repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	result := &TeardownResult{RepoRoot: repo.repoRoot}
+
+	if repo.isWorktree {
+		logger.InfoContext(ctx, "Hooks are shared with the main repository; run devback teardown there to remove them")
+	} else if err := teardownHooks(ctx, deps, repo.hooksDir, opts.DryRun, result, logger); err != nil {
+		return nil, err
+	}
+	if err := teardownConfig(ctx, deps.Git, repo, opts.DryRun, result, logger); err != nil {
+		return nil, err
+	}
+
+	registryPath := normalizePath(deps.FileSystem, defaultRegistryPath, homeDir)
+	repoPath := normalizeRepoPath(deps.FileSystem, repo.repoRoot)
+	removed, err := unregisterRepo(ctx, deps.FileSystem, registryPath, repoPath, opts.DryRun)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to update repository registry", "error", err)
+	}
+	if removed {
+		logger.InfoContext(ctx, teardownVerb(opts.DryRun, "Unregistered", "unregister")+" repository", "path", repoPath)
+	}
+	result.Unregistered = removed
+
+	if opts.DryRun {
+		logger.InfoContext(ctx, "Dry run: teardown planned, nothing changed")
+		return result, nil
+	}
+	logger.InfoContext(ctx, "Teardown completed; snapshots were kept")
+	return result, nil
+}
+
+func teardownHooks(
+	ctx context.Context,
+	deps *Dependencies,
+	hooksDir string,
+	dryRun bool,
+	result *TeardownResult,
+	logger *slog.Logger,
+) error {
+	entries, err := deps.FileSystem.ReadDir(ctx, hooksDir)
+	if err != nil {
+		if deps.FileSystem.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read hooks dir: %w", ErrCritical)
+	}
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			return ErrInterrupted
+		}
+		name := e.Name()
+		if e.IsDir() || strings.Contains(name, ".devback.orig") || strings.HasSuffix(name, ".sample") {
+			continue
+		}
+		dst := deps.FileSystem.Join(hooksDir, name)
+		data, err := deps.FileSystem.ReadFile(ctx, dst)
+		if err != nil {
+			return fmt.Errorf("read hook %s: %w", name, ErrCritical)
+		}
+		if !isDevbackHookContent(data, name) {
+			continue
+		}
+
+		backupName := mergedHookBackupName(data)
+		backupPath := ""
+		if backupName != "" {
+			backupPath = deps.FileSystem.Join(hooksDir, backupName)
+			if exists, err := pathExists(ctx, deps.FileSystem, backupPath); err != nil || !exists {
+				logger.WarnContext(ctx, "Original hook is missing, removing wrapper", "hook", name, "backup", backupName)
+				backupPath = ""
+			}
+		}
+
+		if backupPath == "" {
+			result.RemovedHooks = append(result.RemovedHooks, name)
+			logger.InfoContext(ctx, teardownVerb(dryRun, "Removed", "remove")+" devback hook", "hook", name)
+			if !dryRun {
+				if err := deps.FileSystem.RemoveAll(ctx, dst); err != nil {
+					return fmt.Errorf("remove hook %s: %w", name, ErrCritical)
+				}
+			}
+			continue
+		}
+		result.RestoredHooks = append(result.RestoredHooks, name)
+		logger.InfoContext(ctx, teardownVerb(dryRun, "Restored", "restore")+" original hook",
+			"hook", name, "backup", backupName)
+		if dryRun {
+			continue
+		}
+		if err := deps.FileSystem.RemoveAll(ctx, dst); err != nil {
+			return fmt.Errorf("remove hook %s: %w", name, ErrCritical)
+		}
+		if err := deps.FileSystem.Move(ctx, backupPath, dst); err != nil {
+			return fmt.Errorf("restore hook %s: %w", name, ErrCritical)
+		}
+	}
+	return nil
+}
+
+// mergedHookBackupName returns the original hook referenced by a wrapper from buildMergedHook.
+func mergedHookBackupName(data []byte) string {
+	clean := normalizeLineEndings(data)
+	if !bytes.Contains(clean, []byte(devbackMergedHookMarker)) {
+		return ""
+	}
+	for _, line := range strings.Split(string(clean), "\n") {
+		value, ok := strings.CutPrefix(strings.TrimSpace(line), "ORIG_HOOK=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, "\"'")
+		name, ok := strings.CutPrefix(value, "$HOOK_DIR/")
+		if !ok || name == "" || strings.ContainsAny(name, `/\`) {
+			return ""
+		}
+		return name
+	}
+	return ""
+}
+
+func teardownConfig(
+	ctx context.Context,
+	git GitPort,
+	repo setupRepo,
+	dryRun bool,
+	result *TeardownResult,
+	logger *slog.Logger,
+) error {
+	// Without extensions.worktreeConfig, --worktree reads the shared config and is covered below.
+	worktreeScope := false
+	if value, err := git.ConfigGetLocal(ctx, repo.repoRoot, "extensions.worktreeConfig"); err == nil {
+		worktreeScope = parseBoolValue(value)
+	}
+	for _, key := range setupConfigKeys {
+		value, err := git.ConfigGetWorktree(ctx, repo.repoRoot, key)
+		if worktreeScope && err == nil && strings.TrimSpace(value) != "" {
+			result.UnsetConfig = append(result.UnsetConfig, key+" (worktree)")
+			logger.InfoContext(ctx, teardownVerb(dryRun, "Unset", "unset")+" git config", "key", key, "scope", "worktree")
+			if !dryRun {
+				if err := git.ConfigUnsetWorktree(ctx, repo.repoRoot, key); err != nil {
+					return fmt.Errorf("unset git config %s (worktree): %w", key, ErrCritical)
+				}
+			}
+		}
+		if repo.isWorktree {
+			continue
+		}
+		// Only the repository config is read: values from ~/.gitconfig are not ours to unset.
+		if value, err := git.ConfigGetLocal(ctx, repo.repoRoot, key); err == nil && strings.TrimSpace(value) != "" {
+			result.UnsetConfig = append(result.UnsetConfig, key)
+			logger.InfoContext(ctx, teardownVerb(dryRun, "Unset", "unset")+" git config", "key", key, "scope", "local")
+			if !dryRun {
+				if err := git.ConfigUnset(ctx, repo.repoRoot, key); err != nil {
+					return fmt.Errorf("unset git config %s: %w", key, ErrCritical)
+				}
+			}
+		}
+	}
+	if repo.isWorktree {
+		logger.InfoContext(ctx,
+			"Repository config shared with the main repository was kept; run devback teardown there to disable backups")
+	}
+	return nil
+}
+
+func teardownVerb(dryRun bool, done, planned string) string {
+	if dryRun {
+		return "Dry run: would " + planned
+	}
+	return done
+}
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTeardown_RestoresHooksAndUnsetsConfig(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	env := newSetupEnv(t)
+
+	templatesDir := expandHomeDir(DefaultTemplatesDir(), env.homeDir)
+	for _, name := range []string{"post-commit", "post-merge"} {
+		writeTestFile(t, filepath.Join(templatesDir, name), hookTemplateContent(name))
+	}
+	hooksDir := filepath.Join(env.gitDir, "hooks")
+	writeTestFile(t, filepath.Join(hooksDir, "post-commit"), "old")
+	writeTestFile(t, filepath.Join(hooksDir, "pre-push"), "user hook")
+
+	if err := Setup(ctx, SetupOptions{HomeDir: env.homeDir, Slug: "owner/project"}, env.deps, logger); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	registryPath := expandHomeDir(defaultRegistryPath, env.homeDir)
+	if entries, err := loadRegistry(ctx, env.deps.FileSystem, registryPath); err != nil || len(entries) != 1 {
+		t.Fatalf("expected one registry entry after setup, got %v (%v)", entries, err)
+	}
+
+	dry, err := Teardown(ctx, TeardownOptions{HomeDir: env.homeDir, DryRun: true}, env.deps, logger)
+	if err != nil {
+		t.Fatalf("dry-run teardown failed: %v", err)
+	}
+	if len(dry.RestoredHooks) != 1 || len(dry.RemovedHooks) != 1 || !dry.Unregistered {
+		t.Fatalf("unexpected dry-run plan: %+v", dry)
+	}
+	if _, err := os.Stat(filepath.Join(hooksDir, "post-commit.devback.orig")); err != nil {
+		t.Fatalf("dry run must keep the hook backup: %v", err)
+	}
+	if env.git.local["backup.enabled"] != gitConfigTrue {
+		t.Fatal("dry run must keep backup.enabled")
+	}
+
+	if _, err := Teardown(ctx, TeardownOptions{HomeDir: env.homeDir}, env.deps, logger); err != nil {
+		t.Fatalf("teardown failed: %v", err)
+	}
+	// #nosec G304 -- test paths are controlled by the test harness.
+	data, err := os.ReadFile(filepath.Join(hooksDir, "post-commit"))
+	if err != nil || string(data) != "old" {
+		t.Fatalf("expected original post-commit to be restored, got %q (%v)", data, err)
+	}
+	for _, name := range []string{"post-commit.devback.orig", "post-merge"} {
+		if _, err := os.Stat(filepath.Join(hooksDir, name)); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be removed, got %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(hooksDir, "pre-push")); err != nil {
+		t.Fatalf("unrelated hook must be kept: %v", err)
+	}
+	for _, key := range setupConfigKeys {
+		if _, ok := env.git.local[key]; ok {
+			t.Fatalf("expected %s to be unset", key)
+		}
+	}
+	if entries, err := loadRegistry(ctx, env.deps.FileSystem, registryPath); err != nil || len(entries) != 0 {
+		t.Fatalf("expected registry to be empty, got %v (%v)", entries, err)
+	}
+}
+
+func TestTeardown_WorktreeKeepsSharedHooks(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	env := newSetupEnv(t)
+	env.git.gitDir = filepath.Join(".git", "worktrees", "wt")
+	if err := os.MkdirAll(filepath.Join(env.repoRoot, env.git.gitDir), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	env.git.local = map[string]string{"backup.enabled": gitConfigTrue, "extensions.worktreeConfig": gitConfigTrue}
+	env.git.worktree = map[string]string{"backup.slug": "owner/project"}
+	hookPath := filepath.Join(env.gitDir, "hooks", "post-commit")
+	writeTestFile(t, hookPath, hookTemplateContent("post-commit"))
+
+	result, err := Teardown(ctx, TeardownOptions{HomeDir: env.homeDir}, env.deps, logger)
+	if err != nil {
+		t.Fatalf("teardown failed: %v", err)
+	}
+	if len(result.RemovedHooks) != 0 || len(result.RestoredHooks) != 0 {
+		t.Fatalf("worktree teardown must not touch shared hooks: %+v", result)
+	}
+	if _, err := os.Stat(hookPath); err != nil {
+		t.Fatalf("shared hook must be kept: %v", err)
+	}
+	if _, ok := env.git.worktree["backup.slug"]; ok {
+		t.Fatal("expected worktree backup.slug to be unset")
+	}
+	if env.git.local["backup.enabled"] != gitConfigTrue {
+		t.Fatal("shared backup.enabled must be kept for the main repository")
+	}
+}
+
+func TestTeardown_IgnoresGlobalConfig(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	env := newSetupEnv(t)
+	env.git.local = map[string]string{"backup.enabled": gitConfigTrue}
+	env.git.global = map[string]string{"backup.enabled": gitConfigTrue, "backup.slug": "owner/global"}
+
+	result, err := Teardown(ctx, TeardownOptions{HomeDir: env.homeDir, DryRun: true}, env.deps, logger)
+	if err != nil {
+		t.Fatalf("teardown failed: %v", err)
+	}
+	if len(result.UnsetConfig) != 1 || result.UnsetConfig[0] != "backup.enabled" {
+		t.Fatalf("only the repository config may be reported, got %v", result.UnsetConfig)
+	}
+}
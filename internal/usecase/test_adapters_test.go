@@ -1,7 +1,10 @@
 package usecase
 
 import (
+	"archive/tar"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -10,6 +13,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 )
@@ -154,6 +158,33 @@ func (a *testFileSystem) Symlink(ctx context.Context, target, path string) error
 	return os.Symlink(target, path)
 }
 
+func (a *testFileSystem) Link(ctx context.Context, oldname, newname string) error {
+	_ = ctx
+	return os.Link(oldname, newname)
+}
+
+func (a *testFileSystem) HashFile(ctx context.Context, path string) (string, error) {
+	_ = ctx
+	data, err := os.ReadFile(path) // #nosec G304 - test path
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (a *testFileSystem) FileID(info FileInfo) (FileID, bool) {
+	if info == nil {
+		return FileID{}, false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return FileID{}, false
+	}
+	//nolint:unconvert // Dev is int32 on darwin and uint64 on linux.
+	return FileID{Device: uint64(st.Dev), Inode: st.Ino}, true
+}
+
 func (a *testFileSystem) Chmod(ctx context.Context, path string, perm int) error {
 	_ = ctx
 	if perm < 0 || perm > 0o777 {
@@ -268,6 +299,16 @@ func (a *testGitAdapter) ConfigGetGlobal(ctx context.Context, key string) (strin
 	return "", fmt.Errorf("not implemented")
 }
 
+func (a *testGitAdapter) ConfigGetLocal(ctx context.Context, repoPath, key string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "config", "--local", "--get", key)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
 func (a *testGitAdapter) ConfigGetWorktree(ctx context.Context, repoPath, key string) (string, error) {
 	cmd := exec.CommandContext(ctx, "git", "config", "--worktree", "--get", key)
 	cmd.Dir = repoPath
@@ -288,6 +329,28 @@ func (a *testGitAdapter) ConfigSetGlobal(ctx context.Context, key, value string)
 	return fmt.Errorf("not implemented")
 }
 
+func (a *testGitAdapter) ConfigUnset(ctx context.Context, repoPath, key string) error {
+	cmd := exec.CommandContext(ctx, "git", "config", "--local", "--unset-all", key)
+	cmd.Dir = repoPath
+	if err := cmd.Run(); err != nil && cmd.ProcessState.ExitCode() != 5 {
+		return err
+	}
+	return nil
+}
+
+func (a *testGitAdapter) ConfigUnsetWorktree(ctx context.Context, repoPath, key string) error {
+	cmd := exec.CommandContext(ctx, "git", "config", "--worktree", "--unset-all", key)
+	cmd.Dir = repoPath
+	if err := cmd.Run(); err != nil && cmd.ProcessState.ExitCode() != 5 {
+		return err
+	}
+	return nil
+}
+
+func (a *testGitAdapter) ConfigUnsetGlobal(ctx context.Context, key string) error {
+	return fmt.Errorf("not implemented")
+}
+
 func (a *testGitAdapter) GitDir(ctx context.Context, repoPath string) (string, error) {
 	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--git-dir")
 	cmd.Dir = repoPath
@@ -336,6 +399,108 @@ func (a *testGitAdapter) ListIgnoredUntracked(ctx context.Context, repoPath stri
 	return results, nil
 }
 
+func (a *testGitAdapter) GetStatus(ctx context.Context, repoPath string) (GitStatus, error) {
+	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain", "-z", "--untracked-files=all")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return GitStatus{}, err
+	}
+	var status GitStatus
+	entries := strings.Split(string(output), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		x, y, p := entry[0], entry[1], entry[3:]
+		if x == 'R' || x == 'C' {
+			i++
+		}
+		switch {
+		case x == '?':
+			status.UntrackedFiles = append(status.UntrackedFiles, p)
+			continue
+		case x != ' ':
+			status.StagedFiles = append(status.StagedFiles, p)
+		}
+		if y != ' ' {
+			status.ModifiedFiles = append(status.ModifiedFiles, p)
+		}
+	}
+	status.Clean = len(status.StagedFiles)+len(status.ModifiedFiles)+len(status.UntrackedFiles) == 0
+	return status, nil
+}
+
+func (a *testGitAdapter) Diff(ctx context.Context, repoPath string, staged bool) ([]byte, error) {
+	args := []string{"diff", "--binary", "--no-color", "--no-ext-diff", "--ignore-submodules"}
+	if staged {
+		args = append(args, "--cached")
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = repoPath
+	return cmd.Output()
+}
+
+func (a *testGitAdapter) ApplyPatch(ctx context.Context, repoPath, patchPath string) error {
+	cmd := exec.CommandContext(ctx, "git", "apply", "--binary", patchPath)
+	cmd.Dir = repoPath
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (a *testGitAdapter) RestoreWorktree(ctx context.Context, repoPath string) error {
+	cmd := exec.CommandContext(ctx, "git", "checkout-index", "--all", "--force")
+	cmd.Dir = repoPath
+	return cmd.Run()
+}
+
+func (a *testGitAdapter) FsckConnectivity(ctx context.Context, gitDir string) error {
+	cmd := exec.CommandContext(ctx, "git", "--git-dir", gitDir, "fsck", "--connectivity-only", "--no-progress")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (a *testGitAdapter) GetCommitHash(ctx context.Context, repoPath string) (string, error) {
+	return a.revParse(ctx, repoPath, "HEAD")
+}
+
+func (a *testGitAdapter) GetCurrentBranch(ctx context.Context, repoPath string) (string, error) {
+	return a.revParse(ctx, repoPath, "--abbrev-ref", "HEAD")
+}
+
+func (a *testGitAdapter) GetRemotes(ctx context.Context, repoPath string) ([]Remote, error) {
+	cmd := exec.CommandContext(ctx, "git", "remote")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	var remotes []Remote
+	for _, name := range strings.Fields(string(output)) {
+		url, err := a.ConfigGet(ctx, repoPath, "remote."+name+".url")
+		if err != nil {
+			return nil, err
+		}
+		remotes = append(remotes, Remote{Name: name, URL: url})
+	}
+	return remotes, nil
+}
+
+func (a *testGitAdapter) revParse(ctx context.Context, repoPath string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"rev-parse"}, args...)...)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
 func parseWorktreeListOutput(output string) []WorktreeInfo {
 	lines := strings.Split(output, "\n")
 	worktrees := make([]WorktreeInfo, 0)
@@ -370,3 +535,179 @@ func parseWorktreeListOutput(output string) []WorktreeInfo {
 	}
 	return worktrees
 }
+
+// testArchive is a tar-only ArchivePort used by usecase tests.
+type testArchive struct{}
+
+type testArchiveWriter struct {
+	mu   sync.Mutex
+	file *os.File
+	tw   *tar.Writer
+}
+
+func (testArchive) Create(ctx context.Context, path, format string, sealer Sealer) (ArchiveWriter, error) {
+	_ = ctx
+	if format != SnapshotFormatTar {
+		return nil, fmt.Errorf("test archive supports only tar, got %q", format)
+	}
+	file, err := os.Create(path) // #nosec G304 - test path
+	if err != nil {
+		return nil, err
+	}
+	return &testArchiveWriter{file: file, tw: tar.NewWriter(file)}, nil
+}
+
+func (testArchive) Extract(ctx context.Context, path, format, dir string, opener Opener) (int, error) {
+	_ = ctx
+	file, err := os.Open(path) // #nosec G304 - test path
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = file.Close() }()
+	tr := tar.NewReader(file)
+	files := 0
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return files, err
+		}
+		dst := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(dst, 0o750)
+		case tar.TypeSymlink:
+			err = os.Symlink(hdr.Linkname, dst)
+		case tar.TypeReg:
+			var data []byte
+			if err = os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+				break
+			}
+			if data, err = io.ReadAll(tr); err == nil {
+				err = os.WriteFile(dst, data, fs.FileMode(hdr.Mode).Perm()) // #nosec G115 - test data
+				files++
+			}
+		}
+		if err != nil {
+			return files, err
+		}
+	}
+}
+
+func (w *testArchiveWriter) AddDir(name string, info FileInfo) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: name + "/", Mode: 0o755})
+}
+
+func (w *testArchiveWriter) AddFile(ctx context.Context, name, src string, info FileInfo) error {
+	_ = ctx
+	data, err := os.ReadFile(src) // #nosec G304 - test path
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	hdr := &tar.Header{Typeflag: tar.TypeReg, Name: name, Size: int64(len(data)), Mode: int64(info.Mode() & 0o777)}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = w.tw.Write(data)
+	return err
+}
+
+func (w *testArchiveWriter) AddSymlink(name, target string, info FileInfo) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.tw.WriteHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: name, Linkname: target})
+}
+
+func (w *testArchiveWriter) Close() error {
+	return errors.Join(w.tw.Close(), w.file.Close())
+}
+
+// testEncryption is an EncryptionPort whose "key" is the first recipient (sealing) or the
+// passphrase (opening); content is XOR-ed and prefixed with the key so a wrong key is detected.
+type testEncryption struct{}
+
+type testCipher struct{ key string }
+
+func (testEncryption) NewSealer(ctx context.Context, settings EncryptionSettings) (Sealer, error) {
+	if len(settings.Recipients) == 0 {
+		return nil, errors.New("no recipients")
+	}
+	return testCipher{key: settings.Recipients[0]}, nil
+}
+
+func (testEncryption) NewOpener(ctx context.Context, settings EncryptionSettings) (Opener, error) {
+	if settings.Passphrase == "" {
+		return nil, errors.New("no passphrase")
+	}
+	return testCipher{key: settings.Passphrase}, nil
+}
+
+func (testEncryption) GenerateIdentity() (string, string, error) {
+	return "test-recipient", "test-identity", nil
+}
+
+func (testEncryption) ValidateRecipient(recipient string) error {
+	if strings.HasPrefix(recipient, "bad") {
+		return fmt.Errorf("invalid recipient %q", recipient)
+	}
+	return nil
+}
+
+func (c testCipher) Scheme() string { return "test" }
+
+func (c testCipher) PassphraseKDF() string { return "" }
+
+func (c testCipher) xor(data []byte) []byte {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[i] = b ^ 0x5a
+	}
+	return out
+}
+
+func (c testCipher) SealWriter(w io.Writer) (io.WriteCloser, error) {
+	return nil, errors.New("test cipher does not stream")
+}
+
+func (c testCipher) OpenReader(r io.Reader) (io.Reader, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	plain, ok := strings.CutPrefix(string(data), "testenc:"+c.key+"\n")
+	if !ok {
+		return nil, errors.New("wrong key")
+	}
+	return strings.NewReader(string(c.xor([]byte(plain)))), nil
+}
+
+func (c testCipher) SealFile(ctx context.Context, src, dst string) error {
+	data, err := os.ReadFile(src) // #nosec G304 - test path
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, append([]byte("testenc:"+c.key+"\n"), c.xor(data)...), 0o600)
+}
+
+func (c testCipher) OpenFile(ctx context.Context, src, dst string) error {
+	file, err := os.Open(src) // #nosec G304 - test path
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	r, err := c.OpenReader(file)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0o600)
+}
@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Modes accepted in backup.tracked_changes.
+const (
+	TrackedChangesOff   = "off"
+	TrackedChangesCopy  = "copy"
+	TrackedChangesPatch = "patch"
+)
+
+// Layout of captured tracked changes inside a snapshot. Staged changes are already part of
+// the copied index; these files add the unstaged ones.
+const (
+	trackedChangesDir    = "_tracked"
+	trackedFilesDir      = "files"
+	worktreePatchName    = "worktree.patch"
+	indexPatchName       = "index.patch"
+	trackedStagingPrefix = "devback-tracked-*"
+)
+
+// normalizeTrackedChanges validates backup.tracked_changes; an empty value means "off".
+func normalizeTrackedChanges(mode string) (string, error) {
+	switch m := strings.ToLower(strings.TrimSpace(mode)); m {
+	case "":
+		return TrackedChangesOff, nil
+	case TrackedChangesOff, TrackedChangesCopy, TrackedChangesPatch:
+		return m, nil
+	default:
+		return "", fmt.Errorf("backup.tracked_changes %q is not supported (use off, copy or patch): %w", mode, ErrUsage)
+	}
+}
+
+// trackedCapture lists the files that preserve uncommitted changes to tracked files:
+// paths relative to srcRoot, stored under dstRel in the snapshot.
+type trackedCapture struct {
+	srcRoot string
+	dstRel  string
+	paths   []string
+	changed int
+}
+
+// prepareTrackedChanges collects the modified tracked files of repoRoot for mode. In copy mode
+// the current contents of every modified file are captured; in patch mode worktree.patch and
+// index.patch are written to a local temporary directory, removed by the returned cleanup. The
+// patches are staged outside backup.base_dir so that only their sealed copies reach the snapshot.
+func prepareTrackedChanges(
+	ctx context.Context,
+	deps *Dependencies,
+	mode,
+	repoRoot string,
+	bc *backupContext,
+) (*trackedCapture, func(), error) {
+	noop := func() {}
+	if mode == "" || mode == TrackedChangesOff {
+		return nil, noop, nil
+	}
+	status, err := deps.Git.GetStatus(ctx, repoRoot)
+	if err != nil {
+		return nil, noop, fmt.Errorf("git status: %w", ErrCritical)
+	}
+
+	if mode == TrackedChangesCopy {
+		capture := &trackedCapture{srcRoot: repoRoot, dstRel: deps.FileSystem.Join(trackedChangesDir, trackedFilesDir)}
+		for _, rel := range status.ModifiedFiles {
+			if _, err := deps.FileSystem.Lstat(ctx, deps.FileSystem.Join(repoRoot, rel)); err != nil {
+				bc.vlogf("   DELETED: %s (not captured in copy mode)", rel)
+				continue
+			}
+			bc.vlogf("   MODIFIED: %s", rel)
+			capture.paths = append(capture.paths, rel)
+		}
+		capture.changed = len(capture.paths)
+		return capture, noop, nil
+	}
+
+	staging, err := deps.FileSystem.TempDir(ctx, "", trackedStagingPrefix)
+	if err != nil {
+		return nil, noop, fmt.Errorf("create staging directory: %w", err)
+	}
+	cleanup := func() { _ = deps.FileSystem.RemoveAll(ctx, staging) }
+	capture := &trackedCapture{srcRoot: staging, dstRel: trackedChangesDir, changed: countTrackedChanges(status)}
+	for _, p := range []struct {
+		name   string
+		staged bool
+	}{{worktreePatchName, false}, {indexPatchName, true}} {
+		data, err := deps.Git.Diff(ctx, repoRoot, p.staged)
+		if err != nil {
+			cleanup()
+			return nil, noop, fmt.Errorf("git diff: %w", ErrCritical)
+		}
+		if len(data) == 0 {
+			continue
+		}
+		if err := deps.FileSystem.WriteFile(ctx, deps.FileSystem.Join(staging, p.name), data, 0o644); err != nil {
+			cleanup()
+			return nil, noop, fmt.Errorf("write %s: %w", p.name, err)
+		}
+		bc.vlogf("   PATCH: %s (%d bytes)", p.name, len(data))
+		capture.paths = append(capture.paths, p.name)
+	}
+	return capture, cleanup, nil
+}
+
+// countTrackedChanges counts distinct tracked paths with staged or unstaged changes.
+func countTrackedChanges(status GitStatus) int {
+	seen := make(map[string]struct{}, len(status.ModifiedFiles)+len(status.StagedFiles))
+	for _, p := range status.ModifiedFiles {
+		seen[p] = struct{}{}
+	}
+	for _, p := range status.StagedFiles {
+		seen[p] = struct{}{}
+	}
+	return len(seen)
+}
+
+// copyTrackedChanges stores modified tracked files of a directory snapshot under _tracked.
+func copyTrackedChanges(
+	ctx context.Context,
+	deps *Dependencies,
+	mode,
+	repoRoot,
+	targetPath string,
+	result *BackupResult,
+	bc *backupContext,
+) error {
+	capture, cleanup, err := prepareTrackedChanges(ctx, deps, mode, repoRoot, bc)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if capture == nil {
+		return nil
+	}
+	dst := deps.FileSystem.Join(targetPath, capture.dstRel)
+	if err := copySelectedFiles(ctx, deps, capture.paths, capture.srcRoot, dst, result, bc); err != nil {
+		return err
+	}
+	result.TrackedChanges = capture.changed
+	logTrackedChanges(mode, capture, bc)
+	return nil
+}
+
+func logTrackedChanges(mode string, capture *trackedCapture, bc *backupContext) {
+	switch {
+	case capture.changed == 0:
+		bc.logf("⌘ No modified tracked files to capture")
+	case mode == TrackedChangesPatch:
+		bc.logf("✓ Captured changes of %d tracked file(s) as patches", capture.changed)
+	default:
+		bc.logf("✓ Copied modified tracked: %d file(s)", capture.changed)
+	}
+}
+
+// planTrackedChanges reports what copyTrackedChanges would capture.
+func planTrackedChanges(ctx context.Context, deps *Dependencies, mode, repoRoot string, bc *backupContext) error {
+	if mode == "" || mode == TrackedChangesOff {
+		return nil
+	}
+	status, err := deps.Git.GetStatus(ctx, repoRoot)
+	if err != nil {
+		return fmt.Errorf("git status: %w", ErrCritical)
+	}
+	if mode == TrackedChangesPatch {
+		bc.logf("Dry run: would write %s and %s for %d changed tracked file(s)",
+			worktreePatchName, indexPatchName, countTrackedChanges(status))
+		return nil
+	}
+	bc.logf("Dry run: would copy modified tracked: %d file(s)", len(status.ModifiedFiles))
+	return nil
+}
+
+// applyTrackedChanges reapplies the unstaged changes captured in target/_tracked on top of the
+// worktree rebuilt from the index, then removes the directory.
+func applyTrackedChanges(ctx context.Context, deps *Dependencies, target string, bc *backupContext) error {
+	dir := deps.FileSystem.Join(target, trackedChangesDir)
+	if exists, err := pathExists(ctx, deps.FileSystem, dir); err != nil || !exists {
+		return err
+	}
+
+	filesDir := deps.FileSystem.Join(dir, trackedFilesDir)
+	if exists, _ := pathExists(ctx, deps.FileSystem, filesDir); exists {
+		// Contents were already decrypted when the snapshot was copied into target.
+		plain := newBackupContext(bc.logger, bc.verbose)
+		var copied BackupResult
+		if err := copyDirRecursive(ctx, deps, filesDir, target, &copied, plain); err != nil {
+			return fmt.Errorf("copy modified tracked files: %w", err)
+		}
+		bc.logf("✓ Modified tracked files reapplied: %d file(s)", copied.CopiedFiles)
+	}
+
+	patch := deps.FileSystem.Join(dir, worktreePatchName)
+	if exists, _ := pathExists(ctx, deps.FileSystem, patch); exists {
+		if err := deps.Git.ApplyPatch(ctx, target, patch); err != nil {
+			return fmt.Errorf("apply %s: %w", worktreePatchName, err)
+		}
+		bc.logf("✓ Unstaged changes reapplied from %s", worktreePatchName)
+	}
+	return deps.FileSystem.RemoveAll(ctx, dir)
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNormalizeTrackedChanges(t *testing.T) {
+	for in, want := range map[string]string{"": "off", "off": "off", " Copy ": "copy", "patch": "patch"} {
+		got, err := normalizeTrackedChanges(in)
+		if err != nil || got != want {
+			t.Fatalf("normalizeTrackedChanges(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := normalizeTrackedChanges("diff"); !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+}
+
+// writeRecordingFS records the paths of files written through WriteFile.
+type writeRecordingFS struct {
+	*testFileSystem
+	mu     sync.Mutex
+	writes []string
+}
+
+func (f *writeRecordingFS) WriteFile(ctx context.Context, path string, data []byte, perm int) error {
+	f.mu.Lock()
+	f.writes = append(f.writes, path)
+	f.mu.Unlock()
+	return f.testFileSystem.WriteFile(ctx, path, data, perm)
+}
+
+func TestBackupRestore_TrackedChanges(t *testing.T) {
+	for _, tc := range []struct {
+		mode, format string
+		files        []string
+	}{
+		{TrackedChangesCopy, SnapshotFormatDir, []string{"files/a.txt", "files/dir/b.txt"}},
+		{TrackedChangesPatch, SnapshotFormatDir, []string{worktreePatchName, indexPatchName}},
+		{TrackedChangesCopy, SnapshotFormatTar, nil},
+		{TrackedChangesPatch, SnapshotFormatTar, nil},
+	} {
+		t.Run(tc.mode+"-"+tc.format, func(t *testing.T) {
+			ctx := context.Background()
+			repoRoot := t.TempDir()
+			runGitForTest(t, repoRoot, "init", "-q")
+			writeTestFile(t, filepath.Join(repoRoot, "a.txt"), "a1\n")
+			writeTestFile(t, filepath.Join(repoRoot, "dir", "b.txt"), "b1\n")
+			runGitForTest(t, repoRoot, "add", ".")
+			runGitForTest(t, repoRoot, "commit", "-q", "-m", "init")
+			// a.txt has a staged and an unstaged change; dir/b.txt only an unstaged one.
+			writeTestFile(t, filepath.Join(repoRoot, "a.txt"), "a2\n")
+			runGitForTest(t, repoRoot, "add", "a.txt")
+			writeTestFile(t, filepath.Join(repoRoot, "a.txt"), "a3\n")
+			writeTestFile(t, filepath.Join(repoRoot, "dir", "b.txt"), "b2\n")
+
+			backupDir := t.TempDir()
+			repoKey := "repo--deadbeef"
+			repoDir := filepath.Join(backupDir, repoKey)
+			cfg := &Config{BackupDir: backupDir, NoSize: true, Format: tc.format, TrackedChanges: tc.mode}
+			fs := &writeRecordingFS{testFileSystem: newTestFileSystem()}
+			deps := &Dependencies{
+				FileSystem: fs,
+				Git:        newTestGitAdapter(),
+				Lock:       &mockLock{},
+				Process:    &mockProcess{},
+				Archive:    testArchive{},
+			}
+			result, err := handleBackupFlow(ctx, cfg, deps, repoRoot, repoDir, newTestBackupContext(false))
+			if err != nil {
+				t.Fatalf("backup failed: %v", err)
+			}
+			if result.TrackedChanges != 2 {
+				t.Fatalf("expected 2 tracked changes, got %d", result.TrackedChanges)
+			}
+			snaps, err := listSnapshots(ctx, deps, repoDir)
+			if err != nil || len(snaps) != 1 {
+				t.Fatalf("expected one snapshot, got %d (%v)", len(snaps), err)
+			}
+			// Patches are staged outside the backup directory and removed afterwards.
+			patches := 0
+			for _, path := range fs.writes {
+				if !strings.HasSuffix(path, ".patch") {
+					continue
+				}
+				patches++
+				if strings.HasPrefix(path, backupDir) {
+					t.Fatalf("patch staged inside the backup directory: %s", path)
+				}
+				if _, err := os.Stat(filepath.Dir(path)); !os.IsNotExist(err) {
+					t.Fatalf("staging directory must be removed: %v", err)
+				}
+			}
+			if tc.mode == TrackedChangesPatch && patches != 2 {
+				t.Fatalf("expected 2 staged patches, got %d", patches)
+			}
+			for _, name := range tc.files {
+				if _, err := os.Stat(filepath.Join(snaps[0].TimeDir, trackedChangesDir, name)); err != nil {
+					t.Fatalf("expected %s in snapshot: %v", name, err)
+				}
+			}
+
+			target := filepath.Join(t.TempDir(), "restored")
+			if _, err := Restore(ctx, cfg, RestoreOptions{RepoKey: repoKey, Target: target}, deps,
+				newTestBackupContext(false).logger); err != nil {
+				t.Fatalf("restore failed: %v", err)
+			}
+			for name, want := range map[string]string{"a.txt": "a3\n", filepath.Join("dir", "b.txt"): "b2\n"} {
+				data, err := os.ReadFile(filepath.Join(target, name)) // #nosec G304 -- test paths.
+				if err != nil || string(data) != want {
+					t.Fatalf("expected %s = %q, got %q (%v)", name, want, data, err)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(target, trackedChangesDir)); !os.IsNotExist(err) {
+				t.Fatalf("%s must be removed after restore: %v", trackedChangesDir, err)
+			}
+			cmd := exec.Command("git", "diff", "--cached", "--name-only")
+			cmd.Dir = target
+			out, err := cmd.Output()
+			if err != nil || strings.TrimSpace(string(out)) != "a.txt" {
+				t.Fatalf("expected staged a.txt to survive restore, got %q (%v)", out, err)
+			}
+		})
+	}
+}
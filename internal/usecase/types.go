@@ -11,12 +11,52 @@ type Config struct {
 	TestLocks         bool
 	KeepCount         int
 	KeepDays          int
+	KeepHourly        int
+	KeepDaily         int
+	KeepWeekly        int
+	KeepMonthly       int
+	KeepYearly        int
 	MaxTotalGBPerRepo int
 	SizeMarginMB      int
 	RepoKeyStyle      string
 	AutoRemoteMerge   bool
 	RemoteHashLen     int
 	NoSize            bool
+	Dedup             bool
+	Format            string
+	TrackedChanges    string
+	MaxFileMB         int // guards for ignored/untracked files, see applyUntrackedGuards
+	MaxUntrackedMB    int
+	SkipExtensions    []string
+	AllowLarge        bool
+	Encryption        EncryptionSettings
+	SweepRoots        []string
+	SweepConcurrency  int
+	SweepMaxDepth     int
+	WatchDebounce     int // seconds
+	WatchMinInterval  int // minutes
+	WatchKeepCount    int
+	WatchKeepHours    int
+	Targets           []TargetSettings
+	RepoRules         []RepoRule
+	GlobalIgnoreFile  string
+	HomeDir           string
+	Trigger           string
+	Pin               bool
+	Label             string
+	Version           string
+}
+
+// EncryptionSettings describes how snapshot files are encrypted and decrypted.
+// Passphrase is only set interactively (e.g. by decrypt) and is never persisted.
+type EncryptionSettings struct {
+	Enabled        bool
+	Recipients     []string
+	PassphraseFile string
+	IdentityFile   string
+	Passphrase     string
+	// PassphraseKDF comes from the manifest of the snapshot being decrypted.
+	PassphraseKDF string
 }
 
 // FileInfo represents file information.
@@ -31,6 +71,12 @@ type FileInfo interface {
 	Sys() interface{}
 }
 
+// FileID identifies a file on disk; hardlinks to the same data share one FileID.
+type FileID struct {
+	Device uint64
+	Inode  uint64
+}
+
 // WalkFunc is called for each file/directory during Walk.
 type WalkFunc func(path string, info FileInfo, err error) error
 
@@ -40,10 +86,70 @@ type DirEntry interface {
 	IsDir() bool
 }
 
+// WatchEvent reports a change below a watched root. Overflow means events were lost and
+// anything below the root may have changed.
+type WatchEvent struct {
+	Path     string
+	Overflow bool
+}
+
+// ScheduleSpec describes the periodic backup-all job installed by devback schedule.
+type ScheduleSpec struct {
+	Executable string
+	Args       []string
+	Every      time.Duration
+	Path       string // PATH for the job, which does not inherit the login shell environment
+}
+
+// ScheduleFile is a job file written by SchedulerPort.Install.
+type ScheduleFile struct {
+	Path    string
+	Content string
+}
+
+// ScheduleInfo reports the state of the installed backup-all job.
+type ScheduleInfo struct {
+	Backend   string
+	Installed bool
+	Active    bool
+	Every     time.Duration
+	Command   string
+	Files     []string
+	NextRun   string
+	LastRun   string
+	Detail    string
+}
+
+// TargetSettings describes a remote snapshot target from [[targets]]. Credentials are
+// read by the storage adapter from CredentialsFile or the environment, never stored here.
+type TargetSettings struct {
+	Name            string
+	Type            string
+	Endpoint        string
+	Bucket          string
+	Prefix          string
+	Region          string
+	CredentialsFile string
+	URL             string
+	IdentityFile    string
+	KnownHostsFile  string
+	Path            string
+	Verify          string
+	KeepCount       int
+	KeepDays        int
+}
+
+// StoredObject describes one object in a SnapshotStore.
+type StoredObject struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
 // Remote represents git remote.
 type Remote struct {
-	Name string
-	URL  string
+	Name string `json:"name"`
+	URL  string `json:"url"`
 }
 
 // WorktreeInfo describes a git worktree entry.
@@ -91,11 +197,24 @@ type ProcessInfo struct {
 
 // BackupResult contains backup execution statistics
 type BackupResult struct {
-	TotalFiles     int
-	CopiedFiles    int
-	SkippedFiles   int
-	SkippedDirs    int
-	PermissionErrs []string
-	OtherErrors    []string
-	PartialSuccess bool
+	// TotalFiles counts the regular files stored in the snapshot: CopiedFiles, which include
+	// linked files, plus ExternalFiles.
+	TotalFiles     int      `json:"total_files"`
+	CopiedFiles    int      `json:"copied_files"`
+	LinkedFiles    int      `json:"linked_files"`
+	SkippedFiles   int      `json:"skipped_files"`
+	SkippedDirs    int      `json:"skipped_dirs"`
+	PermissionErrs []string `json:"permission_errors"`
+	OtherErrors    []string `json:"other_errors"`
+	PartialSuccess bool     `json:"partial_success"`
+	TrackedChanges int      `json:"tracked_changes"`
+	// ExternalPaths lists the .devbackinclude paths captured under _external; their files are
+	// counted in ExternalFiles, not in CopiedFiles.
+	ExternalPaths []string `json:"external_paths,omitempty"`
+	ExternalFiles int      `json:"external_files,omitempty"`
+	// SkippedLarge and SkippedBinary count ignored/untracked files left out by the size and
+	// extension guards; GuardSkipped lists their paths.
+	SkippedLarge  int      `json:"skipped_large,omitempty"`
+	SkippedBinary int      `json:"skipped_binary,omitempty"`
+	GuardSkipped  []string `json:"guard_skipped,omitempty"`
 }
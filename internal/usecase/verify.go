@@ -0,0 +1,428 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	checksumsFileName  = "checksums.json"
+	quarantineMarker   = ".quarantine"
+	checksumsAlgorithm = "sha256"
+)
+
+// snapshotChecksums is stored as checksums.json next to the snapshot content.
+// Keys are slash-separated paths relative to the snapshot directory.
+type snapshotChecksums struct {
+	Algorithm string            `json:"algorithm"`
+	Files     map[string]string `json:"files"`
+}
+
+// recordSnapshotChecksums hashes every stored file of snapDir (ciphertext or archive included)
+// and writes checksums.json. Files hardlinked from prevDir reuse the hash recorded there.
+func recordSnapshotChecksums(
+	ctx context.Context,
+	deps *Dependencies,
+	snapDir,
+	prevDir string,
+	bc *backupContext,
+) error {
+	var prev *snapshotChecksums
+	if prevDir != "" {
+		prev, _ = readSnapshotChecksums(ctx, deps, prevDir)
+	}
+	sums := snapshotChecksums{Algorithm: checksumsAlgorithm, Files: make(map[string]string)}
+	err := walkSnapshotFiles(ctx, deps, snapDir, func(rel, path string, info FileInfo) error {
+		if sum, ok := reusedChecksum(ctx, deps, prev, prevDir, rel, info); ok {
+			sums.Files[rel] = sum
+			return nil
+		}
+		sum, err := deps.FileSystem.HashFile(ctx, path)
+		if err != nil {
+			bc.warnf("checksum '%s': %v", rel, err)
+			return nil
+		}
+		sums.Files[rel] = sum
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(sums, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode checksums: %w", err)
+	}
+	data = append(data, '\n')
+	return deps.FileSystem.WriteFile(ctx, deps.FileSystem.Join(snapDir, checksumsFileName), data, 0o644)
+}
+
+// reusedChecksum returns the previous snapshot's hash for rel when both paths are the same file.
+func reusedChecksum(
+	ctx context.Context,
+	deps *Dependencies,
+	prev *snapshotChecksums,
+	prevDir,
+	rel string,
+	info FileInfo,
+) (string, bool) {
+	if prev == nil {
+		return "", false
+	}
+	sum, ok := prev.Files[rel]
+	if !ok {
+		return "", false
+	}
+	id, ok := deps.FileSystem.FileID(info)
+	if !ok {
+		return "", false
+	}
+	prevInfo, err := deps.FileSystem.Lstat(ctx, deps.FileSystem.Join(prevDir, rel))
+	if err != nil {
+		return "", false
+	}
+	prevID, ok := deps.FileSystem.FileID(prevInfo)
+	return sum, ok && prevID == id
+}
+
+func readSnapshotChecksums(ctx context.Context, deps *Dependencies, snapDir string) (*snapshotChecksums, error) {
+	data, err := deps.FileSystem.ReadFile(ctx, deps.FileSystem.Join(snapDir, checksumsFileName))
+	if err != nil {
+		return nil, err
+	}
+	var sums snapshotChecksums
+	if err := json.Unmarshal(data, &sums); err != nil {
+		return nil, fmt.Errorf("parse checksums: %w", err)
+	}
+	if sums.Algorithm != checksumsAlgorithm {
+		return nil, fmt.Errorf("unsupported checksum algorithm %q", sums.Algorithm)
+	}
+	return &sums, nil
+}
+
+// walkSnapshotFiles calls fn for every regular content file of snapDir, skipping protocol markers.
+func walkSnapshotFiles(
+	ctx context.Context,
+	deps *Dependencies,
+	snapDir string,
+	fn func(rel, path string, info FileInfo) error,
+) error {
+	return deps.FileSystem.Walk(ctx, snapDir, func(path string, info FileInfo, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil || info == nil || !info.IsRegular() {
+			return nil
+		}
+		rel, err := deps.FileSystem.Rel(snapDir, path)
+		if err != nil {
+			return nil
+		}
+		rel = archiveName(deps.FileSystem, rel)
+		if isSnapshotMarker(rel) {
+			return nil
+		}
+		return fn(rel, path, info)
+	})
+}
+
+// VerifyOptions describes snapshot verification behavior.
+type VerifyOptions struct {
+	RepoKey    string
+	Snapshot   string
+	All        bool
+	Quarantine bool
+}
+
+// VerifyReport lists verification results per snapshot.
+type VerifyReport struct {
+	RepoKey   string                 `json:"repo_key"`
+	Snapshots []SnapshotVerification `json:"snapshots"`
+}
+
+// SnapshotVerification is the result of verifying one snapshot.
+type SnapshotVerification struct {
+	ID          string   `json:"id"`
+	Path        string   `json:"path"`
+	Checked     int      `json:"checked_files"`
+	Missing     []string `json:"missing"`
+	Corrupt     []string `json:"corrupt"`
+	NoChecksums bool     `json:"no_checksums"`
+	Fsck        string   `json:"fsck"`
+	FsckError   string   `json:"fsck_error,omitempty"`
+	Quarantined bool     `json:"quarantined"`
+}
+
+const (
+	fsckOK      = "ok"
+	fsckFailed  = "failed"
+	fsckSkipped = "skipped"
+)
+
+// OK reports whether the snapshot passed every check that could be run.
+func (v SnapshotVerification) OK() bool {
+	return len(v.Missing) == 0 && len(v.Corrupt) == 0 && v.Fsck != fsckFailed
+}
+
+// Verify checks recorded checksums and git connectivity of the latest, a given, or all snapshots
+// of a repository. It returns ErrVerifyFailed when any snapshot is broken; with opts.Quarantine
+// broken snapshots get a .quarantine marker so rotation no longer counts or removes them.
+func Verify(
+	ctx context.Context,
+	cfg *Config,
+	opts VerifyOptions,
+	deps *Dependencies,
+	logger *slog.Logger,
+) (*VerifyReport, error) {
+	if logger == nil {
+		panic("logger is required")
+	}
+	if err := validateRestoreDependencies(deps, false); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(cfg.BackupDir) == "" {
+		return nil, fmt.Errorf("backup.base_dir is not configured: %w", ErrUsage)
+	}
+	if opts.All && strings.TrimSpace(opts.Snapshot) != "" {
+		return nil, fmt.Errorf("--snapshot and --all are mutually exclusive: %w", ErrUsage)
+	}
+	bc := newBackupContext(logger, cfg.Verbose)
+	repoKey, err := resolveCommandRepoKey(ctx, cfg, deps, opts.RepoKey, bc)
+	if err != nil {
+		return nil, err
+	}
+	repoDir := deps.FileSystem.Join(cfg.BackupDir, repoKey)
+	snaps, err := selectVerifySnapshots(ctx, deps, repoDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	lockPath, releaseLock, err := acquireBackupLock(ctx, deps, repoDir, repoDir, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseLock()
+	stopRefresh := startLockRefresh(ctx, deps, lockPath, logger)
+	defer stopRefresh()
+
+	report := &VerifyReport{RepoKey: repoKey, Snapshots: []SnapshotVerification{}}
+	broken := 0
+	for _, snap := range snaps {
+		if ctx.Err() != nil {
+			return report, ErrInterrupted
+		}
+		v := verifySnapshot(ctx, deps, repoDir, snap, bc)
+		if !v.OK() {
+			broken++
+		}
+		if opts.Quarantine {
+			updateQuarantine(ctx, deps, snap, &v, bc)
+		} else {
+			v.Quarantined = snap.Quarantined
+		}
+		report.Snapshots = append(report.Snapshots, v)
+	}
+	if broken > 0 {
+		return report, fmt.Errorf("%d of %d snapshot(s) failed verification: %w", broken, len(snaps), ErrVerifyFailed)
+	}
+	return report, nil
+}
+
+func selectVerifySnapshots(
+	ctx context.Context,
+	deps *Dependencies,
+	repoDir string,
+	opts VerifyOptions,
+) ([]snapshot, error) {
+	if !opts.All {
+		snap, err := findSnapshot(ctx, deps, repoDir, opts.Snapshot)
+		if err != nil {
+			return nil, err
+		}
+		return []snapshot{snap}, nil
+	}
+	snaps, err := listSnapshots(ctx, deps, repoDir)
+	if err != nil {
+		if deps.FileSystem.IsNotExist(err) {
+			return nil, fmt.Errorf("no snapshots in %s: %w", repoDir, ErrUsage)
+		}
+		return nil, fmt.Errorf("list snapshots in %s: %w", repoDir, ErrCritical)
+	}
+	if len(snaps) == 0 {
+		return nil, fmt.Errorf("no completed snapshots in %s: %w", repoDir, ErrUsage)
+	}
+	return snaps, nil
+}
+
+func verifySnapshot(
+	ctx context.Context,
+	deps *Dependencies,
+	repoDir string,
+	snap snapshot,
+	bc *backupContext,
+) SnapshotVerification {
+	v := SnapshotVerification{
+		ID:      snapshotID(deps.FileSystem, repoDir, snap),
+		Path:    snap.TimeDir,
+		Missing: []string{},
+		Corrupt: []string{},
+	}
+	bc.vlogf("→ Verify %s", v.ID)
+	if sums, err := readSnapshotChecksums(ctx, deps, snap.TimeDir); err == nil {
+		verifyChecksums(ctx, deps, snap.TimeDir, sums, &v, bc)
+	} else {
+		v.NoChecksums = true
+		bc.warnf("%s: no usable %s (%v); checking git connectivity only", v.ID, checksumsFileName, err)
+	}
+	verifyGitConnectivity(ctx, deps, repoDir, snap, &v, bc)
+
+	switch {
+	case v.OK() && v.NoChecksums:
+		bc.logf("✓ %s: git fsck %s (no checksums recorded)", v.ID, v.Fsck)
+	case v.OK():
+		bc.logf("✓ %s: %d file(s) intact, git fsck %s", v.ID, v.Checked, v.Fsck)
+	default:
+		bc.warnf("✗ %s: %d missing, %d corrupt, git fsck %s", v.ID, len(v.Missing), len(v.Corrupt), v.Fsck)
+	}
+	return v
+}
+
+func verifyChecksums(
+	ctx context.Context,
+	deps *Dependencies,
+	snapDir string,
+	sums *snapshotChecksums,
+	v *SnapshotVerification,
+	bc *backupContext,
+) {
+	names := make([]string, 0, len(sums.Files))
+	for rel := range sums.Files {
+		names = append(names, rel)
+	}
+	sort.Strings(names)
+	for _, rel := range names {
+		if ctx.Err() != nil {
+			return
+		}
+		path := deps.FileSystem.Join(snapDir, rel)
+		got, err := deps.FileSystem.HashFile(ctx, path)
+		v.Checked++
+		switch {
+		case err != nil && deps.FileSystem.IsNotExist(err):
+			v.Missing = append(v.Missing, rel)
+			bc.vlogf("   MISSING: %s", rel)
+		case err != nil || got != sums.Files[rel]:
+			v.Corrupt = append(v.Corrupt, rel)
+			bc.vlogf("   CORRUPT: %s", rel)
+		}
+	}
+}
+
+// verifyGitConnectivity runs git fsck on the snapshot .git; archives are unpacked into a
+// temporary directory under repoDir first. Encrypted snapshots cannot be checked.
+func verifyGitConnectivity(
+	ctx context.Context,
+	deps *Dependencies,
+	repoDir string,
+	snap snapshot,
+	v *SnapshotVerification,
+	bc *backupContext,
+) {
+	if scheme := snapshotEncryption(ctx, deps, snap.TimeDir); scheme != "" {
+		v.Fsck = fsckSkipped
+		bc.vlogf("   git fsck skipped: snapshot is encrypted (%s)", scheme)
+		return
+	}
+	gitDir := deps.FileSystem.Join(snap.TimeDir, ".git")
+	if snap.Archive != "" {
+		tmp := deps.FileSystem.Join(repoDir, ".verify-"+deps.FileSystem.Base(snap.TimeDir))
+		_ = deps.FileSystem.RemoveAll(ctx, tmp)
+		defer func() { _ = deps.FileSystem.RemoveAll(ctx, tmp) }()
+		if err := extractSnapshotArchive(ctx, deps, snap, tmp, nil, nil); err != nil {
+			v.Fsck, v.FsckError = fsckFailed, err.Error()
+			return
+		}
+		gitDir = deps.FileSystem.Join(tmp, ".git")
+	}
+	if _, err := deps.FileSystem.Stat(ctx, gitDir); err != nil {
+		v.Fsck, v.FsckError = fsckFailed, "snapshot has no .git directory"
+		return
+	}
+	if err := deps.Git.FsckConnectivity(ctx, gitDir); err != nil {
+		v.Fsck, v.FsckError = fsckFailed, err.Error()
+		bc.vlogf("   %v", err)
+		return
+	}
+	v.Fsck = fsckOK
+}
+
+// updateQuarantine marks a broken snapshot with .quarantine and clears the marker once it verifies.
+func updateQuarantine(
+	ctx context.Context,
+	deps *Dependencies,
+	snap snapshot,
+	v *SnapshotVerification,
+	bc *backupContext,
+) {
+	marker := deps.FileSystem.Join(snap.TimeDir, quarantineMarker)
+	if v.OK() {
+		if snap.Quarantined {
+			_ = deps.FileSystem.RemoveAll(ctx, marker)
+			bc.logf("✓ %s released from quarantine", v.ID)
+		}
+		return
+	}
+	reason := fmt.Sprintf("verified %s: %d missing, %d corrupt, git fsck %s\n",
+		time.Now().Format(time.RFC3339), len(v.Missing), len(v.Corrupt), v.Fsck)
+	if err := deps.FileSystem.WriteFile(ctx, marker, []byte(reason), 0o644); err != nil {
+		bc.warnf("quarantine %s: %v", v.ID, err)
+		v.Quarantined = snap.Quarantined
+		return
+	}
+	v.Quarantined = true
+	bc.logf("⚠ %s quarantined; rotation will keep it and not count it", v.ID)
+}
+
+// FormatVerify renders a verification report as human-readable text.
+func FormatVerify(report *VerifyReport) string {
+	var b strings.Builder
+	if report == nil || len(report.Snapshots) == 0 {
+		b.WriteString("No snapshots verified\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "%s (%d snapshots)\n", report.RepoKey, len(report.Snapshots))
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  ID\tSTATUS\tFILES\tMISSING\tCORRUPT\tFSCK")
+	for _, v := range report.Snapshots {
+		status := "ok"
+		if !v.OK() {
+			status = "BROKEN"
+		}
+		if v.Quarantined {
+			status += " (quarantined)"
+		}
+		files := fmt.Sprint(v.Checked)
+		if v.NoChecksums {
+			files = "-"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%d\t%d\t%s\n", v.ID, status, files, len(v.Missing), len(v.Corrupt), v.Fsck)
+	}
+	_ = tw.Flush()
+	for _, v := range report.Snapshots {
+		for _, rel := range v.Missing {
+			fmt.Fprintf(&b, "  missing  %s/%s\n", v.ID, rel)
+		}
+		for _, rel := range v.Corrupt {
+			fmt.Fprintf(&b, "  corrupt  %s/%s\n", v.ID, rel)
+		}
+		if v.FsckError != "" {
+			fmt.Fprintf(&b, "  fsck     %s: %s\n", v.ID, v.FsckError)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func latestSnapshotDir(t *testing.T, deps *Dependencies, repoDir string) string {
+	t.Helper()
+	snaps, err := listSnapshots(context.Background(), deps, repoDir)
+	if err != nil || len(snaps) == 0 {
+		t.Fatalf("expected snapshots in %s (%v)", repoDir, err)
+	}
+	return snaps[len(snaps)-1].TimeDir
+}
+
+func TestVerify_DetectsCorruptAndMissingFiles(t *testing.T) {
+	ctx := context.Background()
+	cfg, deps, repoKey := newRestoreFixture(t)
+	logger := newTestBackupContext(false).logger
+	opts := VerifyOptions{RepoKey: repoKey}
+
+	report, err := Verify(ctx, cfg, opts, deps, logger)
+	if err != nil {
+		t.Fatalf("fresh snapshot must verify: %v", err)
+	}
+	if v := report.Snapshots[0]; v.Checked == 0 || v.NoChecksums || v.Fsck != fsckOK {
+		t.Fatalf("unexpected verification: %+v", v)
+	}
+
+	snapDir := latestSnapshotDir(t, deps, filepath.Join(cfg.BackupDir, repoKey))
+	writeTestFile(t, filepath.Join(snapDir, "ignored.txt"), "bit rot")
+	if err := os.Remove(filepath.Join(snapDir, ".git", "index")); err != nil {
+		t.Fatal(err)
+	}
+	report, err = Verify(ctx, cfg, opts, deps, logger)
+	if !errors.Is(err, ErrVerifyFailed) {
+		t.Fatalf("expected ErrVerifyFailed, got %v", err)
+	}
+	v := report.Snapshots[0]
+	if len(v.Corrupt) != 1 || v.Corrupt[0] != "ignored.txt" || len(v.Missing) != 1 || v.Missing[0] != ".git/index" {
+		t.Fatalf("unexpected verification: %+v", v)
+	}
+	if _, err := os.Stat(filepath.Join(snapDir, quarantineMarker)); !os.IsNotExist(err) {
+		t.Fatalf("snapshot must not be quarantined without --quarantine: %v", err)
+	}
+}
+
+func TestVerify_FsckDetectsMissingObjects(t *testing.T) {
+	ctx := context.Background()
+	cfg, deps, repoKey := newRestoreFixture(t)
+	snapDir := latestSnapshotDir(t, deps, filepath.Join(cfg.BackupDir, repoKey))
+	if err := os.Remove(filepath.Join(snapDir, checksumsFileName)); err != nil {
+		t.Fatal(err)
+	}
+	objects := filepath.Join(snapDir, ".git", "objects")
+	entries, err := os.ReadDir(objects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if len(e.Name()) == 2 {
+			if err := os.RemoveAll(filepath.Join(objects, e.Name())); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	report, err := Verify(ctx, cfg, VerifyOptions{RepoKey: repoKey}, deps, newTestBackupContext(false).logger)
+	if !errors.Is(err, ErrVerifyFailed) {
+		t.Fatalf("expected ErrVerifyFailed, got %v", err)
+	}
+	if v := report.Snapshots[0]; !v.NoChecksums || v.Fsck != fsckFailed || v.FsckError == "" {
+		t.Fatalf("unexpected verification: %+v", v)
+	}
+}
+
+func TestVerify_QuarantineKeepsLastGoodSnapshot(t *testing.T) {
+	ctx := context.Background()
+	cfg, deps, repoKey := newRestoreFixture(t)
+	repoDir := filepath.Join(cfg.BackupDir, repoKey)
+	good := latestSnapshotDir(t, deps, repoDir)
+	repoRoot := t.TempDir()
+	runGitForTest(t, repoRoot, "init", "-q")
+	writeTestFile(t, filepath.Join(repoRoot, "tracked.txt"), "tracked")
+	runGitForTest(t, repoRoot, "add", "tracked.txt")
+	runGitForTest(t, repoRoot, "commit", "-q", "-m", "init")
+	if _, err := handleBackupFlow(ctx, cfg, deps, repoRoot, repoDir, newTestBackupContext(false)); err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	broken := latestSnapshotDir(t, deps, repoDir)
+	head := filepath.Join(broken, ".git", "HEAD")
+	original, err := os.ReadFile(head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, head, "damaged")
+
+	logger := newTestBackupContext(false).logger
+	opts := VerifyOptions{RepoKey: repoKey, All: true, Quarantine: true}
+	report, err := Verify(ctx, cfg, opts, deps, logger)
+	if !errors.Is(err, ErrVerifyFailed) {
+		t.Fatalf("expected ErrVerifyFailed, got %v", err)
+	}
+	if len(report.Snapshots) != 2 || report.Snapshots[0].Quarantined || !report.Snapshots[1].Quarantined {
+		t.Fatalf("expected only the broken snapshot to be quarantined: %+v", report.Snapshots)
+	}
+
+	cfg.KeepCount = 1
+	rotateRepo(ctx, deps, repoDir, cfg, false, newTestBackupContext(false))
+	for _, dir := range []string{good, broken} {
+		if _, err := os.Stat(dir); err != nil {
+			t.Fatalf("rotation removed %s: %v", dir, err)
+		}
+	}
+
+	writeTestFile(t, head, string(original))
+	if _, err := Verify(ctx, cfg, opts, deps, logger); err != nil {
+		t.Fatalf("repaired snapshot must verify: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(broken, quarantineMarker)); !os.IsNotExist(err) {
+		t.Fatalf("quarantine marker must be cleared: %v", err)
+	}
+}
+
+func TestFindSnapshot_SkipsQuarantined(t *testing.T) {
+	ctx := context.Background()
+	deps := &Dependencies{FileSystem: newTestFileSystem()}
+	repoDir := t.TempDir()
+	for _, id := range []string{"2023-01-01/000000", "2023-01-02/000000"} {
+		writeTestFile(t, filepath.Join(repoDir, id, ".done"), "")
+	}
+	writeTestFile(t, filepath.Join(repoDir, "2023-01-02", "000000", quarantineMarker), "")
+
+	snap, err := findSnapshot(ctx, deps, repoDir, "")
+	if err != nil || snapshotID(deps.FileSystem, repoDir, snap) != "2023-01-01/000000" {
+		t.Fatalf("expected the newest good snapshot, got %+v (%v)", snap, err)
+	}
+	snap, err = findSnapshot(ctx, deps, repoDir, "2023-01-02/000000")
+	if err != nil || !snap.Quarantined {
+		t.Fatalf("a named quarantined snapshot must still resolve: %+v (%v)", snap, err)
+	}
+
+	writeTestFile(t, filepath.Join(repoDir, "2023-01-01", "000000", quarantineMarker), "")
+	if _, err := findSnapshot(ctx, deps, repoDir, ""); !errors.Is(err, ErrUsage) ||
+		!strings.Contains(err.Error(), "quarantined") {
+		t.Fatalf("expected a usage error naming quarantine, got %v", err)
+	}
+}
@@ -0,0 +1,259 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWatchDebounceSeconds    = 30
+	defaultWatchMinIntervalMinutes = 5
+	defaultWatchKeepCount          = 24
+	defaultWatchKeepHours          = 48
+)
+
+// watchTrigger tags snapshots taken by devback watch in the manifest.
+const watchTrigger = "watch"
+
+//nolint:gochecknoglobals // shortened in tests so debounce periods take milliseconds.
+var watchTimeUnit = time.Second
+
+// WatchOptions describes which repositories devback watch observes.
+type WatchOptions struct {
+	Repos   []string
+	HomeDir string
+}
+
+// Watch observes repositories for filesystem changes and takes a snapshot tagged trigger=watch once
+// a repository has been quiet for the debounce period. Watch snapshots always capture modified
+// tracked files (as patches unless backup.tracked_changes is "copy") and hardlink unchanged files;
+// encrypted and archive snapshots cannot be deduplicated, which is logged as a warning per repository.
+// Without opts.Repos every registered repository with backup enabled is watched. Snapshots go
+// through the regular lock and rotation; a repository whose lock is held is retried after the next
+// quiet period. Watch runs until ctx is cancelled.
+func Watch(ctx context.Context, cfg *Config, opts WatchOptions, deps *Dependencies, logger *slog.Logger) error {
+	if logger == nil {
+		panic("logger is required")
+	}
+	if err := validateBackupDependencies(ctx, cfg, deps, logger); err != nil {
+		return err
+	}
+	if deps.Watcher == nil {
+		return fmt.Errorf("watcher adapter not available: %w", ErrCritical)
+	}
+	repos, err := resolveWatchRepos(ctx, deps, opts)
+	if err != nil {
+		return err
+	}
+
+	debounce := time.Duration(cfg.WatchDebounce) * watchTimeUnit
+	if cfg.WatchDebounce <= 0 {
+		debounce = defaultWatchDebounceSeconds * watchTimeUnit
+	}
+	minInterval := time.Duration(max(cfg.WatchMinInterval, 0)*60) * watchTimeUnit
+	watchCfg := *cfg
+	watchCfg.Trigger = watchTrigger
+	// Edits to tracked files are what `git checkout .` loses; capture them as patches even when
+	// backup.tracked_changes is off. Everything else rarely changes between two watch snapshots,
+	// so it is hardlinked from the previous snapshot instead of copied again.
+	if watchCfg.TrackedChanges == "" || watchCfg.TrackedChanges == TrackedChangesOff {
+		watchCfg.TrackedChanges = TrackedChangesPatch
+	}
+	watchCfg.Dedup = true
+	bc := newBackupContext(logger, cfg.Verbose)
+
+	var wg sync.WaitGroup
+	started := 0
+	for _, root := range repos {
+		ignore, err := newIgnoreMatcher(ctx, deps, root, cfg.GlobalIgnoreFile, bc)
+		if err != nil {
+			bc.warnf("watch: read .devbackignore in %s: %v", root, err)
+		}
+		warnWatchWithoutDedup(ctx, &watchCfg, deps, root, logger)
+		events, err := deps.Watcher.Watch(ctx, root, watchSkipFunc(ctx, deps.FileSystem, root, cfg.BackupDir, ignore))
+		if err != nil {
+			logger.WarnContext(ctx, "Cannot watch repository", "repo", root, "error", err)
+			continue
+		}
+		logger.InfoContext(ctx, "Watching repository", "repo", root)
+		started++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			watchRepo(ctx, &watchCfg, deps, root, events, debounce, minInterval, logger)
+		}()
+	}
+	if started == 0 {
+		return fmt.Errorf("no repository could be watched: %w", ErrCritical)
+	}
+	wg.Wait()
+	if ctx.Err() == nil {
+		return fmt.Errorf("watching ended unexpectedly: %w", ErrCritical)
+	}
+	logger.InfoContext(ctx, "Watch stopped")
+	return nil
+}
+
+// watchRepo debounces events of one repository and snapshots it after debounce without changes,
+// never sooner than minInterval after its previous watch snapshot.
+func watchRepo(
+	ctx context.Context,
+	cfg *Config,
+	deps *Dependencies,
+	root string,
+	events <-chan WatchEvent,
+	debounce, minInterval time.Duration,
+	logger *slog.Logger,
+) {
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	var last time.Time
+	pending := false
+	schedule := func() {
+		wait := debounce
+		if !last.IsZero() {
+			wait = max(wait, time.Until(last.Add(minInterval)))
+		}
+		timer.Reset(wait)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				if ctx.Err() == nil {
+					logger.WarnContext(ctx, "Watch of repository ended", "repo", root)
+				}
+				return
+			}
+			if !pending {
+				logger.DebugContext(ctx, "Change detected", "repo", root, "path", ev.Path, "overflow", ev.Overflow)
+			}
+			pending = true
+			schedule()
+		case <-timer.C:
+			res := sweepRepo(ctx, cfg, deps, root, logger)
+			switch res.Status {
+			case SweepStatusInterrupted:
+				return
+			case SweepStatusLocked:
+				schedule()
+				continue
+			case SweepStatusFailed:
+				logger.WarnContext(ctx, "Watch snapshot failed", "repo", root, "error", res.Err)
+			default:
+				files := 0
+				if res.Result != nil {
+					files = res.Result.TotalFiles
+				}
+				logger.InfoContext(ctx, "Watch snapshot taken", "repo", root, "status", res.Status,
+					"files", files, "duration", res.Duration.Round(time.Millisecond))
+			}
+			last = time.Now()
+			pending = false
+		}
+	}
+}
+
+// warnWatchWithoutDedup warns when the effective settings of root keep watch snapshots from
+// hardlinking unchanged files: encrypted and archive snapshots are never deduplicated, so every
+// watch snapshot of such a repository is a full copy or archive.
+func warnWatchWithoutDedup(ctx context.Context, cfg *Config, deps *Dependencies, root string, logger *slog.Logger) {
+	// Config errors and .devback.toml warnings are reported by each snapshot; stay quiet here.
+	repoCfg, _, err := resolveRepoConfig(ctx, cfg, deps, root, newBackupContext(slog.New(slog.DiscardHandler), false))
+	if err != nil {
+		return
+	}
+	var reason string
+	switch {
+	case repoCfg.Encryption.Enabled:
+		reason = "encryption is enabled"
+	case isArchiveFormat(repoCfg.Format):
+		reason = fmt.Sprintf("format is %q", repoCfg.Format)
+	default:
+		return
+	}
+	logger.WarnContext(ctx, "Watch snapshots are full copies: deduplication does not apply",
+		"repo", root, "reason", reason)
+}
+
+// resolveWatchRepos returns the explicitly given repositories, or every registered repository that
+// still exists and has backup enabled.
+func resolveWatchRepos(ctx context.Context, deps *Dependencies, opts WatchOptions) ([]string, error) {
+	var repos []string
+	if len(opts.Repos) > 0 {
+		for _, p := range opts.Repos {
+			abs, err := deps.FileSystem.Abs(ctx, p)
+			if err != nil {
+				return nil, fmt.Errorf("resolve %s: %w", p, ErrUsage)
+			}
+			if err := ensureGitRepo(ctx, deps, abs); err != nil {
+				return nil, fmt.Errorf("%s is not a git repository: %w", p, ErrUsage)
+			}
+			repos = append(repos, abs)
+		}
+		return repos, nil
+	}
+
+	homeDir := strings.TrimSpace(opts.HomeDir)
+	if homeDir == "" {
+		return nil, fmt.Errorf("home directory is empty: %w", ErrCritical)
+	}
+	entries, err := loadRegistry(ctx, deps.FileSystem, normalizePath(deps.FileSystem, defaultRegistryPath, homeDir))
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if exists, err := pathExists(ctx, deps.FileSystem, entry.Path); err != nil || !exists {
+			continue
+		}
+		if ensureGitRepo(ctx, deps, entry.Path) != nil || !sweepBackupEnabled(ctx, deps.Git, entry.Path) {
+			continue
+		}
+		repos = append(repos, entry.Path)
+	}
+	if len(repos) == 0 {
+		return nil, fmt.Errorf("no repositories to watch (pass repository paths or run devback setup): %w", ErrUsage)
+	}
+	return repos, nil
+}
+
+// watchSkipFunc ignores git metadata, the backup directory and paths excluded by .devbackignore, so
+// neither commits nor the snapshots themselves trigger a watch snapshot.
+func watchSkipFunc(
+	ctx context.Context,
+	fs FileSystemPort,
+	root,
+	backupDir string,
+	ignore *ignoreMatcher,
+) func(rel string) bool {
+	backupRel := ""
+	if backupDir != "" {
+		rel, err := fs.Rel(root, backupDir)
+		rel = strings.ReplaceAll(rel, string(fs.PathSeparator()), "/")
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+			backupRel = rel
+		}
+	}
+	return func(rel string) bool {
+		if path.Base(rel) == ".git" || strings.HasPrefix(rel, ".git/") {
+			return true
+		}
+		if backupRel != "" && (rel == backupRel || strings.HasPrefix(rel, backupRel+"/")) {
+			return true
+		}
+		// The watcher does not say whether rel is a directory; directory-only patterns need to know.
+		info, err := fs.Lstat(ctx, fs.Join(root, rel))
+		skip, _ := ignore.excluded(ctx, rel, err == nil && info.IsDir())
+		return skip
+	}
+}
@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeWatcher struct {
+	events chan WatchEvent
+	skip   func(rel string) bool
+}
+
+func (f *fakeWatcher) Watch(ctx context.Context, root string, skip func(rel string) bool) (<-chan WatchEvent, error) {
+	f.skip = skip
+	return f.events, nil
+}
+
+func watchSnapshotDirs(t *testing.T, backupDir string) []string {
+	t.Helper()
+	dirs, err := filepath.Glob(filepath.Join(backupDir, "*", "*", "*", ".done"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dirs
+}
+
+func TestWatch_DebouncesChangesIntoOneTaggedSnapshot(t *testing.T) {
+	prevUnit := watchTimeUnit
+	watchTimeUnit = time.Millisecond
+	t.Cleanup(func() { watchTimeUnit = prevUnit })
+
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	initSweepRepo(t, repo, true)
+	writeTestFile(t, filepath.Join(repo, "tracked.txt"), "edited")
+	backupDir := filepath.Join(root, "backups")
+	cfg := &Config{BackupDir: backupDir, RepoKeyStyle: repoKeyStyleNameHash, NoSize: true, WatchDebounce: 50}
+	watcher := &fakeWatcher{events: make(chan WatchEvent)}
+	deps := &Dependencies{
+		FileSystem: newTestFileSystem(), Git: newTestGitAdapter(), Lock: &mockLock{}, Process: &mockProcess{},
+		Watcher: watcher,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- Watch(ctx, cfg, WatchOptions{Repos: []string{repo}}, deps, newTestBackupContext(false).logger)
+	}()
+
+	for range 5 {
+		watcher.events <- WatchEvent{Path: filepath.Join(repo, "draft.txt")}
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for len(watchSnapshotDirs(t, backupDir)) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no watch snapshot taken")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(200 * time.Millisecond)
+	snaps := watchSnapshotDirs(t, backupDir)
+	if len(snaps) != 1 {
+		t.Fatalf("expected changes to be debounced into one snapshot, got %d", len(snaps))
+	}
+
+	// #nosec G304 -- test paths are controlled by the test harness.
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(snaps[0]), manifestFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m SnapshotManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Trigger != watchTrigger {
+		t.Fatalf("expected trigger %q, got %q", watchTrigger, m.Trigger)
+	}
+	// #nosec G304 -- test paths are controlled by the test harness.
+	patch, err := os.ReadFile(filepath.Join(filepath.Dir(snaps[0]), trackedChangesDir, worktreePatchName))
+	if err != nil || !strings.Contains(string(patch), "+edited") {
+		t.Fatalf("expected the edit of a tracked file as a patch, got %q (%v)", patch, err)
+	}
+	if !watcher.skip(".git/index") || !watcher.skip("vendor/.git") || watcher.skip("src/main.go") {
+		t.Fatal("unexpected skip rules")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("expected clean stop, got %v", err)
+	}
+}
+
+func TestWatch_RequiresRepositories(t *testing.T) {
+	deps := &Dependencies{
+		FileSystem: newTestFileSystem(), Git: newTestGitAdapter(), Lock: &mockLock{}, Process: &mockProcess{},
+		Watcher: &fakeWatcher{},
+	}
+	err := Watch(context.Background(), &Config{BackupDir: t.TempDir()}, WatchOptions{HomeDir: t.TempDir()}, deps,
+		newTestBackupContext(false).logger)
+	if !errors.Is(err, ErrUsage) {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+}
+
+func TestWatch_WarnsWhenArchiveSnapshotsCannotBeDeduplicated(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	initSweepRepo(t, repo, true)
+	cfg := &Config{
+		BackupDir: filepath.Join(root, "backups"), RepoKeyStyle: repoKeyStyleNameHash, Format: SnapshotFormatTar,
+	}
+	// A closed event channel ends the watch right away, so Watch returns without goroutines racing on logs.
+	events := make(chan WatchEvent)
+	close(events)
+	deps := &Dependencies{
+		FileSystem: newTestFileSystem(), Git: newTestGitAdapter(), Lock: &mockLock{}, Process: &mockProcess{},
+		Watcher: &fakeWatcher{events: events},
+	}
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	err := Watch(context.Background(), cfg, WatchOptions{Repos: []string{repo}}, deps, logger)
+	if !errors.Is(err, ErrCritical) {
+		t.Fatalf("expected the ended watch to be reported, got %v", err)
+	}
+	if !strings.Contains(logs.String(), "deduplication does not apply") ||
+		!strings.Contains(logs.String(), `reason="format is \"tar\""`) {
+		t.Fatalf("expected a warning about full archive snapshots, got:\n%s", logs.String())
+	}
+}